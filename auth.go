@@ -9,6 +9,8 @@
 package mysql
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha1"
@@ -19,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"filippo.io/edwards25519"
 )
@@ -275,10 +278,19 @@ func (mc *mysqlConn) sendEncryptedPassword(seed []byte, pub *rsa.PublicKey) erro
 	return mc.writeAuthSwitchPacket(enc)
 }
 
-func (mc *mysqlConn) auth(authData []byte, plugin string) ([]byte, error) {
+// checkAuthTransport returns ErrInsecureAuthTransport if credentials must
+// only be sent over TLS or unix sockets and the connection uses neither.
+func (mc *mysqlConn) checkAuthTransport() error {
+	if mc.cfg.requireSecureAuthTransport && mc.cfg.TLS == nil && mc.cfg.Net != "unix" {
+		return ErrInsecureAuthTransport
+	}
+	return nil
+}
 
-	// DEBUG
-	// fmt.Printf("[DEBUG-auth.go] : Plugin used: %s", plugin)
+func (mc *mysqlConn) auth(authData []byte, plugin string) ([]byte, error) {
+	if err := mc.checkFIPSPlugin(plugin); err != nil {
+		return nil, err
+	}
 
 	switch plugin {
 	case "caching_sha2_password":
@@ -302,6 +314,9 @@ func (mc *mysqlConn) auth(authData []byte, plugin string) ([]byte, error) {
 		if !mc.cfg.AllowCleartextPasswords {
 			return nil, ErrCleartextPassword
 		}
+		if err := mc.checkAuthTransport(); err != nil {
+			return nil, err
+		}
 		// http://dev.mysql.com/doc/refman/5.7/en/cleartext-authentication-plugin.html
 		// http://dev.mysql.com/doc/refman/5.7/en/pam-authentication-plugin.html
 		return append([]byte(mc.cfg.Passwd), 0), nil
@@ -326,6 +341,12 @@ func (mc *mysqlConn) auth(authData []byte, plugin string) ([]byte, error) {
 			return append([]byte(mc.cfg.Passwd), 0), nil
 		}
 
+		if err := mc.checkAuthTransport(); err != nil {
+			return nil, err
+		}
+		if err := mc.checkFIPSTransport(plugin); err != nil {
+			return nil, err
+		}
 		pubKey := mc.cfg.pubKey
 		if pubKey == nil {
 			// request public key from server
@@ -344,14 +365,7 @@ func (mc *mysqlConn) auth(authData []byte, plugin string) ([]byte, error) {
 
 	// Add support authentication_openid_connect Plugin
 	case "authentication_openid_connect_client":
-		token, ok := mc.cfg.Params["authentication_openid_connect_client_id_token_file"]
-		if !ok || token == "" {
-			return nil, fmt.Errorf("OIDC token not provided")
-		}
-
-		// Debug
-		//fmt.Printf("[DEBUG-auth.go] Sending the OID token : %s\n", token)
-		return []byte(token), nil
+		return mc.oidcAuthResponse(nil)
 
 	default:
 		mc.log("unknown auth plugin:", plugin)
@@ -359,6 +373,71 @@ func (mc *mysqlConn) auth(authData []byte, plugin string) ([]byte, error) {
 	}
 }
 
+// oidcAuthResponse reads the ID token from the file configured with
+// authentication_openid_connect_client_id_token_file or the environment
+// variable configured with authentication_openid_connect_client_id_token_env
+// and returns the auth response of the OpenID Connect client plugin to the
+// data sent by the server, nil in the handshake response.
+func (mc *mysqlConn) oidcAuthResponse(serverData []byte) ([]byte, error) {
+	if err := mc.checkAuthTransport(); err != nil {
+		return nil, err
+	}
+	framing, err := oidcResponseFraming(mc.cfg.oidcFraming, serverData)
+	if err != nil {
+		return nil, err
+	}
+	src, err := mc.cfg.oidcTokenSource().resolve(mc.authContext())
+	if errors.Is(err, ErrOIDCTokenExpiring) {
+		// the subject token of a token exchange
+		mc.oidcTokenEvent(OIDCTokenExpired, src, time.Time{}, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	src.fetched = func(token string, err error) {
+		if err != nil {
+			mc.oidcTokenEvent(OIDCTokenRefreshFailed, src, time.Time{}, err)
+		} else {
+			mc.oidcTokenEvent(OIDCTokenRefreshed, src, jwtExpiry(token), nil)
+		}
+	}
+	jwtToken, err := src.read(mc.authContext())
+	if errors.Is(err, ErrOIDCTokenExpiring) {
+		mc.oidcTokenEvent(OIDCTokenExpired, src, time.Time{}, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	expiry := jwtExpiry(jwtToken)
+	mc.oidcTokenEvent(OIDCTokenLoaded, src, expiry, nil)
+	if !expiry.IsZero() && time.Now().Add(mc.cfg.oidcClockSkew).After(expiry) {
+		// sent anyway, the server decides
+		mc.oidcTokenEvent(OIDCTokenExpired, src, expiry, nil)
+	}
+	if verify := mc.cfg.oidcBinding(); verify != nil {
+		if err := verify(jwtToken, mc.clientCert); err != nil {
+			return nil, err
+		}
+	}
+	mc.oidcToken = newOIDCToken(src, jwtToken)
+
+	if framing == OIDCFramingRaw {
+		return []byte(jwtToken), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(oidcCapabilityIDToken)
+	writeLengthEncodedString(&buf, []byte(jwtToken))
+	return buf.Bytes(), nil
+}
+
+// authContext returns the context of the connect operation, while connecting.
+func (mc *mysqlConn) authContext() context.Context {
+	if mc.hookCtx != nil {
+		return mc.hookCtx
+	}
+	return context.Background()
+}
+
 func (mc *mysqlConn) handleAuthResult(oldAuthData []byte, plugin string) error {
 	// Read Result Packet
 	authData, newPlugin, err := mc.readAuthResult()
@@ -368,6 +447,7 @@ func (mc *mysqlConn) handleAuthResult(oldAuthData []byte, plugin string) error {
 
 	// handle auth plugin switch, if requested
 	if newPlugin != "" {
+		switchData := authData
 		// If CLIENT_PLUGIN_AUTH capability is not supported, no new cipher is
 		// sent and we have to keep using the cipher sent in the init packet.
 		if authData == nil {
@@ -379,7 +459,12 @@ func (mc *mysqlConn) handleAuthResult(oldAuthData []byte, plugin string) error {
 
 		plugin = newPlugin
 
-		authResp, err := mc.auth(authData, plugin)
+		var authResp []byte
+		if isOIDCPlugin(plugin) {
+			authResp, err = mc.oidcAuthResponse(switchData)
+		} else {
+			authResp, err = mc.auth(authData, plugin)
+		}
 		if err != nil {
 			return err
 		}
@@ -398,6 +483,7 @@ func (mc *mysqlConn) handleAuthResult(oldAuthData []byte, plugin string) error {
 			return ErrMalformPkt
 		}
 	}
+	mc.authPlugin = plugin
 
 	switch plugin {
 
@@ -421,6 +507,12 @@ func (mc *mysqlConn) handleAuthResult(oldAuthData []byte, plugin string) error {
 						return err
 					}
 				} else {
+					if err := mc.checkAuthTransport(); err != nil {
+						return err
+					}
+					if err := mc.checkFIPSTransport(plugin); err != nil {
+						return err
+					}
 					pubKey := mc.cfg.pubKey
 					if pubKey == nil {
 						// request public key from server
@@ -492,31 +584,18 @@ func (mc *mysqlConn) handleAuthResult(oldAuthData []byte, plugin string) error {
 			return mc.resultUnchanged().readResultOK()
 		}
 
-	// Add support authentication_openid_connect Plugin
-	case "authentication_openid_connect":
-		// Recover the OIDC token from the configuration entered in the DSN
-		token, ok := mc.cfg.Params["authentication_openid_connect_client_id_token_file"]
-		if !ok {
-			return errors.New("missing required param 'authentication_openid_connect_client_id_token_file'")
+	case "authentication_openid_connect_client", "authentication_openid_connect":
+		if authData == nil {
+			return nil // auth successful
 		}
-		// DEBUG
-		//fmt.Printf("[DEBUG-auth.go] OIDC Token: %s\n", token)
-
-		// Send token as authentication response
-		var packet []byte
-		packet = append(packet, byte(1)) // capability bit
-
-		// Add the encoded token length MySQL-style
-		packet = appendLengthEncodedInteger(packet, uint64(len(token)))
-
-		// Add token
-		packet = append(packet, []byte(token)...)
-
-		// Send authentication package
-		if err := mc.writePacket(packet); err != nil {
-			return fmt.Errorf("failed to send OIDC token with capability: %w", err)
+		// the server requests the ID token again with more data
+		authResp, err := mc.oidcAuthResponse(authData)
+		if err != nil {
+			return err
+		}
+		if err := mc.writeAuthSwitchPacket(authResp); err != nil {
+			return err
 		}
-		// Read final server result (auth OK or error)
 		return mc.resultUnchanged().readResultOK()
 
 	default:
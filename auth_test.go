@@ -14,7 +14,11 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -342,6 +346,139 @@ func TestAuthFastCleartextPasswordNotAllowed(t *testing.T) {
 	}
 }
 
+func TestAuthFastInsecureTransport(t *testing.T) {
+	_, mc := newRWMockConn(1)
+	mc.cfg.User = "root"
+	mc.cfg.Passwd = "secret"
+	mc.cfg.AllowCleartextPasswords = true
+	mc.cfg.requireSecureAuthTransport = true
+
+	authData := []byte{70, 114, 92, 94, 1, 38, 11, 116, 63, 114, 23, 101, 126,
+		103, 26, 95, 81, 17, 24, 21}
+
+	for _, plugin := range []string{"mysql_clear_password", "sha256_password", "authentication_openid_connect_client"} {
+		if _, err := mc.auth(authData, plugin); err != ErrInsecureAuthTransport {
+			t.Errorf("%s: expected ErrInsecureAuthTransport, got %v", plugin, err)
+		}
+	}
+	if _, err := mc.auth(authData, "mysql_native_password"); err != nil {
+		t.Errorf("expected hashed passwords to be sent, got %v", err)
+	}
+
+	mc.cfg.Net = "unix"
+	if _, err := mc.auth(authData, "mysql_clear_password"); err != nil {
+		t.Errorf("expected cleartext passwords to be sent over unix sockets, got %v", err)
+	}
+	mc.cfg.Net = "tcp"
+	mc.cfg.TLS = &tls.Config{InsecureSkipVerify: true}
+	if _, err := mc.auth(authData, "mysql_clear_password"); err != nil {
+		t.Errorf("expected cleartext passwords to be sent over TLS, got %v", err)
+	}
+}
+
+func TestAuthClientPluginInsecureTransport(t *testing.T) {
+	conn, mc := newRWMockConn(1)
+	mc.cfg.User = "root"
+	mc.cfg.requireSecureAuthTransport = true
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("header.payload.signature"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	mc.cfg.Params = map[string]string{
+		"auth_client_plugin": "authentication_openid_connect_client",
+		"authentication_openid_connect_client_id_token_file": tokenFile,
+	}
+
+	// The server announces a password plugin, the configured client plugin
+	// still sends the ID token with the handshake response.
+	if err := mc.writeHandshakeResponsePacket(nil, "mysql_native_password"); err != ErrInsecureAuthTransport {
+		t.Errorf("expected ErrInsecureAuthTransport, got %v", err)
+	}
+	if len(conn.written) != 0 {
+		t.Errorf("expected nothing to be sent, got %v", conn.written)
+	}
+}
+
+func TestAuthFastCachingSHA256PasswordFullRSAInsecureTransport(t *testing.T) {
+	conn, mc := newRWMockConn(1)
+	mc.cfg.User = "root"
+	mc.cfg.Passwd = "secret"
+	mc.cfg.requireSecureAuthTransport = true
+
+	authData := []byte{6, 81, 96, 114, 14, 42, 50, 30, 76, 47, 1, 95, 126, 81,
+		62, 94, 83, 80, 52, 85}
+
+	// auth response to the handshake response packet
+	mc.sequence = 2
+	conn.data = []byte{
+		2, 0, 0, 2, 1, 4, // Perform Full Authentication
+	}
+	conn.maxReads = 1
+
+	if err := mc.handleAuthResult(authData, "caching_sha2_password"); err != ErrInsecureAuthTransport {
+		t.Errorf("expected ErrInsecureAuthTransport, got %v", err)
+	}
+	if len(conn.written) != 0 {
+		t.Errorf("expected nothing to be sent, got %v", conn.written)
+	}
+}
+
+func TestAuthFIPS(t *testing.T) {
+	_, mc := newRWMockConn(1)
+	mc.cfg.User = "root"
+	mc.cfg.Passwd = "secret"
+	mc.cfg.AllowOldPasswords = true
+	mc.cfg.fips = true
+
+	authData := []byte{70, 114, 92, 94, 1, 38, 11, 116, 63, 114, 23, 101, 126,
+		103, 26, 95, 81, 17, 24, 21, 70, 114, 92, 94, 1, 38, 11, 116, 63, 114, 23, 101}
+
+	for _, plugin := range []string{"mysql_old_password", "mysql_native_password", "client_ed25519", "sha256_password"} {
+		_, err := mc.auth(authData, plugin)
+		if !errors.Is(err, ErrFIPSAuth) {
+			t.Errorf("%s: expected ErrFIPSAuth, got %v", plugin, err)
+		} else if !strings.Contains(err.Error(), plugin) {
+			t.Errorf("%s: expected the plugin in the error, got %v", plugin, err)
+		}
+	}
+	if _, err := mc.auth(authData, "caching_sha2_password"); err != nil {
+		t.Errorf("expected caching_sha2_password to be allowed, got %v", err)
+	}
+	if plugin := mc.cfg.defaultAuthPlugin(); plugin != "caching_sha2_password" {
+		t.Errorf("expected caching_sha2_password as default plugin, got %q", plugin)
+	}
+
+	mc.cfg.TLS = &tls.Config{InsecureSkipVerify: true}
+	if _, err := mc.auth(authData, "sha256_password"); err != nil {
+		t.Errorf("expected sha256_password to be allowed over TLS, got %v", err)
+	}
+}
+
+func TestAuthFastCachingSHA256PasswordFullRSAFIPS(t *testing.T) {
+	conn, mc := newRWMockConn(1)
+	mc.cfg.User = "root"
+	mc.cfg.Passwd = "secret"
+	mc.cfg.fips = true
+
+	authData := []byte{6, 81, 96, 114, 14, 42, 50, 30, 76, 47, 1, 95, 126, 81,
+		62, 94, 83, 80, 52, 85}
+
+	// auth response to the handshake response packet
+	mc.sequence = 2
+	conn.data = []byte{
+		2, 0, 0, 2, 1, 4, // Perform Full Authentication
+	}
+	conn.maxReads = 1
+
+	if err := mc.handleAuthResult(authData, "caching_sha2_password"); !errors.Is(err, ErrFIPSAuth) {
+		t.Errorf("expected ErrFIPSAuth, got %v", err)
+	}
+	if len(conn.written) != 0 {
+		t.Errorf("expected nothing to be sent, got %v", conn.written)
+	}
+}
+
 func TestAuthFastCleartextPassword(t *testing.T) {
 	conn, mc := newRWMockConn(1)
 	mc.cfg.User = "root"
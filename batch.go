@@ -0,0 +1,200 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Batch is a list of statements executed together by BatchExecer.ExecBatch.
+type Batch struct {
+	stmts []batchStmt
+}
+
+type batchStmt struct {
+	query string
+	args  []any
+}
+
+// Queue appends a statement to the batch. Its arguments are interpolated
+// into the query on the client, as with InterpolateParams.
+func (b *Batch) Queue(query string, args ...any) {
+	b.stmts = append(b.stmts, batchStmt{query: query, args: args})
+}
+
+// Len returns the number of statements in the batch.
+func (b *Batch) Len() int {
+	return len(b.stmts)
+}
+
+// BatchExecer is implemented by the connections of this driver.
+//
+// This is accessible through sql.Conn.Raw():
+//
+//	var b mysql.Batch
+//	b.Queue("UPDATE accounts SET balance = balance - ? WHERE id = ?", 100, 1)
+//	b.Queue("UPDATE accounts SET balance = balance + ? WHERE id = ?", 100, 2)
+//	err := conn.Raw(func(driverConn any) error {
+//		res, err := driverConn.(mysql.BatchExecer).ExecBatch(ctx, &b)
+//		if err != nil {
+//			return err
+//		}
+//		for _, r := range res.(mysql.Result).StatementResults() {
+//			...
+//		}
+//		return nil
+//	})
+type BatchExecer interface {
+	// ExecBatch executes the statements of b in order and returns a Result
+	// with the result of each statement.
+	//
+	// With multiStatements, the statements are sent as multi-statement
+	// queries, split only to stay below max_allowed_packet, which saves a
+	// round trip for each of them. Otherwise they are executed one by one.
+	// MariaDB's COM_MULTI isn't used, as MariaDB deprecated it in 10.6 and
+	// multi-statement queries save the same round trips.
+	//
+	// The statements after a failing one are not executed. If a statement
+	// fails on the server, the error is a *BatchError.
+	ExecBatch(ctx context.Context, b *Batch) (driver.Result, error)
+}
+
+// BatchError is returned by BatchExecer.ExecBatch when a statement of the
+// batch fails. The statements before it were executed.
+type BatchError struct {
+	Index  int    // index of the failing statement in the batch
+	Result Result // results of the statements before it
+	Err    error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch statement %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+func (mc *mysqlConn) ExecBatch(ctx context.Context, b *Batch) (driver.Result, error) {
+	if b.Len() == 0 {
+		return nil, errors.New("batch is empty")
+	}
+	queries := make([]string, len(b.stmts))
+	for i, s := range b.stmts {
+		query, _, err := mc.interceptQuery(ctx, s.query, nil)
+		if err != nil {
+			return nil, err
+		}
+		queries[i] = query
+	}
+
+	if err := mc.watchCancel(ctx); err != nil {
+		return nil, err
+	}
+	defer mc.finish()
+	defer mc.startStatementTimer()()
+
+	if err := mc.overrideConfig(ctx); err != nil {
+		return nil, err
+	}
+	if err := mc.switchTenant(ctx); err != nil {
+		return nil, err
+	}
+	for i, s := range b.stmts {
+		if len(s.args) == 0 {
+			continue
+		}
+		query, err := mc.interpolateBatchStmt(queries[i], s.args)
+		if err != nil {
+			return nil, fmt.Errorf("batch statement %d: %w", i, err)
+		}
+		queries[i] = query
+	}
+
+	ctx, span := mc.startHook(ctx, HookInfo{Op: HookExec, Query: strings.Join(queries, "; ")})
+	res, err := mc.execBatch(queries)
+	if err == nil {
+		recordGTID(ctx, res)
+	}
+	return res, span.endExec(res, err)
+}
+
+// interpolateBatchStmt interpolates the arguments of a statement of a batch.
+func (mc *mysqlConn) interpolateBatchStmt(query string, args []any) (string, error) {
+	if unsafeCollations[mc.cfg.Collation] {
+		return "", errInvalidDSNUnsafeCollation
+	}
+	dargs := make([]driver.Value, len(args))
+	for i, arg := range args {
+		v, err := converter{}.ConvertValue(arg)
+		if err != nil {
+			return "", fmt.Errorf("parameter %d: %w", i, err)
+		}
+		dargs[i] = v
+	}
+	query, err := mc.interpolateParams(query, dargs)
+	if err == driver.ErrSkip {
+		return "", errors.New("the arguments can not be interpolated into the query")
+	}
+	return query, err
+}
+
+// execBatch executes the queries of a batch, as multi-statement queries if
+// possible.
+func (mc *mysqlConn) execBatch(queries []string) (driver.Result, error) {
+	total := &mysqlResult{}
+	for start := 0; start < len(queries); {
+		end := start + 1
+		query := queries[start]
+		if mc.capabilities&clientMultiStatements != 0 {
+			query = strings.TrimRight(query, "; \t\r\n")
+			var sb strings.Builder
+			sb.WriteString(query)
+			for ; end < len(queries); end++ {
+				next := strings.TrimRight(queries[end], "; \t\r\n")
+				if sb.Len()+2+len(next)+1 > mc.maxAllowedPacket {
+					break
+				}
+				// the newline ends a trailing comment
+				sb.WriteString("\n;")
+				sb.WriteString(next)
+			}
+			query = sb.String()
+		}
+
+		mc.clearResult()
+		err := mc.exec(query)
+		executed := len(mc.result.affectedRows)
+		if err != nil && executed > 0 {
+			// the result of the failing statement was added before its error
+			executed--
+		}
+		total.affectedRows = append(total.affectedRows, mc.result.affectedRows[:executed]...)
+		total.insertIds = append(total.insertIds, mc.result.insertIds[:executed]...)
+		total.warningCounts = append(total.warningCounts, mc.result.warningCounts[:executed]...)
+		total.warnings += mc.result.warnings
+		if mc.result.lastGTID != "" {
+			total.lastGTID = mc.result.lastGTID
+		}
+
+		if err != nil {
+			var mysqlErr *MySQLError
+			if !errors.As(err, &mysqlErr) {
+				return nil, mc.markBadConn(err)
+			}
+			return nil, &BatchError{Index: start + executed, Result: total, Err: err}
+		}
+		start = end
+	}
+	return total, nil
+}
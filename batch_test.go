@@ -0,0 +1,92 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExecBatch(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.capabilities |= clientMultiStatements
+
+	var resp []byte
+	resp = append(resp, mockPacket(1, iOK, 1, 7, byte(statusMoreResultsExists), 0, 0, 0)...)
+	resp = append(resp, mockPacket(2, iOK, 2, 0, 0, 0, 1, 0)...)
+	conn.queuedReplies = [][]byte{resp}
+
+	var b Batch
+	b.Queue("INSERT INTO t (name) VALUES (?);", "it's")
+	b.Queue("UPDATE t SET n = n + ? -- increment", 1)
+	res, err := mc.ExecBatch(context.Background(), &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "INSERT INTO t (name) VALUES ('it\\'s')\n;UPDATE t SET n = n + 1 -- increment"; !strings.HasSuffix(string(conn.written), want) {
+		t.Errorf("expected a single query %q, got %q", want, conn.written)
+	}
+	want := []StatementResult{{RowsAffected: 1, LastInsertId: 7}, {RowsAffected: 2, Warnings: 1}}
+	if got := res.(Result).StatementResults(); !slices.Equal(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestExecBatchError(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.capabilities |= clientMultiStatements
+
+	var resp []byte
+	resp = append(resp, mockPacket(1, iOK, 1, 0, byte(statusMoreResultsExists), 0, 0, 0)...)
+	resp = append(resp, mockPacket(2, iERR, 0x7a, 0x04, '#', '4', '2', 'S', '0', '2', 'n', 'o')...)
+	conn.queuedReplies = [][]byte{resp}
+
+	var b Batch
+	b.Queue("DELETE FROM t")
+	b.Queue("DELETE FROM missing")
+	b.Queue("DELETE FROM u")
+	_, err := mc.ExecBatch(context.Background(), &b)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Index != 1 || !IsUnknownTable(err) {
+		t.Fatalf("expected the second statement to fail, got %v", err)
+	}
+	if got := batchErr.Result.AllRowsAffected(); !slices.Equal(got, []int64{1}) {
+		t.Errorf("expected the result of the first statement, got %v", got)
+	}
+}
+
+func TestExecBatchSeparately(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	conn.queuedReplies = [][]byte{
+		mockPacket(1, iOK, 1, 0, 0, 0, 0, 0),
+		mockPacket(1, iOK, 3, 0, 0, 0, 0, 0),
+	}
+
+	var b Batch
+	b.Queue("DELETE FROM t WHERE id = ?", 1)
+	b.Queue("DELETE FROM u")
+	res, err := mc.ExecBatch(context.Background(), &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.(Result).AllRowsAffected(); !slices.Equal(got, []int64{1, 3}) {
+		t.Errorf("expected the results of both statements, got %v", got)
+	}
+	if n := strings.Count(string(conn.written), "DELETE"); n != 2 {
+		t.Errorf("expected 2 queries, got %q", conn.written)
+	}
+
+	if _, err := mc.ExecBatch(context.Background(), &Batch{}); err == nil {
+		t.Error("expected an empty batch to fail")
+	}
+}
@@ -232,6 +232,7 @@ func BenchmarkInterpolation(b *testing.B) {
 			InterpolateParams: true,
 			Loc:               time.UTC,
 		},
+		connector:        newConnector(NewConfig()),
 		maxAllowedPacket: maxPacketSize,
 		maxWriteSize:     maxPacketSize - 1,
 		buf:              newBuffer(),
@@ -257,6 +258,34 @@ func BenchmarkInterpolation(b *testing.B) {
 	}
 }
 
+// BenchmarkInterpolationBulk benchmarks interpolating a multi-row INSERT of
+// 100 rows, whose placeholder positions are cached by the connector.
+func BenchmarkInterpolationBulk(b *testing.B) {
+	connector := newConnector(NewConfig())
+	mc := &mysqlConn{
+		cfg:              connector.cfg,
+		connector:        connector,
+		maxAllowedPacket: maxPacketSize,
+		maxWriteSize:     maxPacketSize - 1,
+		buf:              newBuffer(),
+	}
+
+	q := "INSERT INTO foo (id, name, score) VALUES (?, ?, ?)" + strings.Repeat(", (?, ?, ?)", 99)
+	args := make([]driver.Value, 0, 300)
+	for i := 0; i < 100; i++ {
+		args = append(args, int64(i), "gopher", float64(i)/3)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := mc.interpolateParams(q, args)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func benchmarkQueryContext(b *testing.B, db *sql.DB, p int) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
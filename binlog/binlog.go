@@ -0,0 +1,243 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package binlog implements a replication client which streams and decodes
+// the binary log of a MySQL or MariaDB server, e.g. for change data capture.
+//
+// The server must be configured with binlog_format=ROW for row changes to be
+// reported, and the user needs the REPLICATION SLAVE privilege:
+//
+//	cfg := mysql.NewConfig()
+//	...
+//	connector, err := mysql.NewConnector(cfg)
+//	s, err := binlog.Dump(ctx, connector, binlog.Config{ServerID: 1001, File: "binlog.000001", Position: 4})
+//	defer s.Close()
+//	for {
+//		ev, err := s.Next()
+//		if err != nil {
+//			return err
+//		}
+//		switch e := ev.Data.(type) {
+//		case *binlog.RowsEvent:
+//			fmt.Println(e.Table.Schema, e.Table.Table, e.Rows)
+//		}
+//	}
+package binlog
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"slices"
+	"time"
+)
+
+// Commands of the replication protocol
+const (
+	comBinlogDump      byte = 0x12
+	comRegisterSlave   byte = 0x15
+	binlogDumpNonBlock      = 0x01
+)
+
+var (
+	errChecksum      = errors.New("binlog: event checksum mismatch")
+	errMalformed     = errors.New("binlog: malformed event")
+	errNotPacketConn = errors.New("binlog: connection does not support the replication protocol")
+)
+
+// Config configures the replica registered by Dump.
+type Config struct {
+	// ServerID identifies the replica. It must differ from the server_id of
+	// the server and of all other replicas.
+	ServerID uint32
+
+	// Hostname and Port are reported to the server with COM_REGISTER_SLAVE
+	// and shown by SHOW REPLICAS. They are optional.
+	Hostname string
+	Port     uint16
+
+	// File and Position are the binlog file and position to start at.
+	File     string
+	Position uint32
+
+	// NonBlocking makes Next return io.EOF when the end of the binlog is
+	// reached, instead of waiting for new events.
+	NonBlocking bool
+
+	// HeartbeatPeriod makes the server send heartbeat events while no
+	// other events are written, which keeps read timeouts from expiring.
+	HeartbeatPeriod time.Duration
+}
+
+// packetConn is implemented by the connections of the mysql driver.
+type packetConn interface {
+	driver.Conn
+	driver.ExecerContext
+	driver.QueryerContext
+	WriteCommand(command byte, arg []byte) error
+	ReadPacket() ([]byte, error)
+}
+
+// Streamer reads the events of a binlog stream started by Dump.
+type Streamer struct {
+	conn     packetConn
+	checksum bool
+	tables   map[uint64]*TableMapEvent
+}
+
+// Dump opens a connection with connector, which must be created by the mysql
+// driver, registers it as replica and starts streaming the binlog. The
+// connection is used exclusively by the returned Streamer.
+func Dump(ctx context.Context, connector driver.Connector, cfg Config) (*Streamer, error) {
+	dc, err := connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn, ok := dc.(packetConn)
+	if !ok {
+		dc.Close()
+		return nil, errNotPacketConn
+	}
+
+	s, err := startDump(ctx, conn, cfg)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func startDump(ctx context.Context, conn packetConn, cfg Config) (*Streamer, error) {
+	checksum, err := queryChecksum(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Announce that the checksums of the server can be handled. MariaDB
+	// additionally needs to know that GTID events are understood.
+	if checksum {
+		if _, err := conn.ExecContext(ctx, "SET @master_binlog_checksum = @@global.binlog_checksum", nil); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := conn.ExecContext(ctx, "SET @mariadb_slave_capability = 4", nil); err != nil {
+		return nil, err
+	}
+	if cfg.HeartbeatPeriod > 0 {
+		query := fmt.Sprintf("SET @master_heartbeat_period = %d", cfg.HeartbeatPeriod.Nanoseconds())
+		if _, err := conn.ExecContext(ctx, query, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	// COM_REGISTER_SLAVE
+	var arg []byte
+	arg = binary.LittleEndian.AppendUint32(arg, cfg.ServerID)
+	arg = appendShortString(arg, cfg.Hostname)
+	arg = appendShortString(arg, "") // user
+	arg = appendShortString(arg, "") // password
+	arg = binary.LittleEndian.AppendUint16(arg, cfg.Port)
+	arg = binary.LittleEndian.AppendUint32(arg, 0) // replication rank
+	arg = binary.LittleEndian.AppendUint32(arg, 0) // master id
+	if err := conn.WriteCommand(comRegisterSlave, arg); err != nil {
+		return nil, err
+	}
+	if _, err := conn.ReadPacket(); err != nil {
+		return nil, err
+	}
+
+	// COM_BINLOG_DUMP
+	var flags uint16
+	if cfg.NonBlocking {
+		flags |= binlogDumpNonBlock
+	}
+	arg = arg[:0]
+	arg = binary.LittleEndian.AppendUint32(arg, cfg.Position)
+	arg = binary.LittleEndian.AppendUint16(arg, flags)
+	arg = binary.LittleEndian.AppendUint32(arg, cfg.ServerID)
+	arg = append(arg, cfg.File...)
+	if err := conn.WriteCommand(comBinlogDump, arg); err != nil {
+		return nil, err
+	}
+
+	return newStreamer(conn, checksum), nil
+}
+
+// queryChecksum reports whether the server writes CRC32 checksums with the
+// binlog events.
+func queryChecksum(ctx context.Context, conn packetConn) (bool, error) {
+	rows, err := conn.QueryContext(ctx, "SHOW GLOBAL VARIABLES LIKE 'binlog_checksum'", nil)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	dest := make([]driver.Value, len(rows.Columns()))
+	if err := rows.Next(dest); err == io.EOF {
+		// Servers older than MySQL 5.6 don't write checksums
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	v, _ := dest[1].([]byte)
+	return string(v) == "CRC32", nil
+}
+
+func newStreamer(conn packetConn, checksum bool) *Streamer {
+	return &Streamer{
+		conn:     conn,
+		checksum: checksum,
+		tables:   make(map[uint64]*TableMapEvent),
+	}
+}
+
+// Next returns the next event of the stream. It blocks until an event is
+// available, unless Config.NonBlocking is set, in which case io.EOF is
+// returned at the end of the binlog.
+func (s *Streamer) Next() (*Event, error) {
+	data, err := s.conn.ReadPacket()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(data) > 0 && data[0] == 0x00:
+		// OK byte followed by the event
+	case len(data) > 0 && data[0] == 0xfe && len(data) < 9:
+		return nil, io.EOF
+	default:
+		return nil, errMalformed
+	}
+
+	buf := slices.Clone(data[1:])
+	if s.checksum {
+		if len(buf) < eventHeaderLen+4 {
+			return nil, errMalformed
+		}
+		n := len(buf) - 4
+		if crc32.ChecksumIEEE(buf[:n]) != binary.LittleEndian.Uint32(buf[n:]) {
+			return nil, errChecksum
+		}
+		buf = buf[:n]
+	}
+	return s.decodeEvent(buf)
+}
+
+// Close closes the connection of the stream.
+func (s *Streamer) Close() error {
+	return s.conn.Close()
+}
+
+func appendShortString(b []byte, s string) []byte {
+	b = append(b, byte(len(s)))
+	return append(b, s...)
+}
@@ -0,0 +1,233 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package binlog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// fakeConn replays packets and records the commands and statements sent.
+type fakeConn struct {
+	checksum string
+	packets  [][]byte
+	commands [][]byte
+	execs    []string
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.execs = append(c.execs, query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{value: c.checksum}, nil
+}
+
+func (c *fakeConn) WriteCommand(command byte, arg []byte) error {
+	c.commands = append(c.commands, append([]byte{command}, arg...))
+	return nil
+}
+
+func (c *fakeConn) ReadPacket() ([]byte, error) {
+	if len(c.packets) == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	p := c.packets[0]
+	c.packets = c.packets[1:]
+	return p, nil
+}
+
+type fakeRows struct {
+	value string
+	done  bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"Variable_name", "Value"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done || r.value == "" {
+		return io.EOF
+	}
+	r.done = true
+	dest[0], dest[1] = []byte("binlog_checksum"), []byte(r.value)
+	return nil
+}
+
+// eventPacket returns the packet of an event with the given type and body.
+func eventPacket(typ EventType, body []byte, checksum bool) []byte {
+	ev := make([]byte, eventHeaderLen, eventHeaderLen+len(body)+4)
+	ev[4] = byte(typ)
+	ev = append(ev, body...)
+	size := len(ev)
+	if checksum {
+		size += 4
+	}
+	binary.LittleEndian.PutUint32(ev[9:], uint32(size))
+	if checksum {
+		ev = binary.LittleEndian.AppendUint32(ev, crc32.ChecksumIEEE(ev))
+	}
+	return append([]byte{0x00}, ev...)
+}
+
+func TestStartDump(t *testing.T) {
+	conn := &fakeConn{checksum: "CRC32", packets: [][]byte{{0x00, 0, 0, 2, 0, 0, 0}}}
+	cfg := Config{ServerID: 7, Hostname: "replica", Port: 3307, File: "binlog.000002", Position: 4, NonBlocking: true}
+
+	s, err := startDump(context.Background(), conn, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.checksum {
+		t.Error("expected checksums to be enabled")
+	}
+	if len(conn.execs) == 0 || conn.execs[0] != "SET @master_binlog_checksum = @@global.binlog_checksum" {
+		t.Errorf("expected checksum announcement, got %q", conn.execs)
+	}
+
+	register := []byte{comRegisterSlave, 7, 0, 0, 0, 7, 'r', 'e', 'p', 'l', 'i', 'c', 'a', 0, 0, 0xeb, 0x0c, 0, 0, 0, 0, 0, 0, 0, 0}
+	dump := append([]byte{comBinlogDump, 4, 0, 0, 0, binlogDumpNonBlock, 0, 7, 0, 0, 0}, "binlog.000002"...)
+	if !reflect.DeepEqual(conn.commands, [][]byte{register, dump}) {
+		t.Errorf("unexpected commands %x", conn.commands)
+	}
+}
+
+func TestStreamerNext(t *testing.T) {
+	conn := &fakeConn{}
+	s := newStreamer(conn, true)
+
+	rotate := binary.LittleEndian.AppendUint64(nil, 4)
+	rotate = append(rotate, "binlog.000003"...)
+
+	query := []byte{
+		1, 0, 0, 0, // thread id
+		0, 0, 0, 0, // execution time
+		4,    // schema length
+		0, 0, // error code
+		0, 0, // status vars length
+	}
+	query = append(query, "test\x00BEGIN"...)
+
+	conn.packets = [][]byte{
+		eventPacket(RotateEventType, rotate, true),
+		eventPacket(QueryEventType, query, true),
+		eventPacket(XIDEventType, []byte{42, 0, 0, 0, 0, 0, 0, 0}, true),
+		{0xfe, 0, 0, 2, 0},
+	}
+
+	ev, err := s.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := ev.Data.(*RotateEvent); !ok || r.File != "binlog.000003" || r.Position != 4 {
+		t.Errorf("unexpected rotate event %#v", ev.Data)
+	}
+
+	ev, err = s.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q, ok := ev.Data.(*QueryEvent); !ok || q.Schema != "test" || q.Query != "BEGIN" {
+		t.Errorf("unexpected query event %#v", ev.Data)
+	}
+
+	ev, err = s.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x, ok := ev.Data.(*XIDEvent); !ok || x.XID != 42 {
+		t.Errorf("unexpected XID event %#v", ev.Data)
+	}
+
+	if _, err := s.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestStreamerChecksumMismatch(t *testing.T) {
+	p := eventPacket(XIDEventType, []byte{42, 0, 0, 0, 0, 0, 0, 0}, true)
+	p[len(p)-1] ^= 0xff
+	s := newStreamer(&fakeConn{packets: [][]byte{p}}, true)
+
+	if _, err := s.Next(); !errors.Is(err, errChecksum) {
+		t.Errorf("expected checksum error, got %v", err)
+	}
+}
+
+func TestStreamerRowsEvent(t *testing.T) {
+	conn := &fakeConn{}
+	s := newStreamer(conn, false)
+
+	// table 0x21 `test`.`t` (id INT, name VARCHAR(20), note VARCHAR(20))
+	tableMap := []byte{0x21, 0, 0, 0, 0, 0, 1, 0}
+	tableMap = append(tableMap, "\x04test\x00\x01t\x00"...)
+	tableMap = append(tableMap, 3, byte(TypeLong), byte(TypeVarChar), byte(TypeVarChar))
+	tableMap = append(tableMap, 4, 80, 0, 80, 0) // metadata
+	tableMap = append(tableMap, 0x06)            // nullable columns
+
+	rows := []byte{0x21, 0, 0, 0, 0, 0, 1, 0}
+	rows = append(rows, 2, 0) // extra data length
+	rows = append(rows, 3)    // column count
+	rows = append(rows, 0x07) // present columns
+	// (1, 'a', NULL)
+	rows = append(rows, 0x04, 1, 0, 0, 0, 1, 'a')
+	// (-2, 'bc', 'd')
+	rows = append(rows, 0x00, 0xfe, 0xff, 0xff, 0xff, 2, 'b', 'c', 1, 'd')
+
+	conn.packets = [][]byte{
+		eventPacket(TableMapEventType, tableMap, false),
+		eventPacket(WriteRowsEventType, rows, false),
+	}
+
+	ev, err := s.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm, ok := ev.Data.(*TableMapEvent)
+	if !ok || tm.Schema != "test" || tm.Table != "t" || tm.TableID != 0x21 {
+		t.Fatalf("unexpected table map event %#v", ev.Data)
+	}
+	if !reflect.DeepEqual(tm.ColumnMeta, []uint16{0, 80, 80}) {
+		t.Errorf("unexpected column metadata %v", tm.ColumnMeta)
+	}
+
+	ev, err = s.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	re, ok := ev.Data.(*RowsEvent)
+	if !ok || re.Table != tm {
+		t.Fatalf("unexpected rows event %#v", ev.Data)
+	}
+	expected := [][]any{{int64(1), "a", nil}, {int64(-2), "bc", "d"}}
+	if !reflect.DeepEqual(re.Rows, expected) {
+		t.Errorf("expected rows %v, got %v", expected, re.Rows)
+	}
+}
+
+func TestStreamerUnknownTable(t *testing.T) {
+	rows := []byte{0x21, 0, 0, 0, 0, 0, 1, 0, 1, 0x01, 0x00, 1, 0, 0, 0}
+	s := newStreamer(&fakeConn{packets: [][]byte{eventPacket(WriteRowsEventV1Type, rows, false)}}, false)
+
+	if _, err := s.Next(); err == nil || !bytes.Contains([]byte(err.Error()), []byte("no table map")) {
+		t.Errorf("expected missing table map error, got %v", err)
+	}
+}
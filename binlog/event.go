@@ -0,0 +1,203 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package binlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// EventType is the type of a binlog event.
+// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_replication_binlog_event.html
+type EventType byte
+
+const (
+	QueryEventType             EventType = 2
+	StopEventType              EventType = 3
+	RotateEventType            EventType = 4
+	FormatDescriptionEventType EventType = 15
+	XIDEventType               EventType = 16
+	TableMapEventType          EventType = 19
+	WriteRowsEventV1Type       EventType = 23
+	UpdateRowsEventV1Type      EventType = 24
+	DeleteRowsEventV1Type      EventType = 25
+	HeartbeatEventType         EventType = 27
+	WriteRowsEventType         EventType = 30
+	UpdateRowsEventType        EventType = 31
+	DeleteRowsEventType        EventType = 32
+	GTIDEventType              EventType = 33
+)
+
+const eventHeaderLen = 19
+
+// EventHeader is the common header of all binlog events.
+type EventHeader struct {
+	Timestamp uint32
+	Type      EventType
+	ServerID  uint32
+	EventSize uint32
+	LogPos    uint32 // position of the next event
+	Flags     uint16
+}
+
+// Event is a binlog event.
+type Event struct {
+	Header EventHeader
+
+	// Data is the decoded event, one of *RotateEvent,
+	// *FormatDescriptionEvent, *QueryEvent, *XIDEvent, *TableMapEvent,
+	// *RowsEvent and *GTIDEvent, or nil for other event types.
+	Data any
+
+	// Raw is the body of the event, following the header and without the
+	// checksum.
+	Raw []byte
+}
+
+// RotateEvent announces the binlog file the following events belong to.
+type RotateEvent struct {
+	Position uint64
+	File     string
+}
+
+// FormatDescriptionEvent describes the format of the binlog.
+type FormatDescriptionEvent struct {
+	BinlogVersion   uint16
+	ServerVersion   string
+	CreateTimestamp uint32
+}
+
+// QueryEvent holds a statement, e.g. DDL or BEGIN.
+type QueryEvent struct {
+	ThreadID      uint32
+	ExecutionTime uint32
+	ErrorCode     uint16
+	Schema        string
+	Query         string
+}
+
+// XIDEvent marks the commit of a transaction.
+type XIDEvent struct {
+	XID uint64
+}
+
+// GTIDEvent holds the GTID of the following transaction.
+type GTIDEvent struct {
+	GTID string // source_id:transaction_id
+}
+
+func (s *Streamer) decodeEvent(buf []byte) (*Event, error) {
+	if len(buf) < eventHeaderLen {
+		return nil, errMalformed
+	}
+	ev := &Event{
+		Header: EventHeader{
+			Timestamp: binary.LittleEndian.Uint32(buf[0:]),
+			Type:      EventType(buf[4]),
+			ServerID:  binary.LittleEndian.Uint32(buf[5:]),
+			EventSize: binary.LittleEndian.Uint32(buf[9:]),
+			LogPos:    binary.LittleEndian.Uint32(buf[13:]),
+			Flags:     binary.LittleEndian.Uint16(buf[17:]),
+		},
+		Raw: buf[eventHeaderLen:],
+	}
+
+	var err error
+	switch t := ev.Header.Type; t {
+	case RotateEventType:
+		ev.Data, err = decodeRotateEvent(ev.Raw)
+	case FormatDescriptionEventType:
+		ev.Data, err = decodeFormatDescriptionEvent(ev.Raw)
+	case QueryEventType:
+		ev.Data, err = decodeQueryEvent(ev.Raw)
+	case XIDEventType:
+		if len(ev.Raw) < 8 {
+			return nil, errMalformed
+		}
+		ev.Data = &XIDEvent{XID: binary.LittleEndian.Uint64(ev.Raw)}
+	case GTIDEventType:
+		ev.Data, err = decodeGTIDEvent(ev.Raw)
+	case TableMapEventType:
+		var tm *TableMapEvent
+		if tm, err = decodeTableMapEvent(ev.Raw); err == nil {
+			s.tables[tm.TableID] = tm
+			ev.Data = tm
+		}
+	case WriteRowsEventV1Type, UpdateRowsEventV1Type, DeleteRowsEventV1Type,
+		WriteRowsEventType, UpdateRowsEventType, DeleteRowsEventType:
+		ev.Data, err = s.decodeRowsEvent(t, ev.Raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("binlog: decoding event type %d at %d: %w", ev.Header.Type, ev.Header.LogPos, err)
+	}
+	return ev, nil
+}
+
+func decodeRotateEvent(data []byte) (*RotateEvent, error) {
+	if len(data) < 8 {
+		return nil, errMalformed
+	}
+	return &RotateEvent{
+		Position: binary.LittleEndian.Uint64(data),
+		File:     string(data[8:]),
+	}, nil
+}
+
+func decodeFormatDescriptionEvent(data []byte) (*FormatDescriptionEvent, error) {
+	// binlog version [2 bytes], server version [50 bytes],
+	// create timestamp [4 bytes], ...
+	if len(data) < 2+50+4 {
+		return nil, errMalformed
+	}
+	version := data[2 : 2+50]
+	if i := bytes.IndexByte(version, 0); i >= 0 {
+		version = version[:i]
+	}
+	return &FormatDescriptionEvent{
+		BinlogVersion:   binary.LittleEndian.Uint16(data),
+		ServerVersion:   string(version),
+		CreateTimestamp: binary.LittleEndian.Uint32(data[52:]),
+	}, nil
+}
+
+func decodeQueryEvent(data []byte) (*QueryEvent, error) {
+	// thread id [4 bytes], execution time [4 bytes], schema length [1 byte],
+	// error code [2 bytes], status vars length [2 bytes]
+	const postHeaderLen = 4 + 4 + 1 + 2 + 2
+	if len(data) < postHeaderLen {
+		return nil, errMalformed
+	}
+	schemaLen := int(data[8])
+	statusLen := int(binary.LittleEndian.Uint16(data[11:]))
+
+	pos := postHeaderLen + statusLen
+	if len(data) < pos+schemaLen+1 {
+		return nil, errMalformed
+	}
+	return &QueryEvent{
+		ThreadID:      binary.LittleEndian.Uint32(data),
+		ExecutionTime: binary.LittleEndian.Uint32(data[4:]),
+		ErrorCode:     binary.LittleEndian.Uint16(data[9:]),
+		Schema:        string(data[pos : pos+schemaLen]),
+		Query:         string(data[pos+schemaLen+1:]),
+	}, nil
+}
+
+func decodeGTIDEvent(data []byte) (*GTIDEvent, error) {
+	// flags [1 byte], source id [16 bytes], transaction id [8 bytes]
+	if len(data) < 1+16+8 {
+		return nil, errMalformed
+	}
+	sid := data[1:17]
+	gno := binary.LittleEndian.Uint64(data[17:])
+	return &GTIDEvent{
+		GTID: fmt.Sprintf("%x-%x-%x-%x-%x:%d", sid[0:4], sid[4:6], sid[6:8], sid[8:10], sid[10:16], gno),
+	}, nil
+}
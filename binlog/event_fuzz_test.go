@@ -0,0 +1,36 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package binlog
+
+import (
+	"testing"
+)
+
+// FuzzDecodeEvent decodes two events with the same Streamer, so that rows
+// events can follow the table map event they refer to.
+func FuzzDecodeEvent(f *testing.F) {
+	event := func(typ EventType, body []byte) []byte {
+		return eventPacket(typ, body, false)[1:]
+	}
+	query := []byte{1, 0, 0, 0, 0, 0, 0, 0, 4, 0, 0, 0, 0}
+	query = append(query, "test\x00BEGIN"...)
+	rows := []byte{0x21, 0, 0, 0, 0, 0, 1, 0, 2, 0, 3, 0x07, 0x04, 1, 0, 0, 0, 1, 'a'}
+
+	f.Add(event(TableMapEventType, fuzzTableMap), event(WriteRowsEventType, rows))
+	f.Add(event(QueryEventType, query), event(XIDEventType, []byte{1, 0, 0, 0, 0, 0, 0, 0}))
+	f.Add(event(RotateEventType, append([]byte{4, 0, 0, 0, 0, 0, 0, 0}, "binlog.000002"...)),
+		event(GTIDEventType, make([]byte, 1+16+8)))
+	f.Add(event(FormatDescriptionEventType, make([]byte, 2+50+4)), []byte{})
+
+	f.Fuzz(func(t *testing.T, first, second []byte) {
+		s := newStreamer(nil, false)
+		s.decodeEvent(first)
+		s.decodeEvent(second)
+	})
+}
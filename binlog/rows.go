@@ -0,0 +1,631 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package binlog
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ColumnType is the type of a column in a TableMapEvent.
+type ColumnType byte
+
+const (
+	TypeDecimal ColumnType = iota
+	TypeTiny
+	TypeShort
+	TypeLong
+	TypeFloat
+	TypeDouble
+	TypeNull
+	TypeTimestamp
+	TypeLongLong
+	TypeInt24
+	TypeDate
+	TypeTime
+	TypeDateTime
+	TypeYear
+	TypeNewDate
+	TypeVarChar
+	TypeBit
+	TypeTimestamp2
+	TypeDateTime2
+	TypeTime2
+)
+
+const (
+	TypeJSON ColumnType = iota + 0xf5
+	TypeNewDecimal
+	TypeEnum
+	TypeSet
+	TypeTinyBLOB
+	TypeMediumBLOB
+	TypeLongBLOB
+	TypeBLOB
+	TypeVarString
+	TypeString
+	TypeGeometry
+)
+
+// TableMapEvent describes the table changed by the following rows events.
+type TableMapEvent struct {
+	TableID     uint64
+	Schema      string
+	Table       string
+	ColumnTypes []ColumnType
+	ColumnMeta  []uint16
+	NullBitmap  []byte // bit i is set if column i is nullable
+}
+
+// RowsEvent holds the rows written, updated or deleted by a statement.
+//
+// The values are decoded as follows: integers as int64, as the binlog doesn't
+// tell their signedness, FLOAT and DOUBLE as float64, DECIMAL and TIME as
+// string, DATE, DATETIME and TIMESTAMP as time.Time in UTC, CHAR, VARCHAR,
+// ENUM and SET strings as string, ENUM and SET values of their own type as
+// int64, BIT as uint64 and BLOB, TEXT, JSON and GEOMETRY as []byte. JSON is
+// MySQL's binary JSON format. Columns which aren't part of the image are nil.
+type RowsEvent struct {
+	Table *TableMapEvent
+
+	// Rows holds the values of the rows. For update events, the before and
+	// after image of each row follow each other.
+	Rows [][]any
+}
+
+func decodeTableMapEvent(data []byte) (*TableMapEvent, error) {
+	// table id [6 bytes], flags [2 bytes]
+	if len(data) < 8 {
+		return nil, errMalformed
+	}
+	tm := &TableMapEvent{TableID: readTableID(data)}
+	pos := 8
+
+	var err error
+	if tm.Schema, pos, err = readNulTerminatedShortString(data, pos); err != nil {
+		return nil, err
+	}
+	if tm.Table, pos, err = readNulTerminatedShortString(data, pos); err != nil {
+		return nil, err
+	}
+
+	// The lengths are compared unconverted, as int conversions of lengths
+	// read from the wire may overflow.
+	count, n := readLengthEncodedInteger(data[pos:])
+	pos += n
+	if n == 0 || count > uint64(len(data)-pos) {
+		return nil, errMalformed
+	}
+	tm.ColumnTypes = make([]ColumnType, count)
+	for i := range tm.ColumnTypes {
+		tm.ColumnTypes[i] = ColumnType(data[pos+i])
+	}
+	pos += int(count)
+
+	metaLen, n := readLengthEncodedInteger(data[pos:])
+	pos += n
+	if n == 0 || metaLen > uint64(len(data)-pos) {
+		return nil, errMalformed
+	}
+	if tm.ColumnMeta, err = decodeColumnMeta(tm.ColumnTypes, data[pos:pos+int(metaLen)]); err != nil {
+		return nil, err
+	}
+	pos += int(metaLen)
+
+	if len(data) < pos+(int(count)+7)/8 {
+		return nil, errMalformed
+	}
+	tm.NullBitmap = data[pos : pos+(int(count)+7)/8]
+	return tm, nil
+}
+
+func decodeColumnMeta(types []ColumnType, data []byte) ([]uint16, error) {
+	meta := make([]uint16, len(types))
+	pos := 0
+	for i, t := range types {
+		switch t {
+		case TypeFloat, TypeDouble, TypeBLOB, TypeGeometry, TypeJSON,
+			TypeTime2, TypeDateTime2, TypeTimestamp2:
+			if len(data) < pos+1 {
+				return nil, errMalformed
+			}
+			meta[i] = uint16(data[pos])
+			pos++
+		case TypeVarChar, TypeVarString:
+			if len(data) < pos+2 {
+				return nil, errMalformed
+			}
+			meta[i] = binary.LittleEndian.Uint16(data[pos:])
+			pos += 2
+		case TypeNewDecimal, TypeString, TypeEnum, TypeSet, TypeBit:
+			if len(data) < pos+2 {
+				return nil, errMalformed
+			}
+			meta[i] = binary.BigEndian.Uint16(data[pos:])
+			pos += 2
+		}
+	}
+	return meta, nil
+}
+
+func (s *Streamer) decodeRowsEvent(typ EventType, data []byte) (*RowsEvent, error) {
+	// table id [6 bytes], flags [2 bytes]
+	if len(data) < 8 {
+		return nil, errMalformed
+	}
+	tableID := readTableID(data)
+	pos := 8
+
+	// extra data length [2 bytes], including the length itself
+	if typ >= WriteRowsEventType {
+		if len(data) < pos+2 {
+			return nil, errMalformed
+		}
+		extraLen := int(binary.LittleEndian.Uint16(data[pos:]))
+		if extraLen < 2 || len(data) < pos+extraLen {
+			return nil, errMalformed
+		}
+		pos += extraLen
+	}
+
+	tm, ok := s.tables[tableID]
+	if !ok {
+		return nil, fmt.Errorf("no table map for table id %d", tableID)
+	}
+	ev := &RowsEvent{Table: tm}
+
+	count, n := readLengthEncodedInteger(data[pos:])
+	pos += n
+	if n == 0 || count != uint64(len(tm.ColumnTypes)) {
+		return nil, errMalformed
+	}
+
+	// bitmap of the columns in the row images
+	bitmapLen := (int(count) + 7) / 8
+	images := 1
+	if typ == UpdateRowsEventType || typ == UpdateRowsEventV1Type {
+		images = 2
+	}
+	if len(data) < pos+images*bitmapLen {
+		return nil, errMalformed
+	}
+	present := [2][]byte{data[pos : pos+bitmapLen]}
+	pos += bitmapLen
+	if images == 2 {
+		present[1] = data[pos : pos+bitmapLen]
+		pos += bitmapLen
+	}
+
+	for i := 0; pos < len(data); i++ {
+		row, n, err := decodeRow(tm, present[i%images], data[pos:])
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, errMalformed
+		}
+		ev.Rows = append(ev.Rows, row)
+		pos += n
+	}
+	return ev, nil
+}
+
+// decodeRow decodes a row image and returns the number of bytes read.
+func decodeRow(tm *TableMapEvent, present []byte, data []byte) ([]any, int, error) {
+	presentCount := 0
+	for _, b := range present {
+		presentCount += bits.OnesCount8(b)
+	}
+
+	// NULL bitmap of the present columns
+	nullLen := (presentCount + 7) / 8
+	if len(data) < nullLen {
+		return nil, 0, errMalformed
+	}
+	nulls := data[:nullLen]
+	pos := nullLen
+
+	row := make([]any, len(tm.ColumnTypes))
+	j := 0
+	for i := range row {
+		if !isBitSet(present, i) {
+			continue
+		}
+		isNull := isBitSet(nulls, j)
+		j++
+		if isNull {
+			continue
+		}
+
+		v, n, err := decodeValue(tm.ColumnTypes[i], tm.ColumnMeta[i], data[pos:])
+		if err != nil {
+			return nil, 0, fmt.Errorf("column %d: %w", i, err)
+		}
+		row[i] = v
+		pos += n
+	}
+	return row, pos, nil
+}
+
+// decodeValue decodes a value of a row image and returns the number of bytes
+// read.
+// https://dev.mysql.com/doc/dev/mysql-server/latest/classbinary__log_1_1Table__map__event.html
+func decodeValue(typ ColumnType, meta uint16, data []byte) (any, int, error) {
+	// Values of CHAR, ENUM and SET columns carry their real type in the meta
+	if typ == TypeString && meta >= 256 {
+		b0, b1 := byte(meta>>8), byte(meta)
+		if b0&0x30 != 0x30 {
+			// the length of long CHAR columns is partially stored in b0
+			meta = uint16(b1) | uint16((b0&0x30)^0x30)<<4
+			typ = ColumnType(b0 | 0x30)
+		} else {
+			meta = uint16(b1)
+			typ = ColumnType(b0)
+		}
+	}
+
+	switch typ {
+	case TypeNull:
+		return nil, 0, nil
+
+	case TypeTiny:
+		if len(data) < 1 {
+			return nil, 0, errMalformed
+		}
+		return int64(int8(data[0])), 1, nil
+
+	case TypeShort:
+		if len(data) < 2 {
+			return nil, 0, errMalformed
+		}
+		return int64(int16(binary.LittleEndian.Uint16(data))), 2, nil
+
+	case TypeInt24:
+		if len(data) < 3 {
+			return nil, 0, errMalformed
+		}
+		v := int32(uint32(data[0])|uint32(data[1])<<8|uint32(data[2])<<16) << 8 >> 8
+		return int64(v), 3, nil
+
+	case TypeLong:
+		if len(data) < 4 {
+			return nil, 0, errMalformed
+		}
+		return int64(int32(binary.LittleEndian.Uint32(data))), 4, nil
+
+	case TypeLongLong:
+		if len(data) < 8 {
+			return nil, 0, errMalformed
+		}
+		return int64(binary.LittleEndian.Uint64(data)), 8, nil
+
+	case TypeFloat:
+		if len(data) < 4 {
+			return nil, 0, errMalformed
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(data))), 4, nil
+
+	case TypeDouble:
+		if len(data) < 8 {
+			return nil, 0, errMalformed
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(data)), 8, nil
+
+	case TypeYear:
+		if len(data) < 1 {
+			return nil, 0, errMalformed
+		}
+		if data[0] == 0 {
+			return int64(0), 1, nil
+		}
+		return int64(data[0]) + 1900, 1, nil
+
+	case TypeNewDecimal:
+		return decodeDecimal(data, int(meta>>8), int(meta&0xff))
+
+	case TypeVarChar, TypeVarString, TypeString:
+		b, n, err := readPrefixedBytes(data, meta)
+		if err != nil {
+			return nil, 0, err
+		}
+		return string(b), n, nil
+
+	case TypeBLOB, TypeGeometry, TypeJSON:
+		if meta < 1 || meta > 4 || len(data) < int(meta) {
+			return nil, 0, errMalformed
+		}
+		length := readUintLE(data[:meta])
+		n := int(meta) + int(length)
+		if len(data) < n {
+			return nil, 0, errMalformed
+		}
+		return data[meta:n], n, nil
+
+	case TypeEnum, TypeSet:
+		n := int(meta & 0xff)
+		if n < 1 || n > 8 || len(data) < n {
+			return nil, 0, errMalformed
+		}
+		return int64(readUintLE(data[:n])), n, nil
+
+	case TypeBit:
+		n := (int(meta&0xff)*8 + int(meta>>8) + 7) / 8
+		if n > 8 || len(data) < n {
+			return nil, 0, errMalformed
+		}
+		var v uint64
+		for _, b := range data[:n] {
+			v = v<<8 | uint64(b)
+		}
+		return v, n, nil
+
+	case TypeTimestamp:
+		if len(data) < 4 {
+			return nil, 0, errMalformed
+		}
+		return time.Unix(int64(binary.LittleEndian.Uint32(data)), 0).UTC(), 4, nil
+
+	case TypeTimestamp2:
+		n := 4 + fracLen(meta)
+		if len(data) < n {
+			return nil, 0, errMalformed
+		}
+		sec := int64(binary.BigEndian.Uint32(data))
+		usec := readFrac(data[4:n])
+		return time.Unix(sec, usec*1000).UTC(), n, nil
+
+	case TypeDate, TypeNewDate:
+		if len(data) < 3 {
+			return nil, 0, errMalformed
+		}
+		v := readUintLE(data[:3])
+		return makeTime(int(v>>9), int(v>>5&0x0f), int(v&0x1f), 0, 0, 0, 0), 3, nil
+
+	case TypeDateTime:
+		if len(data) < 8 {
+			return nil, 0, errMalformed
+		}
+		v := binary.LittleEndian.Uint64(data)
+		d, t := v/1000000, v%1000000
+		return makeTime(int(d/10000), int(d/100%100), int(d%100), int(t/10000), int(t/100%100), int(t%100), 0), 8, nil
+
+	case TypeDateTime2:
+		n := 5 + fracLen(meta)
+		if len(data) < n {
+			return nil, 0, errMalformed
+		}
+		v := int64(readUintBE(data[:5])) - 0x8000000000
+		ymd, hms := v>>17, v%(1<<17)
+		ym := ymd >> 5
+		t := makeTime(int(ym/13), int(ym%13), int(ymd%(1<<5)),
+			int(hms>>12), int(hms>>6%(1<<6)), int(hms%(1<<6)), readFrac(data[5:n]))
+		return t, n, nil
+
+	case TypeTime:
+		if len(data) < 3 {
+			return nil, 0, errMalformed
+		}
+		v := int32(uint32(data[0])|uint32(data[1])<<8|uint32(data[2])<<16) << 8 >> 8
+		sign := ""
+		if v < 0 {
+			sign, v = "-", -v
+		}
+		return fmt.Sprintf("%s%02d:%02d:%02d", sign, v/10000, v/100%100, v%100), 3, nil
+
+	case TypeTime2:
+		return decodeTime2(data, meta)
+	}
+	return nil, 0, fmt.Errorf("unsupported column type %d", typ)
+}
+
+// decodeDecimal decodes a DECIMAL value, which is stored in groups of 9
+// digits taking 4 bytes each.
+// https://github.com/mysql/mysql-server/blob/8.0/strings/decimal.cc
+func decodeDecimal(data []byte, precision, scale int) (string, int, error) {
+	if precision < scale {
+		return "", 0, errMalformed
+	}
+	dig2bytes := [...]int{0, 1, 1, 2, 2, 3, 3, 4, 4, 4}
+	intg := precision - scale
+	intg0, intg0x := intg/9, intg%9
+	frac0, frac0x := scale/9, scale%9
+	size := intg0*4 + dig2bytes[intg0x] + frac0*4 + dig2bytes[frac0x]
+	if size == 0 || len(data) < size {
+		return "", 0, errMalformed
+	}
+
+	// The sign is stored in the inverted highest bit, negative values are
+	// stored inverted.
+	buf := make([]byte, size)
+	copy(buf, data)
+	negative := buf[0]&0x80 == 0
+	buf[0] ^= 0x80
+	if negative {
+		for i := range buf {
+			buf[i] ^= 0xff
+		}
+	}
+
+	var b strings.Builder
+	if negative {
+		b.WriteByte('-')
+	}
+	pos := 0
+	var digits strings.Builder
+	if n := dig2bytes[intg0x]; n > 0 {
+		digits.WriteString(strconv.FormatUint(readUintBE(buf[pos:pos+n]), 10))
+		pos += n
+	}
+	for i := 0; i < intg0; i++ {
+		fmt.Fprintf(&digits, "%09d", binary.BigEndian.Uint32(buf[pos:]))
+		pos += 4
+	}
+	intPart := strings.TrimLeft(digits.String(), "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+	b.WriteString(intPart)
+
+	if scale > 0 {
+		b.WriteByte('.')
+		for i := 0; i < frac0; i++ {
+			fmt.Fprintf(&b, "%09d", binary.BigEndian.Uint32(buf[pos:]))
+			pos += 4
+		}
+		if n := dig2bytes[frac0x]; n > 0 {
+			fmt.Fprintf(&b, "%0*d", frac0x, readUintBE(buf[pos:pos+n]))
+			pos += n
+		}
+	}
+	return b.String(), size, nil
+}
+
+func decodeTime2(data []byte, meta uint16) (string, int, error) {
+	n := 3 + fracLen(meta)
+	if len(data) < n {
+		return "", 0, errMalformed
+	}
+
+	// The value is stored as a fixed point number of seconds with 24 bits of
+	// fractional part, offset to be non-negative.
+	intPart := int64(readUintBE(data[:3])) - 0x800000
+	var tmp int64
+	switch n - 3 {
+	case 1:
+		frac := int64(data[3])
+		if intPart < 0 && frac != 0 {
+			intPart++
+			frac -= 0x100
+		}
+		tmp = intPart<<24 + frac*10000
+	case 2:
+		frac := int64(binary.BigEndian.Uint16(data[3:]))
+		if intPart < 0 && frac != 0 {
+			intPart++
+			frac -= 0x10000
+		}
+		tmp = intPart<<24 + frac*100
+	case 3:
+		tmp = int64(readUintBE(data[:6])) - 0x800000000000
+	default:
+		tmp = intPart << 24
+	}
+
+	sign := ""
+	if tmp < 0 {
+		sign, tmp = "-", -tmp
+	}
+	hms, usec := tmp>>24, tmp%(1<<24)
+	s := fmt.Sprintf("%s%02d:%02d:%02d", sign, hms>>12%(1<<10), hms>>6%(1<<6), hms%(1<<6))
+	if usec != 0 {
+		s += fmt.Sprintf(".%06d", usec)
+	}
+	return s, n, nil
+}
+
+// makeTime returns the time in UTC, or the zero time for zero dates.
+func makeTime(year, month, day, hour, minute, sec int, usec int64) time.Time {
+	if year == 0 && month == 0 && day == 0 {
+		return time.Time{}
+	}
+	return time.Date(year, time.Month(month), day, hour, minute, sec, int(usec)*1000, time.UTC)
+}
+
+// fracLen returns the length of the fractional seconds of temporal values
+// with the given precision.
+func fracLen(fsp uint16) int {
+	return (int(fsp) + 1) / 2
+}
+
+// readFrac reads fractional seconds in microseconds.
+func readFrac(b []byte) int64 {
+	v := int64(readUintBE(b))
+	for i := len(b); i < 3; i++ {
+		v *= 100
+	}
+	return v
+}
+
+func readPrefixedBytes(data []byte, maxLen uint16) ([]byte, int, error) {
+	prefix := 1
+	if maxLen >= 256 {
+		prefix = 2
+	}
+	if len(data) < prefix {
+		return nil, 0, errMalformed
+	}
+	n := prefix + int(readUintLE(data[:prefix]))
+	if len(data) < n {
+		return nil, 0, errMalformed
+	}
+	return data[prefix:n], n, nil
+}
+
+func readTableID(b []byte) uint64 {
+	return readUintLE(b[:6])
+}
+
+func readUintLE(b []byte) uint64 {
+	var v uint64
+	for i := len(b) - 1; i >= 0; i-- {
+		v = v<<8 | uint64(b[i])
+	}
+	return v
+}
+
+func readUintBE(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+func isBitSet(bitmap []byte, i int) bool {
+	return bitmap[i/8]&(1<<(uint(i)%8)) != 0
+}
+
+// readLengthEncodedInteger returns the integer and the number of bytes read,
+// which is 0 if data is too short.
+func readLengthEncodedInteger(data []byte) (uint64, int) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+	n := 1
+	switch data[0] {
+	case 0xfc:
+		n = 3
+	case 0xfd:
+		n = 4
+	case 0xfe:
+		n = 9
+	default:
+		return uint64(data[0]), 1
+	}
+	if len(data) < n {
+		return 0, 0
+	}
+	return readUintLE(data[1:n]), n
+}
+
+func readNulTerminatedShortString(data []byte, pos int) (string, int, error) {
+	if len(data) < pos+1 {
+		return "", 0, errMalformed
+	}
+	n := int(data[pos])
+	if len(data) < pos+1+n+1 {
+		return "", 0, errMalformed
+	}
+	return string(data[pos+1 : pos+1+n]), pos + 1 + n + 1, nil
+}
@@ -0,0 +1,57 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package binlog
+
+import (
+	"testing"
+)
+
+// fuzzTableMap is the table map event of `test`.`t` (id INT, name
+// VARCHAR(20), note VARCHAR(20)) with table id 0x21.
+var fuzzTableMap = []byte{0x21, 0, 0, 0, 0, 0, 1, 0,
+	4, 't', 'e', 's', 't', 0, 1, 't', 0,
+	3, byte(TypeLong), byte(TypeVarChar), byte(TypeVarChar),
+	4, 80, 0, 80, 0,
+	0x06}
+
+func FuzzDecodeTableMapEvent(f *testing.F) {
+	f.Add(fuzzTableMap)
+	f.Add([]byte{0x21, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tm, err := decodeTableMapEvent(data)
+		if err == nil && len(tm.ColumnMeta) != len(tm.ColumnTypes) {
+			t.Errorf("got %d column types but %d metadata", len(tm.ColumnTypes), len(tm.ColumnMeta))
+		}
+	})
+}
+
+func FuzzDecodeRowsEvent(f *testing.F) {
+	f.Add(fuzzTableMap, byte(WriteRowsEventType),
+		[]byte{0x21, 0, 0, 0, 0, 0, 1, 0, 2, 0, 3, 0x07, 0x04, 1, 0, 0, 0, 1, 'a'})
+	f.Add(fuzzTableMap, byte(UpdateRowsEventV1Type),
+		[]byte{0x21, 0, 0, 0, 0, 0, 1, 0, 3, 0x01, 0x01, 0x00, 1, 0, 0, 0, 0x00, 2, 0, 0, 0})
+	f.Add([]byte{0x21, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 1, byte(TypeNewDecimal), 2, 0, 0, 0},
+		byte(DeleteRowsEventType), []byte{0x21, 0, 0, 0, 0, 0, 1, 0, 2, 0, 1, 0x01, 0x00, 0x80})
+
+	f.Fuzz(func(t *testing.T, tableMap []byte, typ byte, data []byte) {
+		switch EventType(typ) {
+		case WriteRowsEventV1Type, UpdateRowsEventV1Type, DeleteRowsEventV1Type,
+			WriteRowsEventType, UpdateRowsEventType, DeleteRowsEventType:
+		default:
+			t.Skip()
+		}
+		tm, err := decodeTableMapEvent(tableMap)
+		if err != nil {
+			t.Skip()
+		}
+		s := &Streamer{tables: map[uint64]*TableMapEvent{tm.TableID: tm}}
+		s.decodeRowsEvent(EventType(typ), data)
+	})
+}
@@ -0,0 +1,112 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package binlog
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDecodeValue(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  ColumnType
+		meta uint16
+		data []byte
+		want any
+		n    int
+	}{
+		{"tiny", TypeTiny, 0, []byte{0xff}, int64(-1), 1},
+		{"int24", TypeInt24, 0, []byte{0xff, 0xff, 0xff}, int64(-1), 3},
+		{"longlong", TypeLongLong, 0, []byte{1, 0, 0, 0, 0, 0, 0, 0}, int64(1), 8},
+		{"double", TypeDouble, 8, []byte{0, 0, 0, 0, 0, 0, 0xf8, 0x3f}, 1.5, 8},
+		{"year", TypeYear, 0, []byte{124}, int64(2024), 1},
+		{"decimal", TypeNewDecimal, 10<<8 | 2, []byte{0x80, 0, 0x30, 0x39, 0x2d}, "12345.45", 5},
+		{"negative decimal", TypeNewDecimal, 10<<8 | 2, []byte{0x7f, 0xff, 0xcf, 0xc6, 0xd2}, "-12345.45", 5},
+		{"char", TypeString, uint16(TypeString)<<8 | 10, []byte{2, 'a', 'b'}, "ab", 3},
+		{"enum", TypeString, uint16(TypeEnum)<<8 | 1, []byte{2}, int64(2), 1},
+		{"blob", TypeBLOB, 2, []byte{3, 0, 'x', 'y', 'z'}, []byte("xyz"), 5},
+		{"bit", TypeBit, 2<<8 | 1, []byte{0x01, 0x03}, uint64(0x103), 2},
+		{"date", TypeDate, 0, []byte{0x4f, 0xd0, 0x0f}, time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), 3},
+		{"datetime2", TypeDateTime2, 0, []byte{0x99, 0xb2, 0x9e, 0x30, 0xc5}, time.Date(2024, 2, 15, 3, 3, 5, 0, time.UTC), 5},
+		{"datetime2 fsp", TypeDateTime2, 3, []byte{0x99, 0xb2, 0x9e, 0x30, 0xc5, 0x04, 0xd2}, time.Date(2024, 2, 15, 3, 3, 5, 123400000, time.UTC), 7},
+		{"timestamp2", TypeTimestamp2, 0, []byte{0x65, 0xcd, 0x8c, 0x00}, time.Unix(0x65cd8c00, 0).UTC(), 4},
+		{"time2", TypeTime2, 0, []byte{0x80, 0x30, 0xc5}, "03:03:05", 3},
+		{"negative time2", TypeTime2, 0, []byte{0x7f, 0xcf, 0x3b}, "-03:03:05", 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, n, err := decodeValue(test.typ, test.meta, test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(v, test.want) || n != test.n {
+				t.Errorf("expected %#v (%d bytes), got %#v (%d bytes)", test.want, test.n, v, n)
+			}
+		})
+	}
+}
+
+func TestDecodeValueMalformed(t *testing.T) {
+	if _, _, err := decodeValue(TypeLong, 0, []byte{1, 2}); err != errMalformed {
+		t.Errorf("expected errMalformed, got %v", err)
+	}
+	if _, _, err := decodeValue(TypeVarChar, 10, []byte{5, 'a'}); err != errMalformed {
+		t.Errorf("expected errMalformed, got %v", err)
+	}
+	if _, _, err := decodeValue(ColumnType(0x80), 0, []byte{0}); err == nil {
+		t.Error("expected error for unsupported column type")
+	}
+}
+
+func TestDecodeDecimalMalformed(t *testing.T) {
+	// precision < scale and zero precision
+	for _, meta := range []uint16{2<<8 | 10, 0} {
+		if _, _, err := decodeValue(TypeNewDecimal, meta, []byte{0x80, 0, 0, 0, 0}); err != errMalformed {
+			t.Errorf("meta %#x: expected errMalformed, got %v", meta, err)
+		}
+	}
+}
+
+func TestDecodeTableMapEventMalformed(t *testing.T) {
+	tableMap := []byte{0x21, 0, 0, 0, 0, 0, 1, 0}
+	tableMap = append(tableMap, "\x04test\x00\x01t\x00"...)
+
+	tests := map[string][]byte{
+		// column count of 2^64-1, which is negative as an int
+		"column count": append(slices.Clip(tableMap), 0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, byte(TypeLong)),
+		"metadata length": append(slices.Clip(tableMap), 1, byte(TypeLong),
+			0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0),
+	}
+	for name, data := range tests {
+		if _, err := decodeTableMapEvent(data); err != errMalformed {
+			t.Errorf("%s: expected errMalformed, got %v", name, err)
+		}
+	}
+}
+
+func TestDecodeRowsEventMalformed(t *testing.T) {
+	s := &Streamer{tables: map[uint64]*TableMapEvent{
+		0x21: {TableID: 0x21, ColumnTypes: []ColumnType{TypeLong}, ColumnMeta: []uint16{0}},
+	}}
+
+	tests := map[string][]byte{
+		"extra data past the end": {0x21, 0, 0, 0, 0, 0, 1, 0, 0xff, 0xff},
+		"short extra data length": {0x21, 0, 0, 0, 0, 0, 1, 0, 1, 0, 1, 0x01, 0x00, 1, 0, 0, 0},
+		"column count":            {0x21, 0, 0, 0, 0, 0, 1, 0, 2, 0, 0xfe, 1, 0, 0, 0, 0, 0, 0, 0},
+	}
+	for name, data := range tests {
+		if _, err := s.decodeRowsEvent(WriteRowsEventType, data); err != errMalformed {
+			t.Errorf("%s: expected errMalformed, got %v", name, err)
+		}
+	}
+}
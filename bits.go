@@ -0,0 +1,72 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Bits is the value of a BIT(n) column, which MySQL returns as n/8 bytes in
+// big-endian order. Bit 0 is the least significant bit. It can also be passed
+// as an argument. NULL is not supported, use sql.Null[Bits] for nullable
+// columns.
+type Bits uint64
+
+// Scan implements sql.Scanner.
+func (b *Bits) Scan(src any) error {
+	switch src := src.(type) {
+	case []byte:
+		if len(src) > 8 {
+			return fmt.Errorf("mysql: %d bytes overflow Bits", len(src))
+		}
+		var v uint64
+		for _, c := range src {
+			v = v<<8 | uint64(c)
+		}
+		*b = Bits(v)
+	case int64:
+		*b = Bits(src)
+	case uint64:
+		*b = Bits(src)
+	default:
+		return fmt.Errorf("mysql: cannot scan %T into Bits", src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (b Bits) Value() (driver.Value, error) {
+	return uint64(b), nil
+}
+
+// Bit reports whether bit i is set.
+func (b Bits) Bit(i int) bool {
+	return i >= 0 && i < 64 && b&(1<<uint(i)) != 0
+}
+
+// Bools returns bits 0 to n-1, e.g. n is 8 for a BIT(8) column.
+func (b Bits) Bools(n int) []bool {
+	bools := make([]bool, n)
+	for i := range bools {
+		bools[i] = b.Bit(i)
+	}
+	return bools
+}
+
+// BitsOf returns the Bits with bit i set for every true bools[i].
+func BitsOf(bools []bool) Bits {
+	var b Bits
+	for i, set := range bools {
+		if set && i < 64 {
+			b |= 1 << uint(i)
+		}
+	}
+	return b
+}
@@ -0,0 +1,66 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestBitsScan(t *testing.T) {
+	tests := []struct {
+		src  any
+		want Bits
+	}{
+		{[]byte{}, 0},
+		{[]byte{0x05}, 5},
+		{[]byte{0x01, 0x02}, 0x0102},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, 1<<64 - 1},
+		{int64(3), 3},
+		{uint64(1 << 63), 1 << 63},
+	}
+	for _, tt := range tests {
+		var b Bits
+		if err := b.Scan(tt.src); err != nil {
+			t.Errorf("Scan(%#v): %v", tt.src, err)
+		} else if b != tt.want {
+			t.Errorf("Scan(%#v): expected %#x, got %#x", tt.src, tt.want, b)
+		}
+	}
+
+	var b Bits
+	if err := b.Scan(make([]byte, 9)); err == nil {
+		t.Error("expected an error for 9 bytes")
+	}
+	if err := b.Scan(nil); err == nil {
+		t.Error("expected an error for NULL")
+	}
+	var null sql.Null[Bits]
+	if err := null.Scan([]byte{1}); err != nil || !null.Valid || null.V != 1 {
+		t.Errorf("expected 1, got %+v, %v", null, err)
+	}
+}
+
+func TestBitsBools(t *testing.T) {
+	b := Bits(0b101)
+	if got, want := b.Bools(4), []bool{true, false, true, false}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if b.Bit(-1) || b.Bit(64) || !b.Bit(2) {
+		t.Error("unexpected Bit results")
+	}
+	if got := BitsOf([]bool{true, false, true}); got != b {
+		t.Errorf("expected %#b, got %#b", b, got)
+	}
+	v, err := converter{}.ConvertValue(Bits(1 << 63))
+	if err != nil || v != uint64(1<<63) {
+		t.Errorf("expected uint64 1<<63, got %#v, %v", v, err)
+	}
+}
@@ -10,11 +10,60 @@ package mysql
 
 import (
 	"io"
+	"math/bits"
+	"sync"
 )
 
 const defaultBufSize = 4096
 const maxCachedBufSize = 256 * 1024
 
+// Buffers are pooled in size classes of powers of two, from defaultBufSize
+// up to the max payload of a packet.
+const (
+	minBufClassShift = 12 // defaultBufSize
+	maxBufClassShift = 24 // maxPacketSize + 1
+)
+
+// bufPools holds the unused buffers of all connections. The buffers of
+// bufPools[i] have a capacity of defaultBufSize << i.
+var bufPools [maxBufClassShift - minBufClassShift + 1]sync.Pool
+
+// bufClass returns the index of the smallest size class holding size bytes,
+// or -1 if size exceeds the largest class.
+func bufClass(size int) int {
+	if size <= defaultBufSize {
+		return 0
+	}
+	c := bits.Len(uint(size-1)) - minBufClassShift
+	if c >= len(bufPools) {
+		return -1
+	}
+	return c
+}
+
+// getBuf returns a buffer of at least size bytes, taken from the pool if
+// possible.
+func getBuf(size int) []byte {
+	c := bufClass(size)
+	if c < 0 {
+		return make([]byte, size)
+	}
+	if p, ok := bufPools[c].Get().(*[]byte); ok {
+		return *p
+	}
+	return make([]byte, defaultBufSize<<c)
+}
+
+// putBuf returns buf to the pool. Nothing may use buf afterwards.
+func putBuf(buf []byte) {
+	c := bufClass(cap(buf))
+	if c < 0 || cap(buf) != defaultBufSize<<c {
+		return
+	}
+	buf = buf[:cap(buf)]
+	bufPools[c].Put(&buf)
+}
+
 // readerFunc is a function that compatible with io.Reader.
 // We use this function type instead of io.Reader because we want to
 // just pass mc.readWithTimeout.
@@ -26,14 +75,47 @@ type readerFunc func([]byte) (int, error)
 // The buffer is similar to bufio.Reader / Writer but zero-copy-ish
 // Also highly optimized for this particular use case.
 type buffer struct {
-	buf       []byte // read buffer.
-	cachedBuf []byte // buffer that will be reused. len(cachedBuf) <= maxCachedBufSize.
+	buf         []byte // read buffer.
+	cachedBuf   []byte // buffer that will be reused. len(cachedBuf) <= maxRetained.
+	size        int    // size of cachedBuf after shrink
+	maxRetained int    // max size of cachedBuf (0: maxCachedBufSize)
 }
 
-// newBuffer allocates and returns a new buffer.
+// newBuffer returns a new buffer using a buffer of the default size from the
+// pool.
 func newBuffer() buffer {
+	return newBufferSize(defaultBufSize)
+}
+
+// newBufferSize returns a new buffer reading up to size bytes at once, rounded
+// up to a power of two.
+func newBufferSize(size int) buffer {
+	buf := getBuf(size)
 	return buffer{
-		cachedBuf: make([]byte, defaultBufSize),
+		cachedBuf: buf,
+		size:      len(buf),
+	}
+}
+
+// retains reports whether a buffer of size bytes is kept for reuse.
+func (b *buffer) retains(size int) bool {
+	if size <= b.size {
+		return true
+	}
+	if b.maxRetained > 0 {
+		return size <= b.maxRetained
+	}
+	return size <= maxCachedBufSize
+}
+
+// shrink returns a grown buffer to the pool and replaces it by one of the
+// default size, so that idle connections don't hold on to the memory needed
+// for large packets. The read buffer must be empty, and no data read before
+// may be used afterwards.
+func (b *buffer) shrink() {
+	if size := max(b.size, defaultBufSize); cap(b.cachedBuf) > size {
+		putBuf(b.cachedBuf)
+		b.cachedBuf = getBuf(size)
 	}
 }
 
@@ -54,13 +136,16 @@ func (b *buffer) fill(need int, r readerFunc) error {
 
 	// grow buffer if necessary to fit the whole packet.
 	if need > len(dest) {
-		// Round up to the next multiple of the default size
-		dest = make([]byte, ((need/defaultBufSize)+1)*defaultBufSize)
-
-		// if the allocated buffer is not too large, move it to backing storage
-		// to prevent extra allocations on applications that perform large reads
-		if len(dest) <= maxCachedBufSize {
+		// if the buffer is not too large, move it to backing storage
+		// to prevent extra allocations on applications that perform large reads.
+		// The previous buffer isn't pooled, as data read from it may
+		// still be in use. Buffers which aren't kept are made of the exact
+		// size, as they aren't pooled either.
+		if b.retains(need) {
+			dest = getBuf(need)
 			b.cachedBuf = dest
+		} else {
+			dest = make([]byte, need)
 		}
 	}
 
@@ -111,9 +196,9 @@ func (b *buffer) takeBuffer(length int) ([]byte, error) {
 		return b.cachedBuf[:length], nil
 	}
 
-	if length < maxCachedBufSize {
-		b.cachedBuf = make([]byte, length)
-		return b.cachedBuf, nil
+	if b.retains(length) {
+		b.cachedBuf = getBuf(length)
+		return b.cachedBuf[:length], nil
 	}
 
 	// buffer is larger than we want to store.
@@ -143,7 +228,7 @@ func (b *buffer) takeCompleteBuffer() ([]byte, error) {
 
 // store stores buf, an updated buffer, if its suitable to do so.
 func (b *buffer) store(buf []byte) {
-	if cap(buf) <= maxCachedBufSize && cap(buf) > cap(b.cachedBuf) {
+	if b.retains(cap(buf)) && cap(buf) > cap(b.cachedBuf) {
 		b.cachedBuf = buf[:cap(buf)]
 	}
 }
@@ -0,0 +1,139 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBufClass(t *testing.T) {
+	tests := []struct {
+		size, class int
+	}{
+		{0, 0},
+		{defaultBufSize, 0},
+		{defaultBufSize + 1, 1},
+		{2 * defaultBufSize, 1},
+		{maxCachedBufSize, 6},
+		{maxPacketSize + 1, maxBufClassShift - minBufClassShift},
+		{maxPacketSize + 5, -1},
+	}
+	for _, tt := range tests {
+		if c := bufClass(tt.size); c != tt.class {
+			t.Errorf("bufClass(%d): want %d, got %d", tt.size, tt.class, c)
+		}
+		if buf := getBuf(tt.size); len(buf) < tt.size || len(buf) != cap(buf) {
+			t.Errorf("getBuf(%d): got len %d, cap %d", tt.size, len(buf), cap(buf))
+		}
+	}
+}
+
+func TestBufferFillRetains(t *testing.T) {
+	data := bytes.Repeat([]byte{'x'}, 3*defaultBufSize)
+	read := func(b []byte) (int, error) {
+		n := copy(b, data)
+		data = data[n:]
+		return n, nil
+	}
+
+	b := newBuffer()
+	b.maxRetained = 2 * defaultBufSize
+	if err := b.fill(3*defaultBufSize, read); err != nil {
+		t.Fatal(err)
+	}
+	if b.len() != 3*defaultBufSize {
+		t.Fatalf("expected %d bytes buffered, got %d", 3*defaultBufSize, b.len())
+	}
+	if cap(b.cachedBuf) != defaultBufSize {
+		t.Errorf("buffer larger than maxRetained was kept: cap %d", cap(b.cachedBuf))
+	}
+	if cap(b.buf) != 3*defaultBufSize {
+		t.Errorf("expected a buffer of the packet size, got cap %d", cap(b.buf))
+	}
+	b.readNext(b.len())
+
+	b.maxRetained = 0
+	data = bytes.Repeat([]byte{'y'}, 3*defaultBufSize)
+	if err := b.fill(3*defaultBufSize, read); err != nil {
+		t.Fatal(err)
+	}
+	if cap(b.cachedBuf) != 4*defaultBufSize {
+		t.Errorf("expected the buffer to be kept, got cap %d", cap(b.cachedBuf))
+	}
+	b.readNext(b.len())
+
+	b.shrink()
+	if cap(b.cachedBuf) != defaultBufSize {
+		t.Errorf("expected shrink to restore the default size, got cap %d", cap(b.cachedBuf))
+	}
+}
+
+func TestBufferTakeBufferRetains(t *testing.T) {
+	b := newBuffer()
+	b.maxRetained = 8 * defaultBufSize
+
+	buf, err := b.takeBuffer(5 * defaultBufSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf) != 5*defaultBufSize || cap(b.cachedBuf) != 8*defaultBufSize {
+		t.Errorf("expected a retained buffer of the size class, got len %d, cap %d", len(buf), cap(b.cachedBuf))
+	}
+
+	if buf, err = b.takeBuffer(9 * defaultBufSize); err != nil {
+		t.Fatal(err)
+	}
+	if len(buf) != 9*defaultBufSize || cap(b.cachedBuf) != 8*defaultBufSize {
+		t.Errorf("buffer larger than maxRetained was kept: len %d, cap %d", len(buf), cap(b.cachedBuf))
+	}
+}
+
+func TestReadBufferSize(t *testing.T) {
+	b := newBufferSize(10000)
+	if len(b.cachedBuf) != 4*defaultBufSize {
+		t.Fatalf("expected the size to be rounded up to %d, got %d", 4*defaultBufSize, len(b.cachedBuf))
+	}
+	b.cachedBuf = getBuf(8 * defaultBufSize)
+	b.shrink()
+	if len(b.cachedBuf) != 4*defaultBufSize {
+		t.Errorf("expected shrink to keep the read buffer size, got %d", len(b.cachedBuf))
+	}
+}
+
+// benchmarkReadSmallPackets reads a result set of 1000 rows of 50 bytes with
+// a read buffer of size bytes.
+func benchmarkReadSmallPackets(b *testing.B, size int) {
+	const rows = 1000
+	var data []byte
+	for i := 0; i < rows; i++ {
+		data = append(data, mockPacket(byte(i), bytes.Repeat([]byte{'x'}, 50)...)...)
+	}
+
+	conn, mc := newRWMockConn(0)
+	mc.buf = newBufferSize(size)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		conn.data = data
+		mc.sequence = 0
+		for j := 0; j < rows; j++ {
+			if _, err := mc.readPacket(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.ReportMetric(float64(conn.reads)/float64(b.N), "reads/op")
+}
+
+func BenchmarkReadSmallPackets(b *testing.B) {
+	b.Run("4KiB", func(b *testing.B) { benchmarkReadSmallPackets(b, defaultBufSize) })
+	b.Run("64KiB", func(b *testing.B) { benchmarkReadSmallPackets(b, 64*1024) })
+}
@@ -0,0 +1,332 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// BulkExecer is implemented by the connections of this driver.
+//
+// This is accessible through sql.Conn.Raw():
+//
+//	err := conn.Raw(func(driverConn any) error {
+//		_, err := driverConn.(mysql.BulkExecer).ExecBulk(ctx,
+//			"INSERT INTO t (id, name) VALUES (?, ?)",
+//			[][]any{{1, "a"}, {2, "b"}, {3, "c"}})
+//		return err
+//	})
+type BulkExecer interface {
+	// ExecBulk executes query once for each set of arguments.
+	//
+	// MariaDB 10.2 and newer receive all argument sets with a single
+	// COM_STMT_BULK_EXECUTE. Other servers execute them one by one. The
+	// returned result holds the sum of the affected rows and the first
+	// inserted ID.
+	ExecBulk(ctx context.Context, query string, args [][]any) (driver.Result, error)
+}
+
+func (mc *mysqlConn) ExecBulk(ctx context.Context, query string, args [][]any) (driver.Result, error) {
+	query, _, err := mc.interceptQuery(ctx, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	dargs, err := convertBulkArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mc.watchCancel(ctx); err != nil {
+		return nil, err
+	}
+	defer mc.finish()
+
+	if err := mc.overrideConfig(ctx); err != nil {
+		return nil, err
+	}
+	if err := mc.switchTenant(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, span := mc.startHook(ctx, HookInfo{Op: HookExec, Query: query})
+	ds, err := mc.Prepare(query)
+	if err != nil {
+		return nil, span.end(err)
+	}
+	stmt := ds.(*mysqlStmt)
+
+	res, err := stmt.execBulk(dargs)
+	if cerr := stmt.Close(); err == nil {
+		err = cerr
+	}
+	if err = span.endExec(res, err); err != nil {
+		return nil, err
+	}
+	recordGTID(ctx, res)
+	return res, nil
+}
+
+// ExecBulk executes the statement once for each set of arguments, like
+// BulkExecer.ExecBulk.
+func (stmt *mysqlStmt) ExecBulk(ctx context.Context, args [][]any) (driver.Result, error) {
+	dargs, err := convertBulkArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stmt.mc.watchCancel(ctx); err != nil {
+		return nil, err
+	}
+	defer stmt.mc.finish()
+
+	if err := stmt.mc.overrideConfig(ctx); err != nil {
+		return nil, err
+	}
+	if err := stmt.mc.switchTenant(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, span := stmt.mc.startHook(ctx, HookInfo{Op: HookExec, Query: stmt.queryStr})
+	res, err := stmt.execBulk(dargs)
+	if err == nil {
+		recordGTID(ctx, res)
+	}
+	return res, span.endExec(res, err)
+}
+
+func convertBulkArgs(args [][]any) ([][]driver.Value, error) {
+	dargs := make([][]driver.Value, len(args))
+	for i, row := range args {
+		dargs[i] = make([]driver.Value, len(row))
+		for j, arg := range row {
+			v, err := converter{}.ConvertValue(arg)
+			if err != nil {
+				return nil, fmt.Errorf("bulk argument set %d, parameter %d: %w", i, j, err)
+			}
+			dargs[i][j] = v
+		}
+	}
+	return dargs, nil
+}
+
+func (stmt *mysqlStmt) execBulk(args [][]driver.Value) (driver.Result, error) {
+	mc := stmt.mc
+	if mc.closed.Load() {
+		return nil, driver.ErrBadConn
+	}
+	for i, row := range args {
+		if len(row) != stmt.paramCount {
+			return nil, fmt.Errorf(
+				"argument count mismatch in bulk argument set %d (got: %d; has: %d)",
+				i,
+				len(row),
+				stmt.paramCount,
+			)
+		}
+	}
+
+	total := &mysqlResult{affectedRows: []int64{0}, insertIds: []int64{0}, warningCounts: []int{0}}
+	addResult := func(res driver.Result) {
+		r := res.(*mysqlResult)
+		for _, n := range r.affectedRows {
+			total.affectedRows[0] += n
+		}
+		if len(r.insertIds) > 0 && total.insertIds[0] == 0 {
+			total.insertIds[0] = r.insertIds[0]
+		}
+		if r.lastGTID != "" {
+			total.lastGTID = r.lastGTID
+		}
+		total.warnings += r.warnings
+		total.warningCounts[0] += r.warnings
+	}
+
+	if mc.extCapabilities&clientStmtBulkOperations == 0 || stmt.paramCount == 0 {
+		for _, row := range args {
+			res, err := stmt.Exec(row)
+			if err != nil {
+				return nil, err
+			}
+			addResult(res)
+		}
+		return total, nil
+	}
+
+	types, err := bulkParamTypes(args, stmt.paramCount)
+	if err != nil {
+		return nil, err
+	}
+
+	// command [1 byte], statement_id [4 bytes], bulk flags [2 bytes],
+	// parameter types [2 bytes each]
+	data := make([]byte, 4, 4+1+4+2+len(types))
+	data = append(data, comStmtBulkExecute)
+	data = binary.LittleEndian.AppendUint32(data, stmt.id)
+	data = binary.LittleEndian.AppendUint16(data, bulkSendTypesToServer)
+	data = append(data, types...)
+	headerLen := len(data)
+
+	// The argument sets are split across several commands if they don't fit
+	// into a single packet.
+	var row []byte
+	for i := 0; i < len(args); i++ {
+		if row, err = mc.appendBulkRow(row[:0], args[i]); err != nil {
+			return nil, err
+		}
+		if len(data)+len(row)-4 > mc.maxAllowedPacket {
+			if len(data) == headerLen {
+				return nil, ErrPktTooLarge
+			}
+			res, err := stmt.writeBulkExecute(data)
+			if err != nil {
+				return nil, err
+			}
+			addResult(res)
+			data = data[:headerLen]
+		}
+		data = append(data, row...)
+	}
+	if len(data) > headerLen {
+		res, err := stmt.writeBulkExecute(data)
+		if err != nil {
+			return nil, err
+		}
+		addResult(res)
+	}
+	return total, nil
+}
+
+// writeBulkExecute sends a COM_STMT_BULK_EXECUTE packet and reads its result.
+func (stmt *mysqlStmt) writeBulkExecute(data []byte) (driver.Result, error) {
+	mc := stmt.mc
+
+	// Reset packet-sequence
+	mc.resetSequence()
+	err := mc.writePacket(data)
+	mc.syncSequence()
+	if err != nil {
+		return nil, mc.markBadConn(err)
+	}
+	return stmt.readExecResult()
+}
+
+// bulkParamTypes returns the parameter types of COM_STMT_BULK_EXECUTE. The
+// type of each parameter is taken from its first non-NULL value.
+func bulkParamTypes(args [][]driver.Value, paramCount int) ([]byte, error) {
+	types := make([]byte, 0, 2*paramCount)
+	for i := 0; i < paramCount; i++ {
+		typ, flags := fieldTypeNULL, byte(0x00)
+		var first driver.Value
+		for _, row := range args {
+			t, f, ok := bulkParamType(row[i])
+			if !ok {
+				return nil, fmt.Errorf("cannot convert type: %T", row[i])
+			}
+			if t == fieldTypeNULL {
+				continue
+			}
+			if typ == fieldTypeNULL {
+				typ, flags, first = t, f, row[i]
+				continue
+			}
+			if t != typ || f != flags {
+				return nil, fmt.Errorf("bulk parameter %d has mixed types %T and %T", i, first, row[i])
+			}
+		}
+		types = append(types, byte(typ), flags)
+	}
+	return types, nil
+}
+
+func bulkParamType(v driver.Value) (fieldType, byte, bool) {
+	switch v := v.(type) {
+	case nil:
+		return fieldTypeNULL, 0x00, true
+	case int64:
+		return fieldTypeLongLong, 0x00, true
+	case uint64:
+		return fieldTypeLongLong, 0x80, true // type is unsigned
+	case float64:
+		return fieldTypeDouble, 0x00, true
+	case bool:
+		return fieldTypeTiny, 0x00, true
+	case []byte:
+		if v == nil {
+			return fieldTypeNULL, 0x00, true
+		}
+		return fieldTypeString, 0x00, true
+	case json.RawMessage, string, time.Time:
+		return fieldTypeString, 0x00, true
+	}
+	return 0, 0, false
+}
+
+// appendBulkRow appends the indicators and values of one argument set to data.
+func (mc *mysqlConn) appendBulkRow(data []byte, args []driver.Value) ([]byte, error) {
+	for _, arg := range args {
+		if v, ok := arg.(json.RawMessage); ok {
+			arg = []byte(v)
+		}
+
+		switch v := arg.(type) {
+		case nil:
+			data = append(data, bulkIndicatorNull)
+		case int64:
+			data = append(data, bulkIndicatorNone)
+			data = binary.LittleEndian.AppendUint64(data, uint64(v))
+		case uint64:
+			data = append(data, bulkIndicatorNone)
+			data = binary.LittleEndian.AppendUint64(data, v)
+		case float64:
+			data = append(data, bulkIndicatorNone)
+			data = binary.LittleEndian.AppendUint64(data, math.Float64bits(v))
+		case bool:
+			data = append(data, bulkIndicatorNone)
+			if v {
+				data = append(data, 0x01)
+			} else {
+				data = append(data, 0x00)
+			}
+		case []byte:
+			if v == nil {
+				data = append(data, bulkIndicatorNull)
+				break
+			}
+			data = append(data, bulkIndicatorNone)
+			data = appendLengthEncodedInteger(data, uint64(len(v)))
+			data = append(data, v...)
+		case string:
+			data = append(data, bulkIndicatorNone)
+			data = appendLengthEncodedString(data, v)
+		case time.Time:
+			var a [64]byte
+			var b = a[:0]
+			if v.IsZero() {
+				b = append(b, "0000-00-00"...)
+			} else {
+				var err error
+				if b, err = appendDateTime(b, v.In(mc.cfg.Loc), mc.cfg.timeTruncate); err != nil {
+					return nil, err
+				}
+			}
+			data = append(data, bulkIndicatorNone)
+			data = appendLengthEncodedInteger(data, uint64(len(b)))
+			data = append(data, b...)
+		default:
+			return nil, fmt.Errorf("cannot convert type: %T", arg)
+		}
+	}
+	return data, nil
+}
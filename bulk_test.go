@@ -0,0 +1,113 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestExecBulk(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.extCapabilities |= clientStmtBulkOperations
+	stmt := &mysqlStmt{mc: mc, id: 5, paramCount: 2}
+
+	// OK: 2 affected rows, insert id 7
+	conn.queuedReplies = [][]byte{{7, 0, 0, 1, iOK, 2, 7, 2, 0, 0, 0}}
+
+	res, err := stmt.execBulk([][]driver.Value{
+		{int64(1), "a"},
+		{nil, "bc"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Errorf("expected 2 affected rows, got %d", n)
+	}
+	if id, _ := res.LastInsertId(); id != 7 {
+		t.Errorf("expected insert id 7, got %d", id)
+	}
+
+	expected := []byte{
+		28, 0, 0, 0, comStmtBulkExecute, 5, 0, 0, 0, 128, 0,
+		byte(fieldTypeLongLong), 0, byte(fieldTypeString), 0,
+		bulkIndicatorNone, 1, 0, 0, 0, 0, 0, 0, 0, bulkIndicatorNone, 1, 'a',
+		bulkIndicatorNull, bulkIndicatorNone, 2, 'b', 'c',
+	}
+	if !bytes.Equal(conn.written, expected) {
+		t.Errorf("expected %x, got %x", expected, conn.written)
+	}
+}
+
+func TestExecBulkSplit(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.extCapabilities |= clientStmtBulkOperations
+	mc.maxAllowedPacket = 16
+	stmt := &mysqlStmt{mc: mc, id: 5, paramCount: 1}
+
+	conn.queuedReplies = [][]byte{
+		{7, 0, 0, 1, iOK, 1, 3, 2, 0, 0, 0},
+		{7, 0, 0, 1, iOK, 1, 4, 2, 0, 0, 0},
+	}
+
+	res, err := stmt.execBulk([][]driver.Value{{"abcd"}, {"efgh"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Errorf("expected 2 affected rows, got %d", n)
+	}
+	if id, _ := res.LastInsertId(); id != 3 {
+		t.Errorf("expected insert id 3, got %d", id)
+	}
+	if n := bytes.Count(conn.written, []byte{comStmtBulkExecute}); n != 2 {
+		t.Errorf("expected 2 bulk commands, got %d", n)
+	}
+
+	mc.maxAllowedPacket = 8
+	if _, err := stmt.execBulk([][]driver.Value{{"abcd"}}); err != ErrPktTooLarge {
+		t.Errorf("expected ErrPktTooLarge, got %v", err)
+	}
+}
+
+func TestExecBulkMixedTypes(t *testing.T) {
+	_, mc := newRWMockConn(0)
+	mc.extCapabilities |= clientStmtBulkOperations
+	stmt := &mysqlStmt{mc: mc, id: 5, paramCount: 1}
+
+	if _, err := stmt.execBulk([][]driver.Value{{int64(1)}, {"a"}}); err == nil {
+		t.Error("expected error for mixed parameter types")
+	}
+	if _, err := stmt.execBulk([][]driver.Value{{int64(1), int64(2)}}); err == nil {
+		t.Error("expected error for argument count mismatch")
+	}
+}
+
+func TestExecBulkFallback(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	stmt := &mysqlStmt{mc: mc, id: 5, paramCount: 1}
+
+	conn.queuedReplies = [][]byte{
+		{7, 0, 0, 1, iOK, 1, 3, 2, 0, 0, 0},
+		{7, 0, 0, 1, iOK, 1, 4, 2, 0, 0, 0},
+	}
+
+	res, err := stmt.execBulk([][]driver.Value{{int64(1)}, {int64(2)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Errorf("expected 2 affected rows, got %d", n)
+	}
+	if bytes.Contains(conn.written, []byte{comStmtBulkExecute}) {
+		t.Error("expected COM_STMT_EXECUTE without bulk support")
+	}
+}
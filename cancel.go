@@ -0,0 +1,87 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/colussim/mysql-auth-oidc-go/mysqlerrors"
+)
+
+// Modes of CancelMode
+const (
+	CancelModeClose = "close"
+	CancelModeKill  = "kill"
+)
+
+// CancelMode sets what happens when the context of a query is done before
+// the query:
+//
+//   - CancelModeClose: the connection is closed, the default. The server
+//     only notices when it writes to the connection, so it may keep working
+//     on the query for a long time.
+//   - CancelModeKill: the connection is closed, then KILL QUERY is sent for
+//     it over a new connection of the same connector, so the server stops
+//     the query right away. The new connection is closed afterwards.
+//
+// KILL QUERY is given the dial timeout (see Timeout), or 5 seconds. Connects
+// canceled during the handshake are closed in either mode.
+func CancelMode(mode string) Option {
+	return func(cfg *Config) error {
+		switch mode {
+		case CancelModeClose, CancelModeKill:
+			cfg.cancelMode = mode
+			return nil
+		}
+		return fmt.Errorf("invalid cancelMode value: %s", mode)
+	}
+}
+
+// killQuery stops the query of the canceled connection on the server with
+// KILL QUERY, sent over an auxiliary connection.
+func (mc *mysqlConn) killQuery() {
+	if mc.connector == nil || mc.threadID == 0 {
+		return
+	}
+
+	timeout := mc.cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultKillTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn, err := mc.connector.Connect(ctx)
+	if err != nil {
+		mc.log("KILL QUERY:", err)
+		return
+	}
+	defer conn.Close()
+
+	aux := conn.(*mysqlConn)
+	aux.auxiliary = true
+	if err := aux.watchCancel(ctx); err != nil {
+		mc.log("KILL QUERY:", err)
+		return
+	}
+	defer aux.finish()
+
+	err = aux.exec("KILL QUERY " + strconv.FormatUint(uint64(mc.threadID), 10))
+	var mysqlErr *MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlerrors.ER_NO_SUCH_THREAD {
+		// ER_NO_SUCH_THREAD: the server noticed the closed connection first
+		return
+	}
+	if err != nil {
+		mc.log("KILL QUERY:", err)
+	}
+}
@@ -0,0 +1,78 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestCancelModeKill(t *testing.T) {
+	for _, mode := range []string{"", CancelModeClose, CancelModeKill} {
+		cfg := NewConfig()
+		cfg.Logger = &NopLogger{}
+		if mode != "" {
+			if err := cfg.Apply(CancelMode(mode)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		dials := 0
+		cfg.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dials++
+			return nil, errors.New("no route configured")
+		}
+
+		_, mc := newRWMockConn(0)
+		mc.cfg = cfg
+		mc.connector = newConnector(cfg)
+		mc.threadID = 42
+		mc.established = true
+		mc.cancel(context.Canceled)
+
+		if !mc.closed.Load() {
+			t.Errorf("cancelMode=%q: expected the connection to be closed", mode)
+		}
+		if err := mc.error(); err != context.Canceled {
+			t.Errorf("cancelMode=%q: expected context.Canceled, got %v", mode, err)
+		}
+		if want := map[bool]int{false: 0, true: 1}[mode == CancelModeKill]; dials != want {
+			t.Errorf("cancelMode=%q: expected %d dials for KILL QUERY, got %d", mode, want, dials)
+		}
+	}
+
+	// neither a handshake nor KILL QUERY itself is killed
+	for _, aux := range []bool{false, true} {
+		cfg := NewConfig()
+		cfg.Logger = &NopLogger{}
+		if err := cfg.Apply(CancelMode(CancelModeKill)); err != nil {
+			t.Fatal(err)
+		}
+		dials := 0
+		cfg.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dials++
+			return nil, errors.New("no route configured")
+		}
+
+		_, mc := newRWMockConn(0)
+		mc.cfg = cfg
+		mc.connector = newConnector(cfg)
+		mc.threadID = 42
+		mc.established, mc.auxiliary = aux, aux
+		mc.cancel(context.DeadlineExceeded)
+		if dials != 0 {
+			t.Errorf("established=%v, auxiliary=%v: expected no KILL QUERY, got %d dials", aux, aux, dials)
+		}
+	}
+
+	if err := NewConfig().Apply(CancelMode("abort")); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
@@ -0,0 +1,126 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// UserChanger is implemented by the connections of this driver.
+//
+// This is accessible through sql.Conn.Raw():
+//
+//	err := conn.Raw(func(driverConn any) error {
+//		return driverConn.(mysql.UserChanger).ChangeUser(ctx, tenantCfg)
+//	})
+type UserChanger interface {
+	// ChangeUser re-authenticates the connection with COM_CHANGE_USER.
+	//
+	// User, Passwd, DBName and Params of cfg, including the OpenID Connect
+	// parameters, replace those of the connection, as do the options
+	// allowing authentication plugins and ServerPubKey. Other fields of cfg
+	// are ignored.
+	//
+	// The server resets the session: prepared statements, user variables
+	// and temporary tables are discarded, and the system variables of
+	// cfg.Params are set again. If the authentication fails, the connection
+	// is closed.
+	ChangeUser(ctx context.Context, cfg *Config) error
+}
+
+func (mc *mysqlConn) ChangeUser(ctx context.Context, cfg *Config) error {
+	if mc.closed.Load() || mc.buf.busy() {
+		return driver.ErrBadConn
+	}
+
+	mc.restoreConfig()
+	cfg = cfg.Clone()
+	if err := cfg.normalize(); err != nil {
+		return err
+	}
+	newCfg := mc.cfg.Clone()
+	newCfg.User = cfg.User
+	newCfg.Passwd = cfg.Passwd
+	newCfg.DBName = cfg.DBName
+	newCfg.Params = cfg.Params
+	newCfg.AllowCleartextPasswords = cfg.AllowCleartextPasswords
+	newCfg.AllowNativePasswords = cfg.AllowNativePasswords
+	newCfg.AllowOldPasswords = cfg.AllowOldPasswords
+	newCfg.ServerPubKey = cfg.ServerPubKey
+	newCfg.pubKey = cfg.pubKey
+
+	if err := mc.watchCancel(ctx); err != nil {
+		return err
+	}
+	defer mc.finish()
+
+	oldCfg, oldToken := mc.cfg, mc.oidcToken
+	mc.cfg, mc.oidcToken = newCfg, nil
+	authResp, plugin, err := mc.changeUserAuthResponse()
+	if err != nil {
+		mc.cfg, mc.oidcToken = oldCfg, oldToken
+		return err
+	}
+
+	mc.clearResult()
+	mc.redactTraffic(true)
+	defer mc.redactTraffic(false)
+	if err := mc.writeChangeUserPacket(authResp, plugin); err != nil {
+		return mc.markBadConn(err)
+	}
+	if err := mc.handleAuthResult(mc.scramble, plugin); err != nil {
+		// The session of the previous user is gone.
+		mc.cleanup()
+		return err
+	}
+
+	// The server discarded the prepared statements and the session state.
+	mc.discardCachedStmts()
+	mc.tenantID = ""
+	mc.tenantSession = TenantSession{}
+
+	return mc.initSession()
+}
+
+// changeUserAuthResponse returns the auth response sent with COM_CHANGE_USER
+// and the plugin it was computed for.
+func (mc *mysqlConn) changeUserAuthResponse() ([]byte, string, error) {
+	plugin := mc.authPlugin
+	if plugin == "" {
+		plugin = mc.cfg.defaultAuthPlugin()
+	}
+	if v, ok := mc.cfg.Params["auth_client_plugin"]; ok && v != "" {
+		plugin = v
+	}
+
+	var authResp []byte
+	var err error
+	if isOIDCPlugin(plugin) {
+		authResp, err = mc.oidcAuthResponse(nil)
+	} else {
+		authResp, err = mc.auth(mc.scramble, plugin)
+		if err != nil {
+			// try the default auth plugin, if using the previous plugin failed
+			plugin = mc.cfg.defaultAuthPlugin()
+			authResp, err = mc.auth(mc.scramble, plugin)
+		}
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	// The auth response of COM_CHANGE_USER is limited to 255 bytes, which
+	// most ID tokens exceed. Without a response for the plugin of the user,
+	// the server requests it with an auth switch.
+	if len(authResp) > 255 {
+		return nil, mc.cfg.defaultAuthPlugin(), nil
+	}
+	return authResp, plugin, nil
+}
@@ -0,0 +1,121 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newChangeUserMockConn() (*mockConn, *mysqlConn) {
+	conn, mc := newRWMockConn(0)
+	mc.capabilities = clientPluginAuth
+	mc.authPlugin = "mysql_native_password"
+	mc.scramble = []byte("abcdefghijklmnopqrst")
+	mc.stmtCache = newStmtCache(2)
+	return conn, mc
+}
+
+func TestChangeUser(t *testing.T) {
+	conn, mc := newChangeUserMockConn()
+	mc.stmtCache.put("SELECT ?", &mysqlStmt{mc: mc, id: 1})
+	mc.tenantID = "acme"
+	conn.queuedReplies = [][]byte{{7, 0, 0, 1, iOK, 0, 0, 2, 0, 0, 0}}
+
+	cfg := NewConfig()
+	cfg.User = "bob"
+	cfg.Passwd = "secret"
+	cfg.DBName = "app"
+	if err := mc.ChangeUser(context.Background(), cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected []byte
+	expected = append(expected, comChangeUser)
+	expected = append(expected, "bob\x00"...)
+	expected = append(expected, 20)
+	expected = append(expected, scramblePassword(mc.scramble, "secret")...)
+	expected = append(expected, "app\x00"...)
+	expected = append(expected, defaultCollationID, 0)
+	expected = append(expected, "mysql_native_password\x00"...)
+	if !bytes.Equal(conn.written[4:], expected) {
+		t.Errorf("expected %q, got %q", expected, conn.written[4:])
+	}
+
+	if mc.cfg.User != "bob" || mc.cfg.DBName != "app" {
+		t.Errorf("expected the configuration of the new user, got %q@%q", mc.cfg.User, mc.cfg.DBName)
+	}
+	if mc.stmtCache.len() != 0 {
+		t.Error("expected the statement cache to be cleared")
+	}
+	if mc.tenantID != "" {
+		t.Error("expected the tenant session to be reset")
+	}
+}
+
+func TestChangeUserFailure(t *testing.T) {
+	conn, mc := newChangeUserMockConn()
+	conn.queuedReplies = [][]byte{{9, 0, 0, 1, iERR, 0x15, 0x04, '#', '2', '8', '0', '0', '0'}}
+
+	cfg := NewConfig()
+	cfg.User = "mallory"
+	err := mc.ChangeUser(context.Background(), cfg)
+	var mysqlErr *MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1045 {
+		t.Fatalf("expected access denied error, got %v", err)
+	}
+	if !mc.closed.Load() {
+		t.Error("expected the connection to be closed")
+	}
+}
+
+func TestChangeUserOIDCAuthSwitch(t *testing.T) {
+	conn, mc := newChangeUserMockConn()
+
+	token := strings.Repeat("t", 300)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte(token+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// auth switch to the OIDC client plugin, then OK
+	plugin := "authentication_openid_connect_client"
+	authSwitch := append([]byte{byte(1 + len(plugin) + 1), 0, 0, 1, iEOF}, plugin...)
+	authSwitch = append(authSwitch, 0)
+	conn.queuedReplies = [][]byte{authSwitch, {7, 0, 0, 3, iOK, 0, 0, 2, 0, 0, 0}}
+
+	cfg := NewConfig()
+	cfg.User = "alice"
+	cfg.Params = map[string]string{
+		"auth_client_plugin": plugin,
+		"authentication_openid_connect_client_id_token_file": tokenFile,
+	}
+	if err := mc.ChangeUser(context.Background(), cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// The token doesn't fit into COM_CHANGE_USER and is sent with the
+	// auth switch response.
+	expected := append([]byte("alice\x00\x00\x00"), defaultCollationID, 0)
+	expected = append(expected, "mysql_native_password\x00"...)
+	if !bytes.Contains(conn.written, expected) {
+		t.Errorf("expected empty auth response with COM_CHANGE_USER, got %q", conn.written)
+	}
+	if !bytes.HasSuffix(conn.written, append([]byte{0x01, 0xfc, 0x2c, 0x01}, token...)) {
+		t.Errorf("expected the token with the auth switch response, got %q", conn.written)
+	}
+	if mc.authPlugin != plugin {
+		t.Errorf("expected auth plugin %q, got %q", plugin, mc.authPlugin)
+	}
+}
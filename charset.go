@@ -0,0 +1,111 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const defaultCollation = "utf8mb4_general_ci"
+
+// mysqlOnlyCollations are the collations of the collations map which MariaDB
+// doesn't know by their id. MariaDB falls back to the default collation of
+// the server for unknown ids in the handshake, e.g. latin1_swedish_ci.
+var mysqlOnlyCollations = map[string]bool{
+	"utf8mb4_0900_ai_ci": true,
+}
+
+// collationAliases maps collations to the collation MariaDB reports for them.
+var collationAliases = map[string]string{
+	"utf8mb4_0900_ai_ci": "utf8mb4_uca1400_ai_ci",
+}
+
+// VerifyCollation makes connections check the collation of the connection
+// after setting up the session, and fail with an error wrapping
+// ErrCollationMismatch if it isn't the configured one, instead of silently
+// using e.g. the latin1 default of the server. The collation is checked if
+// Collation is set, else the charset if charset is set, else the default
+// collation utf8mb4_general_ci.
+//
+// The value the server reports through session state tracking is used when
+// available (see session_track_system_variables), else it is queried.
+func VerifyCollation(yes bool) Option {
+	return func(cfg *Config) error {
+		cfg.verifyCollation = yes
+		return nil
+	}
+}
+
+// handshakeCollation reports whether the collation can be set with its id in
+// the handshake, which only has 1 byte for it.
+func (mc *mysqlConn) handshakeCollation(name string) (byte, bool) {
+	id, ok := collations[name]
+	if !ok || mysqlOnlyCollations[name] && mc.capabilities&clientMySQL == 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// sessionCharsets returns the charsets SET NAMES is tried with. If only a
+// collation is configured which can't be set in the handshake, it is set
+// with the charset of the collation.
+func (mc *mysqlConn) sessionCharsets() []string {
+	if len(mc.cfg.charsets) > 0 {
+		return mc.cfg.charsets
+	}
+	if name := mc.cfg.Collation; name != "" {
+		if _, ok := mc.handshakeCollation(name); !ok {
+			if cs, _, ok := strings.Cut(name, "_"); ok {
+				return []string{cs}
+			}
+		}
+	}
+	return nil
+}
+
+// checkCollation returns an error wrapping ErrCollationMismatch if the
+// collation, or the charset if only charsets are configured, of the
+// connection isn't the configured one.
+func (mc *mysqlConn) checkCollation() error {
+	name, want := "collation_connection", []string{mc.cfg.Collation}
+	if mc.cfg.Collation == "" {
+		if len(mc.cfg.charsets) > 0 {
+			name, want = "character_set_connection", mc.cfg.charsets
+		} else {
+			want = []string{defaultCollation}
+		}
+	}
+
+	got, ok := mc.connectVars[name]
+	if !ok {
+		v, err := mc.getSystemVar(name)
+		if err != nil {
+			return err
+		}
+		got = string(v)
+	}
+
+	if slices.ContainsFunc(want, func(w string) bool {
+		return normalizeCollation(w) == normalizeCollation(got) || collationAliases[w] == got
+	}) {
+		return nil
+	}
+	return fmt.Errorf("%w: requested %s, got %s", ErrCollationMismatch, strings.Join(want, " or "), got)
+}
+
+// normalizeCollation replaces the utf8 alias of utf8mb3 in the name of a
+// charset or collation, which MySQL 8.0.30+ reports as utf8mb3.
+func normalizeCollation(name string) string {
+	if name == "utf8" || strings.HasPrefix(name, "utf8_") {
+		return "utf8mb3" + name[len("utf8"):]
+	}
+	return name
+}
@@ -0,0 +1,97 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCollationID(t *testing.T) {
+	_, mc := newRWMockConn(0)
+	for _, tt := range []struct {
+		collation string
+		mysql     bool
+		id        byte
+		charsets  []string
+	}{
+		{"", true, defaultCollationID, nil},
+		{"utf8mb4_unicode_ci", false, 224, nil},
+		{"utf8mb4_0900_ai_ci", true, 255, nil},
+		{"utf8mb4_0900_ai_ci", false, defaultCollationID, []string{"utf8mb4"}},
+		{"utf8mb4_0900_as_cs", true, defaultCollationID, []string{"utf8mb4"}},
+		{"utf8mb4_uca1400_ai_ci", false, defaultCollationID, []string{"utf8mb4"}},
+	} {
+		mc.cfg.Collation = tt.collation
+		mc.capabilities = 0
+		if tt.mysql {
+			mc.capabilities = clientMySQL
+		}
+		if id := mc.collationID(); id != tt.id {
+			t.Errorf("%q (MySQL: %v): expected id %d, got %d", tt.collation, tt.mysql, tt.id, id)
+		}
+		if cs := mc.sessionCharsets(); !reflect.DeepEqual(cs, tt.charsets) {
+			t.Errorf("%q (MySQL: %v): expected charsets %v, got %v", tt.collation, tt.mysql, tt.charsets, cs)
+		}
+	}
+
+	mc.cfg.charsets = []string{"utf8mb4", "utf8"}
+	if cs := mc.sessionCharsets(); !reflect.DeepEqual(cs, mc.cfg.charsets) {
+		t.Errorf("expected the configured charsets, got %v", cs)
+	}
+}
+
+// mockSystemVarResponse returns the response to SELECT @@name with value.
+func mockSystemVarResponse(value string) []byte {
+	var resp []byte
+	resp = append(resp, 1, 0, 0, 1, 1)
+	resp = append(resp, 1, 0, 0, 2, 0)
+	resp = append(resp, 5, 0, 0, 3, iEOF, 0, 0, 2, 0)
+	resp = append(resp, byte(len(value)+1), 0, 0, 4, byte(len(value)))
+	resp = append(resp, value...)
+	return append(resp, 5, 0, 0, 5, iEOF, 0, 0, 2, 0)
+}
+
+func TestCheckCollation(t *testing.T) {
+	for _, tt := range []struct {
+		collation string
+		charsets  []string
+		tracked   map[string]string
+		queried   string
+		ok        bool
+	}{
+		{"", nil, nil, "utf8mb4_general_ci", true},
+		{"", nil, nil, "latin1_swedish_ci", false},
+		{"utf8mb4_unicode_ci", nil, map[string]string{"collation_connection": "utf8mb4_unicode_ci"}, "", true},
+		{"utf8mb4_unicode_ci", nil, map[string]string{"collation_connection": "latin1_swedish_ci"}, "", false},
+		{"utf8mb4_0900_ai_ci", nil, nil, "utf8mb4_uca1400_ai_ci", true},
+		{"utf8_general_ci", nil, nil, "utf8mb3_general_ci", true},
+		{"", []string{"utf8mb4", "utf8"}, map[string]string{"character_set_connection": "utf8mb3"}, "", true},
+		{"", []string{"utf8mb4"}, nil, "latin1", false},
+	} {
+		conn, mc := newRWMockConn(0)
+		mc.cfg.Collation = tt.collation
+		mc.cfg.charsets = tt.charsets
+		mc.connectVars = tt.tracked
+		if tt.queried != "" {
+			conn.data = mockSystemVarResponse(tt.queried)
+		}
+
+		err := mc.checkCollation()
+		if tt.ok && err != nil {
+			t.Errorf("%q %v: unexpected error %v", tt.collation, tt.charsets, err)
+		} else if !tt.ok && !errors.Is(err, ErrCollationMismatch) {
+			t.Errorf("%q %v: expected ErrCollationMismatch, got %v", tt.collation, tt.charsets, err)
+		}
+		if tt.queried == "" && len(conn.written) > 0 {
+			t.Errorf("%q %v: expected the tracked value to be used", tt.collation, tt.charsets)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+// clientCertificate is the client certificate of mutual TLS, loaded from a
+// certificate and a key file and reloaded when one of them changes.
+type clientCertificate struct {
+	certFile, keyFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	certMod fileVersion
+	keyMod  fileVersion
+}
+
+// fileVersion identifies the content of a file by its modification time and
+// size.
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+func newClientCertificate(certFile, keyFile string) *clientCertificate {
+	return &clientCertificate{certFile: certFile, keyFile: keyFile}
+}
+
+func statFile(name string) (fileVersion, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return fileVersion{}, err
+	}
+	return fileVersion{fi.ModTime(), fi.Size()}, nil
+}
+
+// get implements tls.Config.GetClientCertificate. While the files are being
+// replaced, e.g. the certificate is already new but the key isn't yet, the
+// previous certificate is used.
+func (c *clientCertificate) get(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cert, err := c.load()
+	if err != nil && c.cert != nil {
+		return c.cert, nil
+	}
+	return cert, err
+}
+
+// load returns the certificate, reading the files if they changed.
+func (c *clientCertificate) load() (*tls.Certificate, error) {
+	certMod, err := statFile(c.certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyMod, err := statFile(c.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	if c.cert != nil && certMod == c.certMod && keyMod == c.keyMod {
+		return c.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	c.cert, c.certMod, c.keyMod = &cert, certMod, keyMod
+	return c.cert, nil
+}
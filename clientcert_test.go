@@ -0,0 +1,93 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClientCertificateReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	ca := testCertificate(t, "ca", nil)
+
+	// writeFile writes a PEM file with a distinct modification time.
+	modTime := time.Now().Add(-time.Hour)
+	writeFile := func(name, typ string, der []byte) {
+		if err := os.WriteFile(name, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		modTime = modTime.Add(time.Second)
+		if err := os.Chtimes(name, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeCert := func(cn string) {
+		cert := testCertificate(t, cn, &ca)
+		key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeFile(certFile, "CERTIFICATE", cert.Certificate[0])
+		writeFile(keyFile, "PRIVATE KEY", key)
+	}
+	commonName := func(c *clientCertificate) string {
+		cert, err := c.get(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.Subject.CommonName
+	}
+
+	c := newClientCertificate(certFile, keyFile)
+	if _, err := c.get(nil); err == nil {
+		t.Error("expected an error for missing files")
+	}
+
+	writeCert("app-1")
+	if cn := commonName(c); cn != "app-1" {
+		t.Errorf("expected the certificate of app-1, got %q", cn)
+	}
+
+	writeCert("app-2")
+	if cn := commonName(c); cn != "app-2" {
+		t.Errorf("expected the rotated certificate of app-2, got %q", cn)
+	}
+
+	// the certificate has been replaced, but not the key yet
+	writeFile(certFile, "CERTIFICATE", testCertificate(t, "app-3", &ca).Certificate[0])
+	if cn := commonName(c); cn != "app-2" {
+		t.Errorf("expected the previous certificate while the key doesn't match, got %q", cn)
+	}
+}
+
+func TestClientCertificateRequiresTLS(t *testing.T) {
+	if _, err := ParseDSN("tcp(example.com:3306)/?sslCert=client.crt&sslKey=client.key"); err == nil {
+		t.Error("expected an error without TLS")
+	}
+	if _, err := ParseDSN("tcp(example.com:3306)/?tls=true&sslCert=client.crt"); err == nil {
+		t.Error("expected an error without a key")
+	}
+	cfg, err := ParseDSN("tcp(example.com:3306)/?tls=verify-ca&sslCert=client.crt&sslKey=client.key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TLS.GetClientCertificate == nil {
+		t.Error("expected the client certificate to be set up")
+	}
+}
@@ -0,0 +1,124 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/colussim/mysql-auth-oidc-go/mysqlerrors"
+)
+
+// ServerFlavor identifies MySQL-compatible servers and proxies, for which
+// the driver adjusts the session setup and the reading of results:
+//
+//   - TiDB and Vitess: the session variables of the DSN params and
+//     SessionVars are set one per statement, and variables the server
+//     doesn't know (error 1193) are skipped with a warning instead of
+//     failing the connection.
+//   - Vitess: results of prepared statements are read without server-side
+//     cursors, which Vitess doesn't support, even if useCursorFetch is set.
+//   - ProxySQL: max_allowed_packet isn't queried, as SELECT @@ statements
+//     disable the multiplexing of the connection; the default of
+//     MaxAllowedPacket is used instead.
+//
+// TiDB, Vitess and MariaDB are detected from the version in the handshake.
+// ProxySQL reports the version of its backends and must be configured with
+// UseServerFlavor or the serverFlavor DSN param.
+//
+// The flavor applies once the connection is authenticated: the handshake and
+// the authentication are the same for all flavors, driven by the
+// capabilities and auth plugins the server announces.
+type ServerFlavor string
+
+const (
+	FlavorMySQL    ServerFlavor = "mysql"
+	FlavorMariaDB  ServerFlavor = "mariadb"
+	FlavorTiDB     ServerFlavor = "tidb"
+	FlavorVitess   ServerFlavor = "vitess"
+	FlavorProxySQL ServerFlavor = "proxysql"
+)
+
+// UseServerFlavor overrides the flavor of the server detected from the
+// handshake.
+func UseServerFlavor(f ServerFlavor) Option {
+	return func(cfg *Config) error {
+		if err := f.validate(); err != nil {
+			return err
+		}
+		cfg.serverFlavor = f
+		return nil
+	}
+}
+
+func (f ServerFlavor) validate() error {
+	switch f {
+	case "", FlavorMySQL, FlavorMariaDB, FlavorTiDB, FlavorVitess, FlavorProxySQL:
+		return nil
+	}
+	return errors.New("invalid serverFlavor: " + string(f))
+}
+
+// detectServerFlavor returns the flavor of the server with the version and
+// capabilities of the handshake.
+func detectServerFlavor(version string, capabilities capabilityFlag) ServerFlavor {
+	lower := strings.ToLower(version)
+	switch {
+	case strings.Contains(lower, "tidb"):
+		return FlavorTiDB
+	case strings.Contains(lower, "vitess"):
+		return FlavorVitess
+	case strings.Contains(lower, "proxysql"):
+		return FlavorProxySQL
+	case strings.Contains(lower, "mariadb") || capabilities&clientMySQL == 0:
+		return FlavorMariaDB
+	}
+	return FlavorMySQL
+}
+
+// initFlavor sets the flavor of the server after the handshake.
+func (mc *mysqlConn) initFlavor(capabilities capabilityFlag) {
+	mc.flavor = mc.cfg.serverFlavor
+	if mc.flavor == "" {
+		mc.flavor = detectServerFlavor(mc.serverVersion, capabilities)
+	}
+	if mc.cfg.useCursorFetch && mc.flavor == FlavorVitess {
+		mc.warn("useCursorFetch is ignored, Vitess doesn't support cursors")
+	}
+}
+
+// cursorFetch reports whether results of prepared statements are read
+// through server-side cursors.
+func (mc *mysqlConn) cursorFetch() bool {
+	return mc.cfg.useCursorFetch && mc.flavor != FlavorVitess
+}
+
+// skipUnknownSystemVars reports whether session variables unknown to the
+// server are skipped when setting up the session.
+func (mc *mysqlConn) skipUnknownSystemVars() bool {
+	return mc.flavor == FlavorTiDB || mc.flavor == FlavorVitess
+}
+
+// handleParamsSeparately sets the session variables of the DSN params and
+// SessionVars one per statement, skipping the variables the server doesn't
+// know.
+func (mc *mysqlConn) handleParamsSeparately() error {
+	for _, assignment := range mc.sessionAssignments() {
+		err := mc.exec("SET " + assignment)
+		var mysqlErr *MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlerrors.ER_UNKNOWN_SYSTEM_VARIABLE {
+			mc.warn("skipping session variable unknown to ", mc.flavor, ": ", mysqlErr.Message)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
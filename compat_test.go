@@ -0,0 +1,126 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/colussim/mysql-auth-oidc-go/mysqltest"
+)
+
+func TestDetectServerFlavor(t *testing.T) {
+	for _, tt := range []struct {
+		version string
+		caps    capabilityFlag
+		want    ServerFlavor
+	}{
+		{"8.0.36", clientMySQL, FlavorMySQL},
+		{"5.5.5-10.11.6-MariaDB", clientMySQL, FlavorMariaDB},
+		{"11.4.2-MariaDB", 0, FlavorMariaDB},
+		{"8.0.11-TiDB-v7.5.0", clientMySQL, FlavorTiDB},
+		{"8.0.30-Vitess", clientMySQL, FlavorVitess},
+		{"5.5.30 (ProxySQL)", clientMySQL, FlavorProxySQL},
+	} {
+		if got := detectServerFlavor(tt.version, tt.caps); got != tt.want {
+			t.Errorf("%q: expected %s, got %s", tt.version, tt.want, got)
+		}
+	}
+}
+
+// connectFlavor connects to srv with cfg and returns the connection.
+func connectFlavor(t *testing.T, srv *mysqltest.Server, cfg *Config) *mysqlConn {
+	t.Helper()
+	cfg.DialFunc = srv.Dial
+	c, err := NewConnector(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := c.Connect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn.(*mysqlConn)
+}
+
+func TestFlavorSkipsUnknownSystemVars(t *testing.T) {
+	unknown := mysqltest.Result{Err: &mysqltest.Error{Number: 1193, SQLState: "HY000", Message: "Unknown system variable 'innodb_lock_wait_timeout'"}}
+
+	srv := &mysqltest.Server{Version: "8.0.11-TiDB-v7.5.0"}
+	defer srv.Close()
+	srv.Handle("SET innodb_lock_wait_timeout = 10", unknown)
+
+	cfg := NewConfig()
+	cfg.Params = map[string]string{"innodb_lock_wait_timeout": "10", "autocommit": "1"}
+	mc := connectFlavor(t, srv, cfg)
+	if info := mc.ServerInfo(); info.Flavor != FlavorTiDB {
+		t.Errorf("expected TiDB, got %q", info.Flavor)
+	}
+	var sets int
+	for _, q := range srv.Queries() {
+		if q == "SET innodb_lock_wait_timeout = 10" || q == "SET autocommit = 1" {
+			sets++
+		}
+	}
+	if sets != 2 {
+		t.Errorf("expected a SET per variable, got %q", srv.Queries())
+	}
+
+	// MySQL fails the connection
+	srv = &mysqltest.Server{}
+	defer srv.Close()
+	srv.Handle("SET innodb_lock_wait_timeout = 10", unknown)
+	cfg = NewConfig()
+	cfg.Params = map[string]string{"innodb_lock_wait_timeout": "10"}
+	cfg.DialFunc = srv.Dial
+	c, err := NewConnector(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Connect(context.Background()); err == nil {
+		t.Error("expected the unknown variable to fail the connection")
+	}
+}
+
+func TestFlavorVitessIgnoresCursorFetch(t *testing.T) {
+	srv := &mysqltest.Server{Version: "8.0.30-Vitess"}
+	defer srv.Close()
+
+	cfg := NewConfig()
+	if err := cfg.Apply(CursorFetch(100)); err != nil {
+		t.Fatal(err)
+	}
+	mc := connectFlavor(t, srv, cfg)
+	if mc.flavor != FlavorVitess || mc.cursorFetch() {
+		t.Errorf("expected cursors to be disabled for Vitess, got %q, %v", mc.flavor, mc.cursorFetch())
+	}
+}
+
+func TestFlavorProxySQLMaxAllowedPacket(t *testing.T) {
+	srv := &mysqltest.Server{}
+	defer srv.Close()
+
+	cfg := NewConfig()
+	cfg.MaxAllowedPacket = 0
+	if err := cfg.Apply(UseServerFlavor(FlavorProxySQL)); err != nil {
+		t.Fatal(err)
+	}
+	mc := connectFlavor(t, srv, cfg)
+	if mc.flavor != FlavorProxySQL || mc.maxAllowedPacket != defaultMaxAllowedPacket {
+		t.Errorf("expected ProxySQL with the default max_allowed_packet, got %q, %d", mc.flavor, mc.maxAllowedPacket)
+	}
+	if queries := srv.Queries(); len(queries) != 0 {
+		t.Errorf("expected no queries, got %q", queries)
+	}
+
+	if err := cfg.Apply(UseServerFlavor("oracle")); err == nil {
+		t.Error("expected an unknown flavor to be rejected")
+	}
+}
@@ -21,6 +21,86 @@ var (
 	zwPool *sync.Pool // Do not use directly. Use zCompress() instead.
 )
 
+// CompressionCodec compresses and decompresses the payload of packets sent
+// with the compressed protocol.
+//
+// A codec is shared by all connections using it, so implementations must be
+// safe for concurrent use. Reusable state like compressor instances should be
+// pooled inside the codec.
+//
+// The server (or proxy) must understand the produced format. Since MySQL only
+// supports zlib in the compressed protocol, custom codecs are typically
+// alternative zlib implementations, e.g. hardware-accelerated ones.
+type CompressionCodec interface {
+	// Compress writes the compressed form of src to dst.
+	Compress(dst io.Writer, src []byte) error
+	// Decompress writes the decompressed form of src to dst and returns the
+	// number of bytes written.
+	Decompress(dst *bytes.Buffer, src []byte) (int, error)
+}
+
+// zlibCodec is the default CompressionCodec.
+type zlibCodec struct{}
+
+func (zlibCodec) Compress(dst io.Writer, src []byte) error {
+	return zCompress(src, dst)
+}
+
+func (zlibCodec) Decompress(dst *bytes.Buffer, src []byte) (int, error) {
+	return zDecompress(src, dst)
+}
+
+// Registry for custom compression codecs
+var (
+	compressionCodecLock     sync.RWMutex
+	compressionCodecRegistry map[string]CompressionCodec
+)
+
+// RegisterCompressionCodec registers a custom CompressionCodec to be used
+// with sql.Open. Use the name as a value in the DSN where compress=name.
+//
+//	mysql.RegisterCompressionCodec("fastzlib", myZlibCodec)
+//	db, err := sql.Open("mysql", "user@tcp(localhost:3306)/test?compress=fastzlib")
+func RegisterCompressionCodec(name string, codec CompressionCodec) error {
+	if _, isBool := readBool(name); isBool || name == "" || name == "zlib" {
+		return fmt.Errorf("name '%s' is reserved", name)
+	}
+	if codec == nil {
+		return fmt.Errorf("codec for '%s' is nil", name)
+	}
+
+	compressionCodecLock.Lock()
+	if compressionCodecRegistry == nil {
+		compressionCodecRegistry = make(map[string]CompressionCodec)
+	}
+
+	compressionCodecRegistry[name] = codec
+	compressionCodecLock.Unlock()
+	return nil
+}
+
+// DeregisterCompressionCodec removes the CompressionCodec registered with the
+// given name.
+func DeregisterCompressionCodec(name string) {
+	compressionCodecLock.Lock()
+	if compressionCodecRegistry != nil {
+		delete(compressionCodecRegistry, name)
+	}
+	compressionCodecLock.Unlock()
+}
+
+// getCompressionCodec returns the codec registered with name, the default zlib
+// codec for an empty name or "zlib", and nil if no such codec is registered.
+func getCompressionCodec(name string) (codec CompressionCodec) {
+	if name == "" || name == "zlib" {
+		return zlibCodec{}
+	}
+	compressionCodecLock.RLock()
+	codec = compressionCodecRegistry[name]
+	compressionCodecLock.RUnlock()
+	return
+}
+
 func init() {
 	zrPool = &sync.Pool{
 		New: func() any { return nil },
@@ -70,13 +150,15 @@ func zCompress(src []byte, dst io.Writer) error {
 }
 
 type compIO struct {
-	mc   *mysqlConn
-	buff bytes.Buffer
+	mc    *mysqlConn
+	codec CompressionCodec
+	buff  bytes.Buffer
 }
 
-func newCompIO(mc *mysqlConn) *compIO {
+func newCompIO(mc *mysqlConn, codec CompressionCodec) *compIO {
 	return &compIO{
-		mc: mc,
+		mc:    mc,
+		codec: codec,
 	}
 }
 
@@ -105,18 +187,10 @@ func (c *compIO) readCompressedPacket() error {
 	comprLength := getUint24(header[0:3])
 	compressionSequence := header[3]
 	uncompressedLength := getUint24(header[4:7])
-	if debug {
-		fmt.Printf("uncompress cmplen=%v uncomplen=%v pkt_cmp_seq=%v expected_cmp_seq=%v\n",
-			comprLength, uncompressedLength, compressionSequence, c.mc.sequence)
-	}
 	// Do not return ErrPktSync here.
 	// Server may return error packet (e.g. 1153 Got a packet bigger than 'max_allowed_packet' bytes)
 	// before receiving all packets from client. In this case, seqnr is younger than expected.
 	// NOTE: Both of mariadbclient and mysqlclient do not check seqnr. Only server checks it.
-	if debug && compressionSequence != c.mc.sequence {
-		fmt.Printf("WARN: unexpected cmpress seq nr: expected %v, got %v",
-			c.mc.sequence, compressionSequence)
-	}
 	c.mc.sequence = compressionSequence + 1
 	c.mc.compressSequence = c.mc.sequence
 
@@ -134,7 +208,7 @@ func (c *compIO) readCompressedPacket() error {
 
 	// use existing capacity in bytesBuf if possible
 	c.buff.Grow(uncompressedLength)
-	nread, err := zDecompress(comprData, &c.buff)
+	nread, err := c.codec.Decompress(&c.buff, comprData)
 	if err != nil {
 		return err
 	}
@@ -142,6 +216,9 @@ func (c *compIO) readCompressedPacket() error {
 		return fmt.Errorf("invalid compressed packet: uncompressed length in header is %d, actual %d",
 			uncompressedLength, nread)
 	}
+	if sc := c.mc.cfg.statsCollector; sc != nil {
+		sc.Compressed(uncompressedLength, comprLength)
+	}
 	return nil
 }
 
@@ -168,10 +245,7 @@ func (c *compIO) writePackets(packets []byte) (int, error) {
 			buf.Write(payload)
 			uncompressedLen = 0
 		} else {
-			err := zCompress(payload, buf)
-			if debug && err != nil {
-				fmt.Printf("zCompress error: %v", err)
-			}
+			err := c.codec.Compress(buf, payload)
 			// do not compress if compressed data is larger than uncompressed data
 			// I intentionally miss 7 byte header in the buf; zCompress must compress more than 7 bytes.
 			if err != nil || buf.Len() >= uncompressedLen {
@@ -179,6 +253,8 @@ func (c *compIO) writePackets(packets []byte) (int, error) {
 				buf.Write(blankHeader)
 				buf.Write(payload)
 				uncompressedLen = 0
+			} else if sc := c.mc.cfg.statsCollector; sc != nil {
+				sc.Compressed(uncompressedLen, buf.Len()-7)
 			}
 		}
 
@@ -198,11 +274,6 @@ func (c *compIO) writePackets(packets []byte) (int, error) {
 func (c *compIO) writeCompressedPacket(data []byte, uncompressedLen int) (int, error) {
 	mc := c.mc
 	comprLength := len(data) - 7
-	if debug {
-		fmt.Printf(
-			"writeCompressedPacket: comprLength=%v, uncompressedLen=%v, seq=%v",
-			comprLength, uncompressedLen, mc.compressSequence)
-	}
 
 	// compression header
 	putUint24(data[0:3], comprLength)
@@ -88,10 +88,10 @@ func TestRoundtrip(t *testing.T) {
 
 	_, cSend := newRWMockConn(0)
 	cSend.compress = true
-	cSend.compIO = newCompIO(cSend)
+	cSend.compIO = newCompIO(cSend, zlibCodec{})
 	_, cReceive := newRWMockConn(0)
 	cReceive.compress = true
-	cReceive.compIO = newCompIO(cReceive)
+	cReceive.compIO = newCompIO(cReceive, zlibCodec{})
 
 	for _, test := range tests {
 		t.Run(test.desc, func(t *testing.T) {
@@ -117,3 +117,63 @@ func TestRoundtrip(t *testing.T) {
 		})
 	}
 }
+
+// countingCodec wraps the zlib codec and counts its invocations
+type countingCodec struct {
+	compressed   int
+	decompressed int
+}
+
+func (c *countingCodec) Compress(dst io.Writer, src []byte) error {
+	c.compressed++
+	return zCompress(src, dst)
+}
+
+func (c *countingCodec) Decompress(dst *bytes.Buffer, src []byte) (int, error) {
+	c.decompressed++
+	return zDecompress(src, dst)
+}
+
+func TestCustomCompressionCodec(t *testing.T) {
+	codec := &countingCodec{}
+	if err := RegisterCompressionCodec("counting", codec); err != nil {
+		t.Fatal(err)
+	}
+	defer DeregisterCompressionCodec("counting")
+
+	cfg, err := ParseDSN("/dbname?compress=counting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.compress || cfg.compressCodec != "counting" {
+		t.Fatalf("unexpected compression config: compress=%v codec=%q", cfg.compress, cfg.compressCodec)
+	}
+
+	_, cSend := newRWMockConn(0)
+	cSend.compress = true
+	cSend.compIO = newCompIO(cSend, getCompressionCodec(cfg.compressCodec))
+	_, cReceive := newRWMockConn(0)
+	cReceive.compress = true
+	cReceive.compIO = newCompIO(cReceive, getCompressionCodec(cfg.compressCodec))
+
+	payload := bytes.Repeat([]byte("compress me "), 100)
+	if uncompressed := roundtripHelper(t, cSend, cReceive, payload); !bytes.Equal(uncompressed, payload) {
+		t.Errorf("roundtrip failed")
+	}
+	if codec.compressed != 1 || codec.decompressed != 1 {
+		t.Errorf("expected codec to be used once in each direction, got compressed=%d decompressed=%d",
+			codec.compressed, codec.decompressed)
+	}
+}
+
+func TestRegisterCompressionCodecReserved(t *testing.T) {
+	for _, name := range []string{"", "zlib", "true", "0"} {
+		if err := RegisterCompressionCodec(name, zlibCodec{}); err == nil {
+			t.Errorf("expected name %q to be reserved", name)
+		}
+	}
+
+	if _, err := ParseDSN("/dbname?compress=unregistered"); err == nil {
+		t.Error("expected error for unknown compression codec")
+	}
+}
@@ -0,0 +1,73 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import "context"
+
+type configOverrideCtxKey struct{}
+
+type configOverride struct {
+	opts []Option
+}
+
+// WithConfigOverride returns a copy of ctx which makes the queries executed
+// with it use the configuration of the connection modified by opts, e.g. a
+// longer ReadTimeout for a single report without a second *sql.DB:
+//
+//	ctx = mysql.WithConfigOverride(ctx, func(cfg *mysql.Config) error {
+//		cfg.ReadTimeout = 5 * time.Minute
+//		return nil
+//	})
+//	rows, err := db.QueryContext(ctx, "SELECT ...")
+//
+// Overrides of an outer context are applied first. Only settings which are
+// read while executing queries take effect, such as the I/O timeouts,
+// ParseTime, Loc, InterpolateParams, the fetch size of CursorFetch and the
+// LOAD DATA LOCAL INFILE limits. Settings used to establish the connection,
+// such as the address, the credentials, TLS and Params, are ignored.
+func WithConfigOverride(ctx context.Context, opts ...Option) context.Context {
+	if outer, ok := ctx.Value(configOverrideCtxKey{}).(*configOverride); ok {
+		opts = append(append([]Option(nil), outer.opts...), opts...)
+	}
+	return context.WithValue(ctx, configOverrideCtxKey{}, &configOverride{opts: opts})
+}
+
+// overrideConfig makes mc use the configuration overridden by ctx, or its
+// own configuration if ctx has no override.
+func (mc *mysqlConn) overrideConfig(ctx context.Context) error {
+	o, _ := ctx.Value(configOverrideCtxKey{}).(*configOverride)
+	if o == mc.override {
+		return nil
+	}
+
+	mc.restoreConfig()
+	if o == nil {
+		return nil
+	}
+
+	cfg := mc.cfg.Clone()
+	if err := cfg.Apply(o.opts...); err != nil {
+		return err
+	}
+	if err := cfg.normalize(); err != nil {
+		return err
+	}
+	mc.baseCfg, mc.cfg, mc.override = mc.cfg, cfg, o
+	mc.parseTime = cfg.ParseTime
+	return nil
+}
+
+// restoreConfig reverts an override applied by overrideConfig.
+func (mc *mysqlConn) restoreConfig() {
+	if mc.baseCfg == nil {
+		return
+	}
+	mc.cfg, mc.baseCfg, mc.override = mc.baseCfg, nil, nil
+	mc.parseTime = mc.cfg.ParseTime
+}
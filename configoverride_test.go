@@ -0,0 +1,89 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func readTimeout(d time.Duration) Option {
+	return func(cfg *Config) error {
+		cfg.ReadTimeout = d
+		return nil
+	}
+}
+
+func TestConfigOverride(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	base := mc.cfg
+
+	ctx := WithConfigOverride(context.Background(), readTimeout(time.Minute), TimeTruncate(time.Second))
+	ctx = WithConfigOverride(ctx, readTimeout(time.Hour), func(cfg *Config) error {
+		cfg.ParseTime = true
+		return nil
+	})
+
+	conn.data = []byte{7, 0, 0, 1, iOK, 0, 0, 2, 0, 0, 0}
+	if _, err := mc.ExecContext(ctx, "DO 1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if mc.cfg.ReadTimeout != time.Hour || mc.cfg.timeTruncate != time.Second || !mc.parseTime {
+		t.Errorf("expected the overridden configuration, got %+v", mc.cfg)
+	}
+	if base.ReadTimeout != 0 || base.ParseTime {
+		t.Error("expected the configuration of the connection to be unchanged")
+	}
+
+	// the override is applied once per context
+	overridden := mc.cfg
+	if err := mc.overrideConfig(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if mc.cfg != overridden {
+		t.Error("expected the overridden configuration to be reused")
+	}
+
+	if err := mc.overrideConfig(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if mc.cfg != base || mc.parseTime {
+		t.Error("expected the configuration of the connection to be restored")
+	}
+}
+
+func TestResetSessionRestoresConfig(t *testing.T) {
+	_, mc := newRWMockConn(0)
+	mc.cfg.CheckConnLiveness = false
+	base := mc.cfg
+
+	if err := mc.overrideConfig(WithConfigOverride(context.Background(), readTimeout(time.Hour))); err != nil {
+		t.Fatal(err)
+	}
+	if err := mc.ResetSession(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if mc.cfg != base {
+		t.Error("expected ResetSession to restore the configuration of the connection")
+	}
+}
+
+func TestConfigOverrideInvalid(t *testing.T) {
+	_, mc := newRWMockConn(0)
+	base := mc.cfg
+
+	ctx := WithConfigOverride(context.Background(), CursorFetch(-1))
+	if _, err := mc.ExecContext(ctx, "DO 1", nil); err == nil {
+		t.Fatal("expected error for invalid override")
+	}
+	if mc.cfg != base {
+		t.Error("expected the configuration of the connection to be kept")
+	}
+}
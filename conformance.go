@@ -0,0 +1,313 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ConformanceResult is the outcome of a single conformance check.
+type ConformanceResult struct {
+	Name     string        // Name of the check
+	Detail   string        // Additional information, e.g. the negotiated auth plugin
+	Skipped  string        // Reason why the check was skipped, if it was
+	Err      error         // Cause of the failure, if the check failed
+	Duration time.Duration // Time the check took
+}
+
+// ConformanceReport is the outcome of CheckConformance.
+type ConformanceReport struct {
+	Addr          string
+	ServerVersion string
+	Results       []ConformanceResult
+}
+
+// Failed reports whether any check of the report failed.
+func (r *ConformanceReport) Failed() bool {
+	for _, res := range r.Results {
+		if res.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats the report with one line per check, suitable for attaching
+// to bug reports.
+func (r *ConformanceReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "server %s", r.Addr)
+	if r.ServerVersion != "" {
+		fmt.Fprintf(&b, " (%s)", r.ServerVersion)
+	}
+	b.WriteByte('\n')
+
+	for _, res := range r.Results {
+		switch {
+		case res.Err != nil:
+			fmt.Fprintf(&b, "FAIL %s: %v\n", res.Name, res.Err)
+		case res.Skipped != "":
+			fmt.Fprintf(&b, "SKIP %s: %s\n", res.Name, res.Skipped)
+		case res.Detail != "":
+			fmt.Fprintf(&b, "PASS %s: %s (%v)\n", res.Name, res.Detail, res.Duration)
+		default:
+			fmt.Fprintf(&b, "PASS %s (%v)\n", res.Name, res.Duration)
+		}
+	}
+	return b.String()
+}
+
+// errConformanceSkip is returned by conformance checks which don't apply to
+// the server.
+type errConformanceSkip string
+
+func (e errConformanceSkip) Error() string {
+	return string(e)
+}
+
+type conformanceCheck struct {
+	name string
+	opts []Option
+	run  func(ctx context.Context, mc *mysqlConn) (string, error)
+}
+
+var conformanceChecks = []conformanceCheck{
+	{name: "connect", run: checkConnect},
+	{name: "ping", run: func(ctx context.Context, mc *mysqlConn) (string, error) {
+		return "", mc.Ping(ctx)
+	}},
+	{name: "text protocol", run: func(ctx context.Context, mc *mysqlConn) (string, error) {
+		return "", checkQuery(ctx, mc, "SELECT 1", nil, 1)
+	}},
+	{name: "binary protocol", run: func(ctx context.Context, mc *mysqlConn) (string, error) {
+		return "", checkPrepared(ctx, mc, "SELECT ?", []driver.NamedValue{{Ordinal: 1, Value: int64(1)}}, 1)
+	}},
+	{name: "multi statements", opts: []Option{withMultiStatements}, run: checkMultiStatements},
+	{name: "compression", opts: []Option{EnableCompression(true)}, run: checkCompression},
+	{name: "cursor fetch", opts: []Option{CursorFetch(1)}, run: func(ctx context.Context, mc *mysqlConn) (string, error) {
+		args := []driver.NamedValue{{Ordinal: 1, Value: int64(1)}}
+		return "", checkPrepared(ctx, mc, "SELECT ? UNION ALL SELECT 2", args, 2)
+	}},
+	{name: "pipelined prepare", opts: []Option{PipelinePrepare(true)}, run: checkPipelinePrepare},
+	{name: "query attributes", run: checkQueryAttrs},
+	{name: "session tracking", run: checkSessionTrack},
+}
+
+func withMultiStatements(cfg *Config) error {
+	cfg.MultiStatements = true
+	return nil
+}
+
+// CheckConformance connects to the server configured by cfg and exercises
+// the protocol features implemented by the driver, including the configured
+// authentication. Each check uses a connection of its own.
+//
+// The returned report tells which features work against the server and is
+// meant to be attached to bug reports.
+func CheckConformance(ctx context.Context, cfg *Config) *ConformanceReport {
+	cfg = cfg.Clone()
+	err := cfg.normalize()
+	report := &ConformanceReport{Addr: cfg.Addr}
+
+	for i, check := range conformanceChecks {
+		res := ConformanceResult{Name: check.name}
+		if i == 0 && err != nil {
+			res.Err = err
+			report.Results = append(report.Results, res)
+			continue
+		}
+		if i > 0 && report.Results[0].Err != nil {
+			res.Skipped = "connect failed"
+			report.Results = append(report.Results, res)
+			continue
+		}
+
+		start := time.Now()
+		res.Detail, res.Err = runConformanceCheck(ctx, cfg, check, report)
+		res.Duration = time.Since(start)
+
+		var skip errConformanceSkip
+		if errors.As(res.Err, &skip) {
+			res.Skipped = string(skip)
+			res.Err = nil
+		}
+		report.Results = append(report.Results, res)
+	}
+	return report
+}
+
+func runConformanceCheck(ctx context.Context, cfg *Config, check conformanceCheck, report *ConformanceReport) (string, error) {
+	cfg = cfg.Clone()
+	if err := cfg.Apply(check.opts...); err != nil {
+		return "", err
+	}
+	c, err := NewConnector(cfg)
+	if err != nil {
+		return "", err
+	}
+	conn, err := c.Connect(ctx)
+	if err != nil {
+		return "", err
+	}
+	mc := conn.(*mysqlConn)
+	defer mc.Close()
+
+	if report.ServerVersion == "" {
+		version, err := mc.getSystemVar("version")
+		if err != nil {
+			return "", err
+		}
+		report.ServerVersion = string(version)
+	}
+	return check.run(ctx, mc)
+}
+
+func checkConnect(ctx context.Context, mc *mysqlConn) (string, error) {
+	detail := "auth plugin " + mc.authPlugin
+	if mc.capabilities&clientMySQL == 0 {
+		detail += ", MariaDB"
+	}
+	if mc.capabilities&clientSSL != 0 {
+		detail += ", TLS"
+	}
+	return detail, nil
+}
+
+func checkMultiStatements(ctx context.Context, mc *mysqlConn) (string, error) {
+	rows, err := mc.QueryContext(ctx, "SELECT 1; SELECT 2", nil)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	dest := make([]driver.Value, 1)
+	for want := 1; want <= 2; want++ {
+		if want > 1 {
+			if err := rows.(driver.RowsNextResultSet).NextResultSet(); err != nil {
+				return "", err
+			}
+		}
+		if err := rows.Next(dest); err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+func checkCompression(ctx context.Context, mc *mysqlConn) (string, error) {
+	if !mc.compress {
+		return "", errConformanceSkip("not supported by server")
+	}
+	return "", checkQuery(ctx, mc, "SELECT REPEAT('a', 100000)", nil, 1)
+}
+
+func checkPipelinePrepare(ctx context.Context, mc *mysqlConn) (string, error) {
+	if mc.capabilities&clientMySQL != 0 {
+		return "", errConformanceSkip("requires MariaDB")
+	}
+	if mc.compress {
+		return "", errConformanceSkip("not used on compressed connections")
+	}
+	return "", checkQuery(ctx, mc, "SELECT ?", []driver.NamedValue{{Ordinal: 1, Value: int64(1)}}, 1)
+}
+
+func checkQueryAttrs(ctx context.Context, mc *mysqlConn) (string, error) {
+	if mc.capabilities&clientQueryAttributes == 0 {
+		return "", errConformanceSkip("not supported by server")
+	}
+
+	ctx = QueryAttrs(ctx, map[string]any{"conformance": "ok"})
+	rows, err := mc.QueryContext(ctx, "SELECT mysql_query_attribute_string('conformance')", nil)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	dest := make([]driver.Value, 1)
+	if err := rows.Next(dest); err != nil {
+		return "", err
+	}
+	if v, _ := dest[0].([]byte); string(v) != "ok" {
+		return "", fmt.Errorf("expected attribute value %q, got %q", "ok", dest[0])
+	}
+	return "", nil
+}
+
+func checkSessionTrack(ctx context.Context, mc *mysqlConn) (string, error) {
+	if mc.capabilities&clientSessionTrack == 0 {
+		return "", errConformanceSkip("not supported by server")
+	}
+
+	var state SessionState
+	mc.cfg.sessionStateCallback = func(s SessionState) {
+		state = s
+	}
+	if _, err := mc.ExecContext(ctx, "SET SESSION time_zone = '+00:00'", nil); err != nil {
+		return "", err
+	}
+	if _, ok := state.SystemVariables["time_zone"]; !ok {
+		return "", errConformanceSkip("time_zone is not tracked by session_track_system_variables")
+	}
+	return "", nil
+}
+
+// checkQuery runs query on the connection and checks the number of rows.
+// Like database/sql, it falls back to a prepared statement if necessary.
+func checkQuery(ctx context.Context, mc *mysqlConn, query string, args []driver.NamedValue, n int) error {
+	rows, err := mc.QueryContext(ctx, query, args)
+	if err == driver.ErrSkip {
+		return checkPrepared(ctx, mc, query, args, n)
+	}
+	if err != nil {
+		return err
+	}
+	return checkRows(rows, n)
+}
+
+// checkPrepared prepares and executes query and checks the number of rows.
+func checkPrepared(ctx context.Context, mc *mysqlConn, query string, args []driver.NamedValue, n int) error {
+	stmt, err := mc.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.(*mysqlStmt).QueryContext(ctx, args)
+	if err != nil {
+		return err
+	}
+	return checkRows(rows, n)
+}
+
+// checkRows reads and closes rows and checks their number.
+func checkRows(rows driver.Rows, n int) error {
+	dest := make([]driver.Value, len(rows.Columns()))
+	got := 0
+	for {
+		err := rows.Next(dest)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		got++
+	}
+	if got != n {
+		return fmt.Errorf("expected %d rows, got %d", n, got)
+	}
+	return rows.Close()
+}
@@ -0,0 +1,65 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+// TestConformance runs the conformance checks against the test server.
+// Its log is the report to attach to bug reports:
+//
+//	MYSQL_TEST_ADDR=host:3306 go test -run TestConformance -v
+func TestConformance(t *testing.T) {
+	if !available {
+		t.Skipf("MySQL server not running on %s", netAddr)
+	}
+
+	cfg, err := ParseDSN(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := CheckConformance(context.Background(), cfg)
+	t.Log("\n" + report.String())
+	if report.Failed() {
+		t.Error("conformance checks failed")
+	}
+}
+
+func TestConformanceConnectFailed(t *testing.T) {
+	errDial := errors.New("dial failed")
+	cfg := NewConfig()
+	cfg.Addr = "conformance.invalid:3306"
+	cfg.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, errDial
+	}
+
+	report := CheckConformance(context.Background(), cfg)
+	if !report.Failed() {
+		t.Fatal("expected report to fail")
+	}
+	if !errors.Is(report.Results[0].Err, errDial) {
+		t.Errorf("expected dial error for connect, got %v", report.Results[0].Err)
+	}
+	for _, res := range report.Results[1:] {
+		if res.Skipped == "" {
+			t.Errorf("expected %q to be skipped", res.Name)
+		}
+	}
+
+	out := report.String()
+	if !strings.HasPrefix(out, "server conformance.invalid:3306\nFAIL connect: dial failed\nSKIP ping: connect failed\n") {
+		t.Errorf("unexpected report:\n%s", out)
+	}
+}
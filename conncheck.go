@@ -12,20 +12,17 @@
 package mysql
 
 import (
-	"errors"
 	"io"
 	"net"
 	"syscall"
 )
 
-var errUnexpectedRead = errors.New("unexpected read from socket")
-
 func connCheck(conn net.Conn) error {
 	var sysErr error
 
 	sysConn, ok := conn.(syscall.Conn)
 	if !ok {
-		return nil
+		return probeConnCheck(conn)
 	}
 	rawConn, err := sysConn.SyscallConn()
 	if err != nil {
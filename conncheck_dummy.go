@@ -13,6 +13,8 @@ package mysql
 
 import "net"
 
+// connCheck falls back to probeConnCheck where the socket can't be read
+// without blocking, e.g. on Windows.
 func connCheck(conn net.Conn) error {
-	return nil
+	return probeConnCheck(conn)
 }
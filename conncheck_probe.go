@@ -0,0 +1,44 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"errors"
+	"net"
+	"os"
+	"time"
+)
+
+var errUnexpectedRead = errors.New("unexpected read from socket")
+
+// connCheckProbeTimeout is how long probeConnCheck waits for the connection
+// to become readable. The deadline must lie in the future, otherwise the read
+// fails without looking at the connection.
+const connCheckProbeTimeout = time.Millisecond
+
+// probeConnCheck checks the liveness of conn with a read which gives up
+// almost immediately. Unlike connCheck it works with any net.Conn supporting
+// read deadlines, on all platforms, but it consumes a byte if the server
+// sent one; the connection is unusable in that case anyway. Connections not
+// supporting read deadlines, e.g. of some custom dialers, can't be checked
+// without blocking and are considered alive, like without probeConnCheck.
+func probeConnCheck(conn net.Conn) error {
+	if err := conn.SetReadDeadline(time.Now().Add(connCheckProbeTimeout)); err != nil {
+		return nil
+	}
+	var buf [1]byte
+	n, err := conn.Read(buf[:])
+	if n > 0 {
+		return errUnexpectedRead
+	}
+	if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+		return err
+	}
+	return conn.SetReadDeadline(time.Time{})
+}
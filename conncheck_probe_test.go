@@ -0,0 +1,82 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestProbeConnCheck(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	server, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// hide syscall.Conn, as connections of custom dialers may do
+	client := struct{ net.Conn }{conn}
+
+	if err := probeConnCheck(client); err != nil {
+		t.Fatalf("expected idle connection to be alive, got %v", err)
+	}
+
+	if _, err := server.Write([]byte{0xff}); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		err := probeConnCheck(client)
+		if err == errUnexpectedRead {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected errUnexpectedRead, got %v", err)
+		}
+	}
+
+	server.Close()
+	for {
+		err := probeConnCheck(client)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected io.EOF for closed connection, got %v", err)
+		}
+	}
+}
+
+// noDeadlineConn is a net.Conn not supporting deadlines, of which reads block.
+type noDeadlineConn struct{ net.Conn }
+
+func (noDeadlineConn) SetReadDeadline(time.Time) error {
+	return errors.New("deadlines not supported")
+}
+
+func (noDeadlineConn) Read([]byte) (int, error) {
+	panic("unexpected read")
+}
+
+func TestProbeConnCheckNoDeadline(t *testing.T) {
+	if err := probeConnCheck(noDeadlineConn{}); err != nil {
+		t.Errorf("expected the connection to be considered alive, got %v", err)
+	}
+}
@@ -10,11 +10,13 @@ package mysql
 
 import (
 	"context"
+	"crypto/x509"
 	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"runtime"
 	"strconv"
@@ -25,10 +27,18 @@ import (
 
 type mysqlConn struct {
 	buf              buffer
+	id               uint64 // sequence number assigned by the driver, for logging
+	threadID         uint32 // connection id assigned by the server
 	netConn          net.Conn
 	rawConn          net.Conn    // underlying connection when netConn is TLS connection.
 	result           mysqlResult // managed by clearResult() and handleOkPacket().
 	compIO           *compIO
+	stmtCache        *stmtCache // nil if the statement cache is disabled
+	authPlugin       string     // auth plugin the connection was authenticated with
+	serverVersion    string     // version reported in the handshake
+	scramble         []byte     // auth plugin data of the handshake, reused by COM_CHANGE_USER
+	openStmts        int        // prepared statements open on the server, including cached ones
+	oidcToken        *oidcToken // ID token the connection authenticated with, nil without OIDC
 	cfg              *Config
 	connector        *connector
 	maxAllowedPacket int
@@ -36,10 +46,56 @@ type mysqlConn struct {
 	capabilities     capabilityFlag
 	extCapabilities  extendedCapabilityFlag
 	status           statusFlag
+	warnings         uint16 // warning count of the last statement
 	sequence         uint8
 	compressSequence uint8
 	parseTime        bool
 	compress         bool
+	established      bool // set once the connection is established, for OnClose
+	auxiliary        bool // the connection sends KILL QUERY for another one, see killQuery
+	awaitingResponse bool // a command was sent, no packet of the response was read yet
+
+	// capabilities advertised by the server in the handshake
+	serverCapabilities    capabilityFlag
+	serverExtCapabilities extendedCapabilityFlag
+
+	// last successful Ping, see PingStats
+	lastPing PingStats
+
+	// session state applied for the tenant of the current query context
+	tenantID      string
+	tenantSession TenantSession
+
+	// configuration override of the current query context, see
+	// WithConfigOverride
+	override *configOverride
+	baseCfg  *Config // configuration of the connection while cfg is overridden
+
+	// context of the current COM_QUERY, carrying query attributes and
+	// passed to LOAD DATA LOCAL INFILE reader handlers
+	queryCtx context.Context
+
+	// context of the connect operation reported to Hooks while connecting
+	hookCtx context.Context
+
+	// system variables reported by the server while the session is set up,
+	// if the collation is verified
+	connectVars map[string]string
+
+	// flavor of the server, detected from the handshake or configured
+	flavor ServerFlavor
+
+	// client certificate presented in the TLS handshake, recorded to verify
+	// the binding of ID tokens, see OIDCTokenBinding
+	clientCert *x509.Certificate
+
+	// savepoints of the current transaction, see Savepointer
+	savepoints []string
+
+	// packet recording and tracing
+	recorder      *trafficRecorder
+	redactSent    bool // redact the packets sent, while authenticating
+	tracedCommand byte // last command sent
 
 	// for context support (Go 1.8+)
 	watching bool
@@ -52,16 +108,36 @@ type mysqlConn struct {
 
 // Helper function to call per-connection logger.
 func (mc *mysqlConn) log(v ...any) {
-	_, filename, lineno, ok := runtime.Caller(1)
+	mc.output(LogLevelError, v)
+}
+
+// warn logs a message which doesn't affect the connection.
+func (mc *mysqlConn) warn(v ...any) {
+	mc.output(LogLevelWarn, v)
+}
+
+func (mc *mysqlConn) output(level LogLevel, v []any) {
+	var caller string
+	_, filename, lineno, ok := runtime.Caller(2)
 	if ok {
 		pos := strings.LastIndexByte(filename, '/')
 		if pos != -1 {
 			filename = filename[pos+1:]
 		}
-		prefix := fmt.Sprintf("%s:%d ", filename, lineno)
-		v = append([]any{prefix}, v...)
+		caller = fmt.Sprintf("%s:%d", filename, lineno)
 	}
 
+	if ll, ok := mc.cfg.Logger.(LeveledLogger); ok {
+		ll.Log(level, fmt.Sprint(v...),
+			"conn_id", mc.id, "thread_id", mc.threadID, "addr", mc.cfg.Addr, "caller", caller)
+		return
+	}
+	if level == LogLevelWarn {
+		v = append([]any{"[warn] "}, v...)
+	}
+	if ok {
+		v = append([]any{caller + " "}, v...)
+	}
 	mc.cfg.Logger.Print(v...)
 }
 
@@ -127,30 +203,38 @@ func (mc *mysqlConn) handleParams_org() (err error) {
 }
 
 func (mc *mysqlConn) handleParams() (err error) {
-	var cmdSet strings.Builder
+	if query := mc.paramsQuery(); query != "" {
+		err = mc.exec(query)
+	}
+	return
+}
+
+// paramsQuery returns the SET statement for the system variables of the DSN
+// params and SessionVars, or "" if there are none.
+func (mc *mysqlConn) paramsQuery() string {
+	assignments := mc.sessionAssignments()
+	if len(assignments) == 0 {
+		return ""
+	}
+	return "SET " + strings.Join(assignments, ", ")
+}
 
+// sessionAssignments returns the "name = value" assignments of the system
+// variables of the DSN params and SessionVars.
+func (mc *mysqlConn) sessionAssignments() []string {
+	var assignments []string
 	for param, val := range mc.cfg.Params {
 		// Do not send OIDC parameters as SQL
-		if param == "auth_client_plugin" || param == "authentication_openid_connect_client_id_token_file" {
+		if param == "auth_client_plugin" || param == "authentication_openid_connect_client_id_token_file" ||
+			param == "authentication_openid_connect_client_id_token_env" {
 			continue
 		}
-		if cmdSet.Len() == 0 {
-			// Heuristic: 29 chars for each other key=value to reduce reallocations
-			cmdSet.Grow(4 + len(param) + 3 + len(val) + 30*(len(mc.cfg.Params)-1))
-			cmdSet.WriteString("SET ")
-		} else {
-			cmdSet.WriteString(", ")
-		}
-		cmdSet.WriteString(param)
-		cmdSet.WriteString(" = ")
-		cmdSet.WriteString(val)
+		assignments = append(assignments, param+" = "+val)
 	}
-
-	if cmdSet.Len() > 0 {
-		err = mc.exec(cmdSet.String())
+	for _, v := range mc.cfg.sessionVars {
+		assignments = append(assignments, v.name+" = "+v.value)
 	}
-
-	return
+	return assignments
 }
 
 // markBadConn replaces errBadConnNoWrite with driver.ErrBadConn.
@@ -163,22 +247,17 @@ func (mc *mysqlConn) markBadConn(err error) error {
 }
 
 func (mc *mysqlConn) Begin() (driver.Tx, error) {
-	return mc.begin(false)
+	return mc.begin(context.Background(), false, false)
 }
 
-func (mc *mysqlConn) begin(readOnly bool) (driver.Tx, error) {
+func (mc *mysqlConn) begin(ctx context.Context, readOnly, consistentSnapshot bool) (driver.Tx, error) {
 	if mc.closed.Load() {
 		return nil, driver.ErrBadConn
 	}
-	var q string
-	if readOnly {
-		q = "START TRANSACTION READ ONLY"
-	} else {
-		q = "START TRANSACTION"
-	}
-	err := mc.exec(q)
+	mc.savepoints = nil
+	err := mc.execHooked(ctx, startTransactionQuery(readOnly, consistentSnapshot))
 	if err == nil {
-		return &mysqlTx{mc}, err
+		return &mysqlTx{mc: mc, ctx: ctx}, err
 	}
 	return nil, mc.markBadConn(err)
 }
@@ -187,11 +266,25 @@ func (mc *mysqlConn) Close() (err error) {
 	// Makes Close idempotent
 	if !mc.closed.Load() {
 		err = mc.writeCommandPacket(comQuit)
+		if err == nil && mc.cfg.quitTimeout > 0 {
+			mc.awaitQuit()
+		}
 	}
 	mc.close()
 	return
 }
 
+// awaitQuit waits up to quitTimeout for the server to close the connection
+// after COM_QUIT, discarding the data it still sends.
+func (mc *mysqlConn) awaitQuit() {
+	if err := mc.netConn.SetReadDeadline(time.Now().Add(mc.cfg.quitTimeout)); err != nil {
+		return
+	}
+	// The server closes the connection once it has read COM_QUIT, which ends
+	// the copy with io.EOF. Errors, e.g. of the deadline, are irrelevant.
+	io.Copy(io.Discard, mc.netConn)
+}
+
 // close closes the network connection and clear results without sending COM_QUIT.
 func (mc *mysqlConn) close() {
 	mc.cleanup()
@@ -206,9 +299,17 @@ func (mc *mysqlConn) cleanup() {
 	if mc.closed.Swap(true) {
 		return
 	}
+	if mc.established && mc.cfg.onClose != nil {
+		defer mc.cfg.onClose(mc.connInfo())
+	}
 
 	// Makes cleanup idempotent
 	close(mc.closech)
+	if mc.recorder != nil {
+		if err := mc.recorder.close(); err != nil {
+			mc.warn("closing traffic recording: ", err)
+		}
+	}
 	conn := mc.rawConn
 	if conn == nil {
 		return
@@ -244,37 +345,55 @@ func (mc *mysqlConn) Prepare(query string) (driver.Stmt, error) {
 	}
 
 	stmt := &mysqlStmt{
-		mc: mc,
+		mc:       mc,
+		queryStr: query,
 	}
 
 	// Read Result
+	if err = stmt.readPrepareResult(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// readPrepareResult reads the complete response of COM_STMT_PREPARE.
+func (stmt *mysqlStmt) readPrepareResult() error {
+	mc := stmt.mc
 	columnCount, err := stmt.readPrepareResultPacket()
-	if err == nil {
-		if stmt.paramCount > 0 {
-			if err = mc.skipColumns(stmt.paramCount); err != nil {
-				return nil, err
-			}
+	if err != nil {
+		return err
+	}
+
+	if stmt.paramCount > 0 {
+		if err = mc.skipColumns(stmt.paramCount); err != nil {
+			return err
 		}
+	}
 
-		if columnCount > 0 {
-			if mc.extCapabilities&clientCacheMetadata != 0 {
-				if stmt.columns, err = mc.readColumns(int(columnCount)); err != nil {
-					return nil, err
-				}
-			} else {
-				if err = mc.skipColumns(int(columnCount)); err != nil {
-					return nil, err
-				}
+	if columnCount > 0 {
+		if mc.extCapabilities&clientCacheMetadata != 0 {
+			if _, err = stmt.readColumns(int(columnCount)); err != nil {
+				return err
+			}
+		} else {
+			if err = mc.skipColumns(int(columnCount)); err != nil {
+				return err
 			}
 		}
 	}
-
-	return stmt, err
+	mc.openStmts++
+	return nil
 }
 
 func (mc *mysqlConn) interpolateParams(query string, args []driver.Value) (string, error) {
+	var cache *placeholderCache
+	if mc.connector != nil {
+		cache = mc.connector.placeholders
+	}
+	placeholders := cache.get(query)
+
 	// Number of ? should be same to len(args)
-	if strings.Count(query, "?") != len(args) {
+	if len(placeholders) != len(args) {
 		return "", driver.ErrSkip
 	}
 
@@ -287,19 +406,17 @@ func (mc *mysqlConn) interpolateParams(query string, args []driver.Value) (strin
 		return "", driver.ErrBadConn
 	}
 	buf = buf[:0]
-	argPos := 0
+	if n := interpolatedLen(query, args); n > cap(buf) {
+		// grow once instead of while appending the arguments
+		buf = make([]byte, 0, n)
+	}
+	last := 0
 
-	for i := 0; i < len(query); i++ {
-		q := strings.IndexByte(query[i:], '?')
-		if q == -1 {
-			buf = append(buf, query[i:]...)
-			break
-		}
-		buf = append(buf, query[i:i+q]...)
-		i += q
+	for argPos, q := range placeholders {
+		buf = append(buf, query[last:q]...)
+		last = q + 1
 
 		arg := args[argPos]
-		argPos++
 
 		if arg == nil {
 			buf = append(buf, "NULL"...)
@@ -313,7 +430,13 @@ func (mc *mysqlConn) interpolateParams(query string, args []driver.Value) (strin
 			// Handle uint64 explicitly because our custom ConvertValue emits unsigned values
 			buf = strconv.AppendUint(buf, v, 10)
 		case float64:
-			buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+			// 'g' formats integral values below 1e6 like integers, which
+			// is much cheaper
+			if i := int64(v); float64(i) == v && i > -1e6 && i < 1e6 && (i != 0 || !math.Signbit(v)) {
+				buf = strconv.AppendInt(buf, i, 10)
+			} else {
+				buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+			}
 		case bool:
 			if v {
 				buf = append(buf, '1')
@@ -367,12 +490,36 @@ func (mc *mysqlConn) interpolateParams(query string, args []driver.Value) (strin
 			return "", driver.ErrSkip
 		}
 	}
-	if argPos != len(args) {
-		return "", driver.ErrSkip
-	}
+	buf = append(buf, query[last:]...)
+	mc.buf.store(buf) // allow this buffer to be reused
 	return string(buf), nil
 }
 
+// interpolatedLen estimates the length of query with args interpolated, to
+// size the buffer. Strings may need more for escaping.
+func interpolatedLen(query string, args []driver.Value) int {
+	n := len(query)
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case int64, uint64:
+			n += 20
+		case float64:
+			n += 24
+		case time.Time:
+			n += 32
+		case string:
+			n += len(v) + 2
+		case []byte:
+			n += len(v) + 9
+		case json.RawMessage:
+			n += len(v) + 2
+		default:
+			n += 4
+		}
+	}
+	return n
+}
+
 func (mc *mysqlConn) Exec(query string, args []driver.Value) (driver.Result, error) {
 	if mc.closed.Load() {
 		return nil, driver.ErrBadConn
@@ -399,13 +546,18 @@ func (mc *mysqlConn) Exec(query string, args []driver.Value) (driver.Result, err
 
 // Internal function to execute commands
 func (mc *mysqlConn) exec(query string) error {
-	handleOk := mc.clearResult()
 	// Send command
-	if err := mc.writeCommandPacketStr(comQuery, query); err != nil {
+	if err := mc.writeQueryPacket(query); err != nil {
 		return mc.markBadConn(err)
 	}
 
-	// Read Result
+	return mc.readExecResult()
+}
+
+// readExecResult reads and discards the result of a query sent with
+// writeQueryPacket.
+func (mc *mysqlConn) readExecResult() error {
+	handleOk := mc.clearResult()
 	resLen, _, err := handleOk.readResultSetHeaderPacket()
 	if err != nil {
 		return err
@@ -448,7 +600,7 @@ func (mc *mysqlConn) query(query string, args []driver.Value) (*textRows, error)
 		query = prepared
 	}
 	// Send command
-	err := mc.writeCommandPacketStr(comQuery, query)
+	err := mc.writeQueryPacket(query)
 	if err != nil {
 		return nil, mc.markBadConn(err)
 	}
@@ -483,12 +635,16 @@ func (mc *mysqlConn) query(query string, args []driver.Value) (*textRows, error)
 // The returned byte slice is only valid until the next read
 func (mc *mysqlConn) getSystemVar(name string) ([]byte, error) {
 	// Send command
-	handleOk := mc.clearResult()
-	if err := mc.writeCommandPacketStr(comQuery, "SELECT @@"+name); err != nil {
+	if err := mc.writeQueryPacket("SELECT @@" + name); err != nil {
 		return nil, err
 	}
+	return mc.readSystemVar()
+}
 
-	// Read Result
+// readSystemVar reads the value of a system variable queried with
+// "SELECT @@name".
+func (mc *mysqlConn) readSystemVar() ([]byte, error) {
+	handleOk := mc.clearResult()
 	resLen, _, err := handleOk.readResultSetHeaderPacket()
 	if err == nil {
 		rows := new(textRows)
@@ -514,6 +670,11 @@ func (mc *mysqlConn) getSystemVar(name string) ([]byte, error) {
 func (mc *mysqlConn) cancel(err error) {
 	mc.canceled.Set(err)
 	mc.cleanup()
+	// there is no query to kill during the handshake, and a canceled KILL
+	// QUERY isn't killed in turn
+	if mc.cfg.cancelMode == CancelModeKill && mc.established && !mc.auxiliary {
+		mc.killQuery()
+	}
 }
 
 // finish is called when the query has succeeded.
@@ -539,12 +700,17 @@ func (mc *mysqlConn) Ping(ctx context.Context) (err error) {
 	}
 	defer mc.finish()
 
+	_, span := mc.startHook(ctx, HookInfo{Op: HookPing})
 	handleOk := mc.clearResult()
+	start := time.Now()
 	if err = mc.writeCommandPacket(comPing); err != nil {
-		return mc.markBadConn(err)
+		return span.end(mc.markBadConn(err))
 	}
 
-	return handleOk.readResultOK()
+	if err = handleOk.readResultOK(); err == nil {
+		mc.lastPing = PingStats{Time: start, RTT: time.Since(start)}
+	}
+	return mc.reconnectErr(true, span.end(err))
 }
 
 // BeginTx implements driver.ConnBeginTx interface
@@ -558,22 +724,41 @@ func (mc *mysqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver
 	}
 	defer mc.finish()
 
+	if err := mc.overrideConfig(ctx); err != nil {
+		return nil, err
+	}
+	if err := mc.switchTenant(ctx); err != nil {
+		return nil, err
+	}
+
+	consistentSnapshot := consistentSnapshotFromContext(ctx)
 	if sql.IsolationLevel(opts.Isolation) != sql.LevelDefault {
 		level, err := mapIsolationLevel(opts.Isolation)
 		if err != nil {
 			return nil, err
 		}
-		err = mc.exec("SET TRANSACTION ISOLATION LEVEL " + level)
+		err = mc.execHooked(ctx, "SET TRANSACTION ISOLATION LEVEL "+level)
 		if err != nil {
 			return nil, err
 		}
+		// a snapshot is a repeatable read transaction which reads from the
+		// point it started instead of its first read
+		consistentSnapshot = consistentSnapshot || sql.IsolationLevel(opts.Isolation) == sql.LevelSnapshot
 	}
 
-	return mc.begin(opts.ReadOnly)
+	return mc.begin(ctx, opts.ReadOnly, consistentSnapshot)
 }
 
 func (mc *mysqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
-	dargs, err := namedValueToValue(args)
+	if hasOutArgs(args) {
+		// OUT parameters are only returned to prepared statements
+		return nil, driver.ErrSkip
+	}
+	query, args, err := mc.interceptQuery(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	query, dargs, err := bindNamedArgs(query, args)
 	if err != nil {
 		return nil, err
 	}
@@ -581,18 +766,67 @@ func (mc *mysqlConn) QueryContext(ctx context.Context, query string, args []driv
 	if err := mc.watchCancel(ctx); err != nil {
 		return nil, err
 	}
+	defer mc.startStatementTimer()()
 
-	rows, err := mc.query(query, dargs)
-	if err != nil {
+	if err := mc.overrideConfig(ctx); err != nil {
 		mc.finish()
 		return nil, err
 	}
+	if err := mc.switchTenant(ctx); err != nil {
+		mc.finish()
+		return nil, err
+	}
+
+	ctx, span := mc.startHook(ctx, HookInfo{Op: HookQuery, Query: query})
+
+	if mc.useStmtCache(dargs) {
+		rows, err := mc.queryCached(query, dargs)
+		err = span.end(err)
+		if err != nil {
+			mc.finish()
+			return nil, mc.reconnectErr(isReadOnlyQuery(query), mc.retryReadOnly(query, err))
+		}
+		rows.finish = mc.finish
+		rows.span = mc.startFetchHook(ctx, span, query)
+		return rows, err
+	}
+
+	// Pipelined statements are closed right away, taking their cursor along.
+	if !mc.cursorFetch() && mc.usePipelinedPrepare(dargs) {
+		rows, err := mc.queryPipelined(query, dargs)
+		err = span.end(err)
+		if err != nil {
+			mc.finish()
+			return nil, mc.reconnectErr(isReadOnlyQuery(query), mc.retryReadOnly(query, err))
+		}
+		rows.finish = mc.finish
+		rows.span = mc.startFetchHook(ctx, span, query)
+		return rows, err
+	}
+
+	mc.queryCtx = ctx
+	rows, err := mc.query(mc.deadlineQuery(ctx, query), dargs)
+	mc.queryCtx = nil
+	err = span.end(err)
+	if err != nil {
+		mc.finish()
+		return nil, mc.reconnectErr(isReadOnlyQuery(query), mc.retryReadOnly(query, err))
+	}
 	rows.finish = mc.finish
+	rows.span = mc.startFetchHook(ctx, span, query)
 	return rows, err
 }
 
 func (mc *mysqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
-	dargs, err := namedValueToValue(args)
+	if hasOutArgs(args) {
+		// OUT parameters are only returned to prepared statements
+		return nil, driver.ErrSkip
+	}
+	query, args, err := mc.interceptQuery(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	query, dargs, err := bindNamedArgs(query, args)
 	if err != nil {
 		return nil, err
 	}
@@ -601,20 +835,114 @@ func (mc *mysqlConn) ExecContext(ctx context.Context, query string, args []drive
 		return nil, err
 	}
 	defer mc.finish()
+	defer mc.startStatementTimer()()
 
-	return mc.Exec(query, dargs)
+	if err := mc.overrideConfig(ctx); err != nil {
+		return nil, err
+	}
+	if err := mc.switchTenant(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, span := mc.startHook(ctx, HookInfo{Op: HookExec, Query: query})
+
+	var res driver.Result
+	switch {
+	case mc.useStmtCache(dargs):
+		res, err = mc.execCached(query, dargs)
+	case mc.usePipelinedPrepare(dargs):
+		res, err = mc.execPipelined(query, dargs)
+	default:
+		mc.queryCtx = ctx
+		res, err = mc.Exec(query, dargs)
+		mc.queryCtx = nil
+	}
+	if err == nil {
+		recordGTID(ctx, res)
+	}
+	return res, mc.reconnectErr(isReadOnlyQuery(query), span.endExec(res, err))
+}
+
+// useStmtCache reports whether a query with the given arguments should be
+// executed using a cached prepared statement.
+func (mc *mysqlConn) useStmtCache(args []driver.Value) bool {
+	return mc.stmtCache != nil && len(args) != 0 && !mc.cfg.InterpolateParams
+}
+
+// cachedStmt returns the prepared statement for query from the statement
+// cache. On a cache miss, the query is prepared and added to the cache.
+func (mc *mysqlConn) cachedStmt(query string) (*mysqlStmt, error) {
+	if stmt := mc.stmtCache.get(query); stmt != nil {
+		return stmt, nil
+	}
+
+	ds, err := mc.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	stmt := ds.(*mysqlStmt)
+
+	if evicted := mc.stmtCache.put(query, stmt); evicted != nil {
+		if err := evicted.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
+}
+
+func (mc *mysqlConn) queryCached(query string, args []driver.Value) (*binaryRows, error) {
+	stmt, err := mc.cachedStmt(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.query(args)
+}
+
+func (mc *mysqlConn) execCached(query string, args []driver.Value) (driver.Result, error) {
+	stmt, err := mc.cachedStmt(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Exec(args)
+}
+
+// discardCachedStmts empties the statement cache after the server discarded
+// the prepared statements of the session.
+func (mc *mysqlConn) discardCachedStmts() {
+	if mc.stmtCache != nil {
+		mc.openStmts -= mc.stmtCache.len()
+		mc.stmtCache.clear()
+	}
 }
 
 func (mc *mysqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	query, _, err := mc.interceptQuery(ctx, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := mc.watchCancel(ctx); err != nil {
 		return nil, err
 	}
 
+	if err := mc.overrideConfig(ctx); err != nil {
+		mc.finish()
+		return nil, err
+	}
+	if err := mc.switchTenant(ctx); err != nil {
+		mc.finish()
+		return nil, err
+	}
+
+	query, params := rewriteNamedParams(query, isPrepareParam)
+	_, span := mc.startHook(ctx, HookInfo{Op: HookPrepare, Query: query})
 	stmt, err := mc.Prepare(query)
+	err = span.end(err)
 	mc.finish()
 	if err != nil {
-		return nil, err
+		return nil, mc.reconnectErr(true, err)
 	}
+	stmt.(*mysqlStmt).params = params
 
 	select {
 	default:
@@ -626,7 +954,11 @@ func (mc *mysqlConn) PrepareContext(ctx context.Context, query string) (driver.S
 }
 
 func (stmt *mysqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
-	dargs, err := namedValueToValue(args)
+	args, outs, err := bindOutArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	dargs, err := stmt.bindArgs(args)
 	if err != nil {
 		return nil, err
 	}
@@ -634,18 +966,37 @@ func (stmt *mysqlStmt) QueryContext(ctx context.Context, args []driver.NamedValu
 	if err := stmt.mc.watchCancel(ctx); err != nil {
 		return nil, err
 	}
+	defer stmt.mc.startStatementTimer()()
 
-	rows, err := stmt.query(dargs)
-	if err != nil {
+	if err := stmt.mc.overrideConfig(ctx); err != nil {
 		stmt.mc.finish()
 		return nil, err
 	}
-	rows.finish = stmt.mc.finish
+	if err := stmt.mc.switchTenant(ctx); err != nil {
+		stmt.mc.finish()
+		return nil, err
+	}
+
+	mc := stmt.mc
+	ctx, span := mc.startHook(ctx, HookInfo{Op: HookQuery, Query: stmt.queryStr})
+	rows, err := stmt.query(dargs)
+	err = span.end(err)
+	if err != nil {
+		mc.finish()
+		return nil, mc.reconnectErr(isReadOnlyQuery(stmt.queryStr), mc.retryReadOnly(stmt.queryStr, err))
+	}
+	rows.outs = outs
+	rows.finish = mc.finish
+	rows.span = mc.startFetchHook(ctx, span, stmt.queryStr)
 	return rows, err
 }
 
 func (stmt *mysqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
-	dargs, err := namedValueToValue(args)
+	args, outs, err := bindOutArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	dargs, err := stmt.bindArgs(args)
 	if err != nil {
 		return nil, err
 	}
@@ -654,8 +1005,26 @@ func (stmt *mysqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue
 		return nil, err
 	}
 	defer stmt.mc.finish()
+	defer stmt.mc.startStatementTimer()()
 
-	return stmt.Exec(dargs)
+	if err := stmt.mc.overrideConfig(ctx); err != nil {
+		return nil, err
+	}
+	if err := stmt.mc.switchTenant(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, span := stmt.mc.startHook(ctx, HookInfo{Op: HookExec, Query: stmt.queryStr})
+	var res driver.Result
+	if outs != nil {
+		res, err = stmt.execOut(dargs, outs)
+	} else {
+		res, err = stmt.Exec(dargs)
+	}
+	if err == nil {
+		recordGTID(ctx, res)
+	}
+	return res, stmt.mc.reconnectErr(isReadOnlyQuery(stmt.queryStr), span.endExec(res, err))
 }
 
 func (mc *mysqlConn) watchCancel(ctx context.Context) error {
@@ -699,7 +1068,7 @@ func (mc *mysqlConn) startWatcher() {
 
 			select {
 			case <-ctx.Done():
-				mc.cancel(ctx.Err())
+				mc.cancel(context.Cause(ctx))
 			case <-finished:
 			case <-mc.closech:
 				return
@@ -709,6 +1078,9 @@ func (mc *mysqlConn) startWatcher() {
 }
 
 func (mc *mysqlConn) CheckNamedValue(nv *driver.NamedValue) (err error) {
+	if _, ok := nv.Value.(sql.Out); ok {
+		return nil
+	}
 	nv.Value, err = converter{}.ConvertValue(nv.Value)
 	return
 }
@@ -720,6 +1092,12 @@ func (mc *mysqlConn) ResetSession(ctx context.Context) error {
 		return driver.ErrBadConn
 	}
 
+	// Configuration overrides last for a single query only.
+	mc.restoreConfig()
+
+	// Return the memory of large packets read last time to the pool.
+	mc.buf.shrink()
+
 	// Perform a stale connection check. We only perform this check for
 	// the first query on a connection that has been checked out of the
 	// connection pool: a fresh connection from the pool is more likely
@@ -744,12 +1122,64 @@ func (mc *mysqlConn) ResetSession(ctx context.Context) error {
 		}
 	}
 
+	// Do not keep sessions bound to an ID token which expired or was
+	// rotated, so the pool re-dials with the current token.
+	if mc.oidcToken != nil && mc.oidcToken.stale(time.Now().Add(mc.cfg.oidcClockSkew)) {
+		return driver.ErrBadConn
+	}
+
+	if mc.cfg.resetConnection && !mc.holdsStmts() {
+		if err := mc.resetConnection(); err != nil {
+			mc.log("resetting connection: ", err)
+			return driver.ErrBadConn
+		}
+		return nil
+	}
+
+	// Do not carry the session state of a tenant into the next checkout.
+	if err := mc.switchTenant(ctx); err != nil {
+		mc.log("resetting tenant session: ", err)
+		return driver.ErrBadConn
+	}
+
 	return nil
 }
 
+// resetConnection resets the session with COM_RESET_CONNECTION and sets the
+// charset and the session variables of the DSN params again.
+func (mc *mysqlConn) resetConnection() error {
+	handleOk := mc.clearResult()
+	if err := mc.writeCommandPacket(comResetConnection); err != nil {
+		return err
+	}
+	if err := handleOk.readResultOK(); err != nil {
+		return err
+	}
+
+	// The server discarded the prepared statements and the session state.
+	mc.discardCachedStmts()
+	mc.tenantID = ""
+	mc.tenantSession = TenantSession{}
+
+	return mc.initSession()
+}
+
+// holdsStmts reports whether prepared statements other than the cached ones
+// are open on the connection.
+func (mc *mysqlConn) holdsStmts() bool {
+	cached := 0
+	if mc.stmtCache != nil {
+		cached = mc.stmtCache.len()
+	}
+	return mc.openStmts > cached
+}
+
 // IsValid implements driver.Validator interface
 // (From Go 1.15)
 func (mc *mysqlConn) IsValid() bool {
+	if mc.oidcToken != nil && mc.oidcToken.expired(time.Now().Add(mc.cfg.oidcClockSkew)) {
+		return false
+	}
 	return !mc.closed.Load() && !mc.buf.busy()
 }
 
@@ -13,8 +13,12 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"io"
+	"math"
 	"net"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestInterpolateParams(t *testing.T) {
@@ -115,6 +119,77 @@ func TestInterpolateParamsUint64(t *testing.T) {
 	}
 }
 
+func TestInterpolateParamsNumbers(t *testing.T) {
+	mc := &mysqlConn{
+		buf:              newBuffer(),
+		maxAllowedPacket: maxPacketSize,
+		cfg: &Config{
+			InterpolateParams: true,
+			Loc:               time.UTC,
+		},
+	}
+
+	for _, tt := range []struct {
+		arg  any
+		want string
+	}{
+		{int64(math.MinInt64), "-9223372036854775808"},
+		{uint64(math.MaxUint64), "18446744073709551615"},
+		{0.0, "0"},
+		{math.Copysign(0, -1), "-0"},
+		{-999999.0, "-999999"},
+		{1e6, "1e+06"},
+		{0.1, "0.1"},
+		{1.0 / 3, "0.3333333333333333"},
+		{float32(0.1), "0.1"},
+		{float32(16777217), "1.6777216e+07"},
+		{math.MaxFloat64, "1.7976931348623157e+308"},
+		{math.SmallestNonzeroFloat64, "5e-324"},
+		{int8(-128), "-128"},
+		{time.Date(2024, 2, 29, 23, 59, 59, 999999000, time.UTC), "'2024-02-29 23:59:59.999999'"},
+		{time.Date(2024, 2, 29, 0, 0, 0, 1, time.UTC), "'2024-02-29 00:00:00.000000001'"},
+	} {
+		v, err := converter{}.ConvertValue(tt.arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, err := mc.interpolateParams("SELECT ?", []driver.Value{v})
+		if err != nil {
+			t.Errorf("%v: %v", tt.arg, err)
+		} else if q != "SELECT "+tt.want {
+			t.Errorf("%v: expected %q, got %q", tt.arg, "SELECT "+tt.want, q)
+		}
+	}
+}
+
+func TestInterpolateParamsLarge(t *testing.T) {
+	mc := &mysqlConn{
+		buf:              newBuffer(),
+		maxAllowedPacket: maxPacketSize,
+		cfg: &Config{
+			InterpolateParams: true,
+		},
+	}
+
+	q := "INSERT INTO t VALUES (?, ?)" + strings.Repeat(", (?, ?)", 999)
+	args := make([]driver.Value, 0, 2000)
+	for i := 0; i < 1000; i++ {
+		args = append(args, int64(i), "gopher")
+	}
+	for i := 0; i < 2; i++ {
+		got, err := mc.interpolateParams(q, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "INSERT INTO t VALUES (0, 'gopher')"; !strings.HasPrefix(got, want) || !strings.HasSuffix(got, ", (999, 'gopher')") {
+			t.Fatalf("unexpected query %.40q...", got)
+		}
+	}
+	if n := cap(mc.buf.cachedBuf); n < interpolatedLen(q, args) {
+		t.Errorf("expected the grown buffer to be kept, got a capacity of %d", n)
+	}
+}
+
 func TestCheckNamedValue(t *testing.T) {
 	value := driver.NamedValue{Value: ^uint64(0)}
 	mc := &mysqlConn{}
@@ -191,6 +266,108 @@ func TestPingErrInvalidConn(t *testing.T) {
 	}
 }
 
+func TestResetSessionResetConnection(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.cfg.CheckConnLiveness = false
+	mc.cfg.resetConnection = true
+	mc.stmtCache = newStmtCache(2)
+	mc.stmtCache.put("SELECT ?", &mysqlStmt{mc: mc, id: 1})
+	mc.openStmts = 1
+	mc.tenantID = "acme"
+	conn.queuedReplies = [][]byte{{7, 0, 0, 1, iOK, 0, 0, 2, 0, 0, 0}}
+
+	if err := mc.ResetSession(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []byte{1, 0, 0, 0, comResetConnection}; string(conn.written) != string(expected) {
+		t.Errorf("expected %x, got %x", expected, conn.written)
+	}
+	if mc.stmtCache.len() != 0 || mc.openStmts != 0 {
+		t.Error("expected the cached statements to be discarded")
+	}
+	if mc.tenantID != "" {
+		t.Error("expected the tenant session to be reset")
+	}
+}
+
+func TestResetSessionKeepsPreparedStatements(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.cfg.CheckConnLiveness = false
+	mc.cfg.resetConnection = true
+	mc.openStmts = 1
+
+	if err := mc.ResetSession(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(conn.written) != 0 {
+		t.Errorf("expected no reset while statements are open, got %x", conn.written)
+	}
+}
+
+func newPipeConn(cfg *Config) (net.Conn, *mysqlConn) {
+	client, server := net.Pipe()
+	mc := &mysqlConn{
+		netConn:          client,
+		buf:              newBuffer(),
+		maxAllowedPacket: defaultMaxAllowedPacket,
+		closech:          make(chan struct{}),
+		cfg:              cfg,
+	}
+	return server, mc
+}
+
+func TestCloseAwaitsQuit(t *testing.T) {
+	cfg := NewConfig()
+	cfg.quitTimeout = 5 * time.Second
+	server, mc := newPipeConn(cfg)
+
+	quit := make(chan []byte, 1)
+	go func() {
+		packet := make([]byte, 5)
+		if _, err := io.ReadFull(server, packet); err != nil {
+			t.Error(err)
+		}
+		quit <- packet
+		// The rest of a result set the client has not read
+		server.Write([]byte{1, 0, 0, 3, 0xfe})
+		server.Close()
+	}()
+
+	start := time.Now()
+	if err := mc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d >= cfg.quitTimeout {
+		t.Errorf("expected Close to return once the server closed, took %v", d)
+	}
+	if expected := []byte{1, 0, 0, 0, comQuit}; string(<-quit) != string(expected) {
+		t.Errorf("expected COM_QUIT")
+	}
+	if !mc.closed.Load() {
+		t.Error("expected the connection to be closed")
+	}
+}
+
+func TestCloseQuitTimeout(t *testing.T) {
+	cfg := NewConfig()
+	cfg.quitTimeout = 50 * time.Millisecond
+	server, mc := newPipeConn(cfg)
+	defer server.Close()
+
+	go io.Copy(io.Discard, server)
+
+	start := time.Now()
+	if err := mc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < cfg.quitTimeout {
+		t.Errorf("expected Close to wait for the server, took %v", d)
+	}
+	if !mc.closed.Load() {
+		t.Error("expected the connection to be closed")
+	}
+}
+
 type badConnection struct {
 	n   int
 	err error
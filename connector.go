@@ -10,17 +10,56 @@ package mysql
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql/driver"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
 	"net"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type connector struct {
-	cfg               *Config // immutable private copy.
-	encodedAttributes string  // Encoded connection attributes.
+	cfg               *Config           // immutable private copy.
+	encodedAttributes string            // Encoded connection attributes.
+	connectSlots      chan struct{}     // limits simultaneous handshakes. nil if unlimited.
+	placeholders      *placeholderCache // placeholder positions of interpolated queries
+
+	// max_allowed_packet values of the servers by address, see MaxAllowedPacketTTL
+	maxAllowedPacketMu sync.Mutex
+	maxAllowedPackets  map[string]cachedMaxAllowedPacket
+
+	// connections established by WarmUp, handed out by Connect first
+	warmMu sync.Mutex
+	warm   []*mysqlConn
+
+	// statistics of the connect queue
+	waitCount    atomic.Int64
+	waitDuration atomic.Int64 // nanoseconds
+	waitTimeouts atomic.Int64
+}
+
+// ConnectStats contains statistics about the connections established by a
+// connector.
+//
+// It is accessible by asserting the driver.Connector returned by NewConnector
+// or MySQLDriver.OpenConnector:
+//
+//	stats := connector.(interface{ ConnectStats() mysql.ConnectStats }).ConnectStats()
+type ConnectStats struct {
+	MaxConcurrentConnects int // Maximum number of simultaneous handshakes (0: unlimited)
+	InProgress            int // The number of handshakes currently in progress
+	Warm                  int // The number of connections of WarmUp not handed out yet
+
+	WaitCount    int64         // The total number of connects that waited for a free handshake slot
+	WaitDuration time.Duration // The total time spent waiting for a free handshake slot
+	WaitTimeouts int64         // The total number of connects that gave up waiting for a free handshake slot
 }
 
 func encodeConnectionAttributes(cfg *Config) string {
@@ -56,17 +95,138 @@ func encodeConnectionAttributes(cfg *Config) string {
 
 func newConnector(cfg *Config) *connector {
 	encodedAttributes := encodeConnectionAttributes(cfg)
-	return &connector{
+	c := &connector{
 		cfg:               cfg,
 		encodedAttributes: encodedAttributes,
+		placeholders:      newPlaceholderCache(),
+	}
+	if cfg.maxConcurrentConnects > 0 {
+		c.connectSlots = make(chan struct{}, cfg.maxConcurrentConnects)
+	}
+	// Share TLS sessions between the connections to resume them instead of
+	// doing full handshakes.
+	if cfg.TLS != nil && cfg.TLS.ClientSessionCache == nil {
+		cfg.TLS.ClientSessionCache = tls.NewLRUClientSessionCache(tlsSessionCacheSize)
+	}
+	return c
+}
+
+// acquireConnectSlot blocks until a handshake slot is free, the queue timeout
+// expires or ctx is done.
+func (c *connector) acquireConnectSlot(ctx context.Context) error {
+	// fast path: a slot is free
+	select {
+	case c.connectSlots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	start := time.Now()
+	defer func() {
+		c.waitCount.Add(1)
+		c.waitDuration.Add(int64(time.Since(start)))
+	}()
+
+	var timeout <-chan time.Time
+	if c.cfg.connectQueueTimeout > 0 {
+		timer := time.NewTimer(c.cfg.connectQueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case c.connectSlots <- struct{}{}:
+		return nil
+	case <-timeout:
+		c.waitTimeouts.Add(1)
+		return ErrConnectQueueTimeout
+	case <-ctx.Done():
+		c.waitTimeouts.Add(1)
+		return ctx.Err()
+	}
+}
+
+func (c *connector) releaseConnectSlot() {
+	<-c.connectSlots
+}
+
+// cachedMaxAllowedPacket is a max_allowed_packet value of a server cached by
+// a connector.
+type cachedMaxAllowedPacket struct {
+	n       int
+	expires time.Time
+}
+
+// cachedMaxAllowedPacket returns the max_allowed_packet value cached for the
+// address of cfg, if it hasn't expired.
+func (c *connector) cachedMaxAllowedPacket(cfg *Config) (int, bool) {
+	if cfg.maxAllowedPacketTTL <= 0 {
+		return 0, false
+	}
+	c.maxAllowedPacketMu.Lock()
+	defer c.maxAllowedPacketMu.Unlock()
+	e, ok := c.maxAllowedPackets[cfg.Net+"/"+cfg.Addr]
+	if !ok || time.Now().After(e.expires) {
+		return 0, false
+	}
+	return e.n, true
+}
+
+// cacheMaxAllowedPacket caches the max_allowed_packet value n of the server at
+// the address of cfg for maxAllowedPacketTTL.
+func (c *connector) cacheMaxAllowedPacket(cfg *Config, n int) {
+	if cfg.maxAllowedPacketTTL <= 0 {
+		return
+	}
+	now := time.Now()
+	c.maxAllowedPacketMu.Lock()
+	defer c.maxAllowedPacketMu.Unlock()
+	if c.maxAllowedPackets == nil {
+		c.maxAllowedPackets = make(map[string]cachedMaxAllowedPacket)
+	}
+	// Drop expired values, in case the address changes with every connect
+	for addr, e := range c.maxAllowedPackets {
+		if now.After(e.expires) {
+			delete(c.maxAllowedPackets, addr)
+		}
+	}
+	c.maxAllowedPackets[cfg.Net+"/"+cfg.Addr] = cachedMaxAllowedPacket{n: n, expires: now.Add(cfg.maxAllowedPacketTTL)}
+}
+
+// ConnectStats returns the connect statistics of the connector.
+func (c *connector) ConnectStats() ConnectStats {
+	return ConnectStats{
+		MaxConcurrentConnects: c.cfg.maxConcurrentConnects,
+		InProgress:            len(c.connectSlots),
+		Warm:                  c.warmConns(),
+		WaitCount:             c.waitCount.Load(),
+		WaitDuration:          time.Duration(c.waitDuration.Load()),
+		WaitTimeouts:          c.waitTimeouts.Load(),
 	}
 }
 
 // Connect implements driver.Connector interface.
-// Connect returns a connection to the database.
+// Connect returns a connection to the database, one of WarmUp if available.
 func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	if mc := c.takeWarmConn(ctx); mc != nil {
+		return mc, nil
+	}
+	return c.establish(ctx)
+}
+
+// establish establishes a new connection.
+func (c *connector) establish(ctx context.Context) (driver.Conn, error) {
 	var err error
 
+	// Wait for a free handshake slot if the number of simultaneous
+	// handshakes is limited.
+	if c.connectSlots != nil {
+		if err = c.acquireConnectSlot(ctx); err != nil {
+			return nil, err
+		}
+		defer c.releaseConnectSlot()
+	}
+
 	// Invoke beforeConnect if present, with a copy of the configuration
 	cfg := c.cfg
 	if c.cfg.beforeConnect != nil {
@@ -77,15 +237,56 @@ func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
 		}
 	}
 
+	for attempt := 0; ; attempt++ {
+		mc, authStarted, err := c.connect(ctx, cfg)
+		if err == nil {
+			if err = mc.connected(ctx); err != nil {
+				return nil, err
+			}
+			return mc, nil
+		}
+		if authStarted || attempt >= cfg.dialRetries || ctx.Err() != nil || !isTransientConnectError(err) {
+			return nil, err
+		}
+
+		if sc := cfg.statsCollector; sc != nil {
+			sc.ConnectRetry()
+		}
+		delay := dialBackoffDelay(cfg.dialBackoff, attempt)
+		logTo(c.cfg.Logger, LogLevelWarn, []any{"addr", cfg.Addr}, "connect failed, retrying in ", delay, ": ", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		}
+	}
+}
+
+// connect makes a single attempt to establish a connection. authStarted
+// reports whether the failure happened after the auth response was sent, in
+// which case the attempt must not be retried.
+func (c *connector) connect(ctx context.Context, cfg *Config) (_ *mysqlConn, authStarted bool, err error) {
 	// New mysqlConn
 	mc := &mysqlConn{
 		maxAllowedPacket: maxPacketSize,
 		maxWriteSize:     maxPacketSize - 1,
 		closech:          make(chan struct{}),
+		redactSent:       true, // until authenticated
 		cfg:              cfg,
 		connector:        c,
+		id:               lastConnID.Add(1),
 	}
 	mc.parseTime = mc.cfg.ParseTime
+	if mc.cfg.stmtCacheSize > 0 {
+		mc.stmtCache = newStmtCache(mc.cfg.stmtCacheSize)
+	}
+
+	ctx, span := mc.startHook(ctx, HookInfo{Op: HookConnect})
+	defer func() { err = span.end(err) }()
+	mc.hookCtx = ctx
+	defer func() { mc.hookCtx = nil }()
 
 	// Connect to Server
 	dctx := ctx
@@ -95,6 +296,7 @@ func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
 		defer cancel()
 	}
 
+	dctx, dialSpan := mc.startHook(dctx, HookInfo{Op: HookDial})
 	if c.cfg.DialFunc != nil {
 		mc.netConn, err = c.cfg.DialFunc(dctx, mc.cfg.Net, mc.cfg.Addr)
 	} else {
@@ -108,118 +310,231 @@ func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
 			mc.netConn, err = nd.DialContext(dctx, mc.cfg.Net, mc.cfg.Addr)
 		}
 	}
+	dialSpan.end(err)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	mc.rawConn = mc.netConn
+	mc.startRecording()
 
 	// Enable TCP Keepalives on TCP connections
 	if tc, ok := mc.netConn.(*net.TCPConn); ok {
 		if err := tc.SetKeepAlive(true); err != nil {
-			c.cfg.Logger.Print(err)
+			mc.warn(err)
 		}
 	}
 
+	// Limit the whole handshake, including TLS and all rounds of
+	// authentication, independently of the dial and I/O timeouts.
+	hctx := ctx
+	if cfg.handshakeTimeout > 0 {
+		var cancel context.CancelFunc
+		hctx, cancel = context.WithTimeoutCause(ctx, cfg.handshakeTimeout, ErrHandshakeTimeout)
+		defer cancel()
+	}
+
 	// Call startWatcher for context support (From Go 1.8)
 	mc.startWatcher()
-	if err := mc.watchCancel(ctx); err != nil {
+	if err := mc.watchCancel(hctx); err != nil {
 		mc.cleanup()
-		return nil, err
+		return nil, authStarted, err
 	}
 	defer mc.finish()
 
-	mc.buf = newBuffer()
+	mc.buf = newBufferSize(max(mc.cfg.readBufferSize, defaultBufSize))
+	mc.buf.maxRetained = mc.cfg.maxRetainedBuffer
 
 	// Reading Handshake Initialization Packet
 	authData, serverCapabilities, serverExtCapabilities, plugin, err := mc.readHandshakePacket()
 	if err != nil {
 		mc.cleanup()
-		return nil, err
+		return nil, authStarted, err
 	}
 
+	mc.initFlavor(serverCapabilities)
+
 	if plugin == "" {
-		plugin = defaultAuthPlugin
+		plugin = mc.cfg.defaultAuthPlugin()
 	}
 
 	// Send Client Authentication Packet
 	authResp, err := mc.auth(authData, plugin)
 	if err != nil {
 		// try the default auth plugin, if using the requested plugin failed
-		c.cfg.Logger.Print("could not use requested auth plugin '"+plugin+"': ", err.Error())
-		plugin = defaultAuthPlugin
+		mc.warn("could not use requested auth plugin '"+plugin+"': ", err.Error())
+		plugin = mc.cfg.defaultAuthPlugin()
 		authResp, err = mc.auth(authData, plugin)
 		if err != nil {
 			mc.cleanup()
-			return nil, err
+			return nil, authStarted, err
 		}
 	}
+	_, authSpan := mc.startHook(ctx, HookInfo{Op: HookAuth, AuthPlugin: plugin})
 	mc.initCapabilities(serverCapabilities, serverExtCapabilities, mc.cfg)
 	if err = mc.writeHandshakeResponsePacket(authResp, plugin); err != nil {
+		authSpan.end(err)
 		mc.cleanup()
-		return nil, err
+		return nil, authStarted, err
 	}
 
 	// Handle response to auth packet, switch methods if possible
-	if err = mc.handleAuthResult(authData, plugin); err != nil {
+	authStarted = true
+	err = mc.handleAuthResult(authData, plugin)
+	if authSpan != nil && mc.authPlugin != "" {
+		authSpan.info.AuthPlugin = mc.authPlugin
+	}
+	authSpan.end(err)
+	if err != nil {
 		// Authentication failed and MySQL has already closed the connection
 		// (https://dev.mysql.com/doc/internals/en/authentication-fails.html).
 		// Do not send COM_QUIT, just cleanup and return the error.
 		mc.cleanup()
-		return nil, err
+		info := mc.connInfo()
+		if info.AuthPlugin == "" {
+			info.AuthPlugin = plugin
+		}
+		var mysqlErr *MySQLError
+		if errors.As(err, &mysqlErr) {
+			err = &AuthError{Plugin: info.AuthPlugin, TLS: info.TLS, Err: mysqlErr}
+		}
+		if mysqlErr != nil && mc.oidcToken != nil {
+			mc.oidcTokenEvent(OIDCTokenRejected, mc.oidcToken.src, mc.oidcToken.expiry, err)
+		}
+		if fn := mc.cfg.onAuthFailure; fn != nil {
+			fn(ctx, info, err)
+		}
+		return nil, authStarted, err
 	}
+	mc.scramble = authData
+	mc.redactTraffic(false)
 
 	// compression is enabled after auth, not right after sending handshake response.
 	if mc.capabilities&clientCompress > 0 {
+		codec := getCompressionCodec(mc.cfg.compressCodec)
+		if codec == nil {
+			mc.Close()
+			return nil, authStarted, errors.New("unknown compression codec: " + mc.cfg.compressCodec)
+		}
 		mc.compress = true
-		mc.compIO = newCompIO(mc)
+		mc.compIO = newCompIO(mc, codec)
 	}
+	queryMaxAllowedPacket := false
 	if mc.cfg.MaxAllowedPacket > 0 {
 		mc.maxAllowedPacket = mc.cfg.MaxAllowedPacket
+	} else if n, ok := c.cachedMaxAllowedPacket(mc.cfg); ok {
+		mc.maxAllowedPacket = n
+	} else if mc.flavor == FlavorProxySQL {
+		// SELECT @@max_allowed_packet disables multiplexing in ProxySQL
+		mc.maxAllowedPacket = defaultMaxAllowedPacket
 	} else {
-		// Get max allowed packet size
-		maxap, err := mc.getSystemVar("max_allowed_packet")
-		if err != nil {
-			mc.Close()
-			return nil, err
+		queryMaxAllowedPacket = true
+	}
+
+	if mc.cfg.verifyCollation {
+		mc.connectVars = make(map[string]string)
+		defer func() { mc.connectVars = nil }()
+	}
+	if mc.usePipelinedSessionInit() {
+		err = mc.initSessionPipelined(queryMaxAllowedPacket)
+	} else {
+		if queryMaxAllowedPacket {
+			err = mc.queryMaxAllowedPacket()
 		}
-		n, err := strconv.Atoi(string(maxap))
-		if err != nil {
-			mc.Close()
-			return nil, fmt.Errorf("invalid max_allowed_packet value (%q): %w", maxap, err)
+		if err == nil {
+			err = mc.initSession()
 		}
-		mc.maxAllowedPacket = n - 1
+	}
+	if err == nil && mc.cfg.verifyCollation {
+		err = mc.checkCollation()
+	}
+	if err != nil {
+		mc.Close()
+		return nil, authStarted, err
+	}
+	if queryMaxAllowedPacket {
+		c.cacheMaxAllowedPacket(mc.cfg, mc.maxAllowedPacket)
 	}
 	if mc.maxAllowedPacket < maxPacketSize {
 		mc.maxWriteSize = mc.maxAllowedPacket
 	}
 
+	return mc, false, nil
+}
+
+// queryMaxAllowedPacket sets the max packet size to the max_allowed_packet
+// value of the server.
+func (mc *mysqlConn) queryMaxAllowedPacket() error {
+	maxap, err := mc.getSystemVar("max_allowed_packet")
+	if err != nil {
+		return err
+	}
+	return mc.setMaxAllowedPacket(maxap)
+}
+
+// setMaxAllowedPacket sets the max packet size to the max_allowed_packet
+// value maxap read from the server.
+func (mc *mysqlConn) setMaxAllowedPacket(maxap []byte) error {
+	n, err := strconv.Atoi(string(maxap))
+	if err != nil {
+		return fmt.Errorf("invalid max_allowed_packet value (%q): %w", maxap, err)
+	}
+	mc.maxAllowedPacket = n - 1
+	return nil
+}
+
+// isTransientConnectError reports whether err is a network failure which may
+// not recur on another attempt, such as a refused connection during a
+// failover or a connection reset during the handshake.
+func isTransientConnectError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, ErrInvalidConn) || errors.Is(err, ErrHandshakeTimeout) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// dialBackoffDelay returns the delay before retry attempt+1: the base
+// backoff doubled per attempt, capped at maxDialBackoff, with jitter so that
+// the connections of a pool don't retry in lockstep.
+func dialBackoffDelay(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		base = defaultDialBackoff
+	}
+	d := maxDialBackoff
+	if attempt < 32 && base < maxDialBackoff>>attempt {
+		d = base << attempt
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// initSession sets the charset and the session variables of the DSN params.
+func (mc *mysqlConn) initSession() (err error) {
 	// Charset: character_set_connection, character_set_client, character_set_results
-	if len(mc.cfg.charsets) > 0 {
-		for _, cs := range mc.cfg.charsets {
+	if charsets := mc.sessionCharsets(); len(charsets) > 0 {
+		for _, cs := range charsets {
 			// ignore errors here - a charset may not exist
-			if mc.cfg.Collation != "" {
-				err = mc.exec("SET NAMES " + cs + " COLLATE " + mc.cfg.Collation)
-			} else {
-				err = mc.exec("SET NAMES " + cs)
-			}
+			err = mc.exec(mc.setNamesQuery(cs))
 			if err == nil {
 				break
 			}
 		}
 		if err != nil {
-			mc.Close()
-			return nil, err
+			return err
 		}
 	}
 
 	// Handle DSN Params
-	err = mc.handleParams()
-	if err != nil {
-		mc.Close()
-		return nil, err
+	if mc.skipUnknownSystemVars() {
+		return mc.handleParamsSeparately()
 	}
+	return mc.handleParams()
+}
 
-	return mc, nil
+// setNamesQuery returns the SET NAMES statement for the charset cs and the
+// configured collation.
+func (mc *mysqlConn) setNamesQuery(cs string) string {
+	if mc.cfg.Collation != "" {
+		return "SET NAMES " + cs + " COLLATE " + mc.cfg.Collation
+	}
+	return "SET NAMES " + cs
 }
 
 // Driver implements driver.Connector interface.
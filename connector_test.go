@@ -2,6 +2,8 @@ package mysql
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"net"
 	"testing"
 	"time"
@@ -28,3 +30,216 @@ func TestConnectorReturnsTimeout(t *testing.T) {
 		t.Fatalf("expected %T, got %T", nerr, err)
 	}
 }
+
+func TestConnectorConnectQueueTimeout(t *testing.T) {
+	cfg := NewConfig()
+	if err := cfg.Apply(MaxConcurrentConnects(1, 10*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.normalize(); err != nil {
+		t.Fatal(err)
+	}
+	connector := newConnector(cfg)
+
+	// occupy the only handshake slot
+	connector.connectSlots <- struct{}{}
+
+	_, err := connector.Connect(context.Background())
+	if err != ErrConnectQueueTimeout {
+		t.Fatalf("expected ErrConnectQueueTimeout, got %v", err)
+	}
+
+	stats := connector.ConnectStats()
+	if stats.MaxConcurrentConnects != 1 || stats.InProgress != 1 {
+		t.Errorf("unexpected limits in stats: %+v", stats)
+	}
+	if stats.WaitCount != 1 || stats.WaitTimeouts != 1 {
+		t.Errorf("expected one timed out wait, got %+v", stats)
+	}
+	if stats.WaitDuration < 10*time.Millisecond {
+		t.Errorf("expected wait duration >= 10ms, got %v", stats.WaitDuration)
+	}
+}
+
+func TestConnectorConnectQueueContextCanceled(t *testing.T) {
+	cfg := NewConfig()
+	cfg.maxConcurrentConnects = 1
+	connector := newConnector(cfg)
+	connector.connectSlots <- struct{}{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := connector.Connect(ctx)
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// the slot is released again after the handshake
+	connector.releaseConnectSlot()
+	if err := connector.acquireConnectSlot(context.Background()); err != nil {
+		t.Fatalf("expected a free slot, got %v", err)
+	}
+	if n := connector.ConnectStats().InProgress; n != 1 {
+		t.Errorf("expected 1 handshake in progress, got %d", n)
+	}
+}
+
+func TestConnectorConnectQueueContextDeadline(t *testing.T) {
+	cfg := NewConfig()
+	if err := cfg.Apply(MaxConcurrentConnects(1, time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	connector := newConnector(cfg)
+	connector.connectSlots <- struct{}{}
+
+	// the deadline of the context expires before the queue timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := connector.Connect(ctx)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("expected the queued connect to give up at the deadline, waited %v", d)
+	}
+	if stats := connector.ConnectStats(); stats.WaitCount != 1 || stats.WaitTimeouts != 1 {
+		t.Errorf("expected one timed out wait, got %+v", stats)
+	}
+}
+
+func TestConnectorDialRetries(t *testing.T) {
+	cfg := NewConfig()
+	if err := cfg.Apply(DialRetries(2, time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	dials := 0
+	cfg.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials++
+		return nil, &net.OpError{Op: "dial", Net: network, Err: errors.New("connection refused")}
+	}
+	connector := newConnector(cfg)
+
+	_, err := connector.Connect(context.Background())
+	if _, ok := err.(*net.OpError); !ok {
+		t.Fatalf("expected *net.OpError, got %v", err)
+	}
+	if dials != 3 {
+		t.Errorf("expected 3 dials, got %d", dials)
+	}
+
+	// errors other than network failures are not retried
+	dials = 0
+	cfg.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials++
+		return nil, errors.New("no route configured")
+	}
+	if _, err := connector.Connect(context.Background()); err == nil {
+		t.Fatal("error expected")
+	}
+	if dials != 1 {
+		t.Errorf("expected 1 dial, got %d", dials)
+	}
+}
+
+func TestDialBackoffDelay(t *testing.T) {
+	for attempt, max := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond} {
+		d := dialBackoffDelay(0, attempt)
+		if d < max/2 || d > max {
+			t.Errorf("attempt %d: expected delay in [%v, %v], got %v", attempt, max/2, max, d)
+		}
+	}
+	if d := dialBackoffDelay(time.Second, 100); d < maxDialBackoff/2 || d > maxDialBackoff {
+		t.Errorf("expected delay capped at %v, got %v", maxDialBackoff, d)
+	}
+}
+
+func TestConnectorHandshakeTimeout(t *testing.T) {
+	// a server which accepts connections but never sends the greeting
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	cfg := NewConfig()
+	cfg.Addr = ln.Addr().String()
+	if err := cfg.Apply(HandshakeTimeout(50 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.normalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, err = newConnector(cfg).Connect(context.Background())
+	if err != ErrHandshakeTimeout {
+		t.Fatalf("expected ErrHandshakeTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected Connect to give up after 50ms, took %v", elapsed)
+	}
+}
+
+func TestConnectorTLSSessionCache(t *testing.T) {
+	cfg, err := ParseDSN("tcp(example.com:3306)/?tls=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newConnector(cfg)
+	cache := c.cfg.TLS.ClientSessionCache
+	if cache == nil {
+		t.Fatal("expected the connector to cache TLS sessions")
+	}
+	if c.cfg.Clone().TLS.ClientSessionCache != cache {
+		t.Error("expected the connections to share the TLS session cache")
+	}
+
+	own := tls.NewLRUClientSessionCache(1)
+	cfg = NewConfig()
+	cfg.TLS = &tls.Config{ClientSessionCache: own}
+	if newConnector(cfg).cfg.TLS.ClientSessionCache != own {
+		t.Error("expected the TLS session cache of the config to be kept")
+	}
+}
+
+func TestConnectorMaxAllowedPacketCache(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Net, cfg.Addr = "tcp", "a:3306"
+	c := newConnector(cfg)
+
+	c.cacheMaxAllowedPacket(cfg, 1024)
+	if _, ok := c.cachedMaxAllowedPacket(cfg); ok {
+		t.Fatal("expected no caching without maxAllowedPacketTTL")
+	}
+
+	cfg.maxAllowedPacketTTL = time.Minute
+	c.cacheMaxAllowedPacket(cfg, 1024)
+	if n, ok := c.cachedMaxAllowedPacket(cfg); !ok || n != 1024 {
+		t.Fatalf("expected cached value 1024, got %d, %v", n, ok)
+	}
+
+	other := cfg.Clone()
+	other.Addr = "b:3306"
+	if _, ok := c.cachedMaxAllowedPacket(other); ok {
+		t.Error("expected no cached value for another address")
+	}
+
+	c.maxAllowedPackets["tcp/a:3306"] = cachedMaxAllowedPacket{n: 1024, expires: time.Now().Add(-time.Second)}
+	if _, ok := c.cachedMaxAllowedPacket(cfg); ok {
+		t.Error("expected the expired value to be ignored")
+	}
+	c.cacheMaxAllowedPacket(other, 2048)
+	if _, ok := c.maxAllowedPackets["tcp/a:3306"]; ok {
+		t.Error("expected the expired value to be dropped")
+	}
+}
@@ -8,16 +8,23 @@
 
 package mysql
 
-import "runtime"
+import (
+	"runtime"
+	"time"
+)
 
 const (
-	debug = false // for debugging. Set true only in development.
-
 	defaultAuthPlugin       = "mysql_native_password"
+	defaultDialBackoff      = 100 * time.Millisecond
+	defaultFetchSize        = 1000
+	defaultKillTimeout      = 5 * time.Second
 	defaultMaxAllowedPacket = 64 << 20 // 64 MiB. See https://github.com/go-sql-driver/mysql/issues/1355
+	maxDialBackoff          = 10 * time.Second
 	minProtocolVersion      = 10
 	maxPacketSize           = 1<<24 - 1
 	timeFormat              = "2006-01-02 15:04:05.999999"
+	tlsSessionCacheSize     = 64        // TLS sessions cached per connector
+	writevThreshold         = 64 * 1024 // Min payload size sent with writev instead of copying it
 
 	// Connection attributes
 	// See https://dev.mysql.com/doc/refman/8.0/en/performance-schema-connection-attribute-tables.html#performance-schema-connection-attributes-available
@@ -72,6 +79,9 @@ const (
 	clientCanHandleExpiredPasswords
 	clientSessionTrack
 	clientDeprecateEOF
+	clientOptionalResultsetMetadata
+	clientZstdCompressionAlgorithm
+	clientQueryAttributes
 )
 
 // https://mariadb.com/kb/en/connection/#capabilities
@@ -115,6 +125,28 @@ const (
 	comStmtReset
 	comSetOption
 	comStmtFetch
+	comDaemon
+	comBinlogDumpGTID
+	comResetConnection
+)
+
+// MariaDB COM_STMT_BULK_EXECUTE
+// https://mariadb.com/kb/en/com_stmt_bulk_execute/
+const (
+	comStmtBulkExecute byte = 0xfa
+
+	// flags
+	bulkSendTypesToServer uint16 = 128
+
+	// parameter indicators
+	bulkIndicatorNone byte = 0
+	bulkIndicatorNull byte = 1
+)
+
+// Cursor types of COM_STMT_EXECUTE
+const (
+	cursorTypeNoCursor byte = 0x00
+	cursorTypeReadOnly byte = 0x01
 )
 
 // https://dev.mysql.com/doc/internals/en/com-query-response.html#packet-Protocol::ColumnType
@@ -198,6 +230,17 @@ const (
 	statusSessionStateChanged
 )
 
+// Session state change types
+// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_basic_ok_packet.html
+const (
+	sessionTrackSystemVariables byte = iota
+	sessionTrackSchema
+	sessionTrackStateChange
+	sessionTrackGTIDs
+	sessionTrackTransactionCharacteristics
+	sessionTrackTransactionState
+)
+
 const (
 	cachingSha2PasswordRequestPublicKey          = 2
 	cachingSha2PasswordFastAuthSuccess           = 3
@@ -0,0 +1,101 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PropagateDeadline makes SELECT queries with a context deadline carry the
+// time left as a limit of their execution time, so the server aborts them
+// too instead of only the client giving up on the connection. MySQL gets a
+// /*+ MAX_EXECUTION_TIME(ms) */ hint, MariaDB a SET STATEMENT
+// max_statement_time=s FOR prefix.
+//
+// Only queries sent as text are rewritten, i.e. queries without arguments
+// and queries with arguments when InterpolateParams is enabled. Queries with
+// a MAX_EXECUTION_TIME hint are left as is.
+func PropagateDeadline(yes bool) Option {
+	return func(cfg *Config) error {
+		cfg.propagateDeadline = yes
+		return nil
+	}
+}
+
+// deadlineQuery returns query limited to the time left until the deadline
+// of ctx if propagateDeadline is enabled.
+func (mc *mysqlConn) deadlineQuery(ctx context.Context, query string) string {
+	if !mc.cfg.propagateDeadline {
+		return query
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return query
+	}
+	left := time.Until(deadline)
+	if left <= 0 {
+		return query
+	}
+
+	start := selectKeyword(query)
+	if start < 0 {
+		return query
+	}
+	msecs := int64((left + time.Millisecond - 1) / time.Millisecond)
+	if mc.capabilities&clientMySQL == 0 {
+		secs := strconv.FormatFloat(float64(msecs)/1000, 'f', 3, 64)
+		return "SET STATEMENT max_statement_time=" + secs + " FOR " + query
+	}
+	if strings.Contains(strings.ToUpper(query), "MAX_EXECUTION_TIME") {
+		return query
+	}
+
+	ms := strconv.FormatInt(msecs, 10)
+	end := start + len("SELECT")
+	if rest := strings.TrimLeft(query[end:], " \t\r\n"); strings.HasPrefix(rest, "/*+") {
+		// Only the first hint comment of a query block is used
+		hint := len(query) - len(rest) + len("/*+")
+		return query[:hint] + " MAX_EXECUTION_TIME(" + ms + ")" + query[hint:]
+	}
+	return query[:end] + " /*+ MAX_EXECUTION_TIME(" + ms + ") */" + query[end:]
+}
+
+// selectKeyword returns the position of the SELECT keyword starting query
+// after whitespace and comments, or -1 if it isn't a SELECT.
+func selectKeyword(query string) int {
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '#' || c == '-' && strings.HasPrefix(query[i:], "-- "):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return -1
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return -1
+			}
+			i += 2 + end + 2
+		default:
+			end := i + len("SELECT")
+			if end <= len(query) && strings.EqualFold(query[i:end], "SELECT") &&
+				(end == len(query) || !isIdentChar(query[end])) {
+				return i
+			}
+			return -1
+		}
+	}
+	return -1
+}
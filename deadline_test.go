@@ -0,0 +1,73 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDeadlineQuery(t *testing.T) {
+	mc := &mysqlConn{cfg: NewConfig(), capabilities: clientMySQL}
+	mc.cfg.propagateDeadline = true
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	tests := []struct {
+		in, out string
+	}{
+		{"SELECT 1", "SELECT /*+ MAX_EXECUTION_TIME(N) */ 1"},
+		{"  /* c */ select * from t", "  /* c */ select /*+ MAX_EXECUTION_TIME(N) */ * from t"},
+		{"-- c\nSELECT 1", "-- c\nSELECT /*+ MAX_EXECUTION_TIME(N) */ 1"},
+		{"SELECT /*+ BKA(t) */ * FROM t", "SELECT /*+ MAX_EXECUTION_TIME(N) BKA(t) */ * FROM t"},
+		{"SELECT /*+ MAX_EXECUTION_TIME(5) */ 1", "SELECT /*+ MAX_EXECUTION_TIME(5) */ 1"},
+		{"SELECTED", "SELECTED"},
+		{"UPDATE t SET a = 1", "UPDATE t SET a = 1"},
+		{"(SELECT 1) UNION (SELECT 2)", "(SELECT 1) UNION (SELECT 2)"},
+		{"/* unterminated SELECT 1", "/* unterminated SELECT 1"},
+	}
+	ms := regexp.MustCompile(`MAX_EXECUTION_TIME\((\d+)\)`)
+	for _, tt := range tests {
+		got := mc.deadlineQuery(ctx, tt.in)
+		n := -1
+		if m := ms.FindStringSubmatch(got); m != nil && m[1] != "5" {
+			n, _ = strconv.Atoi(m[1])
+			got = ms.ReplaceAllString(got, "MAX_EXECUTION_TIME(N)")
+		}
+		if got != tt.out {
+			t.Errorf("%q: expected %q, got %q", tt.in, tt.out, got)
+		}
+		if n >= 0 && (n < 59000 || n > 60000) {
+			t.Errorf("%q: expected about 60000ms, got %d", tt.in, n)
+		}
+	}
+
+	if got := mc.deadlineQuery(context.Background(), "SELECT 1"); got != "SELECT 1" {
+		t.Errorf("expected no limit without a deadline, got %q", got)
+	}
+	mc.cfg.propagateDeadline = false
+	if got := mc.deadlineQuery(ctx, "SELECT 1"); got != "SELECT 1" {
+		t.Errorf("expected no limit when disabled, got %q", got)
+	}
+}
+
+func TestDeadlineQueryMariaDB(t *testing.T) {
+	mc := &mysqlConn{cfg: NewConfig()}
+	mc.cfg.propagateDeadline = true
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+
+	got := mc.deadlineQuery(ctx, "SELECT 1")
+	if !regexp.MustCompile(`^SET STATEMENT max_statement_time=1\.\d{3} FOR SELECT 1$`).MatchString(got) {
+		t.Errorf("unexpected query %q", got)
+	}
+}
@@ -0,0 +1,107 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// ParseDecimal makes DECIMAL columns return Decimal values instead of []byte.
+// They can be scanned into *Decimal, *string, *float64 (rounded), *any or a
+// sql.Scanner, which receives the Decimal and can convert it exactly with
+// its String method. ColumnTypeScanType reports Decimal, or sql.Null[Decimal]
+// for nullable columns. ParseDecimal has no effect with RawBytes.
+func ParseDecimal(yes bool) Option {
+	return func(cfg *Config) error {
+		cfg.parseDecimal = yes
+		return nil
+	}
+}
+
+// Decimal is an exact decimal number in the text form of MySQL, e.g.
+// "-12.3400" for a DECIMAL(10,4). It can also be passed as an argument,
+// sending the number as text like a string.
+type Decimal string
+
+// String returns the number as text.
+func (d Decimal) String() string {
+	return string(d)
+}
+
+// Float64 returns the nearest float64 of the number.
+func (d Decimal) Float64() (float64, error) {
+	return strconv.ParseFloat(string(d), 64)
+}
+
+// Rat returns the exact value of the number.
+func (d Decimal) Rat() (*big.Rat, error) {
+	if !isDecimal(string(d)) {
+		return nil, fmt.Errorf("mysql: invalid decimal %q", string(d))
+	}
+	r, _ := new(big.Rat).SetString(string(d))
+	return r, nil
+}
+
+// Value implements driver.Valuer.
+func (d Decimal) Value() (driver.Value, error) {
+	if !isDecimal(string(d)) {
+		return nil, fmt.Errorf("mysql: invalid decimal %q", string(d))
+	}
+	return string(d), nil
+}
+
+// Scan implements sql.Scanner. NULL is not supported, use sql.Null[Decimal]
+// for nullable columns.
+func (d *Decimal) Scan(src any) error {
+	var s string
+	switch src := src.(type) {
+	case Decimal:
+		s = string(src)
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	case int64:
+		s = strconv.FormatInt(src, 10)
+	case uint64:
+		s = strconv.FormatUint(src, 10)
+	case float64:
+		s = strconv.FormatFloat(src, 'f', -1, 64)
+	default:
+		return fmt.Errorf("mysql: cannot scan %T into Decimal", src)
+	}
+	if !isDecimal(s) {
+		return fmt.Errorf("mysql: invalid decimal %q", s)
+	}
+	*d = Decimal(s)
+	return nil
+}
+
+// isDecimal reports whether s is a decimal number like MySQL formats them: an
+// optional sign, digits and optional fractional digits.
+func isDecimal(s string) bool {
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	digits, dot := 0, false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case isDigit(c):
+			digits++
+		case c == '.' && !dot:
+			dot = true
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
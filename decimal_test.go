@@ -0,0 +1,136 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"math/big"
+	"testing"
+)
+
+// mockDecimalColumn returns the definition of a NOT NULL DECIMAL(10,4) column.
+func mockDecimalColumn(seq byte) []byte {
+	return mockPacket(seq,
+		3, 'd', 'e', 'f', 0, 0, 0, 1, 'd', 0, // catalog, schema, tables, names
+		0x0c, 0x3f, 0, 12, 0, 0, 0, byte(fieldTypeNewDecimal), byte(flagNotNULL), 0, 4, 0, 0)
+}
+
+func TestParseDecimalColumns(t *testing.T) {
+	for _, parseDecimal := range []bool{false, true} {
+		conn, mc := newRWMockConn(1)
+		mc.cfg.parseDecimal = parseDecimal
+		conn.data = append(mockDecimalColumn(1), mockEOF(2, 0)...)
+
+		columns, err := mc.readColumns(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := scanTypeString
+		if parseDecimal {
+			want = scanTypeDecimal
+		}
+		if got := columns[0].scanType(); got != want {
+			t.Errorf("parseDecimal=%v: expected scan type %v, got %v", parseDecimal, want, got)
+		}
+
+		num := []byte("-12.3400")
+		conn.data = mockPacket(3, append([]byte{byte(len(num))}, num...)...)
+		rows := &textRows{mysqlRows{mc: mc, rs: resultSet{columns: columns}}}
+		dest := make([]driver.Value, 1)
+		if err := rows.readRow(dest); err != nil {
+			t.Fatal(err)
+		}
+		if parseDecimal {
+			if dest[0] != Decimal("-12.3400") {
+				t.Errorf("expected Decimal -12.3400, got %#v", dest[0])
+			}
+		} else if v, ok := dest[0].([]byte); !ok || string(v) != string(num) {
+			t.Errorf("expected []byte %s, got %#v", num, dest[0])
+		}
+	}
+}
+
+func TestParseDecimalBinaryRow(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	columns := []mysqlField{{fieldType: fieldTypeNewDecimal, asDecimal: true}}
+	conn.data = mockPacket(0, iOK, 0, 4, '0', '.', '1', '0')
+	rows := &binaryRows{mysqlRows: mysqlRows{mc: mc, rs: resultSet{columns: columns}}}
+
+	dest := make([]driver.Value, 1)
+	if err := rows.readRow(dest); err != nil {
+		t.Fatal(err)
+	}
+	if dest[0] != Decimal("0.10") {
+		t.Errorf("expected Decimal 0.10, got %#v", dest[0])
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	d := Decimal("12345678901234567890.0000000001")
+	r, err := d.Rat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := new(big.Rat).SetString("123456789012345678900000000001/10000000000")
+	if r.Cmp(want) != 0 {
+		t.Errorf("expected %v, got %v", want, r)
+	}
+	if f, err := Decimal("1.5").Float64(); err != nil || f != 1.5 {
+		t.Errorf("expected 1.5, got %v, %v", f, err)
+	}
+
+	v, err := converter{}.ConvertValue(d)
+	if err != nil || v != string(d) {
+		t.Errorf("expected %q, got %#v, %v", d, v, err)
+	}
+	for _, s := range []string{"", "-", "1e5", "1.2.3", "0x10", "1/2"} {
+		if _, err := Decimal(s).Value(); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
+
+func TestDecimalScan(t *testing.T) {
+	tests := []struct {
+		src  any
+		want Decimal
+	}{
+		{Decimal("-1.20"), "-1.20"},
+		{"3", "3"},
+		{[]byte(".5"), ".5"},
+		{int64(-7), "-7"},
+		{uint64(18446744073709551615), "18446744073709551615"},
+		{0.25, "0.25"},
+	}
+	for _, tt := range tests {
+		var d Decimal
+		if err := d.Scan(tt.src); err != nil {
+			t.Errorf("Scan(%#v): %v", tt.src, err)
+		} else if d != tt.want {
+			t.Errorf("Scan(%#v): expected %q, got %q", tt.src, tt.want, d)
+		}
+	}
+
+	var d Decimal
+	if err := d.Scan(nil); err == nil {
+		t.Error("expected an error for NULL")
+	}
+	if err := d.Scan("abc"); err == nil {
+		t.Error("expected an error for an invalid number")
+	}
+
+	var null sql.Null[Decimal]
+	if err := null.Scan(nil); err != nil || null.Valid {
+		t.Errorf("expected NULL, got %+v, %v", null, err)
+	}
+	if err := null.Scan(Decimal("1.0")); err != nil || !null.Valid || null.V != "1.0" {
+		t.Errorf("expected 1.0, got %+v, %v", null, err)
+	}
+}
@@ -99,7 +99,10 @@ func init() {
 	}
 }
 
-// NewConnector returns new driver.Connector.
+// NewConnector returns new driver.Connector. Invalid configurations, and
+// missing or malformed OpenID Connect ID tokens of the token file or
+// environment variable params, are reported here rather than at the first
+// connect.
 func NewConnector(cfg *Config) (driver.Connector, error) {
 	cfg = cfg.Clone()
 	// normalize the contents of cfg so calls to NewConnector have the same
@@ -107,6 +110,9 @@ func NewConnector(cfg *Config) (driver.Connector, error) {
 	if err := cfg.normalize(); err != nil {
 		return nil, err
 	}
+	if err := cfg.checkOIDCToken(); err != nil {
+		return nil, err
+	}
 	return newConnector(cfg), nil
 }
 
@@ -116,5 +122,8 @@ func (d MySQLDriver) OpenConnector(dsn string) (driver.Connector, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := cfg.checkOIDCToken(); err != nil {
+		return nil, err
+	}
 	return newConnector(cfg), nil
 }
@@ -15,9 +15,12 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"math/big"
 	"net"
 	"net/url"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -48,10 +51,10 @@ type Config struct {
 	Loc                  *time.Location    // Location for time.Time values
 	MaxAllowedPacket     int               // Max packet size allowed
 	ServerPubKey         string            // Server public key name
-	TLSConfig            string            // TLS configuration name
-	TLS                  *tls.Config       // TLS configuration, its priority is higher than TLSConfig
+	TLSConfig            string            // TLS mode or TLS configuration name
+	TLS                  *tls.Config       // TLS configuration, its priority is higher than a TLSConfig name
 	Timeout              time.Duration     // Dial timeout
-	ReadTimeout          time.Duration     // I/O read timeout
+	ReadTimeout          time.Duration     // I/O read timeout, applied to every read from the network
 	WriteTimeout         time.Duration     // I/O write timeout
 	Logger               Logger            // Logger
 
@@ -76,13 +79,87 @@ type Config struct {
 	// unexported fields. new options should be come here.
 	// boolean first. alphabetical order.
 
-	compress bool // Enable zlib compression
-
-	beforeConnect         func(context.Context, *Config) error // Invoked before a connection is established
-	pubKey                *rsa.PublicKey                       // Server public key
-	timeTruncate          time.Duration                        // Truncate time.Time values to the specified duration
-	charsets              []string                             // Connection charset. When set, this will be set in SET NAMES <charset> query
-	AuthOIDCClientIDToken string                               // Add OIDC Client
+	compress                   bool // Enable zlib compression
+	errorStatement             bool // Record the fingerprint of the failing statement in MySQLError
+	fips                       bool // Restrict authentication to FIPS-approved primitives
+	oidcCertificateBound       bool // Verify that OIDC ID tokens are bound to the client certificate
+	oidcTokenExchange          bool // Exchange the OIDC token for an ID token at oidcTokenEndpoint
+	parseDecimal               bool // Return the values of DECIMAL columns as Decimal
+	parseJSON                  bool // Return the values of JSON columns as json.RawMessage
+	pipelinePrepare            bool // Send COM_STMT_PREPARE and COM_STMT_EXECUTE back-to-back
+	pipelineSessionInit        bool // Send the queries setting up the session back-to-back
+	propagateDeadline          bool // Limit the execution time of SELECT queries to the context deadline
+	rawBytes                   bool // Return the values of text protocol rows as []byte without conversion
+	requireSecureAuthTransport bool // Send passwords and tokens only over TLS or unix sockets
+	resetConnection            bool // Reset the session with COM_RESET_CONNECTION when the connection is reused
+	retryReadOnly              bool // Retry read-only queries failing with retryable errors on another connection
+	slowQueryRedact            bool // Replace literals in queries passed to the slow query function
+	useCursorFetch             bool // Read results of prepared statements through server-side cursors
+	verifyCollation            bool // Check the collation of the connection after setting up the session
+
+	autoReconnect         string                                     // Which commands are retried after the server closed the connection ("": none)
+	beforeConnect         func(context.Context, *Config) error       // Invoked before a connection is established
+	cancelMode            string                                     // How queries are canceled when their context is done ("": close the connection)
+	compressCodec         string                                     // Name of the registered CompressionCodec (default: zlib)
+	connectQueueTimeout   time.Duration                              // Max time to wait for a free handshake slot
+	dialBackoff           time.Duration                              // Initial delay between connect attempts (0: defaultDialBackoff)
+	dialRetries           int                                        // Number of times a failed connect is retried
+	errorInterceptors     []ErrorInterceptorFunc                     // Translate errors returned by the server
+	fetchSize             int                                        // Number of rows fetched per COM_STMT_FETCH (0: defaultFetchSize)
+	handshakeTimeout      time.Duration                              // Max duration of the handshake after dialing (0: unlimited)
+	hooks                 []Hook                                     // Receive the operations of the connections
+	localInfileMaxBytes   int64                                      // Max bytes sent per LOAD DATA LOCAL INFILE request (0: unlimited)
+	localInfileTimeout    time.Duration                              // Max duration of a LOAD DATA LOCAL INFILE request (0: unlimited)
+	maxAllowedPacketTTL   time.Duration                              // Time the max_allowed_packet value of the server is cached (0: not cached)
+	maxConcurrentConnects int                                        // Max number of simultaneous handshakes (0: unlimited)
+	maxResultSetBytes     int64                                      // Max bytes of the rows of a result set (0: unlimited)
+	maxResultSetRows      int64                                      // Max number of rows of a result set (0: unlimited)
+	maxRetainedBuffer     int                                        // Max size of the buffer a connection keeps (0: maxCachedBufSize)
+	oidcAudience          string                                     // Audience the OIDC ID token must be issued for ("": not checked)
+	oidcBindingFunc       OIDCBindingFunc                            // Verifies the binding of OIDC ID tokens to the client certificate
+	oidcClientID          string                                     // Client id authenticating the token exchange
+	oidcClientSecret      string                                     // Client secret authenticating the token exchange
+	oidcClockSkew         time.Duration                              // Max clock difference to the server and the identity provider
+	oidcFraming           string                                     // How the OIDC ID token is framed in auth responses ("": auto)
+	oidcIssuer            string                                     // Issuer URL of oidcProvider
+	oidcMinTTL            time.Duration                              // Min remaining lifetime of the OIDC ID tokens sent (0: unchecked)
+	oidcProvider          string                                     // Preset of the OIDC identity provider ("": none)
+	oidcRefreshToken      string                                     // Refresh token renewing the OIDC ID token
+	oidcRefreshTokenFile  string                                     // File of the refresh token renewing the OIDC ID token
+	oidcSource            string                                     // Where the OIDC ID token is read from ("": the token file or env params)
+	oidcTokenFunc         OIDCTokenFunc                              // Fetches the OIDC ID tokens (nil: read from oidcSource)
+	oidcTokenEndpoint     string                                     // Token endpoint of the OIDC token exchange
+	oidcTokenKey          OIDCTokenKey                               // Cache key of the tokens of oidcTokenFunc
+	oidcTokenStore        TokenStore                                 // Stores the refresh token renewing the OIDC ID token
+	oidcUserTokenFunc     OIDCUserTokenFunc                          // Selects the OIDC ID tokens by user (nil: oidcTokenFunc)
+	onAuthFailure         AuthFailureFunc                            // Called when the server rejects the authentication
+	onClose               CloseFunc                                  // Called when an established connection is closed
+	onConnect             ConnectFunc                                // Called with every new connection
+	onOIDCTokenEvent      OIDCTokenEventFunc                         // Called with the lifecycle events of the OIDC ID tokens
+	packetTraceMaxPayload int                                        // Max bytes of the payloads passed to packetTracer (0: unlimited)
+	packetTracer          PacketTracer                               // Called with every packet sent or received
+	pubKey                *rsa.PublicKey                             // Server public key
+	queryInterceptors     []QueryInterceptorFunc                     // Rewrite queries before they are sent
+	quitTimeout           time.Duration                              // Max time Close waits for the server to close the connection (0: not waited for)
+	readBufferSize        int                                        // Max bytes read from the network at once (0: defaultBufSize)
+	readerHandlers        map[string]func(context.Context) io.Reader // LOAD DATA LOCAL INFILE readers of this connector
+	recordTraffic         string                                     // Directory the packets of the connections are recorded in (""): not recorded)
+	serverFlavor          ServerFlavor                               // Flavor of the server ("": detected from the handshake)
+	sessionStateCallback  SessionStateFunc                           // Called with session state changes reported by the server
+	sessionVars           []sessionVar                               // System variables set at connect, in order
+	slowQueryFunc         SlowQueryFunc                              // Called with slow queries (nil: log them)
+	slowQueryThreshold    time.Duration                              // Min duration of queries reported as slow (0: disabled)
+	sslCert               string                                     // Client certificate file for mutual TLS, reloaded when it changes
+	sslKey                string                                     // Key file of sslCert
+	statementTimeout      time.Duration                              // Max execution time of statements (0: unlimited)
+	statsCollector        StatsCollector                             // Receives statistics of the connections
+	stmtCacheSize         int                                        // Number of prepared statements cached per connection (0: disabled)
+	tenantResolver        TenantResolverFunc                         // Maps tenant ids in query contexts to session state
+	timeTruncate          time.Duration                              // Truncate time.Time values to the specified duration
+	warningsFunc          WarningsFunc                               // Called with the warnings of statements (nil: not fetched)
+	zeroDateTime          string                                     // How zero dates are returned with ParseTime ("": zero time.Time)
+	charsets              []string                                   // Connection charset. When set, this will be set in SET NAMES <charset> query
+	AuthOIDCClientIDToken string                                     // Add OIDC Client
 }
 
 // Functional Options Pattern
@@ -112,6 +189,48 @@ func (c *Config) Apply(opts ...Option) error {
 	return nil
 }
 
+// UseTLS sets the TLS configuration of the connections without registering
+// it with RegisterTLSConfig, and the TLS mode applied to it:
+//
+//   - "true" or "verify-identity": verify the certificate and the host name of
+//     the server
+//   - "verify-ca": verify that the certificate of the server is issued by a
+//     CA of c.RootCAs, or of the system if it is nil, but not its host name
+//   - "required": encrypt the connection without verifying the server
+//   - "preferred": like "required", but fall back to an unencrypted connection
+//     if the server doesn't support TLS
+//   - "skip-verify": like "required"
+//
+// Unlike a registered configuration, c is not part of the DSN returned by
+// FormatDSN; only the mode is. Unless c has a ClientSessionCache, the
+// connections of a Connector share one to resume TLS sessions.
+func UseTLS(mode string, c *tls.Config) Option {
+	return func(cfg *Config) error {
+		if !isTLSMode(mode) {
+			return errors.New("invalid TLS mode: " + mode)
+		}
+		cfg.TLSConfig = mode
+		cfg.TLS = c.Clone()
+		return nil
+	}
+}
+
+// ClientCertificate sets the certificate and key files presented to the
+// server for mutual TLS. They are read on each TLS handshake in which the
+// server requests a client certificate and reloaded when they change, so
+// that rotated short-lived certificates, e.g. of SPIFFE or cert-manager, are
+// used by new connections without a restart. TLS has to be enabled.
+//
+// If the tls.Config already has a GetClientCertificate function, the files
+// are ignored.
+func ClientCertificate(certFile, keyFile string) Option {
+	return func(cfg *Config) error {
+		cfg.sslCert = certFile
+		cfg.sslKey = keyFile
+		return nil
+	}
+}
+
 // TimeTruncate sets the time duration to truncate time.Time values in
 // query parameters.
 func TimeTruncate(d time.Duration) Option {
@@ -121,6 +240,30 @@ func TimeTruncate(d time.Duration) Option {
 	}
 }
 
+// Modes of ZeroDateTime
+const (
+	ZeroDateTimeNearest = "nearest"
+	ZeroDateTimeNil     = "nil"
+	ZeroDateTimeError   = "error"
+)
+
+// ZeroDateTime sets how zero DATE, DATETIME and TIMESTAMP values like
+// "0000-00-00 00:00:00" are returned with ParseTime:
+//
+//   - ZeroDateTimeNearest: as the zero time.Time, the default
+//   - ZeroDateTimeNil: as NULL
+//   - ZeroDateTimeError: as the error ErrZeroDateTime
+func ZeroDateTime(mode string) Option {
+	return func(cfg *Config) error {
+		switch mode {
+		case ZeroDateTimeNearest, ZeroDateTimeNil, ZeroDateTimeError:
+			cfg.zeroDateTime = mode
+			return nil
+		}
+		return fmt.Errorf("invalid zeroDateTime value: %s", mode)
+	}
+}
+
 // BeforeConnect sets the function to be invoked before a connection is established.
 func BeforeConnect(fn func(context.Context, *Config) error) Option {
 	return func(cfg *Config) error {
@@ -129,6 +272,208 @@ func BeforeConnect(fn func(context.Context, *Config) error) Option {
 	}
 }
 
+// MaxAllowedPacketTTL makes a Connector cache the max_allowed_packet value of
+// the server per address for ttl, when MaxAllowedPacket is 0. Connections
+// established within ttl use the cached value instead of querying the
+// system variable, saving a round trip per connect in high-churn pools.
+// Changes of the global value of the server are picked up after ttl.
+func MaxAllowedPacketTTL(ttl time.Duration) Option {
+	return func(cfg *Config) error {
+		cfg.maxAllowedPacketTTL = ttl
+		return nil
+	}
+}
+
+// MaxConcurrentConnects limits the number of connections a Connector
+// establishes simultaneously. When all slots are in use, Connect waits up to
+// queueTimeout for a free slot before failing with ErrConnectQueueTimeout.
+// A zero queueTimeout waits until the context passed to Connect is done; in
+// any case, queued connects give up when its deadline expires.
+//
+// This protects the server (and the identity provider, when token based
+// authentication is used) from connect storms, e.g. when a large pool
+// refills after a failover.
+func MaxConcurrentConnects(n int, queueTimeout time.Duration) Option {
+	return func(cfg *Config) error {
+		cfg.maxConcurrentConnects = n
+		cfg.connectQueueTimeout = queueTimeout
+		return nil
+	}
+}
+
+// MaxRetainedBuffer limits the size of the buffer a connection keeps for
+// reading and writing packets to size bytes (default: 256 KiB). Larger
+// buffers, needed for large rows or statements, are used for a single
+// packet only. Connections return buffers larger than the default size of
+// 4 KiB to a pool shared by all connections when they are reused, so that
+// idle connections don't hold on to the memory of a huge result set.
+func MaxRetainedBuffer(size int) Option {
+	return func(cfg *Config) error {
+		cfg.maxRetainedBuffer = size
+		return nil
+	}
+}
+
+// ReadBufferSize sets the size of the buffer connections read from the
+// network into, rounded up to a power of two (default and minimum: 4 KiB).
+// Packets are read in as few system calls as fit into the buffer, so a larger
+// buffer saves system calls for result sets of many small rows, at the cost
+// of size bytes of memory per connection.
+func ReadBufferSize(size int) Option {
+	return func(cfg *Config) error {
+		cfg.readBufferSize = size
+		return nil
+	}
+}
+
+// DialRetries makes Connect retry up to n times when establishing a
+// connection fails with a transient network error, e.g. a refused dial, a
+// DNS failure or a connection reset during the TLS handshake. The delay
+// before each retry starts at backoff (defaultDialBackoff if 0), doubles
+// with each retry up to maxDialBackoff, and is jittered.
+//
+// Failures after the auth response was sent are not retried, so that a
+// rejected password or token doesn't count against the account repeatedly.
+// Each attempt is limited by Timeout separately.
+func DialRetries(n int, backoff time.Duration) Option {
+	return func(cfg *Config) error {
+		cfg.dialRetries = n
+		cfg.dialBackoff = backoff
+		return nil
+	}
+}
+
+// HandshakeTimeout limits the time from dialing the server to a ready
+// connection, including the TLS handshake and all rounds of authentication,
+// e.g. while the server validates an ID token with the identity provider.
+// If it expires, Connect fails with ErrHandshakeTimeout.
+//
+// Unlike Timeout it doesn't cover dialing, and unlike ReadTimeout it doesn't
+// reset with every packet.
+func HandshakeTimeout(d time.Duration) Option {
+	return func(cfg *Config) error {
+		cfg.handshakeTimeout = d
+		return nil
+	}
+}
+
+// QuitTimeout makes Close wait up to d after sending COM_QUIT for the server
+// to close the connection, discarding what the server still sends, e.g. the
+// rest of a result set. Closing the connection before the server has read
+// COM_QUIT counts it as an aborted client (see the Aborted_clients status
+// variable), which monitoring with strict thresholds reports.
+func QuitTimeout(d time.Duration) Option {
+	return func(cfg *Config) error {
+		cfg.quitTimeout = d
+		return nil
+	}
+}
+
+// StmtCacheSize enables a per-connection LRU cache of server-side prepared
+// statements holding up to n statements.
+//
+// When enabled and InterpolateParams is false, queries with arguments reuse
+// a cached prepared statement instead of preparing, executing and closing a
+// new statement on every call.
+func StmtCacheSize(n int) Option {
+	return func(cfg *Config) error {
+		cfg.stmtCacheSize = n
+		return nil
+	}
+}
+
+// PipelinePrepare enables pipelining of one-shot prepared statements.
+//
+// When enabled and InterpolateParams is false, queries with arguments send
+// COM_STMT_PREPARE and COM_STMT_EXECUTE without waiting for the prepare
+// response in between, saving one round trip per query. This relies on a
+// MariaDB extension; on other servers and on compressed connections the
+// statement is prepared and executed as usual.
+func PipelinePrepare(yes bool) Option {
+	return func(cfg *Config) error {
+		cfg.pipelinePrepare = yes
+		return nil
+	}
+}
+
+// PipelineSessionInit makes connections send the queries setting up the
+// session after authentication, i.e. the query for max_allowed_packet (see
+// MaxAllowedPacket), SET NAMES for the charset and the SET statement for
+// the system variables of the DSN params, without waiting for the response
+// of each query, saving up to two round trips per connect on high-latency
+// links. Connect still fails if any of the queries fails.
+//
+// The queries are sent one by one on compressed connections and when
+// several charsets are configured, since a charset is only tried when the
+// previous one is rejected.
+func PipelineSessionInit(yes bool) Option {
+	return func(cfg *Config) error {
+		cfg.pipelineSessionInit = yes
+		return nil
+	}
+}
+
+// RawBytes makes queries return all non-NULL values as []byte slices of
+// reused buffers, without allocating per value. Rows of the text protocol
+// (queries without arguments, and queries with arguments when
+// InterpolateParams is enabled) are returned as slices of the read buffer.
+// For rows of prepared statements, which use the binary protocol, numbers
+// and dates are formatted as text into one arena, a buffer reused for every
+// row, and strings are slices of the read buffer.
+// Scanning them into *sql.RawBytes, the intended use, doesn't allocate.
+// Scanning into other types works as usual, with database/sql converting
+// the bytes, but values scanned into *any are []byte instead of int64,
+// float64 etc.
+//
+// Like sql.RawBytes, the values are only valid until the next call to Next or
+// Close of the rows, which may overwrite the buffers. RawBytes has no effect
+// with ParseTime.
+func RawBytes(yes bool) Option {
+	return func(cfg *Config) error {
+		cfg.rawBytes = yes
+		return nil
+	}
+}
+
+// ResetConnection makes pooled connections reset their session with
+// COM_RESET_CONNECTION before they are reused, clearing user variables,
+// temporary tables and other session state, so that they behave like fresh
+// connections. Session variables set by DSN params are set again.
+//
+// Connections holding prepared statements other than those of the statement
+// cache are not reset, as the server would discard the statements.
+// COM_RESET_CONNECTION requires MySQL 5.7 or MariaDB 10.2 or newer.
+func ResetConnection(yes bool) Option {
+	return func(cfg *Config) error {
+		cfg.resetConnection = yes
+		return nil
+	}
+}
+
+// RequireSecureAuthTransport refuses to send cleartext passwords, OIDC ID
+// tokens and passwords encrypted with the RSA public key of the server
+// unless the connection uses TLS or a unix socket, so that credentials don't
+// leak over plaintext TCP when TLS is accidentally disabled. Authentication
+// fails with ErrInsecureAuthTransport then.
+func RequireSecureAuthTransport(yes bool) Option {
+	return func(cfg *Config) error {
+		cfg.requireSecureAuthTransport = yes
+		return nil
+	}
+}
+
+// CursorFetch makes prepared statement queries open a read-only server-side
+// cursor and fetch fetchSize rows at a time with COM_STMT_FETCH, instead of
+// streaming the whole result set at once. A fetchSize of 0 uses
+// defaultFetchSize.
+func CursorFetch(fetchSize int) Option {
+	return func(cfg *Config) error {
+		cfg.useCursorFetch = true
+		cfg.fetchSize = fetchSize
+		return nil
+	}
+}
+
 // EnableCompress sets the compression mode.
 func EnableCompression(yes bool) Option {
 	return func(cfg *Config) error {
@@ -137,6 +482,16 @@ func EnableCompression(yes bool) Option {
 	}
 }
 
+// CompressWith enables compression using the CompressionCodec registered with
+// the given name. See RegisterCompressionCodec.
+func CompressWith(codec string) Option {
+	return func(cfg *Config) error {
+		cfg.compress = true
+		cfg.compressCodec = codec
+		return nil
+	}
+}
+
 // Charset sets the connection charset and collation.
 //
 // charset is the connection charset.
@@ -152,6 +507,8 @@ func Charset(charset, collation string) Option {
 	}
 }
 
+// Clone returns a deep copy of cfg, which can be modified without affecting
+// cfg, e.g. by a BeforeConnect function or with Apply.
 func (cfg *Config) Clone() *Config {
 	cp := *cfg
 	if cp.TLS != nil {
@@ -163,6 +520,10 @@ func (cfg *Config) Clone() *Config {
 			cp.Params[k] = v
 		}
 	}
+	if cfg.readerHandlers != nil {
+		cp.readerHandlers = maps.Clone(cfg.readerHandlers)
+	}
+	cp.sessionVars = slices.Clone(cfg.sessionVars)
 	if cfg.pubKey != nil {
 		cp.pubKey = &rsa.PublicKey{
 			N: new(big.Int).Set(cfg.pubKey.N),
@@ -172,6 +533,66 @@ func (cfg *Config) Clone() *Config {
 	return &cp
 }
 
+// isTLSMode reports whether the tls param value mode is a TLS mode rather
+// than the name of a registered TLS configuration.
+func isTLSMode(mode string) bool {
+	switch mode {
+	case "true", "skip-verify", "preferred", "required", "verify-ca", "verify-identity":
+		return true
+	}
+	return false
+}
+
+// normalizeTLS sets cfg.TLS according to cfg.TLSConfig: a TLS mode, which is
+// applied to cfg.TLS if it is set, or the name of a registered configuration.
+func (cfg *Config) normalizeTLS() error {
+	mode := cfg.TLSConfig
+	if cfg.TLS != nil {
+		// the tls.Config set by the caller is modified below
+		cfg.TLS = cfg.TLS.Clone()
+	} else {
+		switch {
+		case mode == "false" || mode == "":
+			// don't set anything
+		case isTLSMode(mode):
+			cfg.TLS = &tls.Config{}
+		default:
+			cfg.TLS = getTLSConfigClone(mode)
+			if cfg.TLS == nil {
+				return errors.New("invalid value / unknown config name: " + mode)
+			}
+		}
+	}
+
+	if cfg.sslCert != "" || cfg.sslKey != "" {
+		if cfg.sslCert == "" || cfg.sslKey == "" {
+			return errors.New("sslCert and sslKey must be set together")
+		}
+		if cfg.TLS == nil {
+			return errors.New("sslCert and sslKey require TLS")
+		}
+		if cfg.TLS.GetClientCertificate == nil {
+			cfg.TLS.GetClientCertificate = newClientCertificate(cfg.sslCert, cfg.sslKey).get
+		}
+	}
+	if cfg.oidcBinding() != nil && cfg.TLS == nil {
+		return errors.New("the binding of OIDC ID tokens requires TLS")
+	}
+	if cfg.TLS == nil {
+		return nil
+	}
+
+	// verify-ca is applied by verifyCAOnly at each TLS handshake
+	switch mode {
+	case "skip-verify", "required", "verify-ca":
+		cfg.TLS.InsecureSkipVerify = true
+	case "preferred":
+		cfg.TLS.InsecureSkipVerify = true
+		cfg.AllowFallbackToPlaintext = true
+	}
+	return nil
+}
+
 func (cfg *Config) normalize() error {
 	if cfg.InterpolateParams && cfg.Collation != "" && unsafeCollations[cfg.Collation] {
 		return errInvalidDSNUnsafeCollation
@@ -196,23 +617,8 @@ func (cfg *Config) normalize() error {
 		cfg.Addr = ensureHavePort(cfg.Addr)
 	}
 
-	if cfg.TLS == nil {
-		switch cfg.TLSConfig {
-		case "false", "":
-			// don't set anything
-		case "true":
-			cfg.TLS = &tls.Config{}
-		case "skip-verify":
-			cfg.TLS = &tls.Config{InsecureSkipVerify: true}
-		case "preferred":
-			cfg.TLS = &tls.Config{InsecureSkipVerify: true}
-			cfg.AllowFallbackToPlaintext = true
-		default:
-			cfg.TLS = getTLSConfigClone(cfg.TLSConfig)
-			if cfg.TLS == nil {
-				return errors.New("invalid value / unknown config name: " + cfg.TLSConfig)
-			}
-		}
+	if err := cfg.normalizeTLS(); err != nil {
+		return err
 	}
 
 	if cfg.TLS != nil && cfg.TLS.ServerName == "" && !cfg.TLS.InsecureSkipVerify {
@@ -222,6 +628,45 @@ func (cfg *Config) normalize() error {
 		}
 	}
 
+	if cfg.compress && getCompressionCodec(cfg.compressCodec) == nil {
+		return errors.New("invalid value / unknown compression codec name: " + cfg.compressCodec)
+	}
+
+	if err := cfg.normalizeOIDCProvider(); err != nil {
+		return err
+	}
+	if cfg.oidcSource == OIDCSourceKubernetes && cfg.oidcAudience == "" {
+		return errors.New("oidcSource=kubernetes requires oidcAudience")
+	}
+	if cfg.oidcTokenExchange {
+		if !isHTTPSURL(cfg.oidcTokenEndpointURL()) {
+			return errors.New("oidcTokenExchange requires the https URL of the token endpoint in oidcTokenEndpoint or oidcProvider")
+		}
+		if cfg.oidcAudience == "" {
+			return errors.New("oidcTokenExchange requires oidcAudience")
+		}
+	}
+	if cfg.oidcTokenFunc != nil && (cfg.oidcTokenExchange || cfg.refreshTokenStore() != nil) {
+		return errors.New("OIDCTokenProvider is mutually exclusive with oidcTokenExchange and OIDC refresh tokens")
+	}
+	if cfg.refreshTokenStore() != nil {
+		if cfg.oidcRefreshToken != "" && (cfg.oidcRefreshTokenFile != "" || cfg.oidcTokenStore != nil) {
+			return errors.New("oidcRefreshToken is mutually exclusive with oidcRefreshTokenFile and OIDCRefreshToken")
+		}
+		if cfg.oidcRefreshTokenFile != "" && cfg.oidcTokenStore != nil {
+			return errors.New("oidcRefreshTokenFile and OIDCRefreshToken are mutually exclusive")
+		}
+		if cfg.oidcTokenExchange {
+			return errors.New("oidcTokenExchange and OIDC refresh tokens are mutually exclusive")
+		}
+		if !isHTTPSURL(cfg.oidcTokenEndpointURL()) {
+			return errors.New("OIDC refresh tokens require the https URL of the token endpoint in oidcTokenEndpoint or oidcProvider")
+		}
+		if cfg.oidcClientID == "" {
+			return errors.New("OIDC refresh tokens require oidcClientID")
+		}
+	}
+
 	if cfg.ServerPubKey != "" {
 		cfg.pubKey = getServerPubKey(cfg.ServerPubKey)
 		if cfg.pubKey == nil {
@@ -233,6 +678,70 @@ func (cfg *Config) normalize() error {
 		cfg.Logger = defaultLogger
 	}
 
+	if cfg.localInfileMaxBytes < 0 || cfg.localInfileTimeout < 0 {
+		return errors.New("invalid local infile limits: must not be negative")
+	}
+
+	if err := cfg.serverFlavor.validate(); err != nil {
+		return err
+	}
+
+	if cfg.packetTraceMaxPayload < 0 {
+		return errors.New("invalid packet trace payload limit: must not be negative")
+	}
+
+	if cfg.maxResultSetBytes < 0 || cfg.maxResultSetRows < 0 {
+		return errors.New("invalid result set limits: must not be negative")
+	}
+
+	if cfg.fetchSize < 0 {
+		return errors.New("invalid fetchSize: must not be negative")
+	}
+
+	if cfg.stmtCacheSize < 0 {
+		return errors.New("invalid stmtCacheSize: must not be negative")
+	}
+
+	if cfg.statementTimeout < 0 {
+		return errors.New("invalid statementTimeout: must not be negative")
+	}
+
+	if cfg.oidcClockSkew < 0 || cfg.oidcMinTTL < 0 {
+		return errors.New("invalid oidcClockSkew or oidcMinTTL: must not be negative")
+	}
+
+	if cfg.maxConcurrentConnects < 0 || cfg.connectQueueTimeout < 0 {
+		return errors.New("invalid maxConcurrentConnects / connectQueueTimeout: must not be negative")
+	}
+
+	if cfg.readBufferSize < 0 || cfg.readBufferSize > 1<<maxBufClassShift {
+		return errors.New("invalid readBufferSize: must be between 0 and 16 MiB")
+	}
+
+	if cfg.maxRetainedBuffer < 0 {
+		return errors.New("invalid maxRetainedBuffer: must not be negative")
+	}
+
+	if cfg.slowQueryThreshold < 0 {
+		return errors.New("invalid slowQueryThreshold: must not be negative")
+	}
+
+	if cfg.handshakeTimeout < 0 {
+		return errors.New("invalid handshakeTimeout: must not be negative")
+	}
+
+	if cfg.quitTimeout < 0 {
+		return errors.New("invalid quitTimeout: must not be negative")
+	}
+
+	if cfg.maxAllowedPacketTTL < 0 {
+		return errors.New("invalid maxAllowedPacketTTL: must not be negative")
+	}
+
+	if cfg.dialRetries < 0 || cfg.dialBackoff < 0 {
+		return errors.New("invalid dialRetries / dialBackoff: must not be negative")
+	}
+
 	return nil
 }
 
@@ -304,10 +813,18 @@ func (cfg *Config) FormatDSN() string {
 		writeDSNParam(&buf, &hasParam, "allowOldPasswords", "true")
 	}
 
+	if cfg.autoReconnect != "" {
+		writeDSNParam(&buf, &hasParam, "autoReconnect", cfg.autoReconnect)
+	}
+
 	if !cfg.CheckConnLiveness {
 		writeDSNParam(&buf, &hasParam, "checkConnLiveness", "false")
 	}
 
+	if cfg.cancelMode != "" {
+		writeDSNParam(&buf, &hasParam, "cancelMode", cfg.cancelMode)
+	}
+
 	if cfg.ClientFoundRows {
 		writeDSNParam(&buf, &hasParam, "clientFoundRows", "true")
 	}
@@ -329,7 +846,39 @@ func (cfg *Config) FormatDSN() string {
 	}
 
 	if cfg.compress {
-		writeDSNParam(&buf, &hasParam, "compress", "true")
+		if cfg.compressCodec != "" {
+			writeDSNParam(&buf, &hasParam, "compress", url.QueryEscape(cfg.compressCodec))
+		} else {
+			writeDSNParam(&buf, &hasParam, "compress", "true")
+		}
+	}
+
+	if cfg.connectQueueTimeout > 0 {
+		writeDSNParam(&buf, &hasParam, "connectQueueTimeout", cfg.connectQueueTimeout.String())
+	}
+
+	if cfg.dialBackoff > 0 {
+		writeDSNParam(&buf, &hasParam, "dialBackoff", cfg.dialBackoff.String())
+	}
+
+	if cfg.dialRetries > 0 {
+		writeDSNParam(&buf, &hasParam, "dialRetries", strconv.Itoa(cfg.dialRetries))
+	}
+
+	if cfg.errorStatement {
+		writeDSNParam(&buf, &hasParam, "errorStatement", "true")
+	}
+
+	if cfg.fips {
+		writeDSNParam(&buf, &hasParam, "fips", "true")
+	}
+
+	if cfg.fetchSize > 0 {
+		writeDSNParam(&buf, &hasParam, "fetchSize", strconv.Itoa(cfg.fetchSize))
+	}
+
+	if cfg.handshakeTimeout > 0 {
+		writeDSNParam(&buf, &hasParam, "handshakeTimeout", cfg.handshakeTimeout.String())
 	}
 
 	if cfg.InterpolateParams {
@@ -340,34 +889,212 @@ func (cfg *Config) FormatDSN() string {
 		writeDSNParam(&buf, &hasParam, "loc", url.QueryEscape(cfg.Loc.String()))
 	}
 
+	if cfg.localInfileMaxBytes > 0 {
+		writeDSNParam(&buf, &hasParam, "localInfileMaxBytes", strconv.FormatInt(cfg.localInfileMaxBytes, 10))
+	}
+
+	if cfg.localInfileTimeout > 0 {
+		writeDSNParam(&buf, &hasParam, "localInfileTimeout", cfg.localInfileTimeout.String())
+	}
+
+	if cfg.maxAllowedPacketTTL > 0 {
+		writeDSNParam(&buf, &hasParam, "maxAllowedPacketTTL", cfg.maxAllowedPacketTTL.String())
+	}
+
+	if cfg.maxConcurrentConnects > 0 {
+		writeDSNParam(&buf, &hasParam, "maxConcurrentConnects", strconv.Itoa(cfg.maxConcurrentConnects))
+	}
+
+	if cfg.maxResultSetBytes > 0 {
+		writeDSNParam(&buf, &hasParam, "maxResultSetBytes", strconv.FormatInt(cfg.maxResultSetBytes, 10))
+	}
+
+	if cfg.maxResultSetRows > 0 {
+		writeDSNParam(&buf, &hasParam, "maxResultSetRows", strconv.FormatInt(cfg.maxResultSetRows, 10))
+	}
+
+	if cfg.maxRetainedBuffer > 0 {
+		writeDSNParam(&buf, &hasParam, "maxRetainedBuffer", strconv.Itoa(cfg.maxRetainedBuffer))
+	}
+
 	if cfg.MultiStatements {
 		writeDSNParam(&buf, &hasParam, "multiStatements", "true")
 	}
 
+	if cfg.oidcAudience != "" {
+		writeDSNParam(&buf, &hasParam, "oidcAudience", url.QueryEscape(cfg.oidcAudience))
+	}
+
+	if cfg.oidcCertificateBound {
+		writeDSNParam(&buf, &hasParam, "oidcCertificateBound", "true")
+	}
+
+	if cfg.oidcClientID != "" {
+		writeDSNParam(&buf, &hasParam, "oidcClientID", url.QueryEscape(cfg.oidcClientID))
+	}
+
+	if cfg.oidcClientSecret != "" {
+		writeDSNParam(&buf, &hasParam, "oidcClientSecret", url.QueryEscape(cfg.oidcClientSecret))
+	}
+
+	if cfg.oidcClockSkew > 0 {
+		writeDSNParam(&buf, &hasParam, "oidcClockSkew", cfg.oidcClockSkew.String())
+	}
+
+	if cfg.oidcFraming != "" {
+		writeDSNParam(&buf, &hasParam, "oidcFraming", cfg.oidcFraming)
+	}
+
+	if cfg.oidcIssuer != "" {
+		writeDSNParam(&buf, &hasParam, "oidcIssuer", url.QueryEscape(cfg.oidcIssuer))
+	}
+
+	if cfg.oidcMinTTL > 0 {
+		writeDSNParam(&buf, &hasParam, "oidcMinTTL", cfg.oidcMinTTL.String())
+	}
+
+	if cfg.oidcProvider != "" {
+		writeDSNParam(&buf, &hasParam, "oidcProvider", cfg.oidcProvider)
+	}
+
+	if cfg.oidcRefreshToken != "" {
+		writeDSNParam(&buf, &hasParam, "oidcRefreshToken", url.QueryEscape(cfg.oidcRefreshToken))
+	}
+
+	if cfg.oidcRefreshTokenFile != "" {
+		writeDSNParam(&buf, &hasParam, "oidcRefreshTokenFile", url.QueryEscape(cfg.oidcRefreshTokenFile))
+	}
+
+	if cfg.oidcSource != "" {
+		writeDSNParam(&buf, &hasParam, "oidcSource", cfg.oidcSource)
+	}
+
+	if cfg.oidcTokenEndpoint != "" {
+		writeDSNParam(&buf, &hasParam, "oidcTokenEndpoint", url.QueryEscape(cfg.oidcTokenEndpoint))
+	}
+
+	if cfg.oidcTokenExchange {
+		writeDSNParam(&buf, &hasParam, "oidcTokenExchange", "true")
+	}
+
+	if cfg.parseDecimal {
+		writeDSNParam(&buf, &hasParam, "parseDecimal", "true")
+	}
+
+	if cfg.parseJSON {
+		writeDSNParam(&buf, &hasParam, "parseJSON", "true")
+	}
+
 	if cfg.ParseTime {
 		writeDSNParam(&buf, &hasParam, "parseTime", "true")
 	}
 
+	if cfg.pipelinePrepare {
+		writeDSNParam(&buf, &hasParam, "pipelinePrepare", "true")
+	}
+
+	if cfg.pipelineSessionInit {
+		writeDSNParam(&buf, &hasParam, "pipelineSessionInit", "true")
+	}
+
+	if cfg.propagateDeadline {
+		writeDSNParam(&buf, &hasParam, "propagateDeadline", "true")
+	}
+
+	if cfg.statementTimeout > 0 {
+		writeDSNParam(&buf, &hasParam, "statementTimeout", cfg.statementTimeout.String())
+	}
+
+	if cfg.stmtCacheSize > 0 {
+		writeDSNParam(&buf, &hasParam, "stmtCacheSize", strconv.Itoa(cfg.stmtCacheSize))
+	}
+
 	if cfg.timeTruncate > 0 {
 		writeDSNParam(&buf, &hasParam, "timeTruncate", cfg.timeTruncate.String())
 	}
 
+	if cfg.zeroDateTime != "" {
+		writeDSNParam(&buf, &hasParam, "zeroDateTime", cfg.zeroDateTime)
+	}
+
+	if cfg.rawBytes {
+		writeDSNParam(&buf, &hasParam, "rawBytes", "true")
+	}
+
+	if cfg.readBufferSize > 0 {
+		writeDSNParam(&buf, &hasParam, "readBufferSize", strconv.Itoa(cfg.readBufferSize))
+	}
+
+	if cfg.quitTimeout > 0 {
+		writeDSNParam(&buf, &hasParam, "quitTimeout", cfg.quitTimeout.String())
+	}
+
 	if cfg.ReadTimeout > 0 {
 		writeDSNParam(&buf, &hasParam, "readTimeout", cfg.ReadTimeout.String())
 	}
 
+	if cfg.recordTraffic != "" {
+		writeDSNParam(&buf, &hasParam, "recordTraffic", url.QueryEscape(cfg.recordTraffic))
+	}
+
 	if cfg.RejectReadOnly {
 		writeDSNParam(&buf, &hasParam, "rejectReadOnly", "true")
 	}
 
+	if cfg.requireSecureAuthTransport {
+		writeDSNParam(&buf, &hasParam, "requireSecureAuthTransport", "true")
+	}
+
+	if cfg.resetConnection {
+		writeDSNParam(&buf, &hasParam, "resetConnection", "true")
+	}
+
+	if cfg.retryReadOnly {
+		writeDSNParam(&buf, &hasParam, "retryReadOnly", "true")
+	}
+
+	if cfg.serverFlavor != "" {
+		writeDSNParam(&buf, &hasParam, "serverFlavor", string(cfg.serverFlavor))
+	}
+
 	if len(cfg.ServerPubKey) > 0 {
 		writeDSNParam(&buf, &hasParam, "serverPubKey", url.QueryEscape(cfg.ServerPubKey))
 	}
 
+	if len(cfg.sessionVars) > 0 {
+		// '&' separates DSN params and isn't escaped by PathEscape
+		vars := url.PathEscape(formatSessionVars(cfg.sessionVars))
+		writeDSNParam(&buf, &hasParam, "sessionVars", strings.ReplaceAll(vars, "&", "%26"))
+	}
+
+	if cfg.slowQueryRedact {
+		writeDSNParam(&buf, &hasParam, "slowQueryRedact", "true")
+	}
+
+	if cfg.slowQueryThreshold > 0 {
+		writeDSNParam(&buf, &hasParam, "slowQueryThreshold", cfg.slowQueryThreshold.String())
+	}
+
+	if cfg.sslCert != "" {
+		writeDSNParam(&buf, &hasParam, "sslCert", url.QueryEscape(cfg.sslCert))
+	}
+
+	if cfg.sslKey != "" {
+		writeDSNParam(&buf, &hasParam, "sslKey", url.QueryEscape(cfg.sslKey))
+	}
+
 	if cfg.Timeout > 0 {
 		writeDSNParam(&buf, &hasParam, "timeout", cfg.Timeout.String())
 	}
 
+	if cfg.useCursorFetch {
+		writeDSNParam(&buf, &hasParam, "useCursorFetch", "true")
+	}
+
+	if cfg.verifyCollation {
+		writeDSNParam(&buf, &hasParam, "verifyCollation", "true")
+	}
+
 	if len(cfg.TLSConfig) > 0 {
 		writeDSNParam(&buf, &hasParam, "tls", url.QueryEscape(cfg.TLSConfig))
 	}
@@ -395,6 +1122,53 @@ func (cfg *Config) FormatDSN() string {
 	return buf.String()
 }
 
+// redactedValue replaces passwords and tokens in redacted DSNs.
+const redactedValue = "xxxxx"
+
+// Redacted formats the Config like FormatDSN, but with the password and the
+// values of params carrying credentials, e.g. the ID token of OpenID Connect,
+// replaced by "xxxxx". Use it to log the configuration.
+func (cfg *Config) Redacted() string {
+	cp := *cfg
+	if len(cp.Passwd) > 0 {
+		cp.Passwd = redactedValue
+	}
+	if len(cp.oidcClientSecret) > 0 {
+		cp.oidcClientSecret = redactedValue
+	}
+	if len(cp.oidcRefreshToken) > 0 {
+		cp.oidcRefreshToken = redactedValue
+	}
+	if len(cfg.Params) > 0 {
+		cp.Params = make(map[string]string, len(cfg.Params))
+		for k, v := range cfg.Params {
+			if isSecretParam(k) {
+				v = redactedValue
+			}
+			cp.Params[k] = v
+		}
+	}
+	return cp.FormatDSN()
+}
+
+// String returns the redacted DSN of the Config, so that printing a Config
+// doesn't leak credentials.
+func (cfg *Config) String() string {
+	return cfg.Redacted()
+}
+
+// isSecretParam reports whether the value of the DSN param may hold a
+// credential and must not be logged.
+func isSecretParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range []string{"password", "passwd", "secret", "token"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
 // ParseDSN parses the DSN string to a Config
 func ParseDSN(dsn string) (cfg *Config, err error) {
 	// New config with some default values
@@ -473,7 +1247,6 @@ func ParseDSN(dsn string) (cfg *Config, err error) {
 	if err = cfg.normalize(); err != nil {
 		return nil, err
 	}
-	//	log.Printf("DSN.GO : Authentication plugin used in DSN: %s", cfg.Params)
 
 	return
 }
@@ -564,10 +1337,71 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 		case "compress":
 			var isBool bool
 			cfg.compress, isBool = readBool(value)
+			if !isBool {
+				name, err := url.QueryUnescape(value)
+				if err != nil {
+					return fmt.Errorf("invalid value for compression codec name: %v", err)
+				}
+				cfg.compress = true
+				cfg.compressCodec = name
+			}
+
+		// Max time to wait for a free handshake slot
+		case "connectQueueTimeout":
+			cfg.connectQueueTimeout, err = time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid connectQueueTimeout value: %v, error: %w", value, err)
+			}
+
+		// Retries of failed connects
+		case "dialBackoff":
+			cfg.dialBackoff, err = time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid dialBackoff value: %v, error: %w", value, err)
+			}
+		case "dialRetries":
+			cfg.dialRetries, err = strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid dialRetries value: %v, error: %w", value, err)
+			}
+
+		// Statement fingerprints in errors
+		case "errorStatement":
+			var isBool bool
+			cfg.errorStatement, isBool = readBool(value)
 			if !isBool {
 				return errors.New("invalid bool value: " + value)
 			}
 
+		// FIPS-approved authentication only
+		case "fips":
+			var isBool bool
+			cfg.fips, isBool = readBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
+		// Rows per COM_STMT_FETCH
+		case "fetchSize":
+			cfg.fetchSize, err = strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid fetchSize value: %v, error: %w", value, err)
+			}
+
+		// Max duration of the handshake
+		case "handshakeTimeout":
+			cfg.handshakeTimeout, err = time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid handshakeTimeout value: %v, error: %w", value, err)
+			}
+
+		// Max wait for the server to close the connection on Close
+		case "quitTimeout":
+			cfg.quitTimeout, err = time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid quitTimeout value: %v, error: %w", value, err)
+			}
+
 		// Enable client side placeholder substitution
 		case "interpolateParams":
 			var isBool bool
@@ -586,6 +1420,51 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 				return
 			}
 
+		// LOAD DATA LOCAL INFILE limits
+		case "localInfileMaxBytes":
+			cfg.localInfileMaxBytes, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid localInfileMaxBytes value: %v, error: %w", value, err)
+			}
+		case "localInfileTimeout":
+			cfg.localInfileTimeout, err = time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid localInfileTimeout value: %v, error: %w", value, err)
+			}
+
+		// Time the max_allowed_packet value of the server is cached
+		case "maxAllowedPacketTTL":
+			cfg.maxAllowedPacketTTL, err = time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid maxAllowedPacketTTL value: %v, error: %w", value, err)
+			}
+
+		// Max number of simultaneous handshakes
+		case "maxConcurrentConnects":
+			cfg.maxConcurrentConnects, err = strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid maxConcurrentConnects value: %v, error: %w", value, err)
+			}
+
+		// Result set limits
+		case "maxResultSetBytes":
+			cfg.maxResultSetBytes, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid maxResultSetBytes value: %v, error: %w", value, err)
+			}
+		case "maxResultSetRows":
+			cfg.maxResultSetRows, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid maxResultSetRows value: %v, error: %w", value, err)
+			}
+
+		// Max size of the buffer kept by a connection
+		case "maxRetainedBuffer":
+			cfg.maxRetainedBuffer, err = strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid maxRetainedBuffer value: %v, error: %w", value, err)
+			}
+
 		// multiple statements in one query
 		case "multiStatements":
 			var isBool bool
@@ -594,6 +1473,110 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 				return errors.New("invalid bool value: " + value)
 			}
 
+		// audience of the OIDC ID token
+		case "oidcAudience":
+			cfg.oidcAudience, err = url.QueryUnescape(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for oidcAudience: %v", err)
+			}
+
+		// binding of the OIDC ID token to the client certificate
+		case "oidcCertificateBound":
+			var isBool bool
+			cfg.oidcCertificateBound, isBool = readBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
+		// client credentials of the OIDC token exchange
+		case "oidcClientID":
+			cfg.oidcClientID, err = url.QueryUnescape(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for oidcClientID: %v", err)
+			}
+		case "oidcClientSecret":
+			cfg.oidcClientSecret, err = url.QueryUnescape(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for oidcClientSecret: %v", err)
+			}
+
+		// expiry checks of the OIDC ID token
+		case "oidcClockSkew":
+			cfg.oidcClockSkew, err = time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid oidcClockSkew value: %v, error: %w", value, err)
+			}
+		case "oidcMinTTL":
+			cfg.oidcMinTTL, err = time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid oidcMinTTL value: %v, error: %w", value, err)
+			}
+
+		// framing of the OIDC ID token
+		case "oidcFraming":
+			if err := OIDCFraming(value)(cfg); err != nil {
+				return err
+			}
+
+		// preset of the OIDC identity provider
+		case "oidcIssuer":
+			cfg.oidcIssuer, err = url.QueryUnescape(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for oidcIssuer: %v", err)
+			}
+		case "oidcProvider":
+			if _, ok := oidcProviderPresets[value]; !ok {
+				return fmt.Errorf("invalid oidcProvider value: %s", value)
+			}
+			cfg.oidcProvider = value
+
+		// refresh token renewing the OIDC ID token
+		case "oidcRefreshToken":
+			cfg.oidcRefreshToken, err = url.QueryUnescape(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for oidcRefreshToken: %v", err)
+			}
+		case "oidcRefreshTokenFile":
+			cfg.oidcRefreshTokenFile, err = url.QueryUnescape(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for oidcRefreshTokenFile: %v", err)
+			}
+
+		// source of the OIDC ID token
+		case "oidcSource":
+			if err := OIDCSource(value)(cfg); err != nil {
+				return err
+			}
+
+		// exchanging the OIDC token for an ID token
+		case "oidcTokenEndpoint":
+			cfg.oidcTokenEndpoint, err = url.QueryUnescape(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for oidcTokenEndpoint: %v", err)
+			}
+		case "oidcTokenExchange":
+			var isBool bool
+			cfg.oidcTokenExchange, isBool = readBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
+		// DECIMAL columns as Decimal
+		case "parseDecimal":
+			var isBool bool
+			cfg.parseDecimal, isBool = readBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
+		// JSON columns as json.RawMessage
+		case "parseJSON":
+			var isBool bool
+			cfg.parseJSON, isBool = readBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
 		// time.Time parsing
 		case "parseTime":
 			var isBool bool
@@ -609,6 +1592,63 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 				return fmt.Errorf("invalid timeTruncate value: %v, error: %w", value, err)
 			}
 
+		// retrying commands after the server closed the connection
+		case "autoReconnect":
+			if err := AutoReconnect(value)(cfg); err != nil {
+				return err
+			}
+
+		// canceling queries
+		case "cancelMode":
+			if err := CancelMode(value)(cfg); err != nil {
+				return err
+			}
+
+		// zero dates with parseTime
+		case "zeroDateTime":
+			if err := ZeroDateTime(value)(cfg); err != nil {
+				return err
+			}
+
+		// Pipeline one-shot prepared statements
+		case "pipelinePrepare":
+			var isBool bool
+			cfg.pipelinePrepare, isBool = readBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
+		// Pipeline the queries setting up the session
+		case "pipelineSessionInit":
+			var isBool bool
+			cfg.pipelineSessionInit, isBool = readBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
+		// Context deadlines as execution time limits
+		case "propagateDeadline":
+			var isBool bool
+			cfg.propagateDeadline, isBool = readBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
+		// Unconverted text protocol values
+		case "rawBytes":
+			var isBool bool
+			cfg.rawBytes, isBool = readBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
+		// Size of the read buffer
+		case "readBufferSize":
+			cfg.readBufferSize, err = strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid readBufferSize value: %v, error: %w", value, err)
+			}
+
 		// I/O read Timeout
 		case "readTimeout":
 			cfg.ReadTimeout, err = time.ParseDuration(value)
@@ -616,6 +1656,13 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 				return
 			}
 
+		// Directory of the traffic recordings
+		case "recordTraffic":
+			cfg.recordTraffic, err = url.QueryUnescape(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for recordTraffic: %v", err)
+			}
+
 		// Reject read-only connections
 		case "rejectReadOnly":
 			var isBool bool
@@ -624,6 +1671,38 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 				return errors.New("invalid bool value: " + value)
 			}
 
+		// Refuse to send credentials over insecure transports
+		case "requireSecureAuthTransport":
+			var isBool bool
+			cfg.requireSecureAuthTransport, isBool = readBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
+		// Reset pooled connections with COM_RESET_CONNECTION
+		case "resetConnection":
+			var isBool bool
+			cfg.resetConnection, isBool = readBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
+		// Retry failed read-only queries on another connection
+		case "retryReadOnly":
+			var isBool bool
+			cfg.retryReadOnly, isBool = readBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
+		// Flavor of the server
+		case "serverFlavor":
+			f := ServerFlavor(strings.ToLower(value))
+			if err := f.validate(); err != nil {
+				return err
+			}
+			cfg.serverFlavor = f
+
 		// Server public key
 		case "serverPubKey":
 			name, err := url.QueryUnescape(value)
@@ -632,6 +1711,56 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 			}
 			cfg.ServerPubKey = name
 
+		// System variables set in order at connect
+		case "sessionVars":
+			// '+' is kept, e.g. in time_zone='+00:00'
+			vars, err := url.PathUnescape(value)
+			if err != nil {
+				return fmt.Errorf("invalid sessionVars value: %v", err)
+			}
+			if cfg.sessionVars, err = parseSessionVars(vars); err != nil {
+				return err
+			}
+
+		// Slow query reporting
+		case "slowQueryRedact":
+			var isBool bool
+			cfg.slowQueryRedact, isBool = readBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+		case "slowQueryThreshold":
+			cfg.slowQueryThreshold, err = time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid slowQueryThreshold value: %v, error: %w", value, err)
+			}
+
+		// Client certificate for mutual TLS
+		case "sslCert":
+			cfg.sslCert, err = url.QueryUnescape(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for sslCert: %v", err)
+			}
+		case "sslKey":
+			cfg.sslKey, err = url.QueryUnescape(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for sslKey: %v", err)
+			}
+
+		// Max execution time of statements
+		case "statementTimeout":
+			cfg.statementTimeout, err = time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid statementTimeout value: %v, error: %w", value, err)
+			}
+
+		// Prepared statement cache
+		case "stmtCacheSize":
+			cfg.stmtCacheSize, err = strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid stmtCacheSize value: %v, error: %w", value, err)
+			}
+
 		// Strict mode
 		case "strict":
 			panic("strict mode has been removed. See https://github.com/go-sql-driver/mysql/wiki/strict-mode")
@@ -652,7 +1781,7 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 				} else {
 					cfg.TLSConfig = "false"
 				}
-			} else if vl := strings.ToLower(value); vl == "skip-verify" || vl == "preferred" {
+			} else if vl := strings.ToLower(value); isTLSMode(vl) {
 				cfg.TLSConfig = vl
 			} else {
 				name, err := url.QueryUnescape(value)
@@ -662,6 +1791,22 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 				cfg.TLSConfig = name
 			}
 
+		// Server-side cursors for prepared statements
+		case "useCursorFetch":
+			var isBool bool
+			cfg.useCursorFetch, isBool = readBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
+		// Check the collation of new connections
+		case "verifyCollation":
+			var isBool bool
+			cfg.verifyCollation, isBool = readBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
 		// I/O write Timeout
 		case "writeTimeout":
 			cfg.WriteTimeout, err = time.ParseDuration(value)
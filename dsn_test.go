@@ -10,9 +10,11 @@ package mysql
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -38,6 +40,9 @@ var testDSNs = []struct {
 }, {
 	"user:password@tcp(localhost:5555)/dbname?charset=utf8mb4,utf8&tls=skip-verify",
 	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "localhost:5555", DBName: "dbname", charsets: []string{"utf8mb4", "utf8"}, Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, TLSConfig: "skip-verify"},
+}, {
+	"user:password@tcp(localhost:5555)/dbname?tls=verify-identity",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "localhost:5555", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, TLSConfig: "verify-identity"},
 }, {
 	"user:password@/dbname?loc=UTC&timeout=30s&readTimeout=1s&writeTimeout=1s&allowAllFiles=1&clientFoundRows=true&allowOldPasswords=TRUE&collation=utf8mb4_unicode_ci&maxAllowedPacket=16777216&tls=false&allowCleartextPasswords=true&parseTime=true&rejectReadOnly=true",
 	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Collation: "utf8mb4_unicode_ci", Loc: time.UTC, TLSConfig: "false", AllowCleartextPasswords: true, AllowNativePasswords: true, Timeout: 30 * time.Second, ReadTimeout: time.Second, WriteTimeout: time.Second, Logger: defaultLogger, AllowAllFiles: true, AllowOldPasswords: true, CheckConnLiveness: true, ClientFoundRows: true, MaxAllowedPacket: 16777216, ParseTime: true, RejectReadOnly: true},
@@ -80,6 +85,117 @@ var testDSNs = []struct {
 }, {
 	"foo:bar@tcp(192.168.1.50:3307)/baz?timeout=10s&connectionAttributes=program_name:MySQLGoDriver%2FTest,program_version:1.2.3",
 	&Config{User: "foo", Passwd: "bar", Net: "tcp", Addr: "192.168.1.50:3307", DBName: "baz", Loc: time.UTC, Timeout: 10 * time.Second, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, ConnectionAttributes: "program_name:MySQLGoDriver/Test,program_version:1.2.3"},
+}, {
+	"user:password@/dbname?maxConcurrentConnects=4&connectQueueTimeout=2s",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, maxConcurrentConnects: 4, connectQueueTimeout: 2 * time.Second},
+}, {
+	"user:password@/dbname?dialRetries=3&dialBackoff=250ms",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, dialRetries: 3, dialBackoff: 250 * time.Millisecond},
+}, {
+	"user:password@/dbname?handshakeTimeout=5s",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, handshakeTimeout: 5 * time.Second},
+}, {
+	"user:password@/dbname?maxAllowedPacket=0&maxAllowedPacketTTL=1m0s",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: 0, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, maxAllowedPacketTTL: time.Minute},
+}, {
+	"user:password@/dbname?pipelineSessionInit=true",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, pipelineSessionInit: true},
+}, {
+	"user:password@/dbname?parseTime=true&zeroDateTime=nil",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, ParseTime: true, zeroDateTime: ZeroDateTimeNil},
+}, {
+	"user:password@/dbname?propagateDeadline=true",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, propagateDeadline: true},
+}, {
+	"user:password@/dbname?autoReconnect=idempotent",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, autoReconnect: AutoReconnectIdempotent},
+}, {
+	"user:password@/dbname?oidcAudience=mysql%3A%2F%2Fdb&oidcSource=kubernetes",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, oidcAudience: "mysql://db", oidcSource: OIDCSourceKubernetes},
+}, {
+	"user:password@/dbname?oidcAudience=mysql&oidcClientID=app&oidcClientSecret=s3cr3t&oidcTokenEndpoint=https%3A%2F%2Fidp.example.com%2Ftoken&oidcTokenExchange=true",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, oidcAudience: "mysql", oidcClientID: "app", oidcClientSecret: "s3cr3t", oidcTokenEndpoint: "https://idp.example.com/token", oidcTokenExchange: true},
+}, {
+	"user:password@/dbname?oidcAudience=mysql&oidcIssuer=https%3A%2F%2Fsso.example.com%2Frealms%2Fprod&oidcProvider=keycloak&oidcTokenExchange=true",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, oidcAudience: "mysql", oidcIssuer: "https://sso.example.com/realms/prod", oidcProvider: OIDCProviderKeycloak, oidcTokenExchange: true},
+}, {
+	"user:password@/dbname?oidcClientID=cli&oidcIssuer=https%3A%2F%2Fexample.okta.com&oidcProvider=okta&oidcRefreshTokenFile=%2Fhome%2Fdba%2F.mysql%2Frefresh-token",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, oidcClientID: "cli", oidcIssuer: "https://example.okta.com", oidcProvider: OIDCProviderOkta, oidcRefreshTokenFile: "/home/dba/.mysql/refresh-token"},
+}, {
+	"user:password@/dbname?oidcClientID=cli&oidcRefreshToken=rt-1&oidcTokenEndpoint=https%3A%2F%2Fidp.example.com%2Ftoken",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, oidcClientID: "cli", oidcRefreshToken: "rt-1", oidcTokenEndpoint: "https://idp.example.com/token"},
+}, {
+	"user:password@/dbname?oidcClockSkew=30s&oidcMinTTL=2m0s",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, oidcClockSkew: 30 * time.Second, oidcMinTTL: 2 * time.Minute},
+}, {
+	"user:password@/dbname?oidcFraming=raw",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, oidcFraming: OIDCFramingRaw},
+}, {
+	"user:password@/dbname?cancelMode=kill",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, cancelMode: CancelModeKill},
+}, {
+	"user:password@/dbname?maxResultSetBytes=1048576&maxResultSetRows=1000",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, maxResultSetBytes: 1048576, maxResultSetRows: 1000},
+}, {
+	"user:password@/dbname?statementTimeout=2s&retryReadOnly=true",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, statementTimeout: 2 * time.Second, retryReadOnly: true},
+}, {
+	"user:password@/dbname?quitTimeout=100ms",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, quitTimeout: 100 * time.Millisecond},
+}, {
+	"user:password@/dbname?parseDecimal=true",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, parseDecimal: true},
+}, {
+	"user:password@/dbname?parseJSON=true",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, parseJSON: true},
+}, {
+	"user:password@/dbname?collation=utf8mb4_0900_as_cs&verifyCollation=true",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Collation: "utf8mb4_0900_as_cs", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, verifyCollation: true},
+}, {
+	"user:password@/dbname?sessionVars=sql_mode='ANSI,NO_ZERO_DATE',time_zone='+00:00',group_concat_max_len=1000000",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, sessionVars: []sessionVar{{"sql_mode", "'ANSI,NO_ZERO_DATE'"}, {"time_zone", "'+00:00'"}, {"group_concat_max_len", "1000000"}}},
+}, {
+	"user:password@/dbname?serverFlavor=proxysql",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, serverFlavor: FlavorProxySQL},
+}, {
+	"user:password@/dbname?errorStatement=true",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, errorStatement: true},
+}, {
+	"user:password@/dbname?slowQueryRedact=true&slowQueryThreshold=1s",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, slowQueryRedact: true, slowQueryThreshold: time.Second},
+}, {
+	"user:password@tcp(localhost:5555)/dbname?sslCert=%2Fetc%2Fmysql%2Fclient.crt&sslKey=%2Fetc%2Fmysql%2Fclient.key&tls=verify-ca",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "localhost:5555", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, TLSConfig: "verify-ca", sslCert: "/etc/mysql/client.crt", sslKey: "/etc/mysql/client.key"},
+}, {
+	"user:password@/dbname?recordTraffic=%2Fvar%2Ftmp%2Fmysql-traces",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, recordTraffic: "/var/tmp/mysql-traces"},
+}, {
+	"user:password@/dbname?stmtCacheSize=256",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, stmtCacheSize: 256},
+}, {
+	"user:password@/dbname?pipelinePrepare=true",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, pipelinePrepare: true},
+}, {
+	"user:password@/dbname?maxRetainedBuffer=65536",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, maxRetainedBuffer: 65536},
+}, {
+	"user:password@/dbname?fips=true",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, fips: true},
+}, {
+	"user:password@/dbname?rawBytes=true",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, rawBytes: true},
+}, {
+	"user:password@/dbname?readBufferSize=65536",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, readBufferSize: 65536},
+}, {
+	"user:password@/dbname?requireSecureAuthTransport=true",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, requireSecureAuthTransport: true},
+}, {
+	"user:password@/dbname?resetConnection=true",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, resetConnection: true},
+}, {
+	"user:password@/dbname?useCursorFetch=true&fetchSize=1000",
+	&Config{User: "user", Passwd: "password", Net: "tcp", Addr: "127.0.0.1:3306", DBName: "dbname", Loc: time.UTC, MaxAllowedPacket: defaultMaxAllowedPacket, Logger: defaultLogger, AllowNativePasswords: true, CheckConnLiveness: true, useCursorFetch: true, fetchSize: 1000},
 },
 }
 
@@ -114,6 +230,31 @@ func TestDSNParserInvalid(t *testing.T) {
 		"user:pass@tcp(127.0.0.1:3306)/db/name", // invalid dbname
 		"user:password@/dbname?allowFallbackToPlaintext=PREFERRED",          // wrong bool flag
 		"user:password@/dbname?connectionAttributes=attr1:/unescaped/value", // unescaped
+		"user:password@/dbname?maxConcurrentConnects=-1",                    // negative limit
+		"user:password@/dbname?sessionVars=sql_mode='ANSI",                  // unterminated quote
+		"user:password@/dbname?zeroDateTime=round",                          // unknown mode
+		"user:password@/dbname?cancelMode=abort",                            // unknown mode
+		"user:password@/dbname?autoReconnect=always",                        // unknown mode
+		"user:password@/dbname?oidcSource=vault",                            // unknown source
+		"user:password@/dbname?oidcSource=kubernetes",                       // no audience
+		"user:password@/dbname?oidcFraming=base64",                          // unknown framing
+		"user:password@/dbname?oidcCertificateBound=true",                   // requires TLS
+		"user:password@/dbname?oidcTokenExchange=true&oidcAudience=mysql",   // no token endpoint
+		"user:password@/dbname?oidcTokenExchange=true",                      // no token endpoint and audience
+		"user:password@/dbname?oidcProvider=google",                         // unknown provider
+		"user:password@/dbname?oidcRefreshToken=rt&oidcClientID=cli",        // no token endpoint
+		"user:password@/dbname?oidcRefreshTokenFile=rt",                     // no token endpoint and client
+		"user:password@/dbname?oidcProvider=okta&oidcIssuer=okta.com",       // issuer not a URL
+		"user:password@/dbname?oidcProvider=okta",                           // no issuer
+		"user:password@/dbname?maxResultSetRows=-1",                         // negative limit
+		"user:password@/dbname?statementTimeout=-1s",                        // negative timeout
+		"user:password@/dbname?oidcMinTTL=-2m",                              // negative TTL
+		"user:password@/dbname?oidcClockSkew=30",                            // missing unit
+		"user:password@/dbname?serverFlavor=oracle",                         // unknown flavor
+		// issuers and token endpoints not https
+		"user:password@/dbname?oidcProvider=okta&oidcIssuer=http%3A%2F%2Fexample.okta.com",
+		"user:password@/dbname?oidcTokenExchange=true&oidcAudience=mysql&oidcTokenEndpoint=http%3A%2F%2Fidp.example.com%2Ftoken",
+		"user:password@/dbname?oidcRefreshToken=rt&oidcClientID=cli&oidcTokenEndpoint=http%3A%2F%2Fidp.example.com%2Ftoken",
 		//"/dbname?arg=/some/unescaped/path",
 	}
 
@@ -345,6 +486,30 @@ func TestParamsAreSorted(t *testing.T) {
 	}
 }
 
+func TestConfigRedacted(t *testing.T) {
+	dsn := "user:p4ss@tcp(localhost:3306)/dbname?authentication_openid_connect_client_id_token_file=%2Fvar%2Frun%2Ftoken&foo=bar"
+	cfg, err := ParseDSN(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "user:xxxxx@tcp(localhost:3306)/dbname?authentication_openid_connect_client_id_token_file=xxxxx&foo=bar"
+	if got := cfg.Redacted(); got != expected {
+		t.Errorf("Redacted: want %q, got %q", expected, got)
+	}
+	if got := fmt.Sprint(cfg); got != expected {
+		t.Errorf("String: want %q, got %q", expected, got)
+	}
+
+	// the Config itself is unchanged
+	if cfg.Passwd != "p4ss" || cfg.Params["authentication_openid_connect_client_id_token_file"] != "/var/run/token" {
+		t.Errorf("Redacted modified the Config: %q, %v", cfg.Passwd, cfg.Params)
+	}
+	if got := cfg.FormatDSN(); got != dsn {
+		t.Errorf("FormatDSN: want %q, got %q", dsn, got)
+	}
+}
+
 func TestCloneConfig(t *testing.T) {
 	RegisterServerPubKey("testKey", testPubKeyRSA)
 	defer DeregisterServerPubKey("testKey")
@@ -395,6 +560,9 @@ func TestNormalizeTLSConfig(t *testing.T) {
 		{"true", &tls.Config{ServerName: "myserver"}},
 		{"skip-verify", &tls.Config{InsecureSkipVerify: true}},
 		{"preferred", &tls.Config{InsecureSkipVerify: true}},
+		{"required", &tls.Config{InsecureSkipVerify: true}},
+		{"verify-ca", &tls.Config{InsecureSkipVerify: true}},
+		{"verify-identity", &tls.Config{ServerName: "myserver"}},
 		{"test_tls_config", &tls.Config{ServerName: "myServerName"}},
 	}
 
@@ -429,6 +597,68 @@ func TestNormalizeTLSConfig(t *testing.T) {
 	}
 }
 
+func TestNormalizeTLSVerifyCA(t *testing.T) {
+	roots := x509.NewCertPool()
+	c := &tls.Config{RootCAs: roots}
+	cfg := NewConfig()
+	cfg.Addr = "myserver:3306"
+	cfg.TLSConfig = "verify-ca"
+	cfg.TLS = c
+	for i := 0; i < 2; i++ {
+		if err := cfg.normalize(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cfg.TLS == c || cfg.TLS.RootCAs != roots || !cfg.TLS.InsecureSkipVerify {
+		t.Errorf("expected a copy of the TLS config without host name verification, got %+v", cfg.TLS)
+	}
+	// the chain is verified by the config of each handshake
+	if cfg.TLS.VerifyConnection != nil {
+		t.Error("expected the verification not to be set on the config")
+	}
+	if c.InsecureSkipVerify || c.VerifyConnection != nil || c.ServerName != "" {
+		t.Error("expected the TLS config of the caller not to be modified")
+	}
+}
+
+func TestUseTLS(t *testing.T) {
+	roots := x509.NewCertPool()
+	c := &tls.Config{RootCAs: roots}
+	cfg := NewConfig()
+	cfg.Addr = "myserver:3306"
+	if err := cfg.Apply(UseTLS("required", c)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TLS == c || cfg.TLS.RootCAs != roots || !cfg.TLS.InsecureSkipVerify {
+		t.Errorf("expected a copy of the TLS config without verification, got %+v", cfg.TLS)
+	}
+	if c.InsecureSkipVerify {
+		t.Error("expected the TLS config passed to UseTLS not to be modified")
+	}
+	if dsn := cfg.FormatDSN(); !strings.HasSuffix(dsn, "?tls=required") {
+		t.Errorf("expected the mode to be formatted, got %q", dsn)
+	}
+
+	cfg = NewConfig()
+	cfg.Addr = "myserver:3306"
+	if err := cfg.Apply(UseTLS("verify-identity", c)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TLS.InsecureSkipVerify || cfg.TLS.ServerName != "myserver" {
+		t.Errorf("expected the host name to be verified, got %+v", cfg.TLS)
+	}
+
+	if err := NewConfig().Apply(UseTLS("custom", c)); err == nil {
+		t.Error("expected an error for an invalid TLS mode")
+	}
+}
+
 func BenchmarkParseDSN(b *testing.B) {
 	b.ReportAllocs()
 
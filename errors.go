@@ -9,10 +9,15 @@
 package mysql
 
 import (
+	"context"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"log"
 	"os"
+	"slices"
+
+	"github.com/colussim/mysql-auth-oidc-go/mysqlerrors"
 )
 
 // Various errors the driver might return. Can change between driver versions.
@@ -30,6 +35,21 @@ var (
 	ErrPktTooLarge       = errors.New("packet for query is too large. Try adjusting the `Config.MaxAllowedPacket`")
 	ErrBusyBuffer        = errors.New("busy buffer")
 
+	ErrLocalInfileTooLarge = errors.New("LOAD DATA LOCAL INFILE data exceeds the limit. Try adjusting `localInfileMaxBytes`")
+	ErrConnectQueueTimeout = errors.New("timed out waiting for a free connection handshake slot. Try adjusting `maxConcurrentConnects` or `connectQueueTimeout`")
+	ErrHandshakeTimeout    = errors.New("connection handshake timed out. Try adjusting `handshakeTimeout`")
+	ErrReadTimeout         = errors.New("reading from the server timed out. Try adjusting `readTimeout`")
+	ErrStatementTimeout    = errors.New("statement timed out. Try adjusting `statementTimeout`")
+
+	ErrInsecureAuthTransport = errors.New("refusing to send credentials without TLS or a unix socket. Enable TLS or unset `requireSecureAuthTransport`")
+	ErrFIPSAuth              = errors.New("authentication method not allowed in FIPS mode")
+	ErrCollationMismatch     = errors.New("the connection collation differs from the configured one")
+	ErrZeroDateTime          = errors.New("zero DATE or DATETIME value. Try adjusting `zeroDateTime`")
+	ErrResultSetTooLarge     = errors.New("result set exceeds the limit. Try adjusting `maxResultSetBytes` or `maxResultSetRows`")
+	ErrNoRefreshToken        = errors.New("no refresh token stored for the OIDC client")
+	ErrKeychainUnsupported   = errors.New("no OS keychain is supported on this platform")
+	ErrOIDCTokenExpiring     = errors.New("OIDC ID token expires too soon. Try adjusting `oidcMinTTL` or `oidcClockSkew`")
+
 	// errBadConnNoWrite is used for connection errors where nothing was sent to the database yet.
 	// If this happens first in a function starting a database interaction, it should be replaced by driver.ErrBadConn
 	// to trigger a resend. Use mc.markBadConn(err) to do this.
@@ -60,11 +80,124 @@ func SetLogger(logger Logger) error {
 	return nil
 }
 
+// Classes of server errors. errors.Is reports whether a *MySQLError belongs
+// to a class, whichever of the error numbers used for it by the versions of
+// MySQL and MariaDB it has:
+//
+//	if errors.Is(err, mysql.ErrDuplicateEntry) {
+//		return ErrUserExists
+//	}
+var (
+	ErrAccessDenied error = &mysqlErrorClass{"access denied", []uint16{
+		mysqlerrors.ER_DBACCESS_DENIED_ERROR,
+		mysqlerrors.ER_ACCESS_DENIED_ERROR,
+		mysqlerrors.ER_TABLEACCESS_DENIED_ERROR,
+		mysqlerrors.ER_COLUMNACCESS_DENIED_ERROR,
+		mysqlerrors.ER_SPECIFIC_ACCESS_DENIED_ERROR,
+		mysqlerrors.ER_PROCACCESS_DENIED_ERROR,
+		mysqlerrors.ER_ACCESS_DENIED_NO_PASSWORD_ERROR,
+		mysqlerrors.ER_ACCOUNT_HAS_BEEN_LOCKED,
+	}}
+	ErrUnknownDatabase error = &mysqlErrorClass{"unknown database", []uint16{
+		mysqlerrors.ER_BAD_DB_ERROR,
+	}}
+	ErrUnknownTable error = &mysqlErrorClass{"unknown table", []uint16{
+		mysqlerrors.ER_BAD_TABLE_ERROR,
+		mysqlerrors.ER_UNKNOWN_TABLE,
+		mysqlerrors.ER_NO_SUCH_TABLE,
+	}}
+	ErrDuplicateEntry error = &mysqlErrorClass{"duplicate entry", []uint16{
+		mysqlerrors.ER_DUP_KEY,
+		mysqlerrors.ER_DUP_ENTRY,
+		mysqlerrors.ER_DUP_ENTRY_WITH_KEY_NAME,
+	}}
+	ErrDeadlock error = &mysqlErrorClass{"deadlock", []uint16{
+		mysqlerrors.ER_LOCK_DEADLOCK,
+		mysqlerrors.ER_XA_RBDEADLOCK,
+	}}
+	ErrLockWaitTimeout error = &mysqlErrorClass{"lock wait timeout", []uint16{
+		mysqlerrors.ER_LOCK_WAIT_TIMEOUT,
+	}}
+	ErrReadOnly error = &mysqlErrorClass{"read-only", []uint16{
+		mysqlerrors.ER_OPTION_PREVENTS_STATEMENT,
+		mysqlerrors.ER_CANT_EXECUTE_IN_READ_ONLY_TRANSACTION,
+		mysqlerrors.ER_READ_ONLY_MODE,
+	}}
+)
+
+// IsAccessDenied reports whether err is a server error of the class
+// ErrAccessDenied.
+func IsAccessDenied(err error) bool { return errors.Is(err, ErrAccessDenied) }
+
+// IsUnknownDatabase reports whether err is a server error of the class
+// ErrUnknownDatabase.
+func IsUnknownDatabase(err error) bool { return errors.Is(err, ErrUnknownDatabase) }
+
+// IsUnknownTable reports whether err is a server error of the class
+// ErrUnknownTable.
+func IsUnknownTable(err error) bool { return errors.Is(err, ErrUnknownTable) }
+
+// IsDuplicateEntry reports whether err is a server error of the class
+// ErrDuplicateEntry.
+func IsDuplicateEntry(err error) bool { return errors.Is(err, ErrDuplicateEntry) }
+
+// IsDeadlock reports whether err is a server error of the class ErrDeadlock.
+func IsDeadlock(err error) bool { return errors.Is(err, ErrDeadlock) }
+
+// IsLockWaitTimeout reports whether err is a server error of the class
+// ErrLockWaitTimeout.
+func IsLockWaitTimeout(err error) bool { return errors.Is(err, ErrLockWaitTimeout) }
+
+// IsReadOnly reports whether err is a server error of the class ErrReadOnly.
+func IsReadOnly(err error) bool { return errors.Is(err, ErrReadOnly) }
+
+// IsErrorNumber reports whether err is a server error with one of the
+// numbers, e.g. mysqlerrors.ER_NO_REFERENCED_ROW_2.
+func IsErrorNumber(err error, numbers ...uint16) bool {
+	var me *MySQLError
+	return errors.As(err, &me) && slices.Contains(numbers, me.Number)
+}
+
+// mysqlErrorClass is a class of server errors, see ErrAccessDenied.
+type mysqlErrorClass struct {
+	name    string
+	numbers []uint16
+}
+
+func (c *mysqlErrorClass) Error() string {
+	return c.name
+}
+
 // MySQLError is an error type which represents a single MySQL error
 type MySQLError struct {
 	Number   uint16
 	SQLState [5]byte
 	Message  string
+
+	// Statement is the fingerprint of the failing statement, with literals
+	// replaced by '?'. It is only set with the ErrorStatement option.
+	Statement string
+}
+
+// AuthError is returned when the server rejects the authentication of a new
+// connection, e.g. because of an expired OIDC token or a wrong password. It
+// wraps the error sent by the server.
+type AuthError struct {
+	Plugin string // authentication plugin in use when the server rejected it
+	TLS    bool   // whether the connection used TLS
+	Err    *MySQLError
+}
+
+func (e *AuthError) Error() string {
+	tls := "without TLS"
+	if e.TLS {
+		tls = "with TLS"
+	}
+	return fmt.Sprintf("authentication with %s %s failed: %v", e.Plugin, tls, e.Err)
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
 }
 
 func (me *MySQLError) Error() string {
@@ -76,8 +209,64 @@ func (me *MySQLError) Error() string {
 }
 
 func (me *MySQLError) Is(err error) bool {
-	if merr, ok := err.(*MySQLError); ok {
-		return merr.Number == me.Number
+	switch target := err.(type) {
+	case *MySQLError:
+		return target.Number == me.Number
+	case *mysqlErrorClass:
+		return slices.Contains(target.numbers, me.Number)
 	}
 	return false
 }
+
+// State returns the SQLSTATE of the error, e.g. "23000", or an empty string
+// if the server didn't send one.
+func (me *MySQLError) State() string {
+	if me.SQLState == [5]byte{} {
+		return ""
+	}
+	return string(me.SQLState[:])
+}
+
+// Retryable reports whether the statement, or the transaction it belongs to,
+// may succeed when it is retried: deadlocks, lock wait timeouts, a server
+// which is read-only or shutting down during a failover, and network errors
+// reported by the server.
+func (me *MySQLError) Retryable() bool {
+	switch me.Number {
+	case mysqlerrors.ER_CON_COUNT_ERROR,
+		mysqlerrors.ER_SERVER_SHUTDOWN,
+		mysqlerrors.ER_NET_READ_ERROR,
+		mysqlerrors.ER_NET_READ_INTERRUPTED,
+		mysqlerrors.ER_NET_ERROR_ON_WRITE,
+		mysqlerrors.ER_NET_WRITE_INTERRUPTED,
+		mysqlerrors.ER_LOCK_WAIT_TIMEOUT,
+		mysqlerrors.ER_LOCK_DEADLOCK,
+		mysqlerrors.ER_OPTION_PREVENTS_STATEMENT, // returned by Aurora during failover
+		mysqlerrors.ER_XA_RBDEADLOCK,
+		mysqlerrors.ER_TOO_MANY_CONCURRENT_TRXS,
+		mysqlerrors.ER_READ_ONLY_MODE,
+		mysqlerrors.ER_CONNECTION_KILLED: // MariaDB
+		return true
+	}
+	// serialization failure, e.g. a certification failure of Galera
+	return me.SQLState == [5]byte{'4', '0', '0', '0', '1'}
+}
+
+// IsRetryableError reports whether an operation failing with err may succeed
+// when it is retried: a *MySQLError which is Retryable, or a network error,
+// including driver.ErrBadConn. Errors of the context are not retryable.
+//
+// Deadlocks and lock wait timeouts roll back the transaction or the
+// statement, so retrying means rerunning the whole transaction. After a
+// network error it is unknown whether the statement was executed; only
+// idempotent statements should be retried then.
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var me *MySQLError
+	if errors.As(err, &me) {
+		return me.Retryable()
+	}
+	return errors.Is(err, driver.ErrBadConn) || isTransientConnectError(err)
+}
@@ -10,9 +10,17 @@ package mysql
 
 import (
 	"bytes"
+	"context"
+	"database/sql/driver"
 	"errors"
+	"fmt"
+	"io"
 	"log"
+	"net"
+	"syscall"
 	"testing"
+
+	"github.com/colussim/mysql-auth-oidc-go/mysqlerrors"
 )
 
 func TestErrorsSetLogger(t *testing.T) {
@@ -59,3 +67,100 @@ func TestMySQLErrIs(t *testing.T) {
 		t.Fatalf("expected errors to be different: %+v %+v", infraErr, nonMysqlErr)
 	}
 }
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}, true},
+		{&MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}, true},
+		{&MySQLError{Number: 1290, Message: "The MySQL server is running with the --read-only option"}, true},
+		{&MySQLError{Number: 1836, Message: "Running in read-only mode"}, true},
+		{&MySQLError{Number: 1180, SQLState: [5]byte{'4', '0', '0', '0', '1'}}, true},
+		{&MySQLError{Number: 1062, Message: "Duplicate entry"}, false},
+		{fmt.Errorf("insert: %w", &MySQLError{Number: 1213}), true},
+		{driver.ErrBadConn, true},
+		{ErrInvalidConn, true},
+		{&net.OpError{Op: "read", Err: syscall.ECONNRESET}, true},
+		{io.ErrUnexpectedEOF, true},
+		{context.Canceled, false},
+		{fmt.Errorf("dial: %w", context.DeadlineExceeded), false},
+		{ErrPktTooLarge, false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryableError(tt.err); got != tt.want {
+			t.Errorf("IsRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestMySQLErrorClasses(t *testing.T) {
+	err := fmt.Errorf("connect: %w", &MySQLError{Number: 1698, SQLState: [5]byte{'2', '8', '0', '0', '0'}})
+	if !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("expected %v to be an access denied error", err)
+	}
+	if errors.Is(err, ErrUnknownDatabase) {
+		t.Errorf("expected %v not to be an unknown database error", err)
+	}
+	var me *MySQLError
+	if !errors.As(err, &me) || me.State() != "28000" {
+		t.Errorf("expected SQLSTATE 28000, got %v", err)
+	}
+	if state := (&MySQLError{Number: 1049}).State(); state != "" {
+		t.Errorf("expected no SQLSTATE, got %q", state)
+	}
+}
+
+func TestMySQLErrorHelpers(t *testing.T) {
+	for _, tt := range []struct {
+		number uint16
+		is     func(error) bool
+	}{
+		{mysqlerrors.ER_ACCESS_DENIED_ERROR, IsAccessDenied},
+		{mysqlerrors.ER_BAD_DB_ERROR, IsUnknownDatabase},
+		{mysqlerrors.ER_NO_SUCH_TABLE, IsUnknownTable},
+		{mysqlerrors.ER_DUP_ENTRY, IsDuplicateEntry},
+		{mysqlerrors.ER_LOCK_DEADLOCK, IsDeadlock},
+		{mysqlerrors.ER_LOCK_WAIT_TIMEOUT, IsLockWaitTimeout},
+		{mysqlerrors.ER_READ_ONLY_MODE, IsReadOnly},
+	} {
+		err := fmt.Errorf("exec: %w", &MySQLError{Number: tt.number})
+		if !tt.is(err) {
+			t.Errorf("expected error %d to match", tt.number)
+		}
+		if tt.is(&MySQLError{Number: mysqlerrors.ER_PARSE_ERROR}) || tt.is(io.EOF) || tt.is(nil) {
+			t.Errorf("expected only error %d to match", tt.number)
+		}
+	}
+
+	err := fmt.Errorf("insert: %w", &MySQLError{Number: mysqlerrors.ER_NO_REFERENCED_ROW_2})
+	if !IsErrorNumber(err, mysqlerrors.ER_NO_REFERENCED_ROW, mysqlerrors.ER_NO_REFERENCED_ROW_2) {
+		t.Errorf("expected %v to match", err)
+	}
+	if IsErrorNumber(err, mysqlerrors.ER_ROW_IS_REFERENCED_2) || IsErrorNumber(io.EOF, mysqlerrors.ER_NO_REFERENCED_ROW_2) {
+		t.Errorf("expected %v not to match", err)
+	}
+}
+
+func TestAuthError(t *testing.T) {
+	errPacket := append([]byte{iERR, 0x15, 0x04, '#'}, "28000Access denied for user 'app'@'%' (using password: YES)"...)
+	c := newMockServerConnector(t, mockPacket(2, errPacket...))
+
+	_, err := c.Connect(context.Background())
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("expected an AuthError, got %T: %v", err, err)
+	}
+	if authErr.Plugin != "mysql_native_password" || authErr.TLS || authErr.Err.State() != "28000" {
+		t.Errorf("unexpected AuthError %+v", authErr)
+	}
+	if !IsAccessDenied(err) {
+		t.Error("expected the AuthError to unwrap to the access denied error")
+	}
+	want := "authentication with mysql_native_password without TLS failed: Error 1045 (28000): Access denied for user 'app'@'%' (using password: YES)"
+	if err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+}
@@ -10,10 +10,21 @@ package mysql
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"reflect"
+	"strings"
 )
 
 func (mf *mysqlField) typeDatabaseName() string {
+	// Types of MariaDB data type plugins are sent as strings or geometries
+	if mf.extFormat == "json" {
+		return "JSON"
+	}
+	if mf.extType != "" {
+		return strings.ToUpper(mf.extType)
+	}
+
 	switch mf.fieldType {
 	case fieldTypeBit:
 		return "BIT"
@@ -120,24 +131,28 @@ func (mf *mysqlField) typeDatabaseName() string {
 }
 
 var (
-	scanTypeFloat32    = reflect.TypeOf(float32(0))
-	scanTypeFloat64    = reflect.TypeOf(float64(0))
-	scanTypeInt8       = reflect.TypeOf(int8(0))
-	scanTypeInt16      = reflect.TypeOf(int16(0))
-	scanTypeInt32      = reflect.TypeOf(int32(0))
-	scanTypeInt64      = reflect.TypeOf(int64(0))
-	scanTypeNullFloat  = reflect.TypeOf(sql.NullFloat64{})
-	scanTypeNullInt    = reflect.TypeOf(sql.NullInt64{})
-	scanTypeNullUint   = reflect.TypeOf(sql.Null[uint64]{})
-	scanTypeNullTime   = reflect.TypeOf(sql.NullTime{})
-	scanTypeUint8      = reflect.TypeOf(uint8(0))
-	scanTypeUint16     = reflect.TypeOf(uint16(0))
-	scanTypeUint32     = reflect.TypeOf(uint32(0))
-	scanTypeUint64     = reflect.TypeOf(uint64(0))
-	scanTypeString     = reflect.TypeOf("")
-	scanTypeNullString = reflect.TypeOf(sql.NullString{})
-	scanTypeBytes      = reflect.TypeOf([]byte{})
-	scanTypeUnknown    = reflect.TypeOf(new(any))
+	scanTypeFloat32     = reflect.TypeOf(float32(0))
+	scanTypeFloat64     = reflect.TypeOf(float64(0))
+	scanTypeInt8        = reflect.TypeOf(int8(0))
+	scanTypeInt16       = reflect.TypeOf(int16(0))
+	scanTypeInt32       = reflect.TypeOf(int32(0))
+	scanTypeInt64       = reflect.TypeOf(int64(0))
+	scanTypeNullFloat   = reflect.TypeOf(sql.NullFloat64{})
+	scanTypeNullInt     = reflect.TypeOf(sql.NullInt64{})
+	scanTypeNullUint    = reflect.TypeOf(sql.Null[uint64]{})
+	scanTypeNullTime    = reflect.TypeOf(sql.NullTime{})
+	scanTypeUint8       = reflect.TypeOf(uint8(0))
+	scanTypeUint16      = reflect.TypeOf(uint16(0))
+	scanTypeUint32      = reflect.TypeOf(uint32(0))
+	scanTypeUint64      = reflect.TypeOf(uint64(0))
+	scanTypeString      = reflect.TypeOf("")
+	scanTypeNullString  = reflect.TypeOf(sql.NullString{})
+	scanTypeJSON        = reflect.TypeOf(json.RawMessage{})
+	scanTypeNullJSON    = reflect.TypeOf(sql.Null[json.RawMessage]{})
+	scanTypeDecimal     = reflect.TypeOf(Decimal(""))
+	scanTypeNullDecimal = reflect.TypeOf(sql.Null[Decimal]{})
+	scanTypeBytes       = reflect.TypeOf([]byte{})
+	scanTypeUnknown     = reflect.TypeOf(new(any))
 )
 
 type mysqlField struct {
@@ -148,6 +163,35 @@ type mysqlField struct {
 	fieldType fieldType
 	decimals  byte
 	charSet   uint8
+	asJSON    bool   // JSON column returned as json.RawMessage, see ParseJSON
+	asDecimal bool   // DECIMAL column returned as Decimal, see ParseDecimal
+	extType   string // MariaDB extended metadata type, e.g. "uuid" or "point"
+	extFormat string // MariaDB extended metadata format, e.g. "json"
+}
+
+// readExtendedMetadata reads the MariaDB extended metadata of a column
+// definition: a list of the kind of each entry [uint8] followed by its value
+// [len coded string].
+// https://mariadb.com/kb/en/result-set-packets/#column-definition-packet
+func (mf *mysqlField) readExtendedMetadata(data []byte) error {
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return ErrMalformPkt
+		}
+		kind := data[0]
+		value, _, n, err := readLengthEncodedString(data[1:])
+		if err != nil {
+			return err
+		}
+		data = data[1+n:]
+		switch kind {
+		case 0:
+			mf.extType = string(value)
+		case 1:
+			mf.extFormat = string(value)
+		}
+	}
+	return nil
 }
 
 func (mf *mysqlField) scanType() reflect.Type {
@@ -211,6 +255,18 @@ func (mf *mysqlField) scanType() reflect.Type {
 		fallthrough
 	case fieldTypeDecimal, fieldTypeNewDecimal, fieldTypeVarChar,
 		fieldTypeEnum, fieldTypeSet, fieldTypeJSON, fieldTypeTime:
+		if mf.asDecimal {
+			if mf.flags&flagNotNULL != 0 {
+				return scanTypeDecimal
+			}
+			return scanTypeNullDecimal
+		}
+		if mf.asJSON {
+			if mf.flags&flagNotNULL != 0 {
+				return scanTypeJSON
+			}
+			return scanTypeNullJSON
+		}
 		if mf.flags&flagNotNULL != 0 {
 			return scanTypeString
 		}
@@ -226,3 +282,96 @@ func (mf *mysqlField) scanType() reflect.Type {
 		return scanTypeUnknown
 	}
 }
+
+// ColumnMetadata is the metadata MySQL sends for a column of a result set.
+type ColumnMetadata struct {
+	Table         string // alias of the table, if any
+	Name          string // alias of the column
+	Type          string // like DatabaseTypeName of sql.ColumnType, e.g. "UNSIGNED INT"
+	Collation     string // "binary" for binary strings, "" if unknown
+	Length        uint32 // max length of the values in bytes
+	Decimals      uint8
+	NotNull       bool
+	PrimaryKey    bool
+	UniqueKey     bool
+	MultipleKey   bool
+	Unsigned      bool
+	ZeroFill      bool
+	Binary        bool
+	AutoIncrement bool
+}
+
+// ColumnMetadataRows is implemented by the rows of the driver. database/sql
+// doesn't expose them, so query through sql.Conn.Raw to use it:
+//
+//	err := conn.Raw(func(dc any) error {
+//		rows, err := dc.(driver.QueryerContext).QueryContext(ctx, "SELECT * FROM t", nil)
+//		if err != nil {
+//			return err
+//		}
+//		defer rows.Close()
+//		md := rows.(mysql.ColumnMetadataRows).ColumnMetadata(0)
+//		...
+//	})
+type ColumnMetadataRows interface {
+	driver.Rows
+	ColumnMetadata(i int) ColumnMetadata
+}
+
+// collationNames maps the ids of the collations map to their names.
+var collationNames = func() map[byte]string {
+	names := make(map[byte]string, len(collations))
+	for name, id := range collations {
+		names[id] = name
+	}
+	return names
+}()
+
+// charsetMaxLens are the max lengths of a character in bytes of multi-byte
+// charsets.
+var charsetMaxLens = map[string]int64{
+	"big5":    2,
+	"cp932":   2,
+	"eucjpms": 3,
+	"euckr":   2,
+	"gb18030": 4,
+	"gb2312":  2,
+	"gbk":     2,
+	"sjis":    2,
+	"ucs2":    2,
+	"ujis":    3,
+	"utf16":   4,
+	"utf16le": 4,
+	"utf32":   4,
+	"utf8":    3,
+	"utf8mb3": 3,
+	"utf8mb4": 4,
+}
+
+// charsetMaxLen returns the max length of a character of the column in bytes.
+func (mf *mysqlField) charsetMaxLen() int64 {
+	cs, _, _ := strings.Cut(collationNames[mf.charSet], "_")
+	if n, ok := charsetMaxLens[cs]; ok {
+		return n
+	}
+	return 1
+}
+
+func (mf *mysqlField) metadata() ColumnMetadata {
+	return ColumnMetadata{
+		Table:         mf.tableName,
+		Name:          mf.name,
+		Type:          mf.typeDatabaseName(),
+		Collation:     collationNames[mf.charSet],
+		Length:        mf.length,
+		Decimals:      mf.decimals,
+		NotNull:       mf.flags&flagNotNULL != 0,
+		PrimaryKey:    mf.flags&flagPriKey != 0,
+		UniqueKey:     mf.flags&flagUniqueKey != 0,
+		MultipleKey:   mf.flags&flagMultipleKey != 0,
+		Unsigned:      mf.flags&flagUnsigned != 0,
+		ZeroFill:      mf.flags&flagZeroFill != 0,
+		Binary:        mf.flags&flagBinary != 0,
+		AutoIncrement: mf.flags&flagAutoIncrement != 0,
+	}
+}
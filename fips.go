@@ -0,0 +1,71 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import "fmt"
+
+// FIPS restricts authentication to plugins computing the auth response with
+// FIPS-approved primitives, for regulated environments:
+//
+//   - mysql_old_password, mysql_native_password (SHA-1 based) and
+//     client_ed25519 are refused.
+//   - passwords are never encrypted with the RSA public key of the server,
+//     which the server requires to be done with RSA-OAEP and SHA-1; full
+//     authentication of caching_sha2_password and sha256_password requires
+//     TLS or a unix socket.
+//   - caching_sha2_password is used instead of mysql_native_password when the
+//     server doesn't announce a plugin or the announced one can't be used.
+//
+// Authentication fails with an error wrapping ErrFIPSAuth when the server
+// demands a plugin which isn't allowed. Building with the fips build tag
+// enables FIPS mode for all connections.
+func FIPS(yes bool) Option {
+	return func(cfg *Config) error {
+		cfg.fips = yes
+		return nil
+	}
+}
+
+// fipsMode reports whether authentication is restricted to FIPS-approved
+// primitives, by the fips build tag or the FIPS option.
+func (cfg *Config) fipsMode() bool {
+	return fipsBuild || cfg.fips
+}
+
+// defaultAuthPlugin returns the plugin used when the server doesn't announce
+// one or the announced one can't be used.
+func (cfg *Config) defaultAuthPlugin() string {
+	if cfg.fipsMode() {
+		return "caching_sha2_password"
+	}
+	return defaultAuthPlugin
+}
+
+// checkFIPSPlugin returns an error wrapping ErrFIPSAuth if the plugin isn't
+// allowed in FIPS mode.
+func (mc *mysqlConn) checkFIPSPlugin(plugin string) error {
+	if !mc.cfg.fipsMode() {
+		return nil
+	}
+	switch plugin {
+	case "mysql_old_password", "mysql_native_password", "client_ed25519":
+		return fmt.Errorf("%w: %s", ErrFIPSAuth, plugin)
+	}
+	return nil
+}
+
+// checkFIPSTransport returns an error wrapping ErrFIPSAuth if the password of
+// the plugin would have to be encrypted with the RSA public key of the server
+// in FIPS mode.
+func (mc *mysqlConn) checkFIPSTransport(plugin string) error {
+	if mc.cfg.fipsMode() && mc.cfg.TLS == nil && mc.cfg.Net != "unix" {
+		return fmt.Errorf("%w: %s requires TLS or a unix socket", ErrFIPSAuth, plugin)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Geometry is the value of a spatial column. MySQL stores and returns them
+// in its internal format, the SRID as 4 bytes in little-endian order followed
+// by the WKB (well-known binary) of the geometry.
+//
+// Passed as an argument, it is sent in the internal format, which can be
+// assigned to spatial columns directly:
+//
+//	db.Exec("INSERT INTO places (pos) VALUES (?)", mysql.Geometry{SRID: 4326, WKB: wkb})
+//
+// NULL is not supported, use sql.Null[Geometry] for nullable columns.
+type Geometry struct {
+	SRID uint32
+	WKB  []byte
+}
+
+// geometry types of WKB
+const (
+	GeometryPoint              uint32 = 1
+	GeometryLineString         uint32 = 2
+	GeometryPolygon            uint32 = 3
+	GeometryMultiPoint         uint32 = 4
+	GeometryMultiLineString    uint32 = 5
+	GeometryMultiPolygon       uint32 = 6
+	GeometryGeometryCollection uint32 = 7
+)
+
+var errInvalidWKB = errors.New("mysql: invalid WKB")
+
+// Scan implements sql.Scanner. The WKB is a copy of src.
+func (g *Geometry) Scan(src any) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("mysql: cannot scan %T into Geometry", src)
+	}
+	if len(b) < 4 {
+		return fmt.Errorf("mysql: %d bytes are too short for a geometry", len(b))
+	}
+	wkb := b[4:]
+	if _, err := wkbType(wkb); err != nil {
+		return err
+	}
+	g.SRID = binary.LittleEndian.Uint32(b)
+	g.WKB = bytes.Clone(wkb)
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (g Geometry) Value() (driver.Value, error) {
+	if _, err := wkbType(g.WKB); err != nil {
+		return nil, err
+	}
+	b := make([]byte, 4, 4+len(g.WKB))
+	binary.LittleEndian.PutUint32(b, g.SRID)
+	return append(b, g.WKB...), nil
+}
+
+// Type returns the geometry type of the WKB, e.g. GeometryPoint.
+func (g Geometry) Type() (uint32, error) {
+	return wkbType(g.WKB)
+}
+
+// wkbType returns the geometry type in the header of wkb, a byte order
+// followed by the type as 4 bytes.
+func wkbType(wkb []byte) (uint32, error) {
+	if len(wkb) < 5 {
+		return 0, errInvalidWKB
+	}
+	switch wkb[0] {
+	case 0:
+		return binary.BigEndian.Uint32(wkb[1:5]), nil
+	case 1:
+		return binary.LittleEndian.Uint32(wkb[1:5]), nil
+	}
+	return 0, errInvalidWKB
+}
@@ -0,0 +1,78 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+)
+
+// POINT(1 2) with SRID 4326, as returned by MySQL
+var mockPoint = []byte{
+	0xe6, 0x10, 0x00, 0x00, // SRID
+	0x01, 0x01, 0x00, 0x00, 0x00, // little-endian point
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf0, 0x3f, // 1.0
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, // 2.0
+}
+
+func TestGeometryScan(t *testing.T) {
+	src := bytes.Clone(mockPoint)
+	var g Geometry
+	if err := g.Scan(src); err != nil {
+		t.Fatal(err)
+	}
+	if g.SRID != 4326 {
+		t.Errorf("expected SRID 4326, got %d", g.SRID)
+	}
+	if !bytes.Equal(g.WKB, mockPoint[4:]) {
+		t.Errorf("unexpected WKB %x", g.WKB)
+	}
+	if typ, err := g.Type(); err != nil || typ != GeometryPoint {
+		t.Errorf("expected a point, got %d, %v", typ, err)
+	}
+	src[4] = 0xff
+	if g.WKB[0] != 0x01 {
+		t.Error("expected the WKB to be a copy")
+	}
+
+	v, err := converter{}.ConvertValue(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := v.([]byte); !ok || !bytes.Equal(b, mockPoint) {
+		t.Errorf("expected the internal format %x, got %#v", mockPoint, v)
+	}
+
+	// big-endian WKB
+	var be Geometry
+	if err := be.Scan([]byte{0, 0, 0, 0, 0, 0, 0, 0, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if typ, _ := be.Type(); typ != GeometryPolygon {
+		t.Errorf("expected a polygon, got %d", typ)
+	}
+}
+
+func TestGeometryInvalid(t *testing.T) {
+	var g Geometry
+	for _, src := range []any{nil, "x", []byte{1, 2, 3}, []byte{0, 0, 0, 0, 1}, []byte{0, 0, 0, 0, 2, 1, 0, 0, 0}} {
+		if err := g.Scan(src); err == nil {
+			t.Errorf("Scan(%#v): expected an error", src)
+		}
+	}
+	if _, err := (Geometry{WKB: []byte{1}}).Value(); err == nil {
+		t.Error("expected an error for invalid WKB")
+	}
+
+	var null sql.Null[Geometry]
+	if err := null.Scan(nil); err != nil || null.Valid {
+		t.Errorf("expected NULL, got %+v, %v", null, err)
+	}
+}
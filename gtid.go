@@ -0,0 +1,75 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+)
+
+type gtidCtxKey struct{}
+
+type gtidTracker struct {
+	mu   sync.Mutex
+	gtid string
+}
+
+// GTIDResult exposes the GTIDs of a write. It is implemented by the results
+// of the driver, like Result:
+//
+//	res, err := rawConn.Exec(...)
+//	res.(mysql.GTIDResult).LastGTID()
+type GTIDResult interface {
+	driver.Result
+	// LastGTID returns the GTIDs reported by the server through session
+	// tracking (see session_track_gtids), or an empty string.
+	LastGTID() string
+}
+
+// WithGTIDTracking returns a copy of ctx which records the GTIDs of the writes
+// executed with it, including the commits of transactions begun with it.
+// Read them with LastGTID.
+//
+// The server reports GTIDs only if session_track_gtids is set to OWN_GTID or
+// ALL_GTIDS. The recorded GTIDs allow reading your own writes on replicas:
+//
+//	ctx = mysql.WithGTIDTracking(ctx)
+//	_, err := db.ExecContext(ctx, "INSERT INTO orders ...")
+//	...
+//	_, err = replica.ExecContext(ctx, "SELECT WAIT_FOR_EXECUTED_GTID_SET(?, 1)", mysql.LastGTID(ctx))
+func WithGTIDTracking(ctx context.Context) context.Context {
+	return context.WithValue(ctx, gtidCtxKey{}, &gtidTracker{})
+}
+
+// LastGTID returns the GTIDs reported by the server for the last write
+// executed with ctx, which must be derived from WithGTIDTracking. It returns
+// an empty string if no GTIDs were reported.
+func LastGTID(ctx context.Context) string {
+	t, ok := ctx.Value(gtidCtxKey{}).(*gtidTracker)
+	if !ok {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.gtid
+}
+
+// recordGTID stores the GTIDs reported with res in the tracker of ctx.
+func recordGTID(ctx context.Context, res driver.Result) {
+	t, ok := ctx.Value(gtidCtxKey{}).(*gtidTracker)
+	if !ok {
+		return
+	}
+	if r, ok := res.(*mysqlResult); ok && r.lastGTID != "" {
+		t.mu.Lock()
+		t.gtid = r.lastGTID
+		t.mu.Unlock()
+	}
+}
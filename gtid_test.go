@@ -0,0 +1,69 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestGTIDTracking(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.capabilities |= clientSessionTrack
+
+	changes := append([]byte{sessionTrackGTIDs}, appendLengthEncodedString(nil, "\x00\x06uuid:7")...)
+	conn.queuedReplies = [][]byte{sessionStateOkPacket(changes), okPacket}
+
+	ctx := WithGTIDTracking(context.Background())
+	res, err := mc.ExecContext(ctx, "INSERT INTO t VALUES (1)", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gtid := res.(GTIDResult).LastGTID(); gtid != "uuid:7" {
+		t.Errorf("expected result GTID uuid:7, got %q", gtid)
+	}
+	if gtid := LastGTID(ctx); gtid != "uuid:7" {
+		t.Errorf("expected tracked GTID uuid:7, got %q", gtid)
+	}
+
+	// writes without reported GTIDs keep the last one
+	if _, err := mc.ExecContext(ctx, "DO 1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if gtid := LastGTID(ctx); gtid != "uuid:7" {
+		t.Errorf("expected tracked GTID to be kept, got %q", gtid)
+	}
+
+	if gtid := LastGTID(context.Background()); gtid != "" {
+		t.Errorf("expected no GTID without tracking, got %q", gtid)
+	}
+}
+
+func TestGTIDTrackingCommit(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.capabilities |= clientSessionTrack
+
+	ctx := WithGTIDTracking(context.Background())
+	conn.queuedReplies = [][]byte{okPacket}
+	tx, err := mc.BeginTx(ctx, driver.TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the GTID is reported when the transaction commits
+	changes := append([]byte{sessionTrackGTIDs}, appendLengthEncodedString(nil, "\x00\x06uuid:8")...)
+	conn.queuedReplies = [][]byte{sessionStateOkPacket(changes)}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if gtid := LastGTID(ctx); gtid != "uuid:8" {
+		t.Errorf("expected tracked GTID uuid:8, got %q", gtid)
+	}
+}
@@ -0,0 +1,99 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// PingStats describes the last successful Ping of a connection. It is
+// accessible through sql.Conn.Raw():
+//
+//	err := conn.PingContext(ctx)
+//	...
+//	err = conn.Raw(func(driverConn any) error {
+//		stats := driverConn.(interface{ PingStats() mysql.PingStats }).PingStats()
+//		...
+//	})
+type PingStats struct {
+	Time time.Time     // when the ping was sent, zero if the connection was never pinged
+	RTT  time.Duration // round trip time of the ping
+}
+
+// PingStats returns the stats of the last successful Ping.
+func (mc *mysqlConn) PingStats() PingStats {
+	return mc.lastPing
+}
+
+// HealthCheckResult is the result of a HealthCheck of a Connector.
+type HealthCheckResult struct {
+	Server      ServerInfo    // server of the checked connection
+	AuthPlugin  string        // auth plugin the connection authenticated with
+	TLS         bool          // whether the connection is encrypted with TLS
+	ConnectTime time.Duration // time to establish the connection, including authentication
+	QueryTime   time.Duration // round trip time of SELECT 1
+}
+
+// HealthCheck establishes a new connection, authenticating like Connect,
+// e.g. with a fresh OIDC token, runs SELECT 1 on it and closes it. It is
+// meant for readiness probes, which should not depend on the idle
+// connections of a pool. The result describes the steps up to the failure,
+// if any.
+//
+// HealthCheck is a method of the driver.Connector returned by NewConnector:
+//
+//	res, err := connector.(interface {
+//		HealthCheck(context.Context) (mysql.HealthCheckResult, error)
+//	}).HealthCheck(ctx)
+func (c *connector) HealthCheck(ctx context.Context) (res HealthCheckResult, err error) {
+	start := time.Now()
+	dc, err := c.establish(ctx)
+	res.ConnectTime = time.Since(start)
+	if err != nil {
+		return res, err
+	}
+	mc := dc.(*mysqlConn)
+	defer mc.Close()
+
+	info := mc.connInfo()
+	res.Server = mc.ServerInfo()
+	res.AuthPlugin = info.AuthPlugin
+	res.TLS = info.TLS
+
+	start = time.Now()
+	err = mc.selectOne(ctx)
+	res.QueryTime = time.Since(start)
+	return res, err
+}
+
+// selectOne runs SELECT 1 and checks its result.
+func (mc *mysqlConn) selectOne(ctx context.Context) error {
+	rows, err := mc.QueryContext(ctx, "SELECT 1", nil)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	dest := make([]driver.Value, len(rows.Columns()))
+	if err := rows.Next(dest); err != nil {
+		if err == io.EOF {
+			err = errors.New("mysql: no rows returned by SELECT 1")
+		}
+		return err
+	}
+	if len(dest) != 1 || outString(dest[0]) != "1" {
+		return fmt.Errorf("mysql: unexpected result of SELECT 1: %v", dest)
+	}
+	return rows.Close()
+}
@@ -0,0 +1,93 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// selectOneReply answers SELECT 1 with its result set and other commands
+// with an OK packet.
+func selectOneReply(value byte) func(cmd []byte) []byte {
+	return func(cmd []byte) []byte {
+		if cmd[0] != comQuery || string(cmd[1:]) != "SELECT 1" {
+			return mockPacket(1, iOK, 0, 0, 2, 0, 0, 0)
+		}
+		return bytes.Join([][]byte{
+			mockPacket(1, 1),
+			mockColumn(2),
+			mockEOF(3, statusInAutocommit),
+			mockPacket(4, 1, value),
+			mockEOF(5, statusInAutocommit),
+		}, nil)
+	}
+}
+
+func TestPingStats(t *testing.T) {
+	c := newMockServerConnector(t, mockPacket(2, iOK, 0, 0, 2, 0, 0, 0))
+	conn, err := c.Connect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	mc := conn.(*mysqlConn)
+
+	if stats := mc.PingStats(); !stats.Time.IsZero() {
+		t.Errorf("expected no ping stats before Ping, got %+v", stats)
+	}
+	before := time.Now()
+	if err := mc.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	stats := mc.PingStats()
+	if stats.Time.Before(before) || stats.RTT <= 0 || stats.RTT > time.Since(before) {
+		t.Errorf("unexpected ping stats %+v", stats)
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	c := newMockServerConnectorReply(t, mockPacket(2, iOK, 0, 0, 2, 0, 0, 0), selectOneReply('1'))
+	res, err := c.HealthCheck(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Server.Version != "8.0.36" || res.Server.ThreadID != 42 || res.AuthPlugin != "mysql_native_password" || res.TLS {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if res.ConnectTime <= 0 || res.QueryTime <= 0 {
+		t.Errorf("expected the steps to be timed, got %+v", res)
+	}
+}
+
+func TestHealthCheckUnexpectedResult(t *testing.T) {
+	c := newMockServerConnectorReply(t, mockPacket(2, iOK, 0, 0, 2, 0, 0, 0), selectOneReply('2'))
+	res, err := c.HealthCheck(context.Background())
+	if err == nil {
+		t.Fatal("expected an error for an unexpected result")
+	}
+	if res.Server.Version != "8.0.36" {
+		t.Errorf("expected the server info of the connection, got %+v", res)
+	}
+}
+
+func TestHealthCheckAuthFailure(t *testing.T) {
+	errPacket := append([]byte{iERR, 0x15, 0x04, '#'}, "28000Access denied"...)
+	c := newMockServerConnectorReply(t, mockPacket(2, errPacket...), nil)
+	res, err := c.HealthCheck(context.Background())
+	if !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("expected ErrAccessDenied, got %v", err)
+	}
+	if res.ConnectTime <= 0 || res.QueryTime != 0 {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
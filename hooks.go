@@ -0,0 +1,193 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// HookOp identifies the operation reported to a Hook.
+type HookOp string
+
+const (
+	HookConnect HookOp = "connect" // establishing a connection, from dialing to the ready session
+	HookDial    HookOp = "dial"    // dialing the server
+	HookTLS     HookOp = "tls"     // TLS handshake
+	HookAuth    HookOp = "auth"    // sending the handshake response and authenticating
+	HookQuery   HookOp = "query"   // running a query until its result set header is read
+	HookExec    HookOp = "exec"    // running a statement not returning rows
+	HookPrepare HookOp = "prepare" // preparing a statement
+	HookFetch   HookOp = "fetch"   // reading the rows of a query until they are closed
+	HookPing    HookOp = "ping"    // pinging the server
+)
+
+// HookInfo describes an operation reported to a Hook.
+type HookInfo struct {
+	Op       HookOp
+	Addr     string // address of the server
+	ConnID   uint64 // sequence number of the connection assigned by the driver
+	ThreadID uint32 // connection id assigned by the server, 0 before the handshake
+	Query    string // query of HookQuery, HookExec, HookPrepare and HookFetch
+
+	// set before After is called
+	AuthPlugin   string // plugin the connection authenticated with, for HookAuth
+	TLSResumed   bool   // whether a cached TLS session was resumed, for HookTLS
+	RowsAffected int64  // for HookExec
+	Rows         int64  // number of rows read, for HookFetch
+}
+
+// Hook receives the operations of the connections of a Connector, e.g. to
+// record them as trace spans. Set it with the Hooks option.
+//
+// Before is called when an operation starts. The context it returns is
+// passed to the After call of the operation and is the parent context of
+// nested operations: HookDial, HookTLS and HookAuth are nested in
+// HookConnect, HookFetch follows HookQuery in the context of the query.
+// A tracer would start a span in Before and end it in After:
+//
+//	func (t tracer) Before(ctx context.Context, info *mysql.HookInfo) context.Context {
+//		ctx, _ = t.Start(ctx, "mysql."+string(info.Op))
+//		return ctx
+//	}
+//
+//	func (t tracer) After(ctx context.Context, info *mysql.HookInfo, err error) {
+//		span := trace.SpanFromContext(ctx)
+//		if err != nil {
+//			span.RecordError(err)
+//		}
+//		span.End()
+//	}
+//
+// The driver doesn't provide an OpenTelemetry adapter, as it would make
+// OpenTelemetry a dependency of all users of the driver; a tracer like the
+// one above is all an adapter takes.
+//
+// Hooks are called synchronously on the goroutine using the connection and
+// must not use the connection.
+type Hook interface {
+	Before(ctx context.Context, info *HookInfo) context.Context
+	After(ctx context.Context, info *HookInfo, err error)
+}
+
+// Hooks sets the hooks receiving the operations of the connections. Before
+// is called in the order of hooks, After in the reverse order.
+func Hooks(hooks ...Hook) Option {
+	return func(cfg *Config) error {
+		cfg.hooks = hooks
+		return nil
+	}
+}
+
+// hookSpan is an operation reported to the hooks of a connection. A nil
+// *hookSpan is valid and ignores end.
+type hookSpan struct {
+	mc         *mysqlConn
+	hooks      []Hook
+	stats      StatsCollector
+	start      time.Time
+	queryStart time.Time // start of the query, for HookFetch
+	ctx        context.Context
+	info       HookInfo
+}
+
+// startHook reports the start of an operation to the hooks of the
+// connection. It returns the context for the operation and the span to end
+// when it is done, nil if there are neither hooks nor a StatsCollector nor
+// error interceptors and neither slow queries, warnings nor the statements of
+// errors are reported.
+func (mc *mysqlConn) startHook(ctx context.Context, info HookInfo) (context.Context, *hookSpan) {
+	if len(mc.cfg.hooks) == 0 && mc.cfg.statsCollector == nil && len(mc.cfg.errorInterceptors) == 0 &&
+		mc.cfg.slowQueryThreshold <= 0 && mc.cfg.warningsFunc == nil && !mc.cfg.errorStatement {
+		return ctx, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	info.Addr = mc.cfg.Addr
+	info.ConnID = mc.id
+	info.ThreadID = mc.threadID
+
+	s := &hookSpan{mc: mc, hooks: mc.cfg.hooks, stats: mc.cfg.statsCollector, start: time.Now(), info: info}
+	for _, h := range s.hooks {
+		ctx = h.Before(ctx, &s.info)
+	}
+	s.ctx = ctx
+	return ctx, s
+}
+
+// startFetchHook starts the HookFetch span of the rows of the query reported
+// with querySpan.
+func (mc *mysqlConn) startFetchHook(ctx context.Context, querySpan *hookSpan, query string) *hookSpan {
+	_, s := mc.startHook(ctx, HookInfo{Op: HookFetch, Query: query})
+	if s != nil && querySpan != nil {
+		s.queryStart = querySpan.start
+	}
+	return s
+}
+
+// execHooked executes query, a statement run for database/sql rather than
+// the application, e.g. COMMIT, as a HookExec operation.
+func (mc *mysqlConn) execHooked(ctx context.Context, query string) error {
+	_, span := mc.startHook(ctx, HookInfo{Op: HookExec, Query: query})
+	return span.end(mc.exec(query))
+}
+
+// endExec reports the end of a HookExec operation with the result res.
+func (s *hookSpan) endExec(res driver.Result, err error) error {
+	if s != nil && err == nil {
+		s.info.RowsAffected, _ = res.RowsAffected()
+	}
+	return s.end(err)
+}
+
+// end reports the end of the operation. It returns err, translated by the
+// error interceptors unless the operation is nested in HookConnect.
+func (s *hookSpan) end(err error) error {
+	if s == nil {
+		return err
+	}
+	switch s.info.Op {
+	case HookDial, HookTLS, HookAuth:
+	default:
+		err = s.intercept(err)
+	}
+	for i := len(s.hooks) - 1; i >= 0; i-- {
+		s.hooks[i].After(s.ctx, &s.info, err)
+	}
+	if s.stats != nil {
+		s.stats.OpDone(&s.info, time.Since(s.start), err)
+	}
+	switch s.info.Op {
+	case HookExec:
+		s.mc.reportSlowQuery(s.ctx, s.info.Query, s.start, s.info.RowsAffected, err)
+		s.mc.reportWarnings(s.ctx, s.info.Query, err)
+	case HookFetch:
+		s.mc.reportSlowQuery(s.ctx, s.info.Query, s.queryStart, s.info.Rows, err)
+		s.mc.reportWarnings(s.ctx, s.info.Query, err)
+	}
+	return err
+}
+
+// intercept records the statement of a *MySQLError and passes err through
+// the error interceptors of the connection.
+func (s *hookSpan) intercept(err error) error {
+	if s == nil || err == nil {
+		return err
+	}
+	if s.mc.cfg.errorStatement && s.info.Query != "" {
+		var me *MySQLError
+		if errors.As(err, &me) && me.Statement == "" {
+			me.Statement = redactQuery(s.info.Query)
+		}
+	}
+	return s.mc.interceptError(s.ctx, &s.info, err)
+}
@@ -0,0 +1,118 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+)
+
+type hookCtxKey struct{}
+
+type recordingHook struct {
+	name   string
+	events *[]string
+}
+
+func (h recordingHook) Before(ctx context.Context, info *HookInfo) context.Context {
+	*h.events = append(*h.events, fmt.Sprintf("%s before %s %q", h.name, info.Op, info.Query))
+	return context.WithValue(ctx, hookCtxKey{}, info.Op)
+}
+
+func (h recordingHook) After(ctx context.Context, info *HookInfo, err error) {
+	if op := ctx.Value(hookCtxKey{}); op != info.Op {
+		*h.events = append(*h.events, fmt.Sprintf("%s unexpected context of %s: %v", h.name, info.Op, op))
+	}
+	*h.events = append(*h.events, fmt.Sprintf("%s after %s rows=%d affected=%d err=%v",
+		h.name, info.Op, info.Rows, info.RowsAffected, err))
+}
+
+func TestHooksQuery(t *testing.T) {
+	var events []string
+	conn, mc := newRWMockConn(0)
+	mc.cfg.hooks = []Hook{recordingHook{"a", &events}, recordingHook{"b", &events}}
+
+	// OK packet with 3 affected rows
+	conn.data = []byte{7, 0, 0, 1, iOK, 3, 0, 2, 0, 0, 0}
+	if _, err := mc.ExecContext(context.Background(), "DELETE FROM t", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// result set with one column and two rows
+	conn.data = []byte{
+		1, 0, 0, 1, 1, // column count
+		0x17, 0, 0, 2, 3, 'd', 'e', 'f', 0, 0, 0, 1, 'a', 0, 0x0c, 0x21, 0, 1, 0, 0, 0, 0xfd, 0, 0, 0, 0, 0, // column
+		5, 0, 0, 3, iEOF, 0, 0, 2, 0,
+		2, 0, 0, 4, 1, '1',
+		2, 0, 0, 5, 1, '2',
+		5, 0, 0, 6, iEOF, 0, 0, 2, 0,
+	}
+	rows, err := mc.QueryContext(context.Background(), "SELECT a FROM t", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest := make([]driver.Value, 1)
+	for rows.Next(dest) == nil {
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`a before exec "DELETE FROM t"`,
+		`b before exec "DELETE FROM t"`,
+		`b after exec rows=0 affected=3 err=<nil>`,
+		`a after exec rows=0 affected=3 err=<nil>`,
+		`a before query "SELECT a FROM t"`,
+		`b before query "SELECT a FROM t"`,
+		`b after query rows=0 affected=0 err=<nil>`,
+		`a after query rows=0 affected=0 err=<nil>`,
+		`a before fetch "SELECT a FROM t"`,
+		`b before fetch "SELECT a FROM t"`,
+		`b after fetch rows=2 affected=0 err=<nil>`,
+		`a after fetch rows=2 affected=0 err=<nil>`,
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events\n%q\ngot\n%q", expected, events)
+	}
+}
+
+func TestHooksConnect(t *testing.T) {
+	var events []string
+	cfg := NewConfig()
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: io.EOF}
+	cfg.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, dialErr
+	}
+	if err := cfg.Apply(Hooks(recordingHook{"a", &events})); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.normalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newConnector(cfg).Connect(context.Background()); !errors.Is(err, dialErr) {
+		t.Fatalf("expected dial error, got %v", err)
+	}
+	expected := []string{
+		`a before connect ""`,
+		`a before dial ""`,
+		fmt.Sprintf(`a after dial rows=0 affected=0 err=%v`, dialErr),
+		fmt.Sprintf(`a after connect rows=0 affected=0 err=%v`, dialErr),
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events\n%q\ngot\n%q", expected, events)
+	}
+}
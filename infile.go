@@ -9,17 +9,19 @@
 package mysql
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 	"sync"
+	"time"
 )
 
 var (
 	fileRegister       map[string]struct{}
 	fileRegisterLock   sync.RWMutex
-	readerRegister     map[string]func() io.Reader
+	readerRegister     map[string]func(context.Context) io.Reader
 	readerRegisterLock sync.RWMutex
 )
 
@@ -66,16 +68,59 @@ func DeregisterLocalFile(filePath string) {
 //	if err != nil {
 //	...
 func RegisterReaderHandler(name string, handler func() io.Reader) {
+	RegisterReaderHandlerContext(name, func(context.Context) io.Reader {
+		return handler()
+	})
+}
+
+// RegisterReaderHandlerContext is like RegisterReaderHandler, but the handler
+// receives the context of the query, bounded by the localInfileTimeout DSN
+// parameter. Readers should stop and return an error once it is done.
+//
+//	mysql.RegisterReaderHandlerContext("data", func(ctx context.Context) io.Reader {
+//		req, _ := http.NewRequestWithContext(ctx, "GET", csvURL, nil)
+//		...
+//		return resp.Body
+//	})
+func RegisterReaderHandlerContext(name string, handler func(ctx context.Context) io.Reader) {
 	readerRegisterLock.Lock()
 	// lazy map init
 	if readerRegister == nil {
-		readerRegister = make(map[string]func() io.Reader)
+		readerRegister = make(map[string]func(context.Context) io.Reader)
 	}
 
 	readerRegister[name] = handler
 	readerRegisterLock.Unlock()
 }
 
+// ReaderHandler registers a reader handler for the connections of a single
+// connector. It takes precedence over handlers registered globally with
+// RegisterReaderHandlerContext under the same name.
+func ReaderHandler(name string, handler func(ctx context.Context) io.Reader) Option {
+	return func(cfg *Config) error {
+		if cfg.readerHandlers == nil {
+			cfg.readerHandlers = make(map[string]func(context.Context) io.Reader)
+		}
+		cfg.readerHandlers[name] = handler
+		return nil
+	}
+}
+
+// LocalInfileLimits bounds the data sent for LOAD DATA LOCAL INFILE requests
+// to maxBytes bytes and timeout, protecting against servers requesting
+// arbitrary amounts of data. Zero values disable the respective limit.
+//
+// A request exceeding a limit fails and closes the connection, so that the
+// server aborts the statement instead of loading partial data. So do errors
+// of the reader or file other than io.EOF.
+func LocalInfileLimits(maxBytes int64, timeout time.Duration) Option {
+	return func(cfg *Config) error {
+		cfg.localInfileMaxBytes = maxBytes
+		cfg.localInfileTimeout = timeout
+		return nil
+	}
+}
+
 // DeregisterReaderHandler removes the ReaderHandler function with
 // the given name from the registry.
 func DeregisterReaderHandler(name string) {
@@ -96,17 +141,31 @@ const defaultPacketSize = 16 * 1024 // 16KB is small enough for disk readahead a
 func (mc *okHandler) handleInFileRequest(name string) (err error) {
 	var rdr io.Reader
 	packetSize := min(mc.maxWriteSize, defaultPacketSize)
+	maxBytes := mc.cfg.localInfileMaxBytes
+
+	ctx := mc.queryCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if mc.cfg.localInfileTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, mc.cfg.localInfileTimeout)
+		defer cancel()
+	}
 
 	if idx := strings.Index(name, "Reader::"); idx == 0 || (idx > 0 && name[idx-1] == '/') { // io.Reader
 		// The server might return an an absolute path. See issue #355.
 		name = name[idx+8:]
 
-		readerRegisterLock.RLock()
-		handler, inMap := readerRegister[name]
-		readerRegisterLock.RUnlock()
+		handler, inMap := mc.cfg.readerHandlers[name]
+		if !inMap {
+			readerRegisterLock.RLock()
+			handler, inMap = readerRegister[name]
+			readerRegisterLock.RUnlock()
+		}
 
 		if inMap {
-			rdr = handler()
+			rdr = handler(ctx)
 			if rdr != nil {
 				if cl, ok := rdr.(io.Closer); ok {
 					defer deferredClose(&err, cl)
@@ -132,7 +191,9 @@ func (mc *okHandler) handleInFileRequest(name string) (err error) {
 				// get file size
 				if fi, err = file.Stat(); err == nil {
 					rdr = file
-					if fileSize := int(fi.Size()); fileSize < packetSize {
+					if maxBytes > 0 && fi.Size() > maxBytes {
+						err = fmt.Errorf("local file '%s': %w", name, ErrLocalInfileTooLarge)
+					} else if fileSize := int(fi.Size()); fileSize < packetSize {
 						packetSize = fileSize
 					}
 				}
@@ -149,17 +210,31 @@ func (mc *okHandler) handleInFileRequest(name string) (err error) {
 	if err == nil && packetSize > 0 {
 		data = make([]byte, 4+packetSize)
 		var n int
+		var sent int64
 		for err == nil {
 			n, err = rdr.Read(data[4:])
+			// Abort by closing the connection; the server would load the
+			// data sent so far when terminated regularly.
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				mc.conn().cleanup()
+				return ctxErr
+			}
 			if n > 0 {
+				sent += int64(n)
+				if maxBytes > 0 && sent > maxBytes {
+					mc.conn().cleanup()
+					return fmt.Errorf("reader '%s': %w", name, ErrLocalInfileTooLarge)
+				}
 				if ioErr := mc.conn().writePacket(data[:4+n]); ioErr != nil {
 					return ioErr
 				}
 			}
 		}
-		if err == io.EOF {
-			err = nil
+		if err != io.EOF {
+			mc.conn().cleanup()
+			return err
 		}
+		err = nil
 	}
 
 	// send empty packet (termination)
@@ -0,0 +1,110 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type infileCtxKey struct{}
+
+func infileRequest(name string) []byte {
+	return append([]byte{byte(1 + len(name)), 0, 0, 1, iLocalInFile}, name...)
+}
+
+func TestInfileReaderHandlerContext(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.maxWriteSize = defaultPacketSize
+	var got any
+	if err := mc.cfg.Apply(ReaderHandler("data", func(ctx context.Context) io.Reader {
+		got = ctx.Value(infileCtxKey{})
+		return strings.NewReader("1,2\n")
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	ok := []byte{7, 0, 0, 4, iOK, 1, 0, 2, 0, 0, 0}
+	conn.queuedReplies = [][]byte{append(infileRequest("Reader::data"), ok...)}
+
+	mc.queryCtx = context.WithValue(context.Background(), infileCtxKey{}, "query")
+	if err := mc.exec("LOAD DATA LOCAL INFILE 'Reader::data' INTO TABLE t"); err != nil {
+		t.Fatal(err)
+	}
+	if got != "query" {
+		t.Errorf("expected handler to receive the query context, got %v", got)
+	}
+	if !bytes.HasSuffix(conn.written, []byte{4, 0, 0, 2, '1', ',', '2', '\n', 0, 0, 0, 3}) {
+		t.Errorf("unexpected data sent: %x", conn.written)
+	}
+}
+
+func TestInfileMaxBytes(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.maxWriteSize = defaultPacketSize
+	if err := mc.cfg.Apply(
+		ReaderHandler("data", func(ctx context.Context) io.Reader {
+			return strings.NewReader("too much data")
+		}),
+		LocalInfileLimits(4, 0),
+	); err != nil {
+		t.Fatal(err)
+	}
+	conn.queuedReplies = [][]byte{infileRequest("Reader::data")}
+
+	err := mc.exec("LOAD DATA LOCAL INFILE 'Reader::data' INTO TABLE t")
+	if !errors.Is(err, ErrLocalInfileTooLarge) {
+		t.Fatalf("expected ErrLocalInfileTooLarge, got %v", err)
+	}
+	if !mc.closed.Load() {
+		t.Error("expected the connection to be closed to abort the statement")
+	}
+	if bytes.Contains(conn.written, []byte("too much")) {
+		t.Error("expected no data to be sent")
+	}
+}
+
+// deadlineReader returns a row, then fails like a reader of a network
+// source whose deadline expired.
+type deadlineReader struct{ read bool }
+
+func (r *deadlineReader) Read(p []byte) (int, error) {
+	if r.read {
+		return 0, context.DeadlineExceeded
+	}
+	r.read = true
+	return copy(p, "1,2\n"), nil
+}
+
+func TestInfileReaderError(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.maxWriteSize = defaultPacketSize
+	if err := mc.cfg.Apply(ReaderHandler("data", func(ctx context.Context) io.Reader {
+		return &deadlineReader{}
+	})); err != nil {
+		t.Fatal(err)
+	}
+	conn.queuedReplies = [][]byte{infileRequest("Reader::data")}
+
+	err := mc.exec("LOAD DATA LOCAL INFILE 'Reader::data' INTO TABLE t")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if !mc.closed.Load() {
+		t.Error("expected the connection to be closed to abort the statement")
+	}
+	// the empty packet would make the server load the partial data
+	if bytes.HasSuffix(conn.written, []byte{0, 0, 0, 3}) {
+		t.Errorf("expected the data not to be terminated: %x", conn.written)
+	}
+}
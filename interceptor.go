@@ -0,0 +1,103 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"slices"
+)
+
+// QueryInterceptorFunc rewrites a query and its arguments before they are
+// sent to the server. Returning an error aborts the query.
+type QueryInterceptorFunc func(ctx context.Context, query string, args []driver.NamedValue) (string, []driver.NamedValue, error)
+
+// QueryInterceptor adds fn to the interceptors rewriting the queries of
+// Query, Exec and Prepare, e.g. to add optimizer hints or to append SQL
+// comments carrying trace context (sqlcommenter):
+//
+//	mysql.QueryInterceptor(func(ctx context.Context, query string, args []driver.NamedValue) (string, []driver.NamedValue, error) {
+//		return query + " /*traceparent='" + traceParent(ctx) + "'*/", args, nil
+//	})
+//
+// Interceptors run in the order they were added, each receiving the result
+// of the previous one. Prepared statements are intercepted once, when they
+// are prepared, with nil args; the returned args are ignored then.
+func QueryInterceptor(fn QueryInterceptorFunc) Option {
+	return func(cfg *Config) error {
+		// clip, so that clones of the Config don't share the chain
+		cfg.queryInterceptors = append(slices.Clip(cfg.queryInterceptors), fn)
+		return nil
+	}
+}
+
+// interceptQuery passes query and args through the interceptors.
+func (mc *mysqlConn) interceptQuery(ctx context.Context, query string, args []driver.NamedValue) (string, []driver.NamedValue, error) {
+	var err error
+	for _, fn := range mc.cfg.queryInterceptors {
+		query, args, err = fn(ctx, query, args)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	return query, args, nil
+}
+
+// ErrorInterceptorFunc translates an error returned by the server. info
+// describes the operation that failed and its connection. The returned
+// error is returned instead of err, e.g. err wrapped in a domain error;
+// returning nil keeps err.
+type ErrorInterceptorFunc func(ctx context.Context, info *HookInfo, err *MySQLError) error
+
+// ErrorInterceptor adds fn to the interceptors receiving the *MySQLError
+// of connecting, pings, queries, statements, reading rows, and of starting,
+// committing and rolling back transactions before it is returned, e.g. to
+// map error numbers to domain errors or to record diagnostics centrally:
+//
+//	mysql.ErrorInterceptor(func(ctx context.Context, info *mysql.HookInfo, err *mysql.MySQLError) error {
+//		if err.Number == 1062 { // ER_DUP_ENTRY
+//			return fmt.Errorf("%w: %w", ErrAlreadyExists, err)
+//		}
+//		return nil
+//	})
+//
+// Interceptors run in the order they were added, as long as the error
+// returned by the previous one still wraps a *MySQLError.
+func ErrorInterceptor(fn ErrorInterceptorFunc) Option {
+	return func(cfg *Config) error {
+		cfg.errorInterceptors = append(slices.Clip(cfg.errorInterceptors), fn)
+		return nil
+	}
+}
+
+// interceptError passes the *MySQLError wrapped by err through the error
+// interceptors of the connection.
+func (mc *mysqlConn) interceptError(ctx context.Context, info *HookInfo, err error) error {
+	for _, fn := range mc.cfg.errorInterceptors {
+		var me *MySQLError
+		if !errors.As(err, &me) {
+			break
+		}
+		if ierr := fn(ctx, info, me); ierr != nil {
+			err = ierr
+		}
+	}
+	return err
+}
+
+// ErrorStatement records the fingerprint of the failing statement, with
+// literals replaced by '?', in the Statement field of the *MySQLError of
+// queries and statements.
+func ErrorStatement(yes bool) Option {
+	return func(cfg *Config) error {
+		cfg.errorStatement = yes
+		return nil
+	}
+}
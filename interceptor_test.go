@@ -0,0 +1,166 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestQueryInterceptor(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.cfg.InterpolateParams = true
+	errDenied := errors.New("denied")
+	if err := mc.cfg.Apply(
+		QueryInterceptor(func(ctx context.Context, query string, args []driver.NamedValue) (string, []driver.NamedValue, error) {
+			if query == "DROP TABLE t" {
+				return "", nil, errDenied
+			}
+			return "DELETE /*+ MAX_EXECUTION_TIME(1000) */" + query[len("DELETE"):], args, nil
+		}),
+		QueryInterceptor(func(ctx context.Context, query string, args []driver.NamedValue) (string, []driver.NamedValue, error) {
+			args = append(args, driver.NamedValue{Ordinal: len(args) + 1, Value: int64(42)})
+			return query + " AND tenant = ?", args, nil
+		}),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.data = []byte{7, 0, 0, 1, iOK, 0, 0, 2, 0, 0, 0}
+	args := []driver.NamedValue{{Ordinal: 1, Value: int64(7)}}
+	if _, err := mc.ExecContext(context.Background(), "DELETE FROM t WHERE id = ?", args); err != nil {
+		t.Fatal(err)
+	}
+	expected := "DELETE /*+ MAX_EXECUTION_TIME(1000) */ FROM t WHERE id = 7 AND tenant = 42"
+	if !bytes.HasSuffix(conn.written, []byte(expected)) {
+		t.Errorf("expected %q to be sent, got %q", expected, conn.written)
+	}
+
+	conn.written = nil
+	if _, err := mc.ExecContext(context.Background(), "DROP TABLE t", nil); err != errDenied {
+		t.Errorf("expected the error of the interceptor, got %v", err)
+	}
+	if len(conn.written) != 0 {
+		t.Errorf("expected nothing to be sent, got %q", conn.written)
+	}
+}
+
+func TestErrorInterceptor(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	errExists := errors.New("already exists")
+	var got HookInfo
+	if err := mc.cfg.Apply(
+		ErrorInterceptor(func(ctx context.Context, info *HookInfo, err *MySQLError) error {
+			got = *info
+			if err.Number == 1062 {
+				return fmt.Errorf("%w: %w", errExists, err)
+			}
+			return nil
+		}),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := "Duplicate entry '1' for key 'PRIMARY'"
+	pkt := []byte{0, 0, 0, 1, iERR, 0x26, 0x04, '#', '2', '3', '0', '0', '0'}
+	pkt = append(pkt, msg...)
+	pkt[0] = byte(len(pkt) - 4)
+	conn.data = pkt
+
+	_, err := mc.ExecContext(context.Background(), "INSERT INTO t VALUES (1)", nil)
+	if !errors.Is(err, errExists) {
+		t.Fatalf("expected the translated error, got %v", err)
+	}
+	var me *MySQLError
+	if !errors.As(err, &me) || me.Number != 1062 || me.Message != msg {
+		t.Errorf("expected the server error to be wrapped, got %v", err)
+	}
+	if got.Op != HookExec || got.Query != "INSERT INTO t VALUES (1)" || got.ConnID != mc.id {
+		t.Errorf("unexpected info %+v", got)
+	}
+}
+
+func TestErrorStatement(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.cfg.errorStatement = true
+
+	msg := "Duplicate entry 'bob' for key 'name'"
+	pkt := []byte{0, 0, 0, 1, iERR, 0x26, 0x04, '#', '2', '3', '0', '0', '0'}
+	pkt = append(pkt, msg...)
+	pkt[0] = byte(len(pkt) - 4)
+	conn.data = pkt
+
+	_, err := mc.ExecContext(context.Background(), "INSERT INTO users (name) VALUES ('bob')", nil)
+	var me *MySQLError
+	if !errors.As(err, &me) {
+		t.Fatalf("expected a *MySQLError, got %v", err)
+	}
+	if me.Statement != "INSERT INTO users (name) VALUES (?)" {
+		t.Errorf("unexpected statement %q", me.Statement)
+	}
+	if !errors.Is(err, ErrDuplicateEntry) {
+		t.Errorf("expected %v to be a duplicate entry error", err)
+	}
+}
+
+func TestErrorInterceptorTransaction(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	var got []HookInfo
+	if err := mc.cfg.Apply(
+		ErrorInterceptor(func(ctx context.Context, info *HookInfo, err *MySQLError) error {
+			got = append(got, *info)
+			return nil
+		}),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := "Lock wait timeout exceeded"
+	errPkt := []byte{0, 0, 0, 1, iERR, 0xcd, 0x04, '#', 'H', 'Y', '0', '0', '0'}
+	errPkt = append(errPkt, msg...)
+	errPkt[0] = byte(len(errPkt) - 4)
+	okPkt := []byte{7, 0, 0, 1, iOK, 0, 0, 2, 0, 0, 0}
+	ctx := context.Background()
+
+	conn.data = errPkt
+	if _, err := mc.BeginTx(ctx, driver.TxOptions{}); err == nil {
+		t.Fatal("expected BeginTx to fail")
+	}
+	conn.data = okPkt
+	tx, err := mc.BeginTx(ctx, driver.TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.data = errPkt
+	if err := tx.Commit(); err == nil {
+		t.Fatal("expected Commit to fail")
+	}
+	conn.data = errPkt
+	if err := mc.Ping(ctx); err == nil {
+		t.Fatal("expected Ping to fail")
+	}
+
+	expected := []HookInfo{
+		{Op: HookExec, Query: "START TRANSACTION"},
+		{Op: HookExec, Query: "COMMIT"},
+		{Op: HookPing},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d intercepted errors, got %+v", len(expected), got)
+	}
+	for i, info := range got {
+		if info.Op != expected[i].Op || info.Query != expected[i].Query {
+			t.Errorf("expected error %d of %s %q, got %+v", i, expected[i].Op, expected[i].Query, info)
+		}
+	}
+}
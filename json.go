@@ -0,0 +1,66 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// ParseJSON makes JSON columns return json.RawMessage values, a copy of the
+// document, instead of []byte. They can be scanned into *json.RawMessage,
+// *[]byte, *any or a sql.Scanner like JSON. ColumnTypeScanType reports
+// json.RawMessage, or sql.Null[json.RawMessage] for nullable columns.
+//
+// Only columns with the JSON type of MySQL are affected; MariaDB reports JSON
+// columns as LONGTEXT. ParseJSON has no effect with RawBytes.
+func ParseJSON(yes bool) Option {
+	return func(cfg *Config) error {
+		cfg.parseJSON = yes
+		return nil
+	}
+}
+
+// JSON is a value marshaled with encoding/json when passed as an argument,
+// and unmarshaled into V when scanned:
+//
+//	db.Exec("INSERT INTO docs (doc) VALUES (?)", mysql.JSON{V: doc})
+//	db.QueryRow("SELECT doc FROM docs").Scan(mysql.JSON{V: &doc})
+//
+// To scan, V must be a pointer. NULL is unmarshaled like the JSON null,
+// which sets pointers, maps and slices to nil and leaves other values
+// unchanged.
+type JSON struct {
+	V any
+}
+
+// Value implements driver.Valuer.
+func (j JSON) Value() (driver.Value, error) {
+	b, err := json.Marshal(j.V)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (j JSON) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		return json.Unmarshal([]byte("null"), j.V)
+	case json.RawMessage:
+		return json.Unmarshal(src, j.V)
+	case []byte:
+		return json.Unmarshal(src, j.V)
+	case string:
+		return json.Unmarshal([]byte(src), j.V)
+	}
+	return fmt.Errorf("mysql: cannot scan %T into JSON", src)
+}
@@ -0,0 +1,119 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+)
+
+// mockJSONColumn returns the definition of a nullable JSON column.
+func mockJSONColumn(seq byte) []byte {
+	return mockPacket(seq,
+		3, 'd', 'e', 'f', 0, 0, 0, 1, 'j', 0, // catalog, schema, tables, names
+		0x0c, 0x3f, 0, 0xff, 0xff, 0xff, 0xff, byte(fieldTypeJSON), 0, 0, 0, 0, 0)
+}
+
+func TestParseJSONColumns(t *testing.T) {
+	for _, parseJSON := range []bool{false, true} {
+		conn, mc := newRWMockConn(1)
+		mc.cfg.parseJSON = parseJSON
+		conn.data = append(mockJSONColumn(1), mockEOF(2, 0)...)
+
+		columns, err := mc.readColumns(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := scanTypeNullString
+		if parseJSON {
+			want = scanTypeNullJSON
+		}
+		if got := columns[0].scanType(); got != want {
+			t.Errorf("parseJSON=%v: expected scan type %v, got %v", parseJSON, want, got)
+		}
+
+		doc := []byte(`{"a":1}`)
+		conn.data = mockPacket(3, append([]byte{byte(len(doc))}, doc...)...)
+		rows := &textRows{mysqlRows{mc: mc, rs: resultSet{columns: columns}}}
+		dest := make([]driver.Value, 1)
+		if err := rows.readRow(dest); err != nil {
+			t.Fatal(err)
+		}
+		if parseJSON {
+			v, ok := dest[0].(json.RawMessage)
+			if !ok || string(v) != string(doc) {
+				t.Fatalf("expected json.RawMessage %s, got %#v", doc, dest[0])
+			}
+		} else if v, ok := dest[0].([]byte); !ok || string(v) != string(doc) {
+			t.Errorf("expected []byte %s, got %#v", doc, dest[0])
+		}
+	}
+}
+
+func TestParseJSONBinaryRow(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.cfg.parseJSON = true
+	columns := []mysqlField{{fieldType: fieldTypeJSON, asJSON: true}}
+	conn.data = mockPacket(0, iOK, 0, 3, '[', '1', ']')
+	rows := &binaryRows{mysqlRows: mysqlRows{mc: mc, rs: resultSet{columns: columns}}}
+
+	dest := make([]driver.Value, 1)
+	if err := rows.readRow(dest); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := dest[0].(json.RawMessage); !ok || string(v) != "[1]" {
+		t.Errorf("expected json.RawMessage [1], got %#v", dest[0])
+	}
+}
+
+func TestJSONValue(t *testing.T) {
+	v, err := converter{}.ConvertValue(JSON{V: map[string]int{"a": 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j, ok := v.(json.RawMessage); !ok || string(j) != `{"a":1}` {
+		t.Errorf("expected json.RawMessage {\"a\":1}, got %#v", v)
+	}
+
+	if _, err := (JSON{V: make(chan int)}).Value(); err == nil {
+		t.Error("expected an error for a value which can't be marshaled")
+	}
+}
+
+func TestJSONScan(t *testing.T) {
+	type doc struct{ A int }
+	for _, src := range []any{json.RawMessage(`{"A":1}`), []byte(`{"A":1}`), `{"A":1}`} {
+		var d doc
+		if err := (JSON{V: &d}).Scan(src); err != nil {
+			t.Fatalf("%T: %v", src, err)
+		}
+		if d.A != 1 {
+			t.Errorf("%T: expected A=1, got %d", src, d.A)
+		}
+	}
+
+	p := &doc{}
+	if err := (JSON{V: &p}).Scan(nil); err != nil || p != nil {
+		t.Errorf("expected NULL to set the pointer to nil, got %v, %v", p, err)
+	}
+	if err := (JSON{V: &p}).Scan(int64(1)); err == nil {
+		t.Error("expected an error for an int64")
+	}
+
+	// sql.Null scans json.RawMessage values like database/sql does
+	var null sql.Null[json.RawMessage]
+	if err := null.Scan(json.RawMessage(`1`)); err != nil {
+		t.Fatal(err)
+	}
+	if !null.Valid || string(null.V) != "1" {
+		t.Errorf("expected 1, got %+v", null)
+	}
+}
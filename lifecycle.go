@@ -0,0 +1,97 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql/driver"
+)
+
+// ConnInfo describes a connection passed to the OnConnect, OnClose and
+// OnAuthFailure callbacks.
+type ConnInfo struct {
+	Addr          string // address of the server
+	ConnID        uint64 // sequence number of the connection assigned by the driver
+	ThreadID      uint32 // connection id assigned by the server
+	ServerVersion string // version reported by the server in the handshake
+	AuthPlugin    string // auth plugin used, the last one requested by the server
+	TLS           bool   // whether the connection is encrypted with TLS
+	TLSVersion    uint16 // negotiated TLS version, e.g. tls.VersionTLS13
+}
+
+// ConnectFunc is called with every new connection before it is returned to
+// database/sql. conn implements driver.ExecerContext and
+// driver.QueryerContext and can be used to set up the session; statements
+// with arguments need InterpolateParams. A non-nil error closes the
+// connection and is returned by Connect.
+type ConnectFunc func(ctx context.Context, conn driver.Conn, info ConnInfo) error
+
+// CloseFunc is called once when a connection passed to the ConnectFunc is
+// closed, by Close or because of an error. It must not block.
+type CloseFunc func(info ConnInfo)
+
+// AuthFailureFunc is called when the server rejects the authentication of a
+// new connection, e.g. because of an expired OIDC token. err is the error
+// returned by Connect.
+type AuthFailureFunc func(ctx context.Context, info ConnInfo, err error)
+
+// OnConnect sets the function called with every new connection.
+func OnConnect(fn ConnectFunc) Option {
+	return func(cfg *Config) error {
+		cfg.onConnect = fn
+		return nil
+	}
+}
+
+// OnClose sets the function called when a connection is closed.
+func OnClose(fn CloseFunc) Option {
+	return func(cfg *Config) error {
+		cfg.onClose = fn
+		return nil
+	}
+}
+
+// OnAuthFailure sets the function called when the server rejects the
+// authentication of a new connection.
+func OnAuthFailure(fn AuthFailureFunc) Option {
+	return func(cfg *Config) error {
+		cfg.onAuthFailure = fn
+		return nil
+	}
+}
+
+// connInfo returns the description of the connection for the callbacks.
+func (mc *mysqlConn) connInfo() ConnInfo {
+	info := ConnInfo{
+		Addr:          mc.cfg.Addr,
+		ConnID:        mc.id,
+		ThreadID:      mc.threadID,
+		ServerVersion: mc.serverVersion,
+		AuthPlugin:    mc.authPlugin,
+	}
+	if tc, ok := mc.netConn.(*tls.Conn); ok {
+		info.TLS = true
+		info.TLSVersion = tc.ConnectionState().Version
+	}
+	return info
+}
+
+// connected marks the connection as established and calls the ConnectFunc.
+func (mc *mysqlConn) connected(ctx context.Context) error {
+	mc.established = true
+	if mc.cfg.onConnect == nil {
+		return nil
+	}
+	if err := mc.cfg.onConnect(ctx, mc, mc.connInfo()); err != nil {
+		mc.Close()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+// mockHandshake is the initial handshake packet of a MySQL 8.0.36 server
+// with thread id 42 requesting mysql_native_password.
+func mockHandshake() []byte {
+	payload := []byte{10}
+	payload = append(payload, "8.0.36\x00"...)
+	payload = append(payload, 42, 0, 0, 0)
+	payload = append(payload, "abcdefgh"...)
+	payload = append(payload, 0)
+	payload = append(payload, 0x01, 0x82) // clientMySQL, clientProtocol41, clientSecureConn
+	payload = append(payload, 0xff, 0x02, 0x00)
+	payload = append(payload, 0x08, 0x00) // clientPluginAuth
+	payload = append(payload, 21, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	payload = append(payload, "ijklmnopqrst\x00"...)
+	payload = append(payload, "mysql_native_password\x00"...)
+	return mockPacket(0, payload...)
+}
+
+// mockServer answers the handshake with auth and every following command
+// with the response of reply, or an OK packet if reply is nil, until the
+// client closes the connection.
+func mockServer(t *testing.T, server net.Conn, auth []byte, reply func(cmd []byte) []byte) {
+	defer server.Close()
+	if _, err := server.Write(mockHandshake()); err != nil {
+		t.Error(err)
+		return
+	}
+	buf := make([]byte, 4096)
+	if _, err := server.Read(buf); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := server.Write(auth); err != nil || auth[4] == iERR {
+		return
+	}
+	for {
+		n, err := server.Read(buf)
+		if err != nil || n < 5 || buf[4] == comQuit {
+			io.Copy(io.Discard, server)
+			return
+		}
+		if reply != nil {
+			server.Write(reply(buf[4:n]))
+		} else {
+			server.Write(mockPacket(1, iOK, 0, 0, 2, 0, 0, 0))
+		}
+	}
+}
+
+func newMockServerConnector(t *testing.T, auth []byte, opts ...Option) *connector {
+	return newMockServerConnectorReply(t, auth, nil, opts...)
+}
+
+func newMockServerConnectorReply(t *testing.T, auth []byte, reply func(cmd []byte) []byte, opts ...Option) *connector {
+	cfg := NewConfig()
+	cfg.Addr = "mock:3306"
+	cfg.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		client, server := net.Pipe()
+		go mockServer(t, server, auth, reply)
+		return client, nil
+	}
+	if err := cfg.Apply(opts...); err != nil {
+		t.Fatal(err)
+	}
+	return newConnector(cfg)
+}
+
+func TestOnConnectOnClose(t *testing.T) {
+	var connected, closed []ConnInfo
+	c := newMockServerConnector(t, mockPacket(2, iOK, 0, 0, 2, 0, 0, 0),
+		OnConnect(func(ctx context.Context, conn driver.Conn, info ConnInfo) error {
+			connected = append(connected, info)
+			_, err := conn.(driver.ExecerContext).ExecContext(ctx, "SET @app = 'test'", nil)
+			return err
+		}),
+		OnClose(func(info ConnInfo) {
+			closed = append(closed, info)
+		}),
+	)
+
+	conn, err := c.Connect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(connected) != 1 {
+		t.Fatalf("expected OnConnect to be called once, got %d", len(connected))
+	}
+	info := connected[0]
+	if info.ThreadID != 42 || info.ServerVersion != "8.0.36" || info.AuthPlugin != "mysql_native_password" ||
+		info.Addr != "mock:3306" || info.TLS {
+		t.Errorf("unexpected connection info %+v", info)
+	}
+	if len(closed) != 0 {
+		t.Fatal("expected OnClose not to be called before Close")
+	}
+
+	conn.Close()
+	conn.Close()
+	if len(closed) != 1 || closed[0] != info {
+		t.Errorf("expected OnClose to be called once with %+v, got %+v", info, closed)
+	}
+}
+
+func TestOnConnectError(t *testing.T) {
+	errSetup := errors.New("setup failed")
+	closed := 0
+	c := newMockServerConnector(t, mockPacket(2, iOK, 0, 0, 2, 0, 0, 0),
+		OnConnect(func(ctx context.Context, conn driver.Conn, info ConnInfo) error {
+			return errSetup
+		}),
+		OnClose(func(info ConnInfo) {
+			closed++
+		}),
+	)
+
+	if _, err := c.Connect(context.Background()); err != errSetup {
+		t.Fatalf("expected %v, got %v", errSetup, err)
+	}
+	if closed != 1 {
+		t.Errorf("expected the connection to be closed, got %d OnClose calls", closed)
+	}
+}
+
+func TestOnAuthFailure(t *testing.T) {
+	var failures []ConnInfo
+	var failErr error
+	errPacket := append([]byte{iERR, 0x15, 0x04, '#'}, "28000Access denied"...)
+	c := newMockServerConnector(t, mockPacket(2, errPacket...),
+		OnAuthFailure(func(ctx context.Context, info ConnInfo, err error) {
+			failures = append(failures, info)
+			failErr = err
+		}),
+		OnClose(func(info ConnInfo) {
+			t.Error("expected OnClose not to be called for failed connections")
+		}),
+	)
+
+	_, err := c.Connect(context.Background())
+	var mysqlErr *MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1045 {
+		t.Fatalf("expected error 1045, got %v", err)
+	}
+	if len(failures) != 1 || failErr != err {
+		t.Fatalf("expected OnAuthFailure to be called once with the error, got %d calls with %v", len(failures), failErr)
+	}
+	if info := failures[0]; info.ThreadID != 42 || info.ServerVersion != "8.0.36" || info.AuthPlugin != "mysql_native_password" {
+		t.Errorf("unexpected connection info %+v", info)
+	}
+}
@@ -0,0 +1,77 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+)
+
+// LogLevel is the severity of a message passed to a LeveledLogger. The
+// values match those of slog.Level.
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = -4
+	LogLevelInfo  LogLevel = 0
+	LogLevelWarn  LogLevel = 4
+	LogLevelError LogLevel = 8
+)
+
+// LeveledLogger is a Logger which also receives the level of messages and
+// fields describing their origin, as alternating keys and values like the
+// arguments of slog.Logger.Log.
+//
+// Messages of a connection carry the fields "conn_id", a sequence number
+// assigned by the driver, "thread_id", the connection id assigned by the
+// server, "addr" and "caller". Loggers set with Config.Logger or SetLogger
+// which implement LeveledLogger receive messages through Log instead of
+// Print.
+type LeveledLogger interface {
+	Logger
+	Log(level LogLevel, msg string, fields ...any)
+}
+
+// NewSlogLogger returns a LeveledLogger writing to l.
+func NewSlogLogger(l *slog.Logger) LeveledLogger {
+	return &slogLogger{l: l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// Print implements Logger interface.
+func (sl *slogLogger) Print(v ...any) {
+	sl.l.Error(fmt.Sprint(v...))
+}
+
+// Log implements LeveledLogger interface.
+func (sl *slogLogger) Log(level LogLevel, msg string, fields ...any) {
+	sl.l.Log(context.Background(), slog.Level(level), msg, fields...)
+}
+
+// lastConnID is the sequence number of the last connection established by
+// the driver.
+var lastConnID atomic.Uint64
+
+// logTo passes a message to logger, with the level and fields if logger is a
+// LeveledLogger. Other loggers get warnings prefixed with "[warn]".
+func logTo(logger Logger, level LogLevel, fields []any, v ...any) {
+	if ll, ok := logger.(LeveledLogger); ok {
+		ll.Log(level, fmt.Sprint(v...), fields...)
+		return
+	}
+	if level == LogLevelWarn {
+		v = append([]any{"[warn] "}, v...)
+	}
+	logger.Print(v...)
+}
@@ -0,0 +1,54 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLeveledLogger(t *testing.T) {
+	var buf bytes.Buffer
+	_, mc := newRWMockConn(0)
+	mc.cfg.Logger = NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	mc.cfg.Addr = "db1:3306"
+	mc.id = 7
+	mc.threadID = 42
+
+	mc.warn("unexpected seq nr")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record["level"] != "WARN" || record["msg"] != "unexpected seq nr" {
+		t.Errorf("unexpected level or message: %v", record)
+	}
+	if record["conn_id"] != 7.0 || record["thread_id"] != 42.0 || record["addr"] != "db1:3306" {
+		t.Errorf("expected connection fields, got %v", record)
+	}
+	if caller, _ := record["caller"].(string); !strings.HasPrefix(caller, "logger_test.go:") {
+		t.Errorf("expected caller in logger_test.go, got %q", caller)
+	}
+}
+
+func TestPlainLoggerWarn(t *testing.T) {
+	var buf bytes.Buffer
+	_, mc := newRWMockConn(0)
+	mc.cfg.Logger = log.New(&buf, "", 0)
+
+	mc.warn("unexpected seq nr")
+	if got := buf.String(); !strings.HasPrefix(got, "logger_test.go:") || !strings.HasSuffix(got, " [warn] unexpected seq nr\n") {
+		t.Errorf("unexpected output %q", got)
+	}
+}
@@ -0,0 +1,32 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package mysqlerrors defines the numbers of the server errors a client is
+// likely to handle, named as in mysqld_error.h of MySQL and MariaDB, so that
+// they can be compared with the Number of a *mysql.MySQLError:
+//
+//	var mysqlErr *mysql.MySQLError
+//	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlerrors.ER_NO_REFERENCED_ROW_2 {
+//		return ErrUnknownCustomer
+//	}
+//
+// The constants are a curated subset of the error lists of the servers,
+// generated from mysqld_error.h of MySQL and errmsg-utf8.txt of MariaDB for
+// the names in names.txt: they cover the errors of connecting,
+// authentication, privileges, constraints, locking, replication and
+// read-only servers, but not the thousands of errors of storage engines, DDL
+// and server internals, which are compared by number. To add an error, add
+// its name to names.txt and run go generate.
+//
+// Both servers use the same numbers for the errors they have in common, but
+// MariaDB reuses some numbers of the errors added by MySQL 5.7 and later for
+// other errors: the numbers of the errors of only one of the servers are only
+// meaningful for that server.
+package mysqlerrors
+
+//go:generate go run generate.go
@@ -0,0 +1,223 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build ignore
+
+// generate writes mysqlerrors.go with the numbers of the errors listed in
+// names.txt, looked up in mysqld_error.h of MySQL and errmsg-utf8.txt of
+// MariaDB. The sources are file paths or URLs:
+//
+//	go run generate.go -mysql /usr/include/mysql/mysqld_error.h
+//
+// MySQL generates mysqld_error.h at build time; it is installed with the
+// client development package, e.g. libmysqlclient-dev.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	mysqlSource   = flag.String("mysql", "/usr/include/mysql/mysqld_error.h", "path or URL of mysqld_error.h of MySQL")
+	mariadbSource = flag.String("mariadb", "https://raw.githubusercontent.com/MariaDB/server/11.4/sql/share/errmsg-utf8.txt", "path or URL of errmsg-utf8.txt of MariaDB")
+	namesFile     = flag.String("names", "names.txt", "file of the names of the errors")
+	output        = flag.String("o", "mysqlerrors.go", "output file")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("generate: ")
+	flag.Parse()
+
+	names, err := readNames(*namesFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := open(*mysqlSource)
+	if err != nil {
+		log.Fatal(err)
+	}
+	mysql, err := parseHeader(src)
+	if err != nil {
+		log.Fatalf("%s: %v", *mysqlSource, err)
+	}
+	if src, err = open(*mariadbSource); err != nil {
+		log.Fatal(err)
+	}
+	mariadb, err := parseErrmsg(src)
+	if err != nil {
+		log.Fatalf("%s: %v", *mariadbSource, err)
+	}
+
+	var common, mysqlOnly, mariadbOnly []constant
+	for _, name := range names {
+		my, inMySQL := mysql[name]
+		ma, inMariaDB := mariadb[name]
+		switch {
+		case inMySQL && inMariaDB && my == ma:
+			common = append(common, constant{name, my})
+		case inMySQL && inMariaDB:
+			log.Fatalf("%s is %d in MySQL but %d in MariaDB", name, my, ma)
+		case inMySQL:
+			mysqlOnly = append(mysqlOnly, constant{name, my})
+		case inMariaDB:
+			mariadbOnly = append(mariadbOnly, constant{name, ma})
+		default:
+			log.Fatalf("%s is neither a MySQL nor a MariaDB error", name)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by generate.go; DO NOT EDIT.\n\npackage mysqlerrors\n")
+	writeConstants(&buf, "Errors of MySQL and MariaDB.", common)
+	writeConstants(&buf, "Errors of MySQL only.", mysqlOnly)
+	writeConstants(&buf, "Errors of MariaDB only.", mariadbOnly)
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*output, b, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type constant struct {
+	name   string
+	number int
+}
+
+func writeConstants(w io.Writer, doc string, constants []constant) {
+	if len(constants) == 0 {
+		return
+	}
+	// by number, like the error lists
+	sort.Slice(constants, func(i, j int) bool { return constants[i].number < constants[j].number })
+	fmt.Fprintf(w, "\n// %s\nconst (\n", doc)
+	for _, c := range constants {
+		fmt.Fprintf(w, "\t%s = %d\n", c.name, c.number)
+	}
+	fmt.Fprintf(w, ")\n")
+}
+
+// readNames reads the names of the errors, one per line. Empty lines and
+// lines starting with # are skipped.
+func readNames(path string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if seen[line] {
+			return nil, fmt.Errorf("%s: %s is listed twice", path, line)
+		}
+		seen[line] = true
+		names = append(names, line)
+	}
+	return names, nil
+}
+
+// open returns the content of the file or URL.
+func open(source string) (io.Reader, error) {
+	if !strings.HasPrefix(source, "https://") {
+		b, err := os.ReadFile(source)
+		return bytes.NewReader(b), err
+	}
+	resp, err := http.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", source, resp.Status)
+	}
+	b, err := io.ReadAll(resp.Body)
+	return bytes.NewReader(b), err
+}
+
+var defineRegexp = regexp.MustCompile(`^#define\s+((?:ER|WARN)_[A-Z0-9_]+)\s+(\d+)\s*$`)
+
+// parseHeader returns the error numbers of the #define lines of
+// mysqld_error.h by name.
+func parseHeader(r io.Reader) (map[string]int, error) {
+	numbers := make(map[string]int)
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		m := defineRegexp.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			return nil, err
+		}
+		numbers[m[1]] = n
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(numbers) == 0 {
+		return nil, fmt.Errorf("no errors found")
+	}
+	return numbers, nil
+}
+
+var entryRegexp = regexp.MustCompile(`^([A-Z][A-Z0-9_]+)(\s|$)`)
+
+// parseErrmsg returns the error numbers of errmsg-utf8.txt by name. The
+// errors are numbered in order of appearance, from the number of the last
+// start-error-number line; the messages are indented.
+func parseErrmsg(r io.Reader) (map[string]int, error) {
+	numbers := make(map[string]int)
+	next := -1
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := s.Text()
+		if rest, ok := strings.CutPrefix(line, "start-error-number"); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(rest))
+			if err != nil {
+				return nil, fmt.Errorf("invalid line %q", line)
+			}
+			next = n
+			continue
+		}
+		m := entryRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if next < 0 {
+			return nil, fmt.Errorf("%s precedes start-error-number", m[1])
+		}
+		numbers[m[1]] = next
+		next++
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(numbers) == 0 {
+		return nil, fmt.Errorf("no errors found")
+	}
+	return numbers, nil
+}
@@ -0,0 +1,198 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysqltest
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// Credentials are the credentials a client authenticates with.
+type Credentials struct {
+	User     string
+	Database string // database selected in the handshake, if any
+	Plugin   string // auth plugin of AuthResponse
+
+	// AuthResponse is the response of the client to the auth challenge of
+	// the plugin, e.g. the scrambled password.
+	AuthResponse []byte
+
+	// Scramble is the random challenge the server sent.
+	Scramble []byte
+}
+
+// CheckPassword reports whether the client authenticated with password,
+// using mysql_native_password, caching_sha2_password or
+// mysql_clear_password.
+func (c Credentials) CheckPassword(password string) bool {
+	var want []byte
+	switch c.Plugin {
+	case "mysql_native_password":
+		want = scramblePassword(c.Scramble, password)
+	case "caching_sha2_password":
+		want = scrambleSHA256Password(c.Scramble, password)
+	case "mysql_clear_password":
+		want = append([]byte(password), 0)
+	default:
+		return false
+	}
+	return subtle.ConstantTimeCompare(c.AuthResponse, want) == 1
+}
+
+// Token returns the ID token a client authenticated with using
+// authentication_openid_connect_client. ok is false for other plugins or
+// malformed responses.
+func (c Credentials) Token() (token string, ok bool) {
+	if c.Plugin != "authentication_openid_connect_client" || len(c.AuthResponse) < 2 || c.AuthResponse[0] != 0x01 {
+		return "", false
+	}
+	b, _, ok := readLengthEncodedString(c.AuthResponse[1:])
+	return string(b), ok
+}
+
+// scramblePassword returns SHA1(password) XOR SHA1(scramble + SHA1(SHA1(password))).
+func scramblePassword(scramble []byte, password string) []byte {
+	if password == "" {
+		return []byte{}
+	}
+	stage1 := sha1.Sum([]byte(password))
+	stage2 := sha1.Sum(stage1[:])
+	h := sha1.New()
+	h.Write(scramble)
+	h.Write(stage2[:])
+	res := h.Sum(nil)
+	for i := range res {
+		res[i] ^= stage1[i]
+	}
+	return res
+}
+
+// scrambleSHA256Password returns
+// SHA256(password) XOR SHA256(SHA256(SHA256(password)) + scramble).
+func scrambleSHA256Password(scramble []byte, password string) []byte {
+	if password == "" {
+		return []byte{}
+	}
+	stage1 := sha256.Sum256([]byte(password))
+	stage2 := sha256.Sum256(stage1[:])
+	h := sha256.New()
+	h.Write(stage2[:])
+	h.Write(scramble)
+	res := h.Sum(nil)
+	for i := range res {
+		res[i] ^= stage1[i]
+	}
+	return res
+}
+
+var errMalformedHandshake = errors.New("mysqltest: malformed handshake response")
+
+// parseHandshakeResponse parses the Handshake Response Packet of a client.
+// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_connection_phase_packets_protocol_handshake_response.html
+func parseHandshakeResponse(data []byte) (Credentials, error) {
+	var cred Credentials
+	// capabilities [4], max packet size [4], collation [1], filler [23]
+	if len(data) < 32 {
+		return cred, errMalformedHandshake
+	}
+	capabilities := binary.LittleEndian.Uint32(data)
+	if capabilities&clientProtocol41 == 0 {
+		return cred, errMalformedHandshake
+	}
+	data = data[32:]
+
+	user, data, ok := readNullTerminatedString(data)
+	if !ok {
+		return cred, errMalformedHandshake
+	}
+	cred.User = string(user)
+
+	var resp []byte
+	if capabilities&clientPluginAuthLenEnc != 0 {
+		resp, data, ok = readLengthEncodedString(data)
+	} else if len(data) > 0 && int(data[0]) < len(data) {
+		resp, data = data[1:1+int(data[0])], data[1+int(data[0]):]
+	} else {
+		ok = false
+	}
+	if !ok {
+		return cred, errMalformedHandshake
+	}
+	cred.AuthResponse = resp
+
+	if capabilities&clientConnectWithDB != 0 {
+		var db []byte
+		if db, data, ok = readNullTerminatedString(data); !ok {
+			return cred, errMalformedHandshake
+		}
+		cred.Database = string(db)
+	}
+	if capabilities&clientPluginAuth != 0 {
+		plugin, _, _ := readNullTerminatedString(data)
+		cred.Plugin = string(plugin)
+	}
+	// connection attributes are ignored
+	return cred, nil
+}
+
+func readNullTerminatedString(b []byte) (s, rest []byte, ok bool) {
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return b, nil, false
+	}
+	return b[:i], b[i+1:], true
+}
+
+func readLengthEncodedString(b []byte) (s, rest []byte, ok bool) {
+	n, b, ok := readLengthEncodedInteger(b)
+	if !ok || uint64(len(b)) < n {
+		return nil, nil, false
+	}
+	return b[:n], b[n:], true
+}
+
+func readLengthEncodedInteger(b []byte) (n uint64, rest []byte, ok bool) {
+	if len(b) == 0 {
+		return 0, nil, false
+	}
+	size := 0
+	switch b[0] {
+	case 0xfc:
+		size = 2
+	case 0xfd:
+		size = 3
+	case 0xfe:
+		size = 8
+	default:
+		return uint64(b[0]), b[1:], b[0] < 0xfb
+	}
+	if len(b) < 1+size {
+		return 0, nil, false
+	}
+	for i := size; i > 0; i-- {
+		n = n<<8 | uint64(b[i])
+	}
+	return n, b[1+size:], true
+}
+
+func appendLengthEncodedInteger(b []byte, n uint64) []byte {
+	switch {
+	case n < 0xfb:
+		return append(b, byte(n))
+	case n < 1<<16:
+		return append(b, 0xfc, byte(n), byte(n>>8))
+	case n < 1<<24:
+		return append(b, 0xfd, byte(n), byte(n>>8), byte(n>>16))
+	}
+	return binary.LittleEndian.AppendUint64(append(b, 0xfe), n)
+}
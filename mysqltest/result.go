@@ -0,0 +1,187 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysqltest
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Result is the canned response of the server to a query.
+type Result struct {
+	// Columns are the column names of a result set. If nil, the query
+	// returns an OK packet with AffectedRows and LastInsertID.
+	Columns []string
+
+	// Rows are the rows of the result set. nil values are NULL, the column
+	// types are derived from the first non-nil value of each column:
+	// integers, floats, bools, strings, []byte and time.Time are supported,
+	// other values are sent as strings formatted by fmt.
+	Rows [][]any
+
+	AffectedRows uint64
+	LastInsertID uint64
+
+	// Err makes the query fail with the error.
+	Err *Error
+
+	// Delay delays the response, e.g. to trigger timeouts.
+	Delay time.Duration
+
+	// Drop closes the connection instead of responding, like a failing
+	// network or server.
+	Drop bool
+}
+
+// Error is an error sent to the client in an ERR packet.
+type Error struct {
+	Number   uint16
+	SQLState string // HY000 if not 5 characters long
+	Message  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("Error %d (%s): %s", e.Number, e.SQLState, e.Message)
+}
+
+// column types and flags of the protocol
+const (
+	typeTiny      = 0x01
+	typeDouble    = 0x05
+	typeNull      = 0x06
+	typeLongLong  = 0x08
+	typeDateTime  = 0x0c
+	typeVarString = 0xfd
+
+	flagUnsigned = 0x20
+	flagBinary   = 0x80
+
+	collationBinary = 63
+)
+
+// column is the definition of a result set column.
+type column struct {
+	name      string
+	collation uint16
+	length    uint32
+	typ       byte
+	flags     uint16
+	decimals  byte
+}
+
+// newColumn returns the definition of the column named name with value v.
+func newColumn(name string, v any) column {
+	c := column{name: name, collation: collationBinary, flags: flagBinary}
+	switch v.(type) {
+	case nil:
+		c.typ = typeNull
+	case bool:
+		c.typ, c.length = typeTiny, 1
+	case int, int8, int16, int32, int64:
+		c.typ, c.length = typeLongLong, 20
+	case uint, uint8, uint16, uint32, uint64:
+		c.typ, c.length, c.flags = typeLongLong, 20, flagBinary|flagUnsigned
+	case float32, float64:
+		c.typ, c.length, c.decimals = typeDouble, 22, 31
+	case []byte:
+		c.typ, c.length = typeVarString, 65535
+	case time.Time:
+		c.typ, c.length, c.decimals = typeDateTime, 26, 6
+	default:
+		c.typ, c.length, c.collation, c.flags = typeVarString, 65535, defaultCollation, 0
+	}
+	return c
+}
+
+// appendValue appends v in the text protocol.
+func appendValue(b []byte, v any) []byte {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xfb)
+	case bool:
+		s = "0"
+		if v {
+			s = "1"
+		}
+	case float32:
+		s = strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		s = strconv.FormatFloat(v, 'g', -1, 64)
+	case []byte:
+		b = appendLengthEncodedInteger(b, uint64(len(v)))
+		return append(b, v...)
+	case time.Time:
+		s = v.Format("2006-01-02 15:04:05.000000")
+	default:
+		s = fmt.Sprint(v)
+	}
+	b = appendLengthEncodedInteger(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendLengthEncodedString(b []byte, s string) []byte {
+	b = appendLengthEncodedInteger(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// writeResultSet writes res as text result set.
+// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_com_query_response_text_resultset.html
+func (c *conn) writeResultSet(res Result) error {
+	if err := c.writePacket(appendLengthEncodedInteger(nil, uint64(len(res.Columns)))); err != nil {
+		return err
+	}
+	for i, name := range res.Columns {
+		var v any
+		for _, row := range res.Rows {
+			if i < len(row) && row[i] != nil {
+				v = row[i]
+				break
+			}
+		}
+		col := newColumn(name, v)
+
+		data := appendLengthEncodedString(nil, "def")
+		data = appendLengthEncodedString(data, "") // schema
+		data = appendLengthEncodedString(data, "") // table
+		data = appendLengthEncodedString(data, "") // org_table
+		data = appendLengthEncodedString(data, col.name)
+		data = appendLengthEncodedString(data, col.name) // org_name
+		data = append(data, 0x0c,
+			byte(col.collation), byte(col.collation>>8),
+			byte(col.length), byte(col.length>>8), byte(col.length>>16), byte(col.length>>24),
+			col.typ,
+			byte(col.flags), byte(col.flags>>8),
+			col.decimals,
+			0, 0, // filler
+		)
+		if err := c.writePacket(data); err != nil {
+			return err
+		}
+	}
+	if err := c.writeEOF(); err != nil {
+		return err
+	}
+
+	for _, row := range res.Rows {
+		var data []byte
+		for i := range res.Columns {
+			var v any
+			if i < len(row) {
+				v = row[i]
+			}
+			data = appendValue(data, v)
+		}
+		if err := c.writePacket(data); err != nil {
+			return err
+		}
+	}
+	return c.writeEOF()
+}
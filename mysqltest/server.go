@@ -0,0 +1,507 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package mysqltest provides an in-process fake MySQL server for unit tests
+// of code using the driver, e.g. of DSNs, authentication or retry logic,
+// without a real MySQL server:
+//
+//	srv := mysqltest.NewServer()
+//	defer srv.Close()
+//	srv.Handle("SELECT name FROM users WHERE id = 1", mysqltest.Result{
+//		Columns: []string{"name"},
+//		Rows:    [][]any{{"gopher"}},
+//	})
+//	db, err := sql.Open("mysql", "user:password@tcp("+srv.Addr+")/?interpolateParams=true")
+//
+// The server speaks the text protocol only. Prepared statements are rejected,
+// so queries with arguments need interpolateParams=true. TLS, compression and
+// multi statements aren't supported either.
+package mysqltest
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a fake MySQL server. The exported fields must be set before
+// the server is started or dialed and not be modified afterwards.
+type Server struct {
+	// Addr is the address the server listens on, set by Start.
+	Addr string
+
+	// Version is the server version sent in the handshake, "8.0.36" if
+	// empty.
+	Version string
+
+	// AuthPlugin is the auth plugin the server requests in the handshake,
+	// mysql_native_password if empty. Clients answering with another plugin
+	// are asked to switch to it. Credentials.CheckPassword supports
+	// mysql_native_password, caching_sha2_password and mysql_clear_password,
+	// Credentials.Token authentication_openid_connect_client.
+	AuthPlugin string
+
+	// Authenticate checks the credentials of connecting clients. A returned
+	// *Error is sent to the client as is, other errors as access denied
+	// error 1045. If nil, every client is accepted.
+	Authenticate func(Credentials) error
+
+	// Handler answers the queries without results registered with Handle.
+	// If it is nil or returns false, SET, USE, DO and transaction statements
+	// succeed and all other queries fail with error 1105.
+	Handler func(query string) (Result, bool)
+
+	mu       sync.Mutex
+	results  map[string]*results
+	queries  []string
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	nextID   uint32
+	closed   bool
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// results are the successive results of a query.
+type results struct {
+	list []Result
+	next int
+}
+
+// NewServer starts and returns a new Server with default settings. The
+// caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{}
+	s.Start()
+	return s
+}
+
+// Start starts the server listening on a port of the loopback interface.
+// It panics if the server can't listen.
+func (s *Server) Start() {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("mysqltest: failed to listen on a port: %v", err))
+	}
+	s.mu.Lock()
+	s.init()
+	s.listener = l
+	s.Addr = l.Addr().String()
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			nc, err := l.Accept()
+			if err != nil {
+				return
+			}
+			s.serve(nc)
+		}
+	}()
+}
+
+// Dial connects to the server through an in-memory connection, without
+// starting it. It can be used as DialFunc of a mysql.Config, which makes the
+// address of the config irrelevant.
+func (s *Server) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	client, server := net.Pipe()
+	s.mu.Lock()
+	s.init()
+	s.mu.Unlock()
+	if !s.serve(server) {
+		client.Close()
+		return nil, errors.New("mysqltest: server closed")
+	}
+	return client, nil
+}
+
+// Close shuts the server down, closing all connections, and waits until
+// they are closed.
+func (s *Server) Close() {
+	s.mu.Lock()
+	s.init()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+		if s.listener != nil {
+			s.listener.Close()
+		}
+		for nc := range s.conns {
+			nc.Close()
+		}
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// Handle registers the results of query, which must match the executed
+// query exactly, apart from leading and trailing white space. Successive
+// executions return the successive results, the last one repeatedly, e.g.
+// to let a query fail once and succeed when retried.
+func (s *Server) Handle(query string, res ...Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.init()
+	s.results[strings.TrimSpace(query)] = &results{list: res}
+}
+
+// Queries returns the queries the server received, in order.
+func (s *Server) Queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.queries...)
+}
+
+func (s *Server) init() {
+	if s.done == nil {
+		s.done = make(chan struct{})
+		s.results = make(map[string]*results)
+		s.conns = make(map[net.Conn]struct{})
+	}
+}
+
+// serve serves the connection nc in a new goroutine. It returns false if
+// the server is closed.
+func (s *Server) serve(nc net.Conn) bool {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		nc.Close()
+		return false
+	}
+	s.nextID++
+	c := &conn{srv: s, nc: nc, r: bufio.NewReader(nc), id: s.nextID, status: statusAutocommit}
+	s.conns[nc] = struct{}{}
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.conns, nc)
+			s.mu.Unlock()
+			nc.Close()
+		}()
+		if err := c.handshake(); err != nil {
+			return
+		}
+		c.run()
+	}()
+	return true
+}
+
+// result returns the result of query.
+func (s *Server) result(query string) Result {
+	s.mu.Lock()
+	s.queries = append(s.queries, query)
+	rs := s.results[strings.TrimSpace(query)]
+	if rs != nil && len(rs.list) > 0 {
+		res := rs.list[rs.next]
+		if rs.next < len(rs.list)-1 {
+			rs.next++
+		}
+		s.mu.Unlock()
+		return res
+	}
+	s.mu.Unlock()
+
+	if s.Handler != nil {
+		if res, ok := s.Handler(query); ok {
+			return res
+		}
+	}
+	return defaultResult(query)
+}
+
+// defaultResult is the result of queries without a registered result.
+func defaultResult(query string) Result {
+	upper := strings.ToUpper(strings.TrimSpace(query))
+	for _, prefix := range []string{"SET ", "USE ", "DO ", "BEGIN", "START TRANSACTION", "COMMIT", "ROLLBACK", "SAVEPOINT ", "RELEASE SAVEPOINT "} {
+		if strings.HasPrefix(upper, prefix) {
+			return Result{}
+		}
+	}
+	return Result{Err: &Error{Number: 1105, SQLState: "HY000", Message: "mysqltest: no result for query: " + query}}
+}
+
+// conn is a client connection of the server.
+type conn struct {
+	srv    *Server
+	nc     net.Conn
+	r      *bufio.Reader
+	seq    byte
+	id     uint32
+	status uint16
+}
+
+const (
+	clientLongPassword     = 1 << 0
+	clientFoundRows        = 1 << 1
+	clientLongFlag         = 1 << 2
+	clientConnectWithDB    = 1 << 3
+	clientProtocol41       = 1 << 9
+	clientTransactions     = 1 << 13
+	clientSecureConn       = 1 << 15
+	clientMultiResults     = 1 << 17
+	clientPluginAuth       = 1 << 19
+	clientConnectAttrs     = 1 << 20
+	clientPluginAuthLenEnc = 1 << 21
+
+	serverCapabilities = clientLongPassword | clientFoundRows | clientLongFlag |
+		clientConnectWithDB | clientProtocol41 | clientTransactions | clientSecureConn |
+		clientMultiResults | clientPluginAuth | clientConnectAttrs | clientPluginAuthLenEnc
+)
+
+const (
+	comQuit            = 0x01
+	comInitDB          = 0x02
+	comQuery           = 0x03
+	comPing            = 0x0e
+	comStmtPrepare     = 0x16
+	comStmtClose       = 0x19
+	comResetConnection = 0x1f
+)
+
+const (
+	statusInTrans    = 1 << 0
+	statusAutocommit = 1 << 1
+
+	defaultCollation           = 255 // utf8mb4_0900_ai_ci
+	maxPacketSize              = 1<<24 - 1
+	cachingSha2FastAuthSuccess = 3
+)
+
+// handshake performs the connection phase.
+func (c *conn) handshake() error {
+	scramble := make([]byte, 20)
+	if _, err := rand.Read(scramble); err != nil {
+		return err
+	}
+	for i := range scramble {
+		// printable characters, as the scramble is a null terminated string
+		scramble[i] = scramble[i]%94 + 33
+	}
+	plugin := c.srv.AuthPlugin
+	if plugin == "" {
+		plugin = "mysql_native_password"
+	}
+	version := c.srv.Version
+	if version == "" {
+		version = "8.0.36"
+	}
+
+	// Initial Handshake Packet
+	data := []byte{10}
+	data = append(data, version...)
+	data = append(data, 0)
+	data = binary.LittleEndian.AppendUint32(data, c.id)
+	data = append(data, scramble[:8]...)
+	data = append(data, 0)
+	data = binary.LittleEndian.AppendUint16(data, uint16(serverCapabilities&0xffff))
+	data = append(data, defaultCollation)
+	data = binary.LittleEndian.AppendUint16(data, c.status)
+	data = binary.LittleEndian.AppendUint16(data, uint16(serverCapabilities>>16))
+	data = append(data, byte(len(scramble)+1))
+	data = append(data, make([]byte, 10)...)
+	data = append(data, scramble[8:]...)
+	data = append(data, 0)
+	data = append(data, plugin...)
+	data = append(data, 0)
+	if err := c.writePacket(data); err != nil {
+		return err
+	}
+
+	// Handshake Response Packet
+	data, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+	cred, err := parseHandshakeResponse(data)
+	if err != nil {
+		return err
+	}
+	cred.Scramble = scramble
+
+	if cred.Plugin != plugin {
+		// Auth Switch Request
+		data = append([]byte{0xfe}, plugin...)
+		data = append(data, 0)
+		data = append(data, scramble...)
+		data = append(data, 0)
+		if err := c.writePacket(data); err != nil {
+			return err
+		}
+		if cred.AuthResponse, err = c.readPacket(); err != nil {
+			return err
+		}
+		cred.Plugin = plugin
+	}
+
+	if c.srv.Authenticate != nil {
+		if err := c.srv.Authenticate(cred); err != nil {
+			var merr *Error
+			if !errors.As(err, &merr) {
+				merr = &Error{
+					Number:   1045,
+					SQLState: "28000",
+					Message:  fmt.Sprintf("Access denied for user '%s'@'localhost' (%v)", cred.User, err),
+				}
+			}
+			c.writeError(merr)
+			return err
+		}
+	}
+	if plugin == "caching_sha2_password" && len(cred.AuthResponse) > 0 {
+		if err := c.writePacket([]byte{0x01, cachingSha2FastAuthSuccess}); err != nil {
+			return err
+		}
+	}
+	return c.writeOK(0, 0)
+}
+
+// run answers commands until the connection is closed.
+func (c *conn) run() {
+	for {
+		c.seq = 0
+		data, err := c.readPacket()
+		if err != nil || len(data) == 0 {
+			return
+		}
+		switch data[0] {
+		case comQuit:
+			return
+		case comQuery:
+			if !c.query(string(data[1:])) {
+				return
+			}
+			continue
+		case comInitDB, comPing, comResetConnection:
+			err = c.writeOK(0, 0)
+		case comStmtClose:
+			continue
+		case comStmtPrepare:
+			err = c.writeError(&Error{Number: 1295, SQLState: "HY000", Message: "mysqltest: prepared statements are not supported"})
+		default:
+			err = c.writeError(&Error{Number: 1047, SQLState: "08S01", Message: "Unknown command"})
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// query answers a query. It returns false if the connection must be closed.
+func (c *conn) query(query string) bool {
+	res := c.srv.result(query)
+	if res.Delay > 0 {
+		t := time.NewTimer(res.Delay)
+		select {
+		case <-t.C:
+		case <-c.srv.done:
+			t.Stop()
+			return false
+		}
+	}
+	if res.Drop {
+		return false
+	}
+	if res.Err != nil {
+		return c.writeError(res.Err) == nil
+	}
+
+	upper := strings.ToUpper(strings.TrimSpace(query))
+	switch {
+	case strings.HasPrefix(upper, "BEGIN"), strings.HasPrefix(upper, "START TRANSACTION"):
+		c.status |= statusInTrans
+	case strings.HasPrefix(upper, "COMMIT"),
+		strings.HasPrefix(upper, "ROLLBACK") && !strings.HasPrefix(upper, "ROLLBACK TO "):
+		c.status &^= statusInTrans
+	}
+
+	if res.Columns == nil {
+		return c.writeOK(res.AffectedRows, res.LastInsertID) == nil
+	}
+	return c.writeResultSet(res) == nil
+}
+
+func (c *conn) readPacket() ([]byte, error) {
+	var payload []byte
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(c.r, header[:]); err != nil {
+			return nil, err
+		}
+		n := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+		c.seq = header[3] + 1
+		data := make([]byte, n)
+		if _, err := io.ReadFull(c.r, data); err != nil {
+			return nil, err
+		}
+		payload = append(payload, data...)
+		if n < maxPacketSize {
+			return payload, nil
+		}
+	}
+}
+
+func (c *conn) writePacket(data []byte) error {
+	for {
+		n := len(data)
+		if n > maxPacketSize {
+			n = maxPacketSize
+		}
+		pkt := []byte{byte(n), byte(n >> 8), byte(n >> 16), c.seq}
+		if _, err := c.nc.Write(append(pkt, data[:n]...)); err != nil {
+			return err
+		}
+		c.seq++
+		data = data[n:]
+		if n < maxPacketSize {
+			return nil
+		}
+	}
+}
+
+func (c *conn) writeOK(affectedRows, insertID uint64) error {
+	data := []byte{0x00}
+	data = appendLengthEncodedInteger(data, affectedRows)
+	data = appendLengthEncodedInteger(data, insertID)
+	data = binary.LittleEndian.AppendUint16(data, c.status)
+	data = append(data, 0, 0) // warnings
+	return c.writePacket(data)
+}
+
+func (c *conn) writeEOF() error {
+	data := []byte{0xfe, 0, 0} // warnings
+	data = binary.LittleEndian.AppendUint16(data, c.status)
+	return c.writePacket(data)
+}
+
+func (c *conn) writeError(e *Error) error {
+	data := []byte{0xff}
+	data = binary.LittleEndian.AppendUint16(data, e.Number)
+	state := e.SQLState
+	if len(state) != 5 {
+		state = "HY000"
+	}
+	data = append(data, '#')
+	data = append(data, state...)
+	data = append(data, e.Message...)
+	return c.writePacket(data)
+}
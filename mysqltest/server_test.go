@@ -0,0 +1,204 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysqltest_test
+
+import (
+	"database/sql"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/colussim/mysql-auth-oidc-go"
+	"github.com/colussim/mysql-auth-oidc-go/mysqltest"
+)
+
+func open(t *testing.T, dsn string) *sql.DB {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestQuery(t *testing.T) {
+	srv := mysqltest.NewServer()
+	defer srv.Close()
+	created := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	srv.Handle("SELECT id, name, score, nickname, created FROM users", mysqltest.Result{
+		Columns: []string{"id", "name", "score", "nickname", "created"},
+		Rows: [][]any{
+			{1, "gopher", 9.5, nil, created},
+			{2, "dolphin", 7.25, "flipper", created},
+		},
+	})
+
+	db := open(t, "user:pass@tcp("+srv.Addr+")/?parseTime=true")
+	rows, err := db.Query("SELECT id, name, score, nickname, created FROM users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	type user struct {
+		id       int64
+		name     string
+		score    float64
+		nickname sql.NullString
+		created  time.Time
+	}
+	var got []user
+	for rows.Next() {
+		var u user
+		if err := rows.Scan(&u.id, &u.name, &u.score, &u.nickname, &u.created); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, u)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []user{
+		{1, "gopher", 9.5, sql.NullString{}, created},
+		{2, "dolphin", 7.25, sql.NullString{String: "flipper", Valid: true}, created},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if q := srv.Queries(); len(q) != 1 || q[0] != "SELECT id, name, score, nickname, created FROM users" {
+		t.Errorf("unexpected queries %q", q)
+	}
+}
+
+func TestExecDial(t *testing.T) {
+	srv := &mysqltest.Server{}
+	defer srv.Close()
+	srv.Handle("INSERT INTO t VALUES (1)", mysqltest.Result{AffectedRows: 1, LastInsertID: 42})
+
+	cfg := mysql.NewConfig()
+	cfg.DialFunc = srv.Dial
+	c, err := mysql.NewConnector(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	res, err := db.Exec("INSERT INTO t VALUES (1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Errorf("expected 1 affected row, got %d", n)
+	}
+	if id, _ := res.LastInsertId(); id != 42 {
+		t.Errorf("expected last insert id 42, got %d", id)
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if _, err := db.Exec("DELETE FROM t"); !errors.As(err, &mysqlErr) || mysqlErr.Number != 1105 {
+		t.Errorf("expected error 1105 for unhandled queries, got %v", err)
+	}
+	if _, err := db.Query("SELECT * FROM t WHERE id = ?", 1); !errors.As(err, &mysqlErr) || mysqlErr.Number != 1295 {
+		t.Errorf("expected error 1295 for prepared statements, got %v", err)
+	}
+}
+
+func TestErrorInjection(t *testing.T) {
+	srv := mysqltest.NewServer()
+	defer srv.Close()
+	deadlock := &mysqltest.Error{Number: 1213, SQLState: "40001", Message: "Deadlock found when trying to get lock"}
+	srv.Handle("UPDATE t SET a = 1",
+		mysqltest.Result{Err: deadlock},
+		mysqltest.Result{Drop: true},
+		mysqltest.Result{AffectedRows: 1},
+	)
+
+	db := open(t, "user:pass@tcp("+srv.Addr+")/")
+	var mysqlErr *mysql.MySQLError
+	_, err := db.Exec("UPDATE t SET a = 1")
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1213 || !mysql.IsRetryableError(err) {
+		t.Fatalf("expected a retryable deadlock error, got %v", err)
+	}
+	if _, err := db.Exec("UPDATE t SET a = 1"); !errors.Is(err, mysql.ErrInvalidConn) {
+		t.Fatalf("expected ErrInvalidConn for a dropped connection, got %v", err)
+	}
+	if _, err := db.Exec("UPDATE t SET a = 1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	srv := mysqltest.NewServer()
+	defer srv.Close()
+	srv.Handle("SELECT SLEEP(1)", mysqltest.Result{Delay: time.Second})
+
+	db := open(t, "user:pass@tcp("+srv.Addr+")/?readTimeout=50ms")
+	if _, err := db.Exec("SELECT SLEEP(1)"); !errors.Is(err, mysql.ErrReadTimeout) {
+		t.Errorf("expected ErrReadTimeout, got %v", err)
+	}
+}
+
+func TestAuthenticatePassword(t *testing.T) {
+	for _, plugin := range []string{"mysql_native_password", "caching_sha2_password"} {
+		srv := &mysqltest.Server{
+			AuthPlugin: plugin,
+			Authenticate: func(c mysqltest.Credentials) error {
+				if c.User != "app" || c.Database != "shop" || !c.CheckPassword("s3cret") {
+					return errors.New("invalid credentials")
+				}
+				return nil
+			},
+		}
+		srv.Start()
+
+		if err := open(t, "app:s3cret@tcp("+srv.Addr+")/shop").Ping(); err != nil {
+			t.Errorf("%s: %v", plugin, err)
+		}
+		var mysqlErr *mysql.MySQLError
+		if err := open(t, "app:wrong@tcp("+srv.Addr+")/shop").Ping(); !errors.As(err, &mysqlErr) || mysqlErr.Number != 1045 {
+			t.Errorf("%s: expected access denied, got %v", plugin, err)
+		}
+		srv.Close()
+	}
+}
+
+func TestAuthenticateOIDC(t *testing.T) {
+	const token = "eyJhbGciOiJSUzI1NiJ9.eyJzdWIiOiJhcHAifQ.c2ln"
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte(token+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var got string
+	srv := &mysqltest.Server{
+		AuthPlugin: "authentication_openid_connect_client",
+		Authenticate: func(c mysqltest.Credentials) error {
+			got, _ = c.Token()
+			if got != token {
+				return &mysqltest.Error{Number: 1045, SQLState: "28000", Message: "invalid token"}
+			}
+			return nil
+		},
+	}
+	srv.Start()
+	defer srv.Close()
+
+	db := open(t, "app@tcp("+srv.Addr+")/?authentication_openid_connect_client_id_token_file="+url.QueryEscape(tokenFile))
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if got != token {
+		t.Errorf("expected token %q, got %q", token, got)
+	}
+}
@@ -0,0 +1,140 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysqltest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Trace is a session recorded with mysql.RecordTraffic, which can be
+// replayed against the driver, e.g. to reproduce protocol bugs:
+//
+//	f, err := os.Open("testdata/regression.trace")
+//	...
+//	trace, err := mysqltest.ReadTrace(f)
+//	...
+//	cfg := mysql.NewConfig()
+//	cfg.DialFunc = trace.Dial
+//
+// Replaying a trace sends the packets received by the recorded client and
+// checks the packets the client sends against the recorded ones, except for
+// redacted packets. The session must be replayed with the settings it was
+// recorded with, apart from credentials, TLS and compression.
+type Trace struct {
+	packets []tracePacket
+
+	mu  sync.Mutex
+	err error
+}
+
+type tracePacket struct {
+	sent     bool // sent by the client
+	seq      byte
+	data     []byte
+	redacted bool
+}
+
+// ReadTrace reads a trace recorded with mysql.RecordTraffic.
+func ReadTrace(r io.Reader) (*Trace, error) {
+	t := &Trace{}
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<30)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 || (fields[0] != ">" && fields[0] != "<") {
+			return nil, fmt.Errorf("mysqltest: malformed trace line %d", n)
+		}
+		seq, err := strconv.ParseUint(fields[1], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("mysqltest: malformed sequence id in trace line %d: %w", n, err)
+		}
+		p := tracePacket{sent: fields[0] == ">", seq: byte(seq)}
+		if p.sent && fields[2] == "redacted" {
+			p.redacted = true
+		} else if p.data, err = hex.DecodeString(fields[2]); err != nil {
+			return nil, fmt.Errorf("mysqltest: malformed payload in trace line %d: %w", n, err)
+		}
+		t.packets = append(t.packets, p)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Dial returns a connection replaying the trace. It can be used as DialFunc
+// of a mysql.Config. Every connection replays the whole trace and is closed
+// at its end or when the client deviates from it, see Err.
+func (t *Trace) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	client, server := net.Pipe()
+	go t.replay(server)
+	return client, nil
+}
+
+// Err returns the first deviation of a client from the trace, if any.
+func (t *Trace) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+func (t *Trace) fail(err error) {
+	t.mu.Lock()
+	if t.err == nil {
+		t.err = err
+	}
+	t.mu.Unlock()
+}
+
+func (t *Trace) replay(nc net.Conn) {
+	defer nc.Close()
+	c := &conn{nc: nc, r: bufio.NewReader(nc)}
+	for i, p := range t.packets {
+		if !p.sent {
+			c.seq = p.seq
+			if err := c.writePacket(p.data); err != nil {
+				return
+			}
+			continue
+		}
+		data, err := c.readPacket()
+		if err != nil {
+			if err != io.EOF {
+				t.fail(fmt.Errorf("mysqltest: reading packet %d of the trace: %w", i+1, err))
+			} else if i < len(t.packets)-1 || !isQuit(p.data) {
+				t.fail(fmt.Errorf("mysqltest: connection closed at packet %d of the trace", i+1))
+			}
+			return
+		}
+		if c.seq-1 != p.seq || (!p.redacted && !bytes.Equal(data, p.data)) {
+			t.fail(fmt.Errorf("mysqltest: packet %d differs from the trace: expected %x (sequence id %d), got %x (sequence id %d)",
+				i+1, p.data, p.seq, data, c.seq-1))
+			return
+		}
+	}
+}
+
+// isQuit reports whether the packet is COM_QUIT, which clients closing the
+// connection may skip.
+func isQuit(data []byte) bool {
+	return len(data) == 1 && data[0] == comQuit
+}
@@ -0,0 +1,33 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysqltest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/colussim/mysql-auth-oidc-go/mysqltest"
+)
+
+func TestReadTraceMalformed(t *testing.T) {
+	for _, trace := range []string{
+		"< 0",
+		"? 0 00",
+		"< 256 00",
+		"< 0 redacted",
+		"> 0 0g",
+	} {
+		if _, err := mysqltest.ReadTrace(strings.NewReader("# comment\n" + trace + "\n")); err == nil {
+			t.Errorf("expected %q to be rejected", trace)
+		}
+	}
+	if _, err := mysqltest.ReadTrace(strings.NewReader("# comment\n\n< 0 0a\n> 1 redacted\n")); err != nil {
+		t.Errorf("expected a valid trace, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Named parameters (sql.Named) are translated to positional ones on the
+// client, for interpolated queries and prepared statements alike:
+//
+//	db.Query("SELECT * FROM t WHERE a = :a OR b = @b", sql.Named("a", 1), sql.Named("b", 2))
+//
+// Queries executed on the DB, Conn or Tx refer to them as :name or @name.
+// Since @name is also a user variable, only names of passed arguments are
+// replaced. Statements prepared with Prepare refer to them as :name only, as
+// the arguments aren't known yet. A name may be used several times, and '?'
+// placeholders take the unnamed arguments in order.
+
+// bindNamedArgs replaces the named placeholders of query referring to named
+// args with '?' and returns the rewritten query with the arguments in the
+// order of its placeholders.
+func bindNamedArgs(query string, args []driver.NamedValue) (string, []driver.Value, error) {
+	names := make(map[string]bool)
+	for _, arg := range args {
+		if arg.Name != "" {
+			names[arg.Name] = true
+		}
+	}
+	if len(names) == 0 {
+		dargs, err := namedValueToValue(args)
+		return query, dargs, err
+	}
+
+	query, params := rewriteNamedParams(query, func(_ byte, name string) bool { return names[name] })
+	dargs, err := bindNamedParams(params, args)
+	return query, dargs, err
+}
+
+// isPrepareParam matches the named placeholders of statements prepared
+// without knowing the arguments.
+func isPrepareParam(prefix byte, _ string) bool {
+	return prefix == ':'
+}
+
+// rewriteNamedParams replaces the named placeholders of query (":name" or
+// "@name") for which match returns true with '?'. Quoted strings and
+// identifiers, comments and system variables (@@name) are skipped. If any
+// placeholder was replaced, it returns the parameters of all placeholders in
+// order, with "" for '?'.
+func rewriteNamedParams(query string, match func(prefix byte, name string) bool) (string, []string) {
+	var b strings.Builder
+	var params []string
+	last := 0
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(query, i)
+		case c == '#' || c == '-' || c == '/':
+			if end := skipComment(query, i); end > i {
+				i = end
+			} else {
+				i++
+			}
+		case c == '?':
+			params = append(params, "")
+			i++
+		case c == '@' && strings.HasPrefix(query[i:], "@@"):
+			i += 2
+			for i < len(query) && (isIdentChar(query[i]) || query[i] == '.') {
+				i++
+			}
+		case (c == ':' || c == '@') && i+1 < len(query) && isNameStart(query[i+1]) && (i == 0 || !isIdentChar(query[i-1])):
+			end := i + 2
+			for end < len(query) && isIdentChar(query[end]) {
+				end++
+			}
+			if name := query[i+1 : end]; match(c, name) {
+				b.WriteString(query[last:i])
+				b.WriteByte('?')
+				params = append(params, name)
+				last = end
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+
+	if last == 0 {
+		return query, nil
+	}
+	b.WriteString(query[last:])
+	return b.String(), params
+}
+
+// bindArgs returns the arguments of the statement in the order of its
+// placeholders.
+func (stmt *mysqlStmt) bindArgs(args []driver.NamedValue) ([]driver.Value, error) {
+	if stmt.params == nil {
+		return namedValueToValue(args)
+	}
+	return bindNamedParams(stmt.params, args)
+}
+
+func isNameStart(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_'
+}
+
+// bindNamedParams returns the arguments for the placeholders with the
+// parameters params returned by rewriteNamedParams.
+func bindNamedParams(params []string, args []driver.NamedValue) ([]driver.Value, error) {
+	named := make(map[string]driver.Value)
+	var positional []driver.Value
+	for _, arg := range args {
+		if arg.Name != "" {
+			named[arg.Name] = arg.Value
+		} else {
+			positional = append(positional, arg.Value)
+		}
+	}
+
+	dargs := make([]driver.Value, len(params))
+	used := make(map[string]bool, len(named))
+	next := 0
+	for i, name := range params {
+		if name == "" {
+			if next == len(positional) {
+				return nil, fmt.Errorf("mysql: missing argument for placeholder %d", i+1)
+			}
+			dargs[i] = positional[next]
+			next++
+			continue
+		}
+		v, ok := named[name]
+		if !ok {
+			return nil, fmt.Errorf("mysql: missing named parameter %q", name)
+		}
+		dargs[i] = v
+		used[name] = true
+	}
+
+	if next < len(positional) {
+		return nil, fmt.Errorf("mysql: %d unnamed arguments for %d '?' placeholders", len(positional), next)
+	}
+	for name := range named {
+		if !used[name] {
+			return nil, fmt.Errorf("mysql: named parameter %q not found in the query", name)
+		}
+	}
+	return dargs, nil
+}
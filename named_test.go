@@ -0,0 +1,111 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestRewriteNamedParams(t *testing.T) {
+	names := map[string]bool{"a": true, "b": true}
+	match := func(_ byte, name string) bool { return names[name] }
+
+	tests := []struct {
+		query  string
+		want   string
+		params []string
+	}{
+		{"SELECT :a, @b", "SELECT ?, ?", []string{"a", "b"}},
+		{"SELECT :a, ?, :a", "SELECT ?, ?, ?", []string{"a", "", "a"}},
+		{"SELECT @c, :c", "SELECT @c, :c", nil},
+		{"SELECT ':a', \"@b\", `:a`, :a", "SELECT ':a', \"@b\", `:a`, ?", []string{"a"}},
+		{"SELECT 'it''s :a', :b", "SELECT 'it''s :a', ?", []string{"b"}},
+		{"SELECT @@a, @@session.b, @a", "SELECT @@a, @@session.b, ?", []string{"a"}},
+		{"SELECT @x := :a", "SELECT @x := ?", []string{"a"}},
+		{"SELECT :a -- :b\n, # :b\n/* :b */ :b", "SELECT ? -- :b\n, # :b\n/* :b */ ?", []string{"a", "b"}},
+		{"SELECT x:a, :ab", "SELECT x:a, :ab", nil},
+	}
+	for _, tt := range tests {
+		got, params := rewriteNamedParams(tt.query, match)
+		if got != tt.want || !reflect.DeepEqual(params, tt.params) {
+			t.Errorf("%q: expected %q %q, got %q %q", tt.query, tt.want, tt.params, got, params)
+		}
+	}
+
+	got, params := rewriteNamedParams("SELECT :a, @b", isPrepareParam)
+	if got != "SELECT ?, @b" || !reflect.DeepEqual(params, []string{"a"}) {
+		t.Errorf("expected only :name to be replaced for Prepare, got %q %q", got, params)
+	}
+}
+
+func TestBindNamedArgs(t *testing.T) {
+	args := []driver.NamedValue{
+		{Name: "a", Ordinal: 1, Value: int64(1)},
+		{Ordinal: 2, Value: "x"},
+		{Name: "b", Ordinal: 3, Value: int64(2)},
+	}
+	query, dargs, err := bindNamedArgs("SELECT :b, ?, @a, :b", args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT ?, ?, ?, ?" || !reflect.DeepEqual(dargs, []driver.Value{int64(2), "x", int64(1), int64(2)}) {
+		t.Errorf("unexpected query %q and args %v", query, dargs)
+	}
+
+	for _, query := range []string{
+		"SELECT :a, ?",     // b not used
+		"SELECT :a, :b",    // unnamed argument not used
+		"SELECT :a, ?, ?",  // too few unnamed arguments
+		"SELECT :a, :c, ?", // c not passed, b not used
+	} {
+		if _, _, err := bindNamedArgs(query, args); err == nil {
+			t.Errorf("%q: expected an error", query)
+		}
+	}
+
+	// positional arguments only
+	query, dargs, err = bindNamedArgs("SELECT ?", args[1:2])
+	if err != nil || query != "SELECT ?" || !reflect.DeepEqual(dargs, []driver.Value{"x"}) {
+		t.Errorf("unexpected query %q, args %v and error %v", query, dargs, err)
+	}
+}
+
+func TestNamedArgsInterpolated(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.cfg.InterpolateParams = true
+
+	conn.data = []byte{7, 0, 0, 1, iOK, 0, 0, 2, 0, 0, 0}
+	args := []driver.NamedValue{{Name: "id", Ordinal: 1, Value: int64(42)}}
+	if _, err := mc.ExecContext(context.Background(), "UPDATE t SET n = n + 1 WHERE id = :id OR parent = :id", args); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte("UPDATE t SET n = n + 1 WHERE id = 42 OR parent = 42"); !bytes.Contains(conn.written, want) {
+		t.Errorf("expected %q to be sent, got %q", want, conn.written)
+	}
+}
+
+func TestStmtNamedArgs(t *testing.T) {
+	stmt := &mysqlStmt{paramCount: 3, params: []string{"a", "", "a"}}
+	if n := stmt.NumInput(); n != -1 {
+		t.Errorf("expected NumInput -1 for named parameters, got %d", n)
+	}
+	dargs, err := stmt.bindArgs([]driver.NamedValue{{Ordinal: 1, Value: "x"}, {Name: "a", Ordinal: 2, Value: int64(1)}})
+	if err != nil || !reflect.DeepEqual(dargs, []driver.Value{int64(1), "x", int64(1)}) {
+		t.Errorf("unexpected args %v and error %v", dargs, err)
+	}
+
+	stmt = &mysqlStmt{paramCount: 1}
+	if _, err := stmt.bindArgs([]driver.NamedValue{{Name: "a", Ordinal: 1, Value: int64(1)}}); err == nil {
+		t.Error("expected an error for a named argument without placeholder")
+	}
+}
@@ -0,0 +1,425 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// oidcCapabilityIDToken is the capability of the authentication_openid_connect
+// protocol to authenticate with an ID token, which prefixes the auth response.
+const oidcCapabilityIDToken = 0x01
+
+// isOIDCPlugin reports whether plugin is the client plugin of OpenID Connect.
+func isOIDCPlugin(plugin string) bool {
+	return plugin == "authentication_openid_connect_client" || plugin == "authentication_openid_connect"
+}
+
+// Framings of OIDCFraming
+const (
+	OIDCFramingAuto       = "auto"
+	OIDCFramingCapability = "capability"
+	OIDCFramingRaw        = "raw"
+)
+
+// OIDCFraming sets how the ID token is framed in the auth responses of the
+// OpenID Connect plugin:
+//
+//   - OIDCFramingAuto: as requested by the server, the default. The token is
+//     prefixed with the capability byte and its length, unless the server
+//     requests it with a capability byte of 0.
+//   - OIDCFramingCapability: prefixed with the capability byte and its
+//     length, as expected by the server plugin of MySQL 9.1 and newer.
+//   - OIDCFramingRaw: the token alone, for servers and proxies implementing
+//     earlier versions of the protocol.
+func OIDCFraming(framing string) Option {
+	return func(cfg *Config) error {
+		switch framing {
+		case OIDCFramingAuto, OIDCFramingCapability, OIDCFramingRaw:
+			cfg.oidcFraming = framing
+			return nil
+		}
+		return fmt.Errorf("invalid oidcFraming value: %s", framing)
+	}
+}
+
+// oidcResponseFraming returns the framing of the auth response to the data of
+// an auth switch or more data request of the OpenID Connect plugin, or to no
+// data in the handshake response. Server plugins of MySQL 9.1 and newer send
+// the capabilities they accept as a single byte; earlier versions send no
+// data or a scramble, and accept capability-prefixed ID tokens.
+func oidcResponseFraming(framing string, data []byte) (string, error) {
+	if framing != "" && framing != OIDCFramingAuto {
+		return framing, nil
+	}
+	if len(data) != 1 || data[0]&oidcCapabilityIDToken != 0 {
+		return OIDCFramingCapability, nil
+	}
+	if data[0] == 0 {
+		return OIDCFramingRaw, nil
+	}
+	return "", fmt.Errorf("the OpenID Connect plugin of the server doesn't accept ID tokens (capabilities %#x)", data[0])
+}
+
+// Sources of OIDCSource
+const (
+	OIDCSourceParams     = "params"
+	OIDCSourceKubernetes = "kubernetes"
+)
+
+// kubernetesTokenFile is the path of the service account token mounted into
+// Kubernetes pods.
+const kubernetesTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// OIDCSource sets where the OpenID Connect ID token is read from:
+//
+//   - OIDCSourceParams: the file of the
+//     authentication_openid_connect_client_id_token_file param or the
+//     environment variable of the
+//     authentication_openid_connect_client_id_token_env param, the default.
+//   - OIDCSourceKubernetes: the service account token of the pod, from
+//     authentication_openid_connect_client_id_token_file if set to the path
+//     of a projected volume, or from the default service account token path.
+//
+// The token is read again for each new connection, so tokens rotated by the
+// kubelet are picked up, and connections authenticated with a rotated token
+// are replaced when they are returned to the pool.
+//
+// OIDCSourceKubernetes requires OIDCAudience: the default service account
+// token is issued for the Kubernetes API server and must not be sent to the
+// database. Mount a projected service account token requested for the
+// audience of the MySQL server instead:
+//
+//	volumes:
+//	- name: mysql-token
+//	  projected:
+//	    sources:
+//	    - serviceAccountToken:
+//	        audience: mysql
+//	        path: token
+func OIDCSource(source string) Option {
+	return func(cfg *Config) error {
+		switch source {
+		case OIDCSourceParams, OIDCSourceKubernetes:
+			cfg.oidcSource = source
+			return nil
+		}
+		return fmt.Errorf("invalid oidcSource value: %s", source)
+	}
+}
+
+// OIDCAudience sets the audience the OpenID Connect ID token must be issued
+// for, e.g. the audience of a projected service account token. Tokens for
+// other audiences are not sent to the server.
+func OIDCAudience(audience string) Option {
+	return func(cfg *Config) error {
+		cfg.oidcAudience = audience
+		return nil
+	}
+}
+
+// OIDCClockSkew sets the max difference between the clock of the client and
+// those of the server and the identity provider. ID tokens are considered
+// expiring by skew earlier, when deciding whether to fetch a new token, send
+// a token or keep a connection authenticated with it.
+func OIDCClockSkew(skew time.Duration) Option {
+	return func(cfg *Config) error {
+		cfg.oidcClockSkew = skew
+		return nil
+	}
+}
+
+// OIDCMinTTL sets the min remaining lifetime of the ID tokens sent to the
+// server, so that tokens don't expire while the server validates them.
+// Cached tokens of OIDCTokenProvider are fetched again before; tokens of the
+// token file or environment variable params expiring sooner fail the
+// authentication without being sent.
+func OIDCMinTTL(ttl time.Duration) Option {
+	return func(cfg *Config) error {
+		cfg.oidcMinTTL = ttl
+		return nil
+	}
+}
+
+// oidcTokenSource is where the OpenID Connect ID token is read from: the
+// OIDCTokenProviderByUser or OIDCTokenProvider, the file of the
+// authentication_openid_connect_client_id_token_file param or the environment
+// variable of the authentication_openid_connect_client_id_token_env param, in
+// this order of precedence.
+type oidcTokenSource struct {
+	provider OIDCTokenFunc
+	key      OIDCTokenKey // cache key of the tokens of provider
+
+	// subject is where the subject token of a token exchange is read from;
+	// resolve sets provider to exchange it
+	subject  *oidcTokenSource
+	exchange func(subjectToken string) OIDCTokenFunc
+
+	file     string
+	env      string
+	audience string        // expected aud claim of the token, if set
+	minTTL   time.Duration // min remaining lifetime of the token, including the clock skew
+
+	// fetched is called when a read fetched a token from provider
+	fetched func(token string, err error)
+}
+
+// oidcTokenSource returns the source of the ID token configured in the DSN
+// params and OIDCSource.
+func (cfg *Config) oidcTokenSource() oidcTokenSource {
+	src := oidcTokenSource{
+		file:     cfg.Params["authentication_openid_connect_client_id_token_file"],
+		env:      cfg.Params["authentication_openid_connect_client_id_token_env"],
+		audience: cfg.oidcAudience,
+		provider: cfg.oidcTokenFunc,
+		key:      cfg.oidcTokenKey,
+		minTTL:   cfg.oidcClockSkew + cfg.oidcMinTTL,
+	}
+	if cfg.oidcUserTokenFunc != nil {
+		if key, fn := cfg.oidcUserTokenFunc(cfg.User); fn != nil {
+			src.provider, src.key = fn, key
+		}
+	}
+	if cfg.oidcSource == OIDCSourceKubernetes {
+		if src.file == "" {
+			src.file = kubernetesTokenFile
+		}
+		src.env = ""
+	}
+	if cfg.oidcTokenExchange && src.provider == nil {
+		// the token of the file or the environment variable is the subject
+		// token, which is exchanged for an ID token for the audience
+		subject := src
+		subject.audience = ""
+		src.subject, src.exchange = &subject, cfg.exchangeOIDCToken
+		src.key = cfg.oidcEndpointTokenKey()
+		return src
+	}
+	if store := cfg.refreshTokenStore(); store != nil && src.provider == nil {
+		src.provider = cfg.refreshOIDCToken(store)
+		src.key = cfg.refreshTokenKey(store)
+	}
+	return src
+}
+
+// checkOIDCToken reads the token of the token file or environment variable
+// params, so that connectors report missing or malformed tokens at their
+// creation rather than at the first connect. The tokens of
+// OIDCTokenProvider and of token endpoints are fetched at the first connect.
+func (cfg *Config) checkOIDCToken() error {
+	if cfg.oidcTokenFunc != nil || cfg.oidcUserTokenFunc != nil || cfg.refreshTokenStore() != nil {
+		return nil
+	}
+	src := cfg.oidcTokenSource()
+	exchange := src.subject != nil
+	if exchange {
+		// the subject token of oidcTokenExchange may be an opaque access
+		// token for another audience
+		src = *src.subject
+	}
+	// the token may well be renewed before the first connect
+	src.minTTL = 0
+	if src.file == "" && src.env == "" {
+		return nil
+	}
+	token, err := src.read(context.Background())
+	if err != nil || exchange {
+		return err
+	}
+	if !jwtClaims(token, &map[string]any{}) {
+		return errors.New("JWT token is malformed")
+	}
+	return nil
+}
+
+// String describes where the token is read from.
+func (src oidcTokenSource) String() string {
+	switch {
+	case src.provider != nil:
+		return fmt.Sprintf("token provider (issuer %s, client %s)", src.key.Issuer, src.key.ClientID)
+	case src.file != "":
+		return "file " + src.file
+	case src.env != "":
+		return "environment variable " + src.env
+	}
+	return "none"
+}
+
+// resolve reads the subject token of a token exchange, and returns src
+// with the provider exchanging it. The ID tokens are cached by the SHA-256 of
+// the subject token, so that connectors exchanging different tokens don't
+// share them.
+func (src oidcTokenSource) resolve(ctx context.Context) (oidcTokenSource, error) {
+	if src.subject == nil {
+		return src, nil
+	}
+	subjectToken, err := src.subject.read(ctx)
+	if err != nil {
+		return src, err
+	}
+	src.provider = src.exchange(subjectToken)
+	src.key.grant = fmt.Sprintf("%x", sha256.Sum256([]byte(subjectToken)))
+	src.subject, src.exchange = nil, nil
+	return src, nil
+}
+
+// read returns the current ID token, trimmed of surrounding whitespace.
+func (src oidcTokenSource) read(ctx context.Context) (string, error) {
+	src, err := src.resolve(ctx)
+	if err != nil {
+		return "", err
+	}
+	var token string
+	switch {
+	case src.provider != nil:
+		var err error
+		var fetched bool
+		token, fetched, err = oidcTokens.get(ctx, src.key, src.provider, max(src.minTTL, oidcTokenRefreshMargin))
+		if fetched && src.fetched != nil {
+			src.fetched(token, err)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch JWT token: %w", err)
+		}
+		token = strings.TrimSpace(token)
+	case src.file != "":
+		b, err := os.ReadFile(src.file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read JWT token file: %v", err)
+		}
+		token = strings.TrimSpace(string(b))
+	case src.env != "":
+		token = strings.TrimSpace(os.Getenv(src.env))
+		if token == "" {
+			return "", fmt.Errorf("JWT token environment variable %s is empty or not set", src.env)
+		}
+	default:
+		return "", errors.New("OIDC plugin selected but no JWT token file or environment variable provided")
+	}
+	if src.audience != "" && !slices.Contains(jwtAudiences(token), src.audience) {
+		return "", fmt.Errorf("JWT token is not issued for the audience %q", src.audience)
+	}
+	if exp := jwtExpiry(token); src.minTTL > 0 && !exp.IsZero() && time.Now().Add(src.minTTL).After(exp) {
+		return "", fmt.Errorf("%w: it expires at %v, in less than %v", ErrOIDCTokenExpiring, exp, src.minTTL)
+	}
+	return token, nil
+}
+
+// oidcToken describes the OpenID Connect ID token a connection authenticated
+// with.
+type oidcToken struct {
+	src    oidcTokenSource // where the token was read from
+	sum    [32]byte        // SHA-256 of the token
+	expiry time.Time       // exp claim of the token, zero if it has none
+
+	// version of the token file last found to hold the token, so that
+	// rotated reads the file only after it changed
+	fileVersion fileVersion
+}
+
+func newOIDCToken(src oidcTokenSource, token string) *oidcToken {
+	return &oidcToken{
+		src:    src,
+		sum:    sha256.Sum256([]byte(token)),
+		expiry: jwtExpiry(token),
+	}
+}
+
+// expired reports whether the token expired before now.
+func (t *oidcToken) expired(now time.Time) bool {
+	return !t.expiry.IsZero() && now.After(t.expiry)
+}
+
+// rotated reports whether the token file or environment variable holds a
+// different token by now, or the token of the OIDCTokenProvider is no longer
+// cached. If the token can't be read, e.g. while the file is being replaced,
+// the token is considered unchanged. The token file is only read again when
+// its modification time or size changed.
+func (t *oidcToken) rotated() bool {
+	if t.src.provider != nil {
+		token, ok := oidcTokens.peek(t.src.key)
+		return !ok || sha256.Sum256([]byte(strings.TrimSpace(token))) != t.sum
+	}
+	var version fileVersion
+	if t.src.file != "" {
+		var err error
+		if version, err = statFile(t.src.file); err != nil || version == t.fileVersion {
+			return false
+		}
+	}
+	token, err := t.src.read(context.Background())
+	if err != nil {
+		return false
+	}
+	if sha256.Sum256([]byte(token)) != t.sum {
+		return true
+	}
+	t.fileVersion = version
+	return false
+}
+
+// stale reports whether the token expired or was rotated, in which case the
+// connection should be replaced by one authenticated with the current token.
+func (t *oidcToken) stale(now time.Time) bool {
+	return t.expired(now) || t.rotated()
+}
+
+// jwtClaims decodes the claims of a JWT. The signature is not verified; the
+// server does that.
+func jwtClaims(token string, claims any) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(payload, claims) == nil
+}
+
+// jwtExpiry returns the exp claim of a JWT, or the zero time if the token
+// can't be decoded or has no exp claim.
+func jwtExpiry(token string) time.Time {
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+	if !jwtClaims(token, &claims) || claims.Exp <= 0 {
+		return time.Time{}
+	}
+	sec := int64(claims.Exp)
+	return time.Unix(sec, int64((claims.Exp-float64(sec))*1e9))
+}
+
+// jwtAudiences returns the aud claim of a JWT, which is either a string or
+// an array of strings.
+func jwtAudiences(token string) []string {
+	var claims struct {
+		Aud json.RawMessage `json:"aud"`
+	}
+	if !jwtClaims(token, &claims) || len(claims.Aud) == 0 {
+		return nil
+	}
+	var aud string
+	if json.Unmarshal(claims.Aud, &aud) == nil {
+		return []string{aud}
+	}
+	var auds []string
+	json.Unmarshal(claims.Aud, &auds)
+	return auds
+}
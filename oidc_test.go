@@ -0,0 +1,362 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func testJWT(exp time.Time) string {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	payload := enc.EncodeToString([]byte(fmt.Sprintf(`{"sub":"app","exp":%d}`, exp.Unix())))
+	return header + "." + payload + ".c2lnbmF0dXJl"
+}
+
+func TestJWTExpiry(t *testing.T) {
+	exp := time.Unix(1700000000, 0)
+	if got := jwtExpiry(testJWT(exp)); !got.Equal(exp) {
+		t.Errorf("expected %v, got %v", exp, got)
+	}
+	for _, token := range []string{"", "opaque-token", "a.!!!.c", "a.e30.c"} {
+		if got := jwtExpiry(token); !got.IsZero() {
+			t.Errorf("expected no expiry for %q, got %v", token, got)
+		}
+	}
+}
+
+func TestOIDCTokenStale(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	token := testJWT(time.Now().Add(time.Hour))
+	if err := os.WriteFile(file, []byte(token+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tok := newOIDCToken(oidcTokenSource{file: file}, token)
+	if tok.stale(time.Now()) {
+		t.Error("expected the current token not to be stale")
+	}
+	if !tok.stale(time.Now().Add(2 * time.Hour)) {
+		t.Error("expected the expired token to be stale")
+	}
+
+	if err := os.WriteFile(file, []byte(testJWT(time.Now().Add(2*time.Hour))), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if !tok.stale(time.Now()) {
+		t.Error("expected the rotated token to be stale")
+	}
+
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	if tok.rotated() {
+		t.Error("expected a missing token file not to count as rotation")
+	}
+}
+
+func TestOIDCTokenRotatedUnchangedFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("token"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok := newOIDCToken(oidcTokenSource{file: file}, "token")
+	if tok.rotated() {
+		t.Fatal("expected the unchanged token not to be rotated")
+	}
+
+	// a token of the same size and modification time isn't read
+	if err := os.WriteFile(file, []byte("TOKEN"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, fi.ModTime(), fi.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if tok.rotated() {
+		t.Error("expected the file of the same version not to be read again")
+	}
+
+	mod := fi.ModTime().Add(time.Minute)
+	if err := os.Chtimes(file, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+	if !tok.rotated() {
+		t.Error("expected the changed file to rotate the token")
+	}
+}
+
+func TestResetSessionStaleOIDCToken(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("new-token"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, mc := newRWMockConn(0)
+	mc.cfg.CheckConnLiveness = false
+	mc.oidcToken = newOIDCToken(oidcTokenSource{file: file}, "old-token")
+	if err := mc.ResetSession(context.Background()); err != driver.ErrBadConn {
+		t.Errorf("expected driver.ErrBadConn, got %v", err)
+	}
+
+	mc.oidcToken = newOIDCToken(oidcTokenSource{file: file}, testJWT(time.Now().Add(-time.Minute)))
+	if mc.IsValid() {
+		t.Error("expected a connection with an expired token to be invalid")
+	}
+}
+
+func TestOIDCTokenEnv(t *testing.T) {
+	t.Setenv("MYSQL_OIDC_TOKEN", " env-token\n")
+
+	_, mc := newRWMockConn(0)
+	mc.cfg.Params = map[string]string{"authentication_openid_connect_client_id_token_env": "MYSQL_OIDC_TOKEN"}
+	resp, err := mc.oidcAuthResponse(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := append([]byte{0x01, 9}, "env-token"...); !bytes.Equal(resp, want) {
+		t.Errorf("expected %q, got %q", want, resp)
+	}
+	if mc.oidcToken.rotated() {
+		t.Error("expected the unchanged token not to be rotated")
+	}
+	t.Setenv("MYSQL_OIDC_TOKEN", "rotated-token")
+	if !mc.oidcToken.rotated() {
+		t.Error("expected the changed environment variable to rotate the token")
+	}
+
+	t.Setenv("MYSQL_OIDC_TOKEN", "")
+	if _, err := mc.oidcAuthResponse(nil); err == nil {
+		t.Error("expected an empty token to be rejected")
+	}
+
+	// the token file takes precedence
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("file-token"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	mc.cfg.Params["authentication_openid_connect_client_id_token_file"] = file
+	if token, err := mc.cfg.oidcTokenSource().read(context.Background()); err != nil || token != "file-token" {
+		t.Errorf("expected the token of the file, got %q, %v", token, err)
+	}
+}
+
+func testJWTAudience(aud string) string {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	payload := enc.EncodeToString([]byte(aud))
+	return header + "." + payload + ".sig"
+}
+
+func TestJWTAudiences(t *testing.T) {
+	for claims, want := range map[string][]string{
+		`{"aud":"mysql"}`:                 {"mysql"},
+		`{"aud":["sts","mysql"]}`:         {"sts", "mysql"},
+		`{"sub":"system:serviceaccount"}`: nil,
+	} {
+		if got := jwtAudiences(testJWTAudience(claims)); !slices.Equal(got, want) {
+			t.Errorf("%s: expected %q, got %q", claims, want, got)
+		}
+	}
+}
+
+func TestOIDCSourceKubernetes(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	token := testJWTAudience(`{"aud":["https://kubernetes.default.svc","mysql"]}`)
+	if err := os.WriteFile(file, []byte(token), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	if err := cfg.Apply(OIDCSource(OIDCSourceKubernetes), OIDCAudience("mysql")); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Params = map[string]string{"authentication_openid_connect_client_id_token_env": "MYSQL_OIDC_TOKEN"}
+	if src := cfg.oidcTokenSource(); src.file != kubernetesTokenFile || src.env != "" {
+		t.Errorf("expected the service account token path, got %+v", src)
+	}
+
+	// projected volumes are mounted at a custom path
+	cfg.Params["authentication_openid_connect_client_id_token_file"] = file
+	if got, err := cfg.oidcTokenSource().read(context.Background()); err != nil || got != token {
+		t.Errorf("expected the projected token, got %q, %v", got, err)
+	}
+
+	cfg.oidcAudience = "other"
+	if _, err := cfg.oidcTokenSource().read(context.Background()); err == nil {
+		t.Error("expected a token for another audience to be rejected")
+	}
+}
+
+// oidcTestResponse returns the auth response of the ID token in a packet.
+func oidcTestResponse(seq byte, token string) []byte {
+	payload := append([]byte{oidcCapabilityIDToken, byte(len(token))}, token...)
+	return append([]byte{byte(len(payload)), 0, 0, seq}, payload...)
+}
+
+func TestOIDCAuthSwitch(t *testing.T) {
+	t.Setenv("MYSQL_OIDC_TOKEN", "id-token")
+	for _, data := range [][]byte{nil, {oidcCapabilityIDToken}, {oidcCapabilityIDToken | 0x80}} {
+		conn, mc := newRWMockConn(2)
+		mc.cfg.Params = map[string]string{"authentication_openid_connect_client_id_token_env": "MYSQL_OIDC_TOKEN"}
+
+		// auth switch request to the OpenID Connect plugin
+		payload := append(append([]byte{iEOF}, "authentication_openid_connect_client\x00"...), data...)
+		conn.data = append([]byte{byte(len(payload)), 0, 0, 2}, payload...)
+		conn.queuedReplies = [][]byte{{7, 0, 0, 4, iOK, 0, 0, 2, 0, 0, 0}}
+
+		if err := mc.handleAuthResult(make([]byte, 20), "mysql_native_password"); err != nil {
+			t.Fatalf("%v: %v", data, err)
+		}
+		if want := oidcTestResponse(3, "id-token"); !bytes.Equal(conn.written, want) {
+			t.Errorf("%v: expected %v, got %v", data, want, conn.written)
+		}
+	}
+
+	// a server plugin without the ID token capability
+	conn, mc := newRWMockConn(2)
+	payload := append([]byte{iEOF}, "authentication_openid_connect_client\x00\x02"...)
+	conn.data = append([]byte{byte(len(payload)), 0, 0, 2}, payload...)
+	if err := mc.handleAuthResult(make([]byte, 20), "mysql_native_password"); err == nil {
+		t.Error("expected a server without the ID token capability to be rejected")
+	}
+}
+
+func TestOIDCAuthMoreData(t *testing.T) {
+	t.Setenv("MYSQL_OIDC_TOKEN", "id-token")
+	conn, mc := newRWMockConn(2)
+	mc.cfg.Params = map[string]string{"authentication_openid_connect_client_id_token_env": "MYSQL_OIDC_TOKEN"}
+
+	// the server requests the ID token again after the handshake response
+	conn.data = []byte{2, 0, 0, 2, iAuthMoreData, oidcCapabilityIDToken}
+	conn.queuedReplies = [][]byte{{7, 0, 0, 4, iOK, 0, 0, 2, 0, 0, 0}}
+	if err := mc.handleAuthResult(make([]byte, 20), "authentication_openid_connect_client"); err != nil {
+		t.Fatal(err)
+	}
+	if want := oidcTestResponse(3, "id-token"); !bytes.Equal(conn.written, want) {
+		t.Errorf("expected %v, got %v", want, conn.written)
+	}
+}
+
+func TestOIDCResponseFraming(t *testing.T) {
+	for _, tt := range []struct {
+		framing string
+		data    []byte
+		want    string
+	}{
+		{"", nil, OIDCFramingCapability},
+		{OIDCFramingAuto, []byte{oidcCapabilityIDToken}, OIDCFramingCapability},
+		{OIDCFramingAuto, make([]byte, 20), OIDCFramingCapability}, // scramble
+		{OIDCFramingAuto, []byte{0}, OIDCFramingRaw},
+		{OIDCFramingRaw, nil, OIDCFramingRaw},
+		{OIDCFramingCapability, []byte{0}, OIDCFramingCapability},
+	} {
+		if got, err := oidcResponseFraming(tt.framing, tt.data); err != nil || got != tt.want {
+			t.Errorf("%q, %v: expected %q, got %q, %v", tt.framing, tt.data, tt.want, got, err)
+		}
+	}
+	if _, err := oidcResponseFraming(OIDCFramingAuto, []byte{0x02}); err == nil {
+		t.Error("expected a server without the ID token capability to be rejected")
+	}
+}
+
+func TestOIDCAuthResponseRaw(t *testing.T) {
+	t.Setenv("MYSQL_OIDC_TOKEN", "id-token")
+	_, mc := newRWMockConn(0)
+	mc.cfg.Params = map[string]string{"authentication_openid_connect_client_id_token_env": "MYSQL_OIDC_TOKEN"}
+	if err := mc.cfg.Apply(OIDCFraming(OIDCFramingRaw)); err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := mc.oidcAuthResponse(nil); err != nil || string(resp) != "id-token" {
+		t.Errorf("expected the raw token, got %q, %v", resp, err)
+	}
+
+	// detected from the capability byte of the server
+	mc.cfg.oidcFraming = OIDCFramingAuto
+	if resp, err := mc.oidcAuthResponse([]byte{0}); err != nil || string(resp) != "id-token" {
+		t.Errorf("expected the raw token, got %q, %v", resp, err)
+	}
+}
+
+func TestCheckOIDCToken(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	t.Setenv("MYSQL_OIDC_TOKEN", "")
+
+	for _, tc := range []struct {
+		name  string
+		dsn   string
+		token string // written to file, if set
+		valid bool
+	}{
+		{"no OIDC", "user@/", "", true},
+		{"token file", "user@/?authentication_openid_connect_client_id_token_file=" + url.QueryEscape(file), testJWT(time.Now().Add(time.Hour)), true},
+		{"missing token file", "user@/?authentication_openid_connect_client_id_token_file=" + url.QueryEscape(file), "", false},
+		{"malformed token", "user@/?authentication_openid_connect_client_id_token_file=" + url.QueryEscape(file), "not-a-jwt", false},
+		{"wrong audience", "user@/?oidcAudience=mysql&authentication_openid_connect_client_id_token_file=" + url.QueryEscape(file), testJWTAudience(`{"aud":"other"}`), false},
+		{"unset environment variable", "user@/?authentication_openid_connect_client_id_token_env=MYSQL_OIDC_TOKEN", "", false},
+		{"opaque subject token", "user@/?oidcAudience=mysql&oidcTokenEndpoint=https%3A%2F%2Fidp.example.com%2Ftoken&oidcTokenExchange=true&authentication_openid_connect_client_id_token_file=" + url.QueryEscape(file), "opaque-access-token", true},
+		{"refresh token", "user@/?oidcClientID=cli&oidcRefreshToken=rt&oidcTokenEndpoint=https%3A%2F%2Fidp.example.com%2Ftoken", "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			os.Remove(file)
+			if tc.token != "" {
+				if err := os.WriteFile(file, []byte(tc.token), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, err := MySQLDriver{}.OpenConnector(tc.dsn)
+			if tc.valid && err != nil {
+				t.Errorf("expected a connector, got %v", err)
+			} else if !tc.valid && err == nil {
+				t.Error("expected the configuration to be rejected")
+			}
+		})
+	}
+}
+
+func TestOIDCMinTTL(t *testing.T) {
+	t.Setenv("MYSQL_OIDC_TOKEN", testJWT(time.Now().Add(90*time.Second)))
+
+	_, mc := newRWMockConn(0)
+	mc.cfg.Params = map[string]string{"authentication_openid_connect_client_id_token_env": "MYSQL_OIDC_TOKEN"}
+	if err := mc.cfg.Apply(OIDCMinTTL(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mc.oidcAuthResponse(nil); err != nil {
+		t.Fatalf("expected a token valid for 90s to be sent, got %v", err)
+	}
+	if !mc.IsValid() {
+		t.Error("expected the connection to be valid")
+	}
+
+	// the clock of the server may be ahead
+	if err := mc.cfg.Apply(OIDCClockSkew(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mc.oidcAuthResponse(nil); err == nil {
+		t.Error("expected a token expiring within the min TTL and the clock skew to be rejected")
+	}
+	mc.cfg.oidcClockSkew = 2 * time.Minute
+	if mc.IsValid() {
+		t.Error("expected the connection to be invalid once the token expired, given the clock skew")
+	}
+}
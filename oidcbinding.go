@@ -0,0 +1,101 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+)
+
+// OIDCBindingFunc verifies that an ID token is bound to the client
+// certificate presented in the TLS handshake, which is nil if none was.
+type OIDCBindingFunc func(token string, cert *x509.Certificate) error
+
+// OIDCTokenBinding sets the function verifying that ID tokens are bound to
+// the client certificate of the connection before they are sent, e.g.
+// VerifyCertificateBinding. Tokens failing the verification are not sent and
+// fail the connect, which catches mismatched pairs of tokens and certificates
+// before the server rejects them. TLS has to be enabled, and sessions are not
+// resumed, as resumed handshakes present no client certificate.
+func OIDCTokenBinding(fn OIDCBindingFunc) Option {
+	return func(cfg *Config) error {
+		cfg.oidcBindingFunc = fn
+		return nil
+	}
+}
+
+// VerifyCertificateBinding is an OIDCBindingFunc for certificate-bound
+// tokens of RFC 8705: it verifies that the x5t#S256 member of the cnf claim
+// of the token is the SHA-256 thumbprint of the client certificate. It is
+// used with the DSN param oidcCertificateBound=true.
+func VerifyCertificateBinding(token string, cert *x509.Certificate) error {
+	var claims struct {
+		Cnf struct {
+			X5tS256 string `json:"x5t#S256"`
+		} `json:"cnf"`
+	}
+	if !jwtClaims(token, &claims) || claims.Cnf.X5tS256 == "" {
+		return errors.New("mysql: the ID token is not bound to a certificate")
+	}
+	if cert == nil {
+		return errors.New("mysql: the ID token is bound to a certificate, but no client certificate was presented")
+	}
+	sum := sha256.Sum256(cert.Raw)
+	if claims.Cnf.X5tS256 != base64.RawURLEncoding.EncodeToString(sum[:]) {
+		return errors.New("mysql: the ID token is bound to another certificate than the client certificate")
+	}
+	return nil
+}
+
+// oidcBinding returns the function verifying the binding of ID tokens, or nil.
+func (cfg *Config) oidcBinding() OIDCBindingFunc {
+	if cfg.oidcBindingFunc != nil {
+		return cfg.oidcBindingFunc
+	}
+	if cfg.oidcCertificateBound {
+		return VerifyCertificateBinding
+	}
+	return nil
+}
+
+// recordClientCert returns a copy of tlsConfig which records the client
+// certificate presented in the handshake in mc.clientCert, to verify the
+// binding of ID tokens. Sessions are not resumed, as no certificate is
+// presented in resumed handshakes.
+func (mc *mysqlConn) recordClientCert(tlsConfig *tls.Config) *tls.Config {
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.ClientSessionCache = nil
+	get := tlsConfig.GetClientCertificate
+	if get == nil {
+		// as crypto/tls selects from Certificates
+		certs := tlsConfig.Certificates
+		get = func(cri *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			for i := range certs {
+				if cri.SupportsCertificate(&certs[i]) == nil {
+					return &certs[i], nil
+				}
+			}
+			return &tls.Certificate{}, nil
+		}
+	}
+	tlsConfig.GetClientCertificate = func(cri *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		cert, err := get(cri)
+		if err == nil && cert != nil && len(cert.Certificate) > 0 {
+			mc.clientCert = cert.Leaf
+			if mc.clientCert == nil {
+				mc.clientCert, err = x509.ParseCertificate(cert.Certificate[0])
+			}
+		}
+		return cert, err
+	}
+	return tlsConfig
+}
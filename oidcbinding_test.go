@@ -0,0 +1,139 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"testing"
+)
+
+// testBoundJWT returns a token bound to cert.
+func testBoundJWT(cert tls.Certificate) string {
+	sum := sha256.Sum256(cert.Certificate[0])
+	return testJWTAudience(fmt.Sprintf(`{"aud":"mysql","cnf":{"x5t#S256":%q}}`, base64.RawURLEncoding.EncodeToString(sum[:])))
+}
+
+func TestVerifyCertificateBinding(t *testing.T) {
+	cert := testCertificate(t, "client", nil)
+	other := testCertificate(t, "other", nil)
+	token := testBoundJWT(cert)
+
+	if err := VerifyCertificateBinding(token, cert.Leaf); err != nil {
+		t.Errorf("expected the bound certificate to be accepted, got %v", err)
+	}
+	if err := VerifyCertificateBinding(token, other.Leaf); err == nil {
+		t.Error("expected another certificate to be rejected")
+	}
+	if err := VerifyCertificateBinding(token, nil); err == nil {
+		t.Error("expected a missing certificate to be rejected")
+	}
+	if err := VerifyCertificateBinding(testJWTAudience(`{"aud":"mysql"}`), cert.Leaf); err == nil {
+		t.Error("expected an unbound token to be rejected")
+	}
+}
+
+func TestRecordClientCert(t *testing.T) {
+	cert := testCertificate(t, "client", nil)
+	_, mc := newRWMockConn(0)
+	tlsConfig := mc.recordClientCert(&tls.Config{Certificates: []tls.Certificate{cert}})
+
+	cri := &tls.CertificateRequestInfo{SignatureSchemes: []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256}, Version: tls.VersionTLS13}
+	got, err := tlsConfig.GetClientCertificate(cri)
+	if err != nil || got.Leaf != cert.Leaf {
+		t.Fatalf("expected the configured certificate, got %v, %v", got, err)
+	}
+	if mc.clientCert != cert.Leaf {
+		t.Error("expected the certificate to be recorded")
+	}
+}
+
+func TestRecordClientCertResumption(t *testing.T) {
+	serverCert := testCertificate(t, "db.internal", nil)
+	cert := testCertificate(t, "client", nil)
+
+	for _, version := range []uint16{tls.VersionTLS12, tls.VersionTLS13} {
+		// net.Pipe is unbuffered and deadlocks the TLS 1.3 handshake
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ln.Close()
+		// shared by the connections to decrypt their session tickets
+		serverConfig := &tls.Config{
+			Certificates: []tls.Certificate{serverCert},
+			ClientAuth:   tls.RequireAnyClientCert,
+			MaxVersion:   version,
+		}
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				server := tls.Server(conn, serverConfig)
+				// the byte written makes the client read the TLS 1.3
+				// session ticket sent after the handshake
+				server.Handshake()
+				server.Write([]byte{0})
+				conn.Close()
+			}
+		}()
+
+		// the cache of the connector, shared by its connections
+		tlsConfig := &tls.Config{
+			Certificates:       []tls.Certificate{cert},
+			InsecureSkipVerify: true,
+			MaxVersion:         version,
+			ClientSessionCache: tls.NewLRUClientSessionCache(1),
+		}
+		for i := 0; i < 2; i++ {
+			conn, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, mc := newRWMockConn(0)
+			client := tls.Client(conn, mc.recordClientCert(tlsConfig))
+			if err := client.Handshake(); err != nil {
+				t.Fatal(err)
+			}
+			client.Read(make([]byte, 1))
+			client.Close()
+
+			if client.ConnectionState().DidResume {
+				t.Errorf("%x: expected the session not to be resumed", version)
+			}
+			if mc.clientCert != cert.Leaf {
+				t.Errorf("%x: connection %d: expected the certificate to be recorded", version, i)
+			}
+		}
+	}
+}
+
+func TestOIDCTokenBinding(t *testing.T) {
+	cert := testCertificate(t, "client", nil)
+	t.Setenv("MYSQL_OIDC_TOKEN", testBoundJWT(cert))
+
+	cfg, err := ParseDSN("user@tcp(localhost:3306)/?tls=skip-verify&oidcCertificateBound=true&authentication_openid_connect_client_id_token_env=MYSQL_OIDC_TOKEN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, mc := newRWMockConn(0)
+	mc.cfg = cfg
+	if _, err := mc.oidcAuthResponse(nil); err == nil {
+		t.Error("expected the token not to be sent without a client certificate")
+	}
+	mc.clientCert = cert.Leaf
+	if _, err := mc.oidcAuthResponse(nil); err != nil {
+		t.Errorf("expected the bound token to be sent, got %v", err)
+	}
+}
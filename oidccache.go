@@ -0,0 +1,226 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// oidcTokenRefreshMargin is how long before their expiry cached ID tokens
+// are fetched again, so that connections aren't authenticated with tokens
+// about to expire.
+const oidcTokenRefreshMargin = time.Minute
+
+// OIDCTokenKey identifies the ID tokens of an OpenID Connect client. Tokens
+// fetched by an OIDCTokenFunc are cached process-wide by key and shared by
+// all connectors using the same key.
+type OIDCTokenKey struct {
+	Issuer   string
+	ClientID string
+	Audience string
+
+	// grant identifies the refresh token store or subject token the ID
+	// tokens of a token endpoint are issued for, so that connectors using
+	// different ones don't share their tokens
+	grant string
+}
+
+// OIDCTokenFunc fetches an ID token from the identity provider, e.g. with
+// the client credentials flow.
+type OIDCTokenFunc func(ctx context.Context, key OIDCTokenKey) (string, error)
+
+// OIDCTokenProvider sets the function fetching the ID tokens of the OpenID
+// Connect authentication plugins. It takes precedence over the token file
+// and environment variable params.
+//
+// The tokens are cached by key until a minute before the expiry of their
+// exp claim, tokens without exp claim until they are invalidated with
+// InvalidateOIDCToken. Concurrent connects wait for a single fetch, so that
+// warming up a pool, or several pools using the same key, doesn't hammer the
+// token endpoint. As the fetch is shared, it isn't canceled with the context
+// of a connect, but after 30 seconds. Connections authenticated with a token
+// which is no longer cached are replaced when they are returned to the pool.
+func OIDCTokenProvider(key OIDCTokenKey, fn OIDCTokenFunc) Option {
+	return func(cfg *Config) error {
+		cfg.oidcTokenKey = key
+		cfg.oidcTokenFunc = fn
+		return nil
+	}
+}
+
+// OIDCUserTokenFunc selects the ID tokens of the MySQL account user: it
+// returns the cache key and the OIDCTokenFunc of the identity of user at the
+// identity provider, or a nil OIDCTokenFunc if user has none.
+type OIDCUserTokenFunc func(user string) (OIDCTokenKey, OIDCTokenFunc)
+
+// OIDCTokenProviderByUser sets the function selecting the ID tokens by
+// Config.User, including the users of ChangeUser, so that one application
+// can keep pools for several MySQL accounts, each backed by its own identity.
+// The tokens it selects take precedence over those of OIDCTokenProvider;
+// users without tokens fall back to OIDCTokenProvider and the other sources.
+//
+// The tokens are cached like those of OIDCTokenProvider: distinct identities
+// need distinct keys.
+func OIDCTokenProviderByUser(fn OIDCUserTokenFunc) Option {
+	return func(cfg *Config) error {
+		cfg.oidcUserTokenFunc = fn
+		return nil
+	}
+}
+
+// OIDCUserToken is the ID token provider of a MySQL account.
+type OIDCUserToken struct {
+	Key  OIDCTokenKey
+	Func OIDCTokenFunc
+}
+
+// OIDCUserTokenProviders is like OIDCTokenProviderByUser, with the token
+// providers of the MySQL accounts in a map by user.
+func OIDCUserTokenProviders(providers map[string]OIDCUserToken) Option {
+	providers = maps.Clone(providers)
+	return OIDCTokenProviderByUser(func(user string) (OIDCTokenKey, OIDCTokenFunc) {
+		p := providers[user]
+		return p.Key, p.Func
+	})
+}
+
+// InvalidateOIDCToken removes the cached ID token of key, e.g. after it was
+// revoked. The next connect fetches a new token.
+func InvalidateOIDCToken(key OIDCTokenKey) {
+	oidcTokens.invalidate(key)
+}
+
+// OIDCTokenStats contains statistics about the process-wide ID token cache
+// of OIDCTokenProvider.
+type OIDCTokenStats struct {
+	Size    int   // The number of cached tokens
+	Hits    int64 // The total number of tokens taken from the cache
+	Misses  int64 // The total number of tokens not found in the cache, or about to expire
+	Fetches int64 // The total number of calls of OIDCTokenFunc
+	Errors  int64 // The total number of failed calls of OIDCTokenFunc
+}
+
+// OIDCTokenCacheStats returns the statistics of the ID token cache.
+func OIDCTokenCacheStats() OIDCTokenStats {
+	return oidcTokens.stats()
+}
+
+var oidcTokens = &oidcTokenCache{}
+
+// oidcTokenCache caches ID tokens by key, with single-flight fetches.
+type oidcTokenCache struct {
+	mu       sync.Mutex
+	tokens   map[OIDCTokenKey]cachedOIDCToken
+	inflight map[OIDCTokenKey]*oidcTokenFetch
+	counters OIDCTokenStats
+	timeout  time.Duration // max duration of a fetch, oidcTokenRequestTimeout if 0
+}
+
+type cachedOIDCToken struct {
+	token  string
+	expiry time.Time // zero if the token has no exp claim
+}
+
+// fresh reports whether the token may still be used at now, for at least
+// margin.
+func (t cachedOIDCToken) fresh(now time.Time, margin time.Duration) bool {
+	return t.expiry.IsZero() || now.Before(t.expiry.Add(-margin))
+}
+
+// oidcTokenFetch is a fetch in flight, which concurrent connects wait for.
+type oidcTokenFetch struct {
+	done  chan struct{} // closed when the fetch completed
+	token string
+	err   error
+}
+
+// get returns the cached token of key, fetching it with fn if it isn't
+// cached or expires within margin. fetched reports whether the call started
+// the fetch, which concurrent calls wait for, and got its outcome.
+func (c *oidcTokenCache) get(ctx context.Context, key OIDCTokenKey, fn OIDCTokenFunc, margin time.Duration) (token string, fetched bool, err error) {
+	c.mu.Lock()
+	if t, ok := c.tokens[key]; ok && t.fresh(time.Now(), margin) {
+		c.counters.Hits++
+		c.mu.Unlock()
+		return t.token, false, nil
+	}
+	c.counters.Misses++
+	f, ok := c.inflight[key]
+	fetched = !ok
+	if !ok {
+		f = &oidcTokenFetch{done: make(chan struct{})}
+		if c.inflight == nil {
+			c.inflight = make(map[OIDCTokenKey]*oidcTokenFetch)
+		}
+		c.inflight[key] = f
+		c.counters.Fetches++
+		c.mu.Unlock()
+		// the fetch is shared, so it isn't canceled with the context of the
+		// connect which started it, but it is bounded so that a hanging
+		// OIDCTokenFunc doesn't block all later connects of key
+		go c.fetch(context.WithoutCancel(ctx), key, fn, f)
+	} else {
+		c.mu.Unlock()
+	}
+
+	select {
+	case <-f.done:
+		return f.token, fetched, f.err
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	}
+}
+
+func (c *oidcTokenCache) fetch(ctx context.Context, key OIDCTokenKey, fn OIDCTokenFunc, f *oidcTokenFetch) {
+	timeout := c.timeout
+	if timeout == 0 {
+		timeout = oidcTokenRequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	f.token, f.err = fn(ctx, key)
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if f.err != nil {
+		c.counters.Errors++
+	} else {
+		if c.tokens == nil {
+			c.tokens = make(map[OIDCTokenKey]cachedOIDCToken)
+		}
+		c.tokens[key] = cachedOIDCToken{token: f.token, expiry: jwtExpiry(f.token)}
+	}
+	c.mu.Unlock()
+	close(f.done)
+}
+
+// peek returns the cached token of key without fetching it.
+func (c *oidcTokenCache) peek(key OIDCTokenKey) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, ok := c.tokens[key]
+	return t.token, ok
+}
+
+func (c *oidcTokenCache) invalidate(key OIDCTokenKey) {
+	c.mu.Lock()
+	delete(c.tokens, key)
+	c.mu.Unlock()
+}
+
+func (c *oidcTokenCache) stats() OIDCTokenStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.counters
+	s.Size = len(c.tokens)
+	return s
+}
@@ -0,0 +1,203 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOIDCTokenCacheSingleFlight(t *testing.T) {
+	c := &oidcTokenCache{}
+	key := OIDCTokenKey{Issuer: "https://idp.example.com", ClientID: "app", Audience: "mysql"}
+	token := testJWT(time.Now().Add(time.Hour))
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(ctx context.Context, k OIDCTokenKey) (string, error) {
+		calls.Add(1)
+		<-release
+		return token, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, _, err := c.get(context.Background(), key, fn, oidcTokenRefreshMargin); err != nil || got != token {
+				t.Errorf("expected the token, got %q, %v", got, err)
+			}
+		}()
+	}
+	for c.stats().Misses < 10 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if _, _, err := c.get(context.Background(), key, fn, oidcTokenRefreshMargin); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected a single fetch, got %d", n)
+	}
+	if s := c.stats(); s != (OIDCTokenStats{Size: 1, Hits: 1, Misses: 10, Fetches: 1}) {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	c.invalidate(key)
+	if _, ok := c.peek(key); ok {
+		t.Error("expected the invalidated token not to be cached")
+	}
+}
+
+func TestOIDCTokenCacheFetchTimeout(t *testing.T) {
+	c := &oidcTokenCache{timeout: 10 * time.Millisecond}
+	key := OIDCTokenKey{Issuer: "https://idp.example.com", ClientID: "app", Audience: "mysql"}
+
+	// a token endpoint which doesn't respond
+	hang := func(ctx context.Context, k OIDCTokenKey) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	if _, _, err := c.get(context.Background(), key, hang, oidcTokenRefreshMargin); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the fetch to time out, got %v", err)
+	}
+
+	token := testJWT(time.Now().Add(time.Hour))
+	fn := func(ctx context.Context, k OIDCTokenKey) (string, error) {
+		return token, nil
+	}
+	if got, fetched, err := c.get(context.Background(), key, fn, oidcTokenRefreshMargin); err != nil || got != token || !fetched {
+		t.Errorf("expected the token to be fetched again, got %q, %v, %v", got, fetched, err)
+	}
+}
+
+func TestOIDCTokenCacheExpiry(t *testing.T) {
+	c := &oidcTokenCache{}
+	key := OIDCTokenKey{ClientID: "app"}
+	var calls int
+	fn := func(ctx context.Context, k OIDCTokenKey) (string, error) {
+		calls++
+		if calls == 3 {
+			return "", errors.New("token endpoint unavailable")
+		}
+		// expires within the refresh margin
+		return testJWT(time.Now().Add(oidcTokenRefreshMargin / 2)), nil
+	}
+	for i := 0; i < 2; i++ {
+		if _, _, err := c.get(context.Background(), key, fn, oidcTokenRefreshMargin); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected tokens about to expire to be fetched again, got %d fetches", calls)
+	}
+	if _, _, err := c.get(context.Background(), key, fn, oidcTokenRefreshMargin); err == nil {
+		t.Error("expected the error of the fetch")
+	}
+	if s := c.stats(); s.Errors != 1 || s.Fetches != 3 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestOIDCTokenProvider(t *testing.T) {
+	key := OIDCTokenKey{Issuer: "https://idp.example.com", ClientID: t.Name()}
+	defer InvalidateOIDCToken(key)
+
+	_, mc := newRWMockConn(0)
+	if err := mc.cfg.Apply(OIDCTokenProvider(key, func(ctx context.Context, k OIDCTokenKey) (string, error) {
+		return "provided-token", nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := mc.oidcAuthResponse(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp[2:]) != "provided-token" {
+		t.Errorf("expected the provided token, got %q", resp)
+	}
+	if mc.oidcToken.rotated() {
+		t.Error("expected the cached token not to be rotated")
+	}
+	InvalidateOIDCToken(key)
+	if !mc.oidcToken.rotated() {
+		t.Error("expected the invalidated token to be rotated")
+	}
+}
+
+func TestOIDCTokenProviderByUser(t *testing.T) {
+	provide := func(token string) OIDCTokenFunc {
+		return func(ctx context.Context, k OIDCTokenKey) (string, error) {
+			return token, nil
+		}
+	}
+	reporting := OIDCTokenKey{Issuer: "https://idp.example.com", ClientID: t.Name() + "-reporting"}
+	etl := OIDCTokenKey{Issuer: "https://idp.example.com", ClientID: t.Name() + "-etl"}
+	fallback := OIDCTokenKey{Issuer: "https://idp.example.com", ClientID: t.Name()}
+	for _, key := range []OIDCTokenKey{reporting, etl, fallback} {
+		defer InvalidateOIDCToken(key)
+	}
+
+	_, mc := newRWMockConn(0)
+	if err := mc.cfg.Apply(
+		OIDCTokenProvider(fallback, provide("fallback-token")),
+		OIDCUserTokenProviders(map[string]OIDCUserToken{
+			"reporting": {Key: reporting, Func: provide("reporting-token")},
+			"etl":       {Key: etl, Func: provide("etl-token")},
+		}),
+	); err != nil {
+		t.Fatal(err)
+	}
+	for user, expected := range map[string]string{"reporting": "reporting-token", "etl": "etl-token", "admin": "fallback-token"} {
+		mc.cfg.User = user
+		resp, err := mc.oidcAuthResponse(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(resp[2:]) != expected {
+			t.Errorf("%s: expected %q, got %q", user, expected, resp)
+		}
+	}
+}
+
+func TestOIDCTokenProviderMinTTL(t *testing.T) {
+	key := OIDCTokenKey{Issuer: "https://idp.example.com", ClientID: t.Name()}
+	defer InvalidateOIDCToken(key)
+
+	var fetches int
+	_, mc := newRWMockConn(0)
+	if err := mc.cfg.Apply(OIDCMinTTL(5*time.Minute), OIDCTokenProvider(key, func(ctx context.Context, k OIDCTokenKey) (string, error) {
+		fetches++
+		if fetches == 1 {
+			return testJWT(time.Now().Add(3 * time.Minute)), nil
+		}
+		return testJWT(time.Now().Add(time.Hour)), nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first token expires within the min TTL
+	if _, err := mc.oidcAuthResponse(nil); err == nil {
+		t.Error("expected the token expiring within the min TTL to be rejected")
+	}
+	// and is fetched again rather than taken from the cache
+	if _, err := mc.oidcAuthResponse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 2 {
+		t.Errorf("expected 2 fetches, got %d", fetches)
+	}
+}
@@ -0,0 +1,115 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// OIDCTokenInfo describes the OpenID Connect ID token of a Config, as
+// returned by DescribeOIDCToken. It holds no part of the token which would
+// allow to authenticate with it.
+type OIDCTokenInfo struct {
+	Source    string    // Where the token was read from
+	Algorithm string    // alg header of the token
+	KeyID     string    // kid header of the token
+	Issuer    string    // iss claim of the token
+	Subject   string    // sub claim of the token
+	Audience  []string  // aud claim of the token
+	IssuedAt  time.Time // iat claim of the token, zero if it has none
+	Expiry    time.Time // exp claim of the token, zero if it has none
+
+	ExpectedAudience string // The audience of OIDCAudience, if set
+}
+
+// DescribeOIDCToken reads the OpenID Connect ID token of cfg like a connect
+// would, e.g. from the token file or fetched from the OIDCTokenProvider, and
+// decodes its header and claims, to help triaging "access denied" errors.
+// The signature is neither verified nor returned.
+//
+// Unlike a connect, tokens issued for another audience than OIDCAudience, or
+// expiring within OIDCMinTTL, are described rather than rejected.
+func DescribeOIDCToken(ctx context.Context, cfg *Config) (*OIDCTokenInfo, error) {
+	src, err := cfg.oidcTokenSource().resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	src.audience, src.minTTL = "", 0
+	token, err := src.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &OIDCTokenInfo{
+		Source:           src.String(),
+		Audience:         jwtAudiences(token),
+		Expiry:           jwtExpiry(token),
+		ExpectedAudience: cfg.oidcAudience,
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.SplitN(token, ".", 2)[0], "="))
+	if err != nil || json.Unmarshal(headerJSON, &header) != nil {
+		return nil, errors.New("JWT token is malformed")
+	}
+	info.Algorithm, info.KeyID = header.Alg, header.Kid
+	var claims struct {
+		Iss string  `json:"iss"`
+		Sub string  `json:"sub"`
+		Iat float64 `json:"iat"`
+	}
+	if !jwtClaims(token, &claims) {
+		return nil, errors.New("JWT token is malformed")
+	}
+	info.Issuer, info.Subject = claims.Iss, claims.Sub
+	if claims.Iat > 0 {
+		info.IssuedAt = time.Unix(int64(claims.Iat), 0)
+	}
+	return info, nil
+}
+
+// String formats the description on several lines, flagging the problems
+// the server would reject the token for.
+func (info *OIDCTokenInfo) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "source:    %s\n", info.Source)
+	fmt.Fprintf(&b, "algorithm: %s", info.Algorithm)
+	if info.KeyID != "" {
+		fmt.Fprintf(&b, " (key %s)", info.KeyID)
+	}
+	fmt.Fprintf(&b, "\nissuer:    %s\n", info.Issuer)
+	fmt.Fprintf(&b, "subject:   %s\n", info.Subject)
+	fmt.Fprintf(&b, "audience:  %s", strings.Join(info.Audience, ", "))
+	if info.ExpectedAudience != "" && !slices.Contains(info.Audience, info.ExpectedAudience) {
+		fmt.Fprintf(&b, " (MISMATCH: expected %s)", info.ExpectedAudience)
+	}
+	if !info.IssuedAt.IsZero() {
+		fmt.Fprintf(&b, "\nissued:    %s", info.IssuedAt.UTC().Format(time.RFC3339))
+	}
+	b.WriteString("\nexpires:   ")
+	switch {
+	case info.Expiry.IsZero():
+		b.WriteString("never")
+	case time.Now().After(info.Expiry):
+		fmt.Fprintf(&b, "%s (EXPIRED)", info.Expiry.UTC().Format(time.RFC3339))
+	default:
+		fmt.Fprintf(&b, "%s (in %s)", info.Expiry.UTC().Format(time.RFC3339), time.Until(info.Expiry).Round(time.Second))
+	}
+	b.WriteString("\nsignature: <redacted>\n")
+	return b.String()
+}
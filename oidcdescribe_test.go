@@ -0,0 +1,62 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDescribeOIDCToken(t *testing.T) {
+	enc := base64.RawURLEncoding
+	token := enc.EncodeToString([]byte(`{"alg":"ES256","kid":"key-1"}`)) + "." +
+		enc.EncodeToString([]byte(`{"iss":"https://idp.example.com","sub":"svc-reporting","aud":["mysql","api"],"iat":1700000000,"exp":1700003600}`)) +
+		".c2lnbmF0dXJl"
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte(token), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.Params = map[string]string{"authentication_openid_connect_client_id_token_file": file}
+	cfg.oidcAudience = "db.example.com"
+	info, err := DescribeOIDCToken(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Source != "file "+file || info.Algorithm != "ES256" || info.KeyID != "key-1" ||
+		info.Issuer != "https://idp.example.com" || info.Subject != "svc-reporting" ||
+		!slices.Equal(info.Audience, []string{"mysql", "api"}) ||
+		!info.IssuedAt.Equal(time.Unix(1700000000, 0)) || !info.Expiry.Equal(time.Unix(1700003600, 0)) {
+		t.Errorf("unexpected description %+v", *info)
+	}
+
+	s := info.String()
+	for _, want := range []string{"subject:   svc-reporting", "MISMATCH: expected db.example.com", "EXPIRED", "signature: <redacted>"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %q in\n%s", want, s)
+		}
+	}
+	if strings.Contains(s, "c2lnbmF0dXJl") {
+		t.Errorf("expected the signature to be redacted in\n%s", s)
+	}
+
+	if err := os.WriteFile(file, []byte("opaque-token"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DescribeOIDCToken(context.Background(), cfg); err == nil {
+		t.Error("expected a malformed token to be reported")
+	}
+}
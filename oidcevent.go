@@ -0,0 +1,65 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"time"
+)
+
+// OIDCTokenEvent identifies an event of the lifecycle of an OpenID Connect
+// ID token reported to an OIDCTokenEventFunc.
+type OIDCTokenEvent string
+
+const (
+	OIDCTokenLoaded        OIDCTokenEvent = "loaded"         // a token was read for a connect
+	OIDCTokenRefreshed     OIDCTokenEvent = "refreshed"      // a token was fetched from the identity provider
+	OIDCTokenRefreshFailed OIDCTokenEvent = "refresh_failed" // fetching a token from the identity provider failed
+	OIDCTokenExpired       OIDCTokenEvent = "expired"        // the token read for a connect expired, or expires within oidcMinTTL
+	OIDCTokenRejected      OIDCTokenEvent = "rejected"       // the server rejected the authentication with the token
+)
+
+// OIDCTokenEventInfo describes an event reported to an OIDCTokenEventFunc.
+type OIDCTokenEventInfo struct {
+	Event  OIDCTokenEvent
+	Source string       // where the token is read from, as in OIDCTokenInfo
+	Key    OIDCTokenKey // cache key of the token, for tokens of the identity provider
+	Expiry time.Time    // exp claim of the token, zero if it has none or wasn't read
+	Err    error        // for OIDCTokenRefreshFailed, OIDCTokenRejected and expired tokens not sent
+}
+
+// OIDCTokenEventFunc is called with the events of the ID tokens of the
+// connects of a Connector, e.g. to count them, so that operators can alert
+// on outages of the identity provider separately from those of the server.
+// It is called synchronously on the connecting goroutine and must not block.
+type OIDCTokenEventFunc func(ctx context.Context, info OIDCTokenEventInfo)
+
+// OnOIDCTokenEvent sets the function called with the events of the ID
+// tokens. OIDCTokenRefreshed and OIDCTokenRefreshFailed are reported once
+// per fetch of the shared token cache, by the connect which started it.
+func OnOIDCTokenEvent(fn OIDCTokenEventFunc) Option {
+	return func(cfg *Config) error {
+		cfg.onOIDCTokenEvent = fn
+		return nil
+	}
+}
+
+// oidcTokenEvent reports an event of the token of src to the
+// OIDCTokenEventFunc of the connection, if set.
+func (mc *mysqlConn) oidcTokenEvent(event OIDCTokenEvent, src oidcTokenSource, expiry time.Time, err error) {
+	fn := mc.cfg.onOIDCTokenEvent
+	if fn == nil {
+		return
+	}
+	info := OIDCTokenEventInfo{Event: event, Source: src.String(), Expiry: expiry, Err: err}
+	if src.provider != nil {
+		info.Key = src.key
+	}
+	fn(mc.authContext(), info)
+}
@@ -0,0 +1,159 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+// recordOIDCTokenEvents returns an Option recording the events of the ID
+// tokens in events.
+func recordOIDCTokenEvents(events *[]OIDCTokenEventInfo) Option {
+	return OnOIDCTokenEvent(func(ctx context.Context, info OIDCTokenEventInfo) {
+		*events = append(*events, info)
+	})
+}
+
+func oidcTokenEventNames(events []OIDCTokenEventInfo) []OIDCTokenEvent {
+	names := make([]OIDCTokenEvent, len(events))
+	for i, e := range events {
+		names[i] = e.Event
+	}
+	return names
+}
+
+func TestOIDCTokenEventsHandshake(t *testing.T) {
+	t.Setenv("MYSQL_OIDC_TOKEN", testJWT(time.Now().Add(time.Hour)))
+	for _, plugin := range []string{"authentication_openid_connect_client", "mysql_native_password"} {
+		var events []OIDCTokenEventInfo
+		conn, mc := newRWMockConn(1)
+		if err := mc.cfg.Apply(recordOIDCTokenEvents(&events)); err != nil {
+			t.Fatal(err)
+		}
+		mc.cfg.Params = map[string]string{
+			"auth_client_plugin": "authentication_openid_connect_client",
+			"authentication_openid_connect_client_id_token_env": "MYSQL_OIDC_TOKEN",
+		}
+
+		// as in connect
+		authResp, err := mc.auth(make([]byte, 20), plugin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := mc.writeHandshakeResponsePacket(authResp, plugin); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := oidcTokenEventNames(events), []OIDCTokenEvent{OIDCTokenLoaded}; !slices.Equal(got, want) {
+			t.Errorf("%s: expected the token to be read once, got %v", plugin, got)
+		}
+		if !bytes.Contains(conn.written, []byte(os.Getenv("MYSQL_OIDC_TOKEN"))) {
+			t.Errorf("%s: expected the token to be sent", plugin)
+		}
+	}
+}
+
+func TestOIDCTokenEventsProvider(t *testing.T) {
+	key := OIDCTokenKey{Issuer: "https://idp.example.com", ClientID: t.Name()}
+	defer InvalidateOIDCToken(key)
+
+	var events []OIDCTokenEventInfo
+	var idpDown bool
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	_, mc := newRWMockConn(0)
+	if err := mc.cfg.Apply(recordOIDCTokenEvents(&events), OIDCTokenProvider(key, func(ctx context.Context, k OIDCTokenKey) (string, error) {
+		if idpDown {
+			return "", errors.New("503 Service Unavailable")
+		}
+		return testJWT(exp), nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	// fetched, then taken from the cache
+	for i := 0; i < 2; i++ {
+		if _, err := mc.oidcAuthResponse(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := oidcTokenEventNames(events), []OIDCTokenEvent{OIDCTokenRefreshed, OIDCTokenLoaded, OIDCTokenLoaded}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if e := events[0]; e.Key != key || !e.Expiry.Equal(exp) || e.Err != nil {
+		t.Errorf("unexpected event %+v", e)
+	}
+
+	events = nil
+	idpDown = true
+	InvalidateOIDCToken(key)
+	if _, err := mc.oidcAuthResponse(nil); err == nil {
+		t.Fatal("expected the failed fetch to fail the authentication")
+	}
+	if len(events) != 1 || events[0].Event != OIDCTokenRefreshFailed || events[0].Err == nil {
+		t.Errorf("expected a failed refresh, got %+v", events)
+	}
+}
+
+func TestOIDCTokenEventsExpired(t *testing.T) {
+	var events []OIDCTokenEventInfo
+	t.Setenv("MYSQL_OIDC_TOKEN", testJWT(time.Now().Add(-time.Minute)))
+	_, mc := newRWMockConn(0)
+	mc.cfg.Params = map[string]string{"authentication_openid_connect_client_id_token_env": "MYSQL_OIDC_TOKEN"}
+	if err := mc.cfg.Apply(recordOIDCTokenEvents(&events)); err != nil {
+		t.Fatal(err)
+	}
+
+	// without oidcMinTTL, the server decides
+	if _, err := mc.oidcAuthResponse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := oidcTokenEventNames(events), []OIDCTokenEvent{OIDCTokenLoaded, OIDCTokenExpired}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if e := events[1]; e.Source != "environment variable MYSQL_OIDC_TOKEN" || e.Err != nil {
+		t.Errorf("unexpected event %+v", e)
+	}
+
+	events = nil
+	mc.cfg.oidcMinTTL = time.Minute
+	if _, err := mc.oidcAuthResponse(nil); !errors.Is(err, ErrOIDCTokenExpiring) {
+		t.Fatalf("expected ErrOIDCTokenExpiring, got %v", err)
+	}
+	if len(events) != 1 || events[0].Event != OIDCTokenExpired || !errors.Is(events[0].Err, ErrOIDCTokenExpiring) {
+		t.Errorf("expected an expired token, got %+v", events)
+	}
+}
+
+func TestOIDCTokenEventsRejected(t *testing.T) {
+	var events []OIDCTokenEventInfo
+	t.Setenv("MYSQL_OIDC_TOKEN", testJWT(time.Now().Add(time.Hour)))
+	errPacket := append([]byte{iERR, 0x15, 0x04, '#'}, "28000Access denied"...)
+	c := newMockServerConnector(t, mockPacket(2, errPacket...), recordOIDCTokenEvents(&events))
+	c.cfg.Params = map[string]string{
+		"auth_client_plugin": "authentication_openid_connect_client",
+		"authentication_openid_connect_client_id_token_env": "MYSQL_OIDC_TOKEN",
+	}
+
+	_, err := c.Connect(context.Background())
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("expected an AuthError, got %v", err)
+	}
+	if got, want := oidcTokenEventNames(events), []OIDCTokenEvent{OIDCTokenLoaded, OIDCTokenRejected}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if e := events[1]; e.Err != err || e.Expiry.IsZero() {
+		t.Errorf("unexpected event %+v", e)
+	}
+}
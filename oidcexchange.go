@@ -0,0 +1,141 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// oidcTokenRequestTimeout is the max duration of a request to the token
+// endpoint.
+const oidcTokenRequestTimeout = 30 * time.Second
+
+// Token types and grant type of RFC 8693
+const (
+	tokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenTypeAccessToken   = "urn:ietf:params:oauth:token-type:access_token"
+	tokenTypeIDToken       = "urn:ietf:params:oauth:token-type:id_token"
+)
+
+// OIDCTokenExchange exchanges the token read from the token file or
+// environment variable params, an access token, for an ID token for the
+// audience set with OIDCAudience, at the token endpoint of the issuer
+// (OAuth 2.0 Token Exchange, RFC 8693). The endpoint must be an https URL,
+// or http on a loopback host; it may be empty if it is derived from the
+// issuer of OIDCProvider. clientID and clientSecret
+// authenticate the driver to the token endpoint with HTTP basic
+// authentication; both may be empty if the endpoint doesn't require it.
+//
+// The ID tokens are cached like the tokens of OIDCTokenProvider, by token
+// endpoint, clientID, audience and subject token: connectors share the ID
+// tokens if they exchange the same token.
+func OIDCTokenExchange(endpoint, clientID, clientSecret string) Option {
+	return func(cfg *Config) error {
+		cfg.oidcTokenExchange = true
+		cfg.oidcTokenEndpoint = endpoint
+		cfg.oidcClientID = clientID
+		cfg.oidcClientSecret = clientSecret
+		return nil
+	}
+}
+
+// oidcEndpointTokenKey returns the cache key of the ID tokens issued by the
+// token endpoint.
+func (cfg *Config) oidcEndpointTokenKey() OIDCTokenKey {
+	return OIDCTokenKey{Issuer: cfg.oidcTokenEndpointURL(), ClientID: cfg.oidcClientID, Audience: cfg.oidcAudience}
+}
+
+// exchangeOIDCToken returns an OIDCTokenFunc exchanging subjectToken for an
+// ID token.
+func (cfg *Config) exchangeOIDCToken(subjectToken string) OIDCTokenFunc {
+	endpoint, clientID, clientSecret := cfg.oidcTokenEndpointURL(), cfg.oidcClientID, cfg.oidcClientSecret
+	scopes := cfg.oidcScopes()
+	return func(ctx context.Context, key OIDCTokenKey) (string, error) {
+		form := url.Values{
+			"grant_type":           {tokenExchangeGrantType},
+			"subject_token":        {subjectToken},
+			"subject_token_type":   {tokenTypeAccessToken},
+			"requested_token_type": {tokenTypeIDToken},
+			"audience":             {key.Audience},
+		}
+		if len(scopes) > 0 {
+			form.Set("scope", strings.Join(scopes, " "))
+		}
+
+		res, err := postTokenRequest(ctx, endpoint, clientID, clientSecret, form)
+		if err != nil {
+			return "", fmt.Errorf("token exchange failed: %w", err)
+		}
+		if res.IssuedTokenType != "" && res.IssuedTokenType != tokenTypeIDToken {
+			return "", fmt.Errorf("token exchange issued a %s instead of an ID token", res.IssuedTokenType)
+		}
+		if res.AccessToken == "" {
+			return "", errors.New("token exchange issued no token")
+		}
+		// RFC 8693 returns the issued token as access_token, whatever its type
+		return res.AccessToken, nil
+	}
+}
+
+// tokenResponse is the response of a token endpoint.
+type tokenResponse struct {
+	AccessToken     string `json:"access_token"`
+	IDToken         string `json:"id_token"`
+	RefreshToken    string `json:"refresh_token"`
+	IssuedTokenType string `json:"issued_token_type"`
+}
+
+// postTokenRequest posts the form to the token endpoint, authenticated as
+// the client if clientID is set.
+func postTokenRequest(ctx context.Context, endpoint, clientID, clientSecret string, form url.Values) (*tokenResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, oidcTokenRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if clientID != "" {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var res struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &res) == nil && res.Error != "" {
+			return nil, fmt.Errorf("%s: %s %s", resp.Status, res.Error, res.ErrorDescription)
+		}
+		return nil, errors.New(resp.Status)
+	}
+	res := &tokenResponse{}
+	if err := json.Unmarshal(body, res); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,81 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOIDCTokenExchange(t *testing.T) {
+	idToken := testJWTAudience(`{"aud":"mysql"}`)
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if id, secret, ok := r.BasicAuth(); !ok || id != "app" || secret != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		if r.PostFormValue("grant_type") != tokenExchangeGrantType || r.PostFormValue("subject_token") != "access-token" ||
+			r.PostFormValue("subject_token_type") != tokenTypeAccessToken || r.PostFormValue("requested_token_type") != tokenTypeIDToken ||
+			r.PostFormValue("audience") != "mysql" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_request", "error_description": r.PostForm.Encode()})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": idToken, "issued_token_type": tokenTypeIDToken, "token_type": "N_A"})
+	}))
+	defer srv.Close()
+
+	t.Setenv("MYSQL_OIDC_ACCESS_TOKEN", "access-token")
+	_, mc := newRWMockConn(0)
+	mc.cfg.Params = map[string]string{"authentication_openid_connect_client_id_token_env": "MYSQL_OIDC_ACCESS_TOKEN"}
+	if err := mc.cfg.Apply(OIDCAudience("mysql"), OIDCTokenExchange(srv.URL, "app", "s3cr3t")); err != nil {
+		t.Fatal(err)
+	}
+	src, err := mc.cfg.oidcTokenSource().resolve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer InvalidateOIDCToken(src.key)
+
+	for i := 0; i < 2; i++ {
+		resp, err := mc.oidcAuthResponse(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(resp[2:]) != idToken {
+			t.Errorf("expected the exchanged ID token, got %q", resp)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the ID token to be cached, got %d requests", requests)
+	}
+
+	// another subject token isn't exchanged for the cached ID token
+	t.Setenv("MYSQL_OIDC_ACCESS_TOKEN", "other-access-token")
+	if _, err := mc.oidcAuthResponse(nil); err == nil {
+		t.Error("expected the ID token of another subject token to be exchanged")
+	}
+	if requests != 2 {
+		t.Errorf("expected the other subject token to be exchanged, got %d requests", requests)
+	}
+	t.Setenv("MYSQL_OIDC_ACCESS_TOKEN", "access-token")
+
+	// rejected by the token endpoint
+	InvalidateOIDCToken(src.key)
+	mc.cfg.oidcClientSecret = "wrong"
+	if _, err := mc.oidcAuthResponse(nil); err == nil {
+		t.Error("expected the failed exchange to fail the authentication")
+	}
+}
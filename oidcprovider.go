@@ -0,0 +1,144 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Identity providers of OIDCProvider
+const (
+	OIDCProviderAuth0    = "auth0"
+	OIDCProviderAzureAD  = "azuread"
+	OIDCProviderKeycloak = "keycloak"
+	OIDCProviderOkta     = "okta"
+)
+
+// oidcProviderPreset describes the endpoints and quirks of an identity
+// provider.
+type oidcProviderPreset struct {
+	// tokenEndpoint returns the token endpoint of the issuer
+	tokenEndpoint func(issuer string) string
+	// scopes requested with the ID tokens
+	scopes []string
+	// whether the provider implements the token exchange of RFC 8693
+	tokenExchange bool
+}
+
+var oidcProviderPresets = map[string]oidcProviderPreset{
+	// https://auth0.com/docs/api/authentication, issuer https://{tenant}.auth0.com/
+	OIDCProviderAuth0: {
+		tokenEndpoint: func(issuer string) string { return issuer + "/oauth/token" },
+		scopes:        []string{"openid"},
+		tokenExchange: true,
+	},
+	// Microsoft Entra ID, issuer https://login.microsoftonline.com/{tenant}/v2.0
+	OIDCProviderAzureAD: {
+		tokenEndpoint: func(issuer string) string {
+			return strings.TrimSuffix(issuer, "/v2.0") + "/oauth2/v2.0/token"
+		},
+		scopes: []string{"openid", "profile"},
+		// Entra ID exchanges tokens with the on-behalf-of flow instead
+		tokenExchange: false,
+	},
+	// issuer https://{host}/realms/{realm}
+	OIDCProviderKeycloak: {
+		tokenEndpoint: func(issuer string) string { return issuer + "/protocol/openid-connect/token" },
+		scopes:        []string{"openid"},
+		tokenExchange: true,
+	},
+	// issuer https://{domain}/oauth2/{authorizationServerId}
+	OIDCProviderOkta: {
+		tokenEndpoint: func(issuer string) string { return issuer + "/v1/token" },
+		scopes:        []string{"openid"},
+		tokenExchange: true,
+	},
+}
+
+// OIDCProvider selects the preset of an identity provider, one of
+// OIDCProviderAuth0, OIDCProviderAzureAD, OIDCProviderKeycloak and
+// OIDCProviderOkta, for the issuer URL. The token endpoint of
+// OIDCTokenExchange is derived from the issuer and the scopes the provider
+// requires are requested, so that only the issuer and the client have to be
+// configured:
+//
+//	user@tcp(db:3306)/?oidcProvider=keycloak&oidcIssuer=https%3A%2F%2Fsso.example.com%2Frealms%2Fprod&oidcTokenExchange=true&oidcAudience=mysql&...
+func OIDCProvider(provider, issuer string) Option {
+	return func(cfg *Config) error {
+		if _, ok := oidcProviderPresets[provider]; !ok {
+			return fmt.Errorf("invalid oidcProvider value: %s", provider)
+		}
+		cfg.oidcProvider = provider
+		cfg.oidcIssuer = issuer
+		return nil
+	}
+}
+
+// oidcTokenEndpointURL returns the token endpoint configured with
+// OIDCTokenExchange, or derived from the issuer of OIDCProvider.
+func (cfg *Config) oidcTokenEndpointURL() string {
+	if cfg.oidcTokenEndpoint != "" {
+		return cfg.oidcTokenEndpoint
+	}
+	if preset, ok := oidcProviderPresets[cfg.oidcProvider]; ok && cfg.oidcIssuer != "" {
+		return preset.tokenEndpoint(strings.TrimSuffix(cfg.oidcIssuer, "/"))
+	}
+	return ""
+}
+
+// oidcScopes returns the scopes requested with ID tokens.
+func (cfg *Config) oidcScopes() []string {
+	return oidcProviderPresets[cfg.oidcProvider].scopes
+}
+
+// normalizeOIDCProvider checks the configuration of OIDCProvider.
+func (cfg *Config) normalizeOIDCProvider() error {
+	if cfg.oidcProvider == "" {
+		return nil
+	}
+	preset, ok := oidcProviderPresets[cfg.oidcProvider]
+	if !ok {
+		return fmt.Errorf("invalid oidcProvider value: %s", cfg.oidcProvider)
+	}
+	if cfg.oidcIssuer == "" {
+		return fmt.Errorf("oidcProvider %s requires oidcIssuer", cfg.oidcProvider)
+	}
+	if !isHTTPSURL(cfg.oidcIssuer) {
+		return fmt.Errorf("invalid oidcIssuer value: %s (an https URL is required)", cfg.oidcIssuer)
+	}
+	if cfg.oidcTokenExchange && !preset.tokenExchange {
+		return fmt.Errorf("oidcProvider %s doesn't support oidcTokenExchange", cfg.oidcProvider)
+	}
+	return nil
+}
+
+// isHTTPSURL reports whether s is an absolute https URL. The refresh and
+// subject tokens posted to token endpoints must not be sent in clear text;
+// http is accepted for loopback hosts only, e.g. for local test identity
+// providers.
+func isHTTPSURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	switch u.Scheme {
+	case "https":
+		return true
+	case "http":
+		if u.Hostname() == "localhost" {
+			return true
+		}
+		ip := net.ParseIP(u.Hostname())
+		return ip != nil && ip.IsLoopback()
+	}
+	return false
+}
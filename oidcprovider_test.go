@@ -0,0 +1,83 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import "testing"
+
+func TestOIDCProviderTokenEndpoint(t *testing.T) {
+	for _, tt := range []struct {
+		provider, issuer, want string
+	}{
+		{OIDCProviderAuth0, "https://example.eu.auth0.com/", "https://example.eu.auth0.com/oauth/token"},
+		{OIDCProviderAzureAD, "https://login.microsoftonline.com/9188040d/v2.0", "https://login.microsoftonline.com/9188040d/oauth2/v2.0/token"},
+		{OIDCProviderKeycloak, "https://sso.example.com/realms/prod", "https://sso.example.com/realms/prod/protocol/openid-connect/token"},
+		{OIDCProviderOkta, "https://example.okta.com/oauth2/default", "https://example.okta.com/oauth2/default/v1/token"},
+	} {
+		cfg := NewConfig()
+		if err := cfg.Apply(OIDCProvider(tt.provider, tt.issuer)); err != nil {
+			t.Fatal(err)
+		}
+		if got := cfg.oidcTokenEndpointURL(); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.provider, tt.want, got)
+		}
+	}
+
+	// an explicit token endpoint takes precedence
+	cfg := NewConfig()
+	if err := cfg.Apply(OIDCProvider(OIDCProviderOkta, "https://example.okta.com"), OIDCTokenExchange("https://idp.example.com/token", "", "")); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.oidcTokenEndpointURL(); got != "https://idp.example.com/token" {
+		t.Errorf("expected the configured token endpoint, got %q", got)
+	}
+
+	if err := cfg.Apply(OIDCProvider("google", "https://accounts.google.com")); err == nil {
+		t.Error("expected an unknown provider to be rejected")
+	}
+}
+
+func TestOIDCProviderTokenExchange(t *testing.T) {
+	cfg := NewConfig()
+	if err := cfg.Apply(OIDCProvider(OIDCProviderAzureAD, "https://login.microsoftonline.com/9188040d/v2.0"),
+		OIDCTokenExchange("", "app", "secret"), OIDCAudience("mysql")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.normalize(); err == nil {
+		t.Error("expected the token exchange to be rejected for Entra ID")
+	}
+
+	cfg.oidcProvider = OIDCProviderKeycloak
+	cfg.oidcIssuer = "https://sso.example.com/realms/prod"
+	if err := cfg.normalize(); err != nil {
+		t.Errorf("expected the token endpoint to be derived from the issuer, got %v", err)
+	}
+	if scopes := cfg.oidcScopes(); len(scopes) != 1 || scopes[0] != "openid" {
+		t.Errorf("expected the openid scope, got %q", scopes)
+	}
+}
+
+func TestIsHTTPSURL(t *testing.T) {
+	for _, tt := range []struct {
+		url  string
+		want bool
+	}{
+		{"https://idp.example.com/token", true},
+		{"http://idp.example.com/token", false},
+		{"http://localhost:8080/token", true},
+		{"http://127.0.0.1:8080/token", true},
+		{"http://[::1]/token", true},
+		{"http://127.0.0.1.example.com/token", false},
+		{"idp.example.com/token", false},
+		{"https:///token", false},
+	} {
+		if got := isHTTPSURL(tt.url); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.url, tt.want, got)
+		}
+	}
+}
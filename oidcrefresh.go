@@ -0,0 +1,128 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// TokenStore stores the refresh token of OIDCRefreshToken, which identity
+// providers may replace with each renewal. NewMemoryTokenStore,
+// NewFileTokenStore and NewKeychainTokenStore return the built-in stores;
+// CLI tools persist the tokens of a device flow login between runs in the
+// latter two.
+type TokenStore interface {
+	// LoadRefreshToken returns the current refresh token of key.
+	LoadRefreshToken(ctx context.Context, key OIDCTokenKey) (string, error)
+
+	// SaveRefreshToken stores the refresh token issued for key with an ID
+	// token, replacing the current one.
+	SaveRefreshToken(ctx context.Context, key OIDCTokenKey, token string) error
+}
+
+// OIDCRefreshToken renews the ID tokens of the OpenID Connect plugins at the
+// token endpoint with the refresh token of store, e.g. obtained by the device
+// or authorization code flow of an interactive login. The refresh tokens
+// issued with the ID tokens are saved to store. The endpoint must be an https
+// URL, or http on a loopback host; it may be empty if it is derived from
+// OIDCProvider. clientSecret is empty for public clients.
+//
+// The DSN params oidcRefreshToken and oidcRefreshTokenFile set a refresh
+// token, of which the renewals are kept in memory, or a file holding it,
+// which is rewritten with the renewals.
+//
+// The ID tokens are cached like the tokens of OIDCTokenProvider, by token
+// endpoint, clientID, audience and store: connectors share the ID tokens if
+// they renew them with the same store, e.g. the same refresh token file.
+func OIDCRefreshToken(endpoint, clientID, clientSecret string, store TokenStore) Option {
+	return func(cfg *Config) error {
+		if endpoint != "" {
+			cfg.oidcTokenEndpoint = endpoint
+		}
+		cfg.oidcClientID = clientID
+		cfg.oidcClientSecret = clientSecret
+		cfg.oidcTokenStore = store
+		return nil
+	}
+}
+
+// refreshTokenStore returns the TokenStore of OIDCRefreshToken or of the
+// refresh token DSN params, nil if none is configured.
+func (cfg *Config) refreshTokenStore() TokenStore {
+	switch {
+	case cfg.oidcTokenStore != nil:
+		return cfg.oidcTokenStore
+	case cfg.oidcRefreshTokenFile != "":
+		return NewFileTokenStore(cfg.oidcRefreshTokenFile)
+	case cfg.oidcRefreshToken != "":
+		return dsnTokenStore(cfg.oidcRefreshToken)
+	}
+	return nil
+}
+
+// refreshTokenKey returns the cache key of the ID tokens renewed with the
+// refresh tokens of store.
+func (cfg *Config) refreshTokenKey(store TokenStore) OIDCTokenKey {
+	key := cfg.oidcEndpointTokenKey()
+	id := fmt.Sprintf("%T:%v", store, store)
+	if v := reflect.ValueOf(store); v.Kind() == reflect.Pointer {
+		// stores of the same type differ by address
+		id = fmt.Sprintf("%T@%#x", store, v.Pointer())
+	}
+	key.grant = fmt.Sprintf("%x", sha256.Sum256([]byte(id)))
+	return key
+}
+
+// refreshOIDCToken returns an OIDCTokenFunc renewing the ID token with the
+// refresh token of store.
+func (cfg *Config) refreshOIDCToken(store TokenStore) OIDCTokenFunc {
+	endpoint, clientID, clientSecret := cfg.oidcTokenEndpointURL(), cfg.oidcClientID, cfg.oidcClientSecret
+	scopes := cfg.oidcScopes()
+	// the store keys the refresh tokens by client, not by store
+	storeKey := cfg.oidcEndpointTokenKey()
+	return func(ctx context.Context, key OIDCTokenKey) (string, error) {
+		refreshToken, err := store.LoadRefreshToken(ctx, storeKey)
+		if err != nil {
+			return "", fmt.Errorf("failed to load the refresh token: %w", err)
+		}
+		form := url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {refreshToken},
+		}
+		authID := clientID // client id of HTTP basic auth
+		if clientID != "" && clientSecret == "" {
+			// public clients identify themselves in the form
+			form.Set("client_id", clientID)
+			authID = ""
+		}
+		if len(scopes) > 0 {
+			form.Set("scope", strings.Join(scopes, " "))
+		}
+
+		res, err := postTokenRequest(ctx, endpoint, authID, clientSecret, form)
+		if err != nil {
+			return "", fmt.Errorf("token refresh failed: %w", err)
+		}
+		if res.IDToken == "" {
+			return "", errors.New("token refresh issued no ID token")
+		}
+		if res.RefreshToken != "" && res.RefreshToken != refreshToken {
+			if err := store.SaveRefreshToken(ctx, storeKey, res.RefreshToken); err != nil {
+				return "", fmt.Errorf("failed to save the refresh token: %w", err)
+			}
+		}
+		return res.IDToken, nil
+	}
+}
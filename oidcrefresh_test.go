@@ -0,0 +1,205 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// newRefreshServer returns a token endpoint renewing the refresh token with
+// every ID token it issues.
+func newRefreshServer(t *testing.T, idToken string) (*httptest.Server, *int) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.PostFormValue("grant_type") != "refresh_token" || r.PostFormValue("client_id") != "cli" ||
+			r.PostFormValue("refresh_token") != "rt-"+strconv.Itoa(requests) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": r.PostForm.Encode()})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token":  "access-token",
+			"id_token":      idToken,
+			"refresh_token": "rt-" + strconv.Itoa(requests+1),
+			"token_type":    "Bearer",
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestOIDCRefreshTokenFile(t *testing.T) {
+	idToken := testJWTAudience(`{"aud":"mysql"}`)
+	srv, requests := newRefreshServer(t, idToken)
+
+	file := filepath.Join(t.TempDir(), "refresh-token")
+	if err := os.WriteFile(file, []byte("rt-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, mc := newRWMockConn(0)
+	mc.cfg.oidcTokenEndpoint = srv.URL
+	mc.cfg.oidcClientID = "cli"
+	mc.cfg.oidcRefreshTokenFile = file
+	if err := mc.cfg.normalize(); err != nil {
+		t.Fatal(err)
+	}
+	key := mc.cfg.oidcTokenSource().key
+	defer InvalidateOIDCToken(key)
+
+	for i := 0; i < 2; i++ {
+		InvalidateOIDCToken(key)
+		resp, err := mc.oidcAuthResponse(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(resp[2:]) != idToken {
+			t.Errorf("expected the renewed ID token, got %q", resp)
+		}
+	}
+	if *requests != 2 {
+		t.Errorf("expected 2 renewals, got %d", *requests)
+	}
+	if b, err := os.ReadFile(file); err != nil || string(b) != "rt-3\n" {
+		t.Errorf("expected the file to hold the renewed refresh token, got %q (%v)", b, err)
+	}
+}
+
+func TestOIDCRefreshTokenParam(t *testing.T) {
+	idToken := testJWTAudience(`{"aud":"mysql"}`)
+	srv, requests := newRefreshServer(t, idToken)
+
+	_, mc := newRWMockConn(0)
+	mc.cfg.oidcTokenEndpoint = srv.URL
+	mc.cfg.oidcClientID = "cli"
+	mc.cfg.oidcRefreshToken = "rt-1"
+	key := mc.cfg.oidcTokenSource().key
+	storeKey := mc.cfg.oidcEndpointTokenKey()
+	defer InvalidateOIDCToken(key)
+	defer renewedRefreshTokens.Delete(renewedRefreshTokenKey{storeKey, "rt-1"})
+
+	for i := 0; i < 2; i++ {
+		InvalidateOIDCToken(key)
+		if _, err := mc.oidcAuthResponse(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if *requests != 2 {
+		t.Errorf("expected 2 renewals, got %d", *requests)
+	}
+
+	// the refresh token is revoked
+	InvalidateOIDCToken(key)
+	renewedRefreshTokens.Store(renewedRefreshTokenKey{storeKey, "rt-1"}, "revoked")
+	if _, err := mc.oidcAuthResponse(nil); err == nil {
+		t.Error("expected the failed renewal to fail the authentication")
+	}
+}
+
+type testTokenStore struct {
+	token string
+	saved []string
+}
+
+func (s *testTokenStore) LoadRefreshToken(ctx context.Context, key OIDCTokenKey) (string, error) {
+	return s.token, nil
+}
+
+func (s *testTokenStore) SaveRefreshToken(ctx context.Context, key OIDCTokenKey, token string) error {
+	s.token = token
+	s.saved = append(s.saved, token)
+	return nil
+}
+
+func TestOIDCRefreshTokenStore(t *testing.T) {
+	idToken := testJWTAudience(`{"aud":"mysql"}`)
+	srv, _ := newRefreshServer(t, idToken)
+
+	store := &testTokenStore{token: "rt-1"}
+	_, mc := newRWMockConn(0)
+	if err := mc.cfg.Apply(OIDCAudience("mysql"), OIDCRefreshToken(srv.URL, "cli", "", store)); err != nil {
+		t.Fatal(err)
+	}
+	defer InvalidateOIDCToken(mc.cfg.oidcTokenSource().key)
+
+	resp, err := mc.oidcAuthResponse(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp[2:]) != idToken {
+		t.Errorf("expected the renewed ID token, got %q", resp)
+	}
+	if len(store.saved) != 1 || store.saved[0] != "rt-2" {
+		t.Errorf("expected the renewed refresh token to be saved, got %q", store.saved)
+	}
+
+	// refresh tokens don't exchange tokens
+	mc.cfg.oidcTokenExchange = true
+	if err := mc.cfg.normalize(); err == nil {
+		t.Error("expected OIDCTokenExchange to conflict with OIDCRefreshToken")
+	}
+}
+
+func TestOIDCRefreshTokenPublicClient(t *testing.T) {
+	idToken := testJWTAudience(`{"aud":"mysql"}`)
+	srv, requests := newRefreshServer(t, idToken)
+
+	cfg := NewConfig()
+	cfg.oidcTokenEndpoint = srv.URL
+	cfg.oidcClientID = "cli"
+	store := &testTokenStore{token: "rt-1"}
+	refresh := cfg.refreshOIDCToken(store)
+
+	// every renewal identifies the public client, not only the first
+	for i := 0; i < 2; i++ {
+		token, err := refresh(context.Background(), cfg.oidcEndpointTokenKey())
+		if err != nil {
+			t.Fatalf("renewal %d: %v", i+1, err)
+		}
+		if token != idToken {
+			t.Errorf("expected the renewed ID token, got %q", token)
+		}
+	}
+	if *requests != 2 {
+		t.Errorf("expected 2 renewals, got %d", *requests)
+	}
+}
+
+func TestOIDCRefreshTokenKey(t *testing.T) {
+	cfg := NewConfig()
+	cfg.oidcTokenEndpoint = "https://idp.example.com/token"
+	cfg.oidcClientID = "cli"
+	alice, bob := &testTokenStore{token: "rt-alice"}, &testTokenStore{token: "rt-bob"}
+	keys := []OIDCTokenKey{
+		cfg.refreshTokenKey(alice),
+		cfg.refreshTokenKey(bob),
+		cfg.refreshTokenKey(dsnTokenStore("rt-alice")),
+		cfg.refreshTokenKey(dsnTokenStore("rt-bob")),
+		cfg.refreshTokenKey(NewFileTokenStore("/home/alice/.refresh-token")),
+		cfg.refreshTokenKey(NewFileTokenStore("/home/bob/.refresh-token")),
+	}
+	// the ID tokens renewed with different refresh tokens are cached apart
+	for i := range keys {
+		for j := range keys[:i] {
+			if keys[i] == keys[j] {
+				t.Errorf("keys %d and %d are equal: %+v", j, i, keys[i])
+			}
+		}
+	}
+	if cfg.refreshTokenKey(alice) != keys[0] || cfg.refreshTokenKey(dsnTokenStore("rt-alice")) != keys[2] {
+		t.Error("expected the same store to have the same key")
+	}
+}
@@ -0,0 +1,276 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// OUT and INOUT parameters of stored procedures are passed as sql.Out
+// arguments of CALL statements:
+//
+//	var total int64
+//	db.Exec("CALL order_total(?, ?)", orderID, sql.Out{Dest: &total})
+//
+// The server returns their values in PS mode only, as a result set of its
+// own flagged with statusPsOutParams, so statements with sql.Out arguments
+// are always prepared. Its columns are assigned to the Dest of the sql.Out
+// arguments in order. The input value of INOUT parameters is the value Dest
+// points to if In is set, else NULL.
+//
+// Exec assigns the parameters before returning. Query still returns their
+// result set and assigns them once it has been read or the rows are closed.
+
+// hasOutArgs reports whether any of args is a sql.Out.
+func hasOutArgs(args []driver.NamedValue) bool {
+	for _, arg := range args {
+		if _, ok := arg.Value.(sql.Out); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// bindOutArgs replaces the sql.Out arguments of args by their input value and
+// returns their destinations in order. args is not modified.
+func bindOutArgs(args []driver.NamedValue) ([]driver.NamedValue, []any, error) {
+	if !hasOutArgs(args) {
+		return args, nil, nil
+	}
+
+	bound := make([]driver.NamedValue, len(args))
+	var outs []any
+	for i, arg := range args {
+		bound[i] = arg
+		out, ok := arg.Value.(sql.Out)
+		if !ok {
+			continue
+		}
+
+		rv := reflect.ValueOf(out.Dest)
+		if rv.Kind() != reflect.Pointer || rv.IsNil() {
+			return nil, nil, fmt.Errorf("mysql: Dest of sql.Out argument %d is not a non-nil pointer", arg.Ordinal)
+		}
+		bound[i].Value = nil
+		if out.In {
+			v, err := converter{}.ConvertValue(rv.Elem().Interface())
+			if err != nil {
+				return nil, nil, err
+			}
+			bound[i].Value = v
+		}
+		outs = append(outs, out.Dest)
+	}
+	return bound, outs, nil
+}
+
+// readOutRow reads a row like readRow. It keeps the last row of every result
+// set and assigns it to the OUT parameters at the end of their result set.
+func (rows *binaryRows) readOutRow(dest []driver.Value) error {
+	mc := rows.mc
+	err := rows.readRow(dest)
+	if err == nil {
+		// The values may refer to the read buffer
+		row := make([]driver.Value, len(dest))
+		for i, v := range dest {
+			if b, ok := v.([]byte); ok {
+				v = bytes.Clone(b)
+			}
+			row[i] = v
+		}
+		rows.outRow = row
+		return nil
+	}
+
+	row := rows.outRow
+	rows.outRow = nil
+	if err == io.EOF && mc.status&statusPsOutParams != 0 && row != nil {
+		outs := rows.outs
+		rows.outs = nil
+		if err := assignOuts(outs, row); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// readOutParams reads the remaining result sets, up to and including the
+// one of the OUT parameters.
+func (rows *binaryRows) readOutParams() error {
+	for rows.mc != nil && rows.outs != nil {
+		if !rows.rs.done {
+			dest := make([]driver.Value, len(rows.rs.columns))
+			for {
+				err := rows.readOutRow(dest)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if err := rows.NextResultSet(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (rows *binaryRows) Close() error {
+	var err error
+	if rows.outs != nil && rows.mc != nil && rows.mc.error() == nil {
+		err = rows.readOutParams()
+	}
+	if cerr := rows.mysqlRows.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// execOut executes the statement like Exec and assigns the OUT parameters of
+// the procedure it calls to outs.
+func (stmt *mysqlStmt) execOut(args []driver.Value, outs []any) (driver.Result, error) {
+	if stmt.mc.closed.Load() {
+		return nil, driver.ErrBadConn
+	}
+	// Send command
+	err := stmt.writeExecutePacket(args, cursorTypeNoCursor)
+	if err != nil {
+		return nil, stmt.mc.markBadConn(err)
+	}
+
+	rows, err := stmt.readQueryResult()
+	if err != nil {
+		return nil, err
+	}
+	rows.outs = outs
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	copied := stmt.mc.result
+	return &copied, nil
+}
+
+// assignOuts assigns the values of the OUT parameters to their destinations.
+func assignOuts(outs []any, row []driver.Value) error {
+	if len(row) != len(outs) {
+		return fmt.Errorf("mysql: procedure returned %d OUT parameters for %d sql.Out arguments", len(row), len(outs))
+	}
+	for i, dest := range outs {
+		if err := assignOut(dest, row[i]); err != nil {
+			return fmt.Errorf("mysql: OUT parameter %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// assignOut stores src in the value dest points to, converting it like
+// Rows.Scan for the common destination types.
+func assignOut(dest any, src driver.Value) error {
+	if s, ok := dest.(sql.Scanner); ok {
+		return s.Scan(src)
+	}
+
+	dv := reflect.ValueOf(dest).Elem()
+	if src == nil {
+		switch dv.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			dv.SetZero()
+			return nil
+		}
+		return fmt.Errorf("cannot assign NULL to %s", dv.Type())
+	}
+
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dv.Type()) {
+		dv.Set(sv)
+		return nil
+	}
+
+	var err error
+	s := outString(src)
+	switch dv.Kind() {
+	case reflect.Pointer:
+		v := reflect.New(dv.Type().Elem())
+		if err = assignOut(v.Interface(), src); err == nil {
+			dv.Set(v)
+		}
+	case reflect.String:
+		dv.SetString(s)
+	case reflect.Slice:
+		if dv.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported destination %s for %T", dv.Type(), src)
+		}
+		dv.SetBytes([]byte(s))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(s, 10, dv.Type().Bits()); err == nil {
+			dv.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(s, 10, dv.Type().Bits()); err == nil {
+			dv.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, dv.Type().Bits()); err == nil {
+			dv.SetFloat(f)
+		}
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			dv.SetBool(b)
+		}
+	default:
+		return fmt.Errorf("unsupported destination %s for %T", dv.Type(), src)
+	}
+	if err != nil {
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) {
+			err = numErr.Err
+		}
+		return fmt.Errorf("converting %q to %s: %w", s, dv.Type(), err)
+	}
+	return nil
+}
+
+// outString returns the text of a value read from a row.
+func outString(v driver.Value) string {
+	switch v := v.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,171 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+)
+
+// mockOutParamsResponse returns the response to a CALL with a SELECT and one
+// OUT parameter.
+func mockOutParamsResponse(selected, out byte) []byte {
+	var resp []byte
+	resp = append(resp, mockPacket(1, 1)...)
+	resp = append(resp, mockColumn(2)...)
+	resp = append(resp, mockEOF(3, 0)...)
+	resp = append(resp, mockBinaryRow(4, selected)...)
+	resp = append(resp, mockEOF(5, statusMoreResultsExists)...)
+	resp = append(resp, mockPacket(6, 1)...)
+	resp = append(resp, mockColumn(7)...)
+	resp = append(resp, mockEOF(8, statusPsOutParams|statusMoreResultsExists)...)
+	resp = append(resp, mockBinaryRow(9, out)...)
+	resp = append(resp, mockEOF(10, statusPsOutParams|statusMoreResultsExists)...)
+	resp = append(resp, mockPacket(11, iOK, 0, 0, 0, 0, 0, 0)...)
+	return resp
+}
+
+func TestStmtExecOutParams(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	stmt := &mysqlStmt{mc: mc, id: 1, paramCount: 1}
+	conn.queuedReplies = [][]byte{mockOutParamsResponse(1, 42)}
+
+	inout := int64(7)
+	args := []driver.NamedValue{{Ordinal: 1, Value: sql.Out{Dest: &inout, In: true}}}
+	if _, err := stmt.ExecContext(context.Background(), args); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(conn.written, []byte{7, 0, 0, 0, 0, 0, 0, 0}) {
+		t.Error("expected the input value to be sent")
+	}
+	if inout != 42 {
+		t.Errorf("expected 42, got %d", inout)
+	}
+	if len(conn.data) != 0 || len(mc.buf.buf) != 0 {
+		t.Error("expected the whole response to be consumed")
+	}
+}
+
+func TestStmtExecOutParamsCount(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	stmt := &mysqlStmt{mc: mc, id: 1, paramCount: 2}
+	conn.queuedReplies = [][]byte{mockOutParamsResponse(1, 42)}
+
+	var a, b int64
+	args := []driver.NamedValue{
+		{Ordinal: 1, Value: sql.Out{Dest: &a}},
+		{Ordinal: 2, Value: sql.Out{Dest: &b}},
+	}
+	if _, err := stmt.ExecContext(context.Background(), args); err == nil {
+		t.Fatal("expected an error for 1 OUT parameter and 2 sql.Out arguments")
+	}
+}
+
+func TestStmtQueryOutParamsClose(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	stmt := &mysqlStmt{mc: mc, id: 1, paramCount: 1}
+	conn.queuedReplies = [][]byte{mockOutParamsResponse(1, 42)}
+
+	var out string
+	args := []driver.NamedValue{{Ordinal: 1, Value: sql.Out{Dest: &out}}}
+	rows, err := stmt.QueryContext(context.Background(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest := make([]driver.Value, 1)
+	if err := rows.Next(dest); err != nil {
+		t.Fatal(err)
+	}
+	if out != "" {
+		t.Errorf("expected the OUT parameter to be assigned on Close, got %q", out)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if out != "42" {
+		t.Errorf("expected \"42\", got %q", out)
+	}
+}
+
+func TestBindOutArgs(t *testing.T) {
+	var out int
+	in := "x"
+	args := []driver.NamedValue{
+		{Ordinal: 1, Value: int64(1)},
+		{Ordinal: 2, Value: sql.Out{Dest: &out}},
+		{Ordinal: 3, Value: sql.Out{Dest: &in, In: true}},
+	}
+	bound, outs, err := bindOutArgs(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bound[0].Value != int64(1) || bound[1].Value != nil || bound[2].Value != "x" {
+		t.Errorf("unexpected input values %v", bound)
+	}
+	if len(outs) != 2 || outs[0] != &out || outs[1] != &in {
+		t.Errorf("unexpected destinations %v", outs)
+	}
+	if _, ok := args[1].Value.(sql.Out); !ok {
+		t.Error("expected args to be left unchanged")
+	}
+
+	if _, _, err := bindOutArgs([]driver.NamedValue{{Ordinal: 1, Value: sql.Out{Dest: out}}}); err == nil {
+		t.Error("expected an error for a non-pointer Dest")
+	}
+}
+
+func TestAssignOut(t *testing.T) {
+	var (
+		i     int32
+		u     uint8
+		f     float64
+		s     string
+		b     []byte
+		ok    bool
+		p     *int64
+		iface any
+		ns    sql.NullString
+	)
+	tests := []struct {
+		dest  any
+		src   driver.Value
+		check func() bool
+	}{
+		{&i, int64(-5), func() bool { return i == -5 }},
+		{&i, []byte("12"), func() bool { return i == 12 }},
+		{&u, int64(200), func() bool { return u == 200 }},
+		{&f, []byte("1.5"), func() bool { return f == 1.5 }},
+		{&s, int64(3), func() bool { return s == "3" }},
+		{&b, []byte("abc"), func() bool { return string(b) == "abc" }},
+		{&ok, int64(1), func() bool { return ok }},
+		{&p, int64(9), func() bool { return p != nil && *p == 9 }},
+		{&p, nil, func() bool { return p == nil }},
+		{&iface, int64(4), func() bool { return iface == int64(4) }},
+		{&ns, []byte("n"), func() bool { return ns.Valid && ns.String == "n" }},
+	}
+	for _, tt := range tests {
+		if err := assignOut(tt.dest, tt.src); err != nil {
+			t.Errorf("assignOut(%T, %#v): %v", tt.dest, tt.src, err)
+			continue
+		}
+		if !tt.check() {
+			t.Errorf("assignOut(%T, %#v) assigned a wrong value", tt.dest, tt.src)
+		}
+	}
+
+	if err := assignOut(&u, int64(300)); err == nil {
+		t.Error("expected an error for an overflowing value")
+	}
+	if err := assignOut(&i, nil); err == nil {
+		t.Error("expected an error for NULL into an int32")
+	}
+}
@@ -14,12 +14,13 @@ import (
 	"database/sql/driver"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"math"
-	"os"
+	"net"
 	"strconv"
-	"strings"
 	"time"
 )
 
@@ -41,6 +42,7 @@ func (mc *mysqlConn) readNext(n int) ([]byte, error) {
 // Read packet to buffer 'data'
 func (mc *mysqlConn) readPacket() ([]byte, error) {
 	var prevData []byte
+	var firstSeq uint8
 	invalidSequence := false
 
 	readNext := mc.readNext
@@ -52,29 +54,23 @@ func (mc *mysqlConn) readPacket() ([]byte, error) {
 		// read packet header
 		data, err := readNext(4)
 		if err != nil {
-			mc.close()
-			if cerr := mc.canceled.Value(); cerr != nil {
-				return nil, cerr
-			}
-			mc.log(err)
-			return nil, ErrInvalidConn
+			return nil, mc.readFailed(err)
 		}
 
 		// packet length [24 bit]
 		pktLen := getUint24(data[:3])
 		seq := data[3]
+		if prevData == nil {
+			firstSeq = seq
+		}
 
 		if mc.compress {
 			// MySQL and MariaDB doesn't check packet nr in compressed packet.
-			if debug && seq != mc.compressSequence {
-				fmt.Printf("[debug] mismatched compression sequence nr: expected: %v, got %v",
-					mc.compressSequence, seq)
-			}
 			mc.compressSequence = seq + 1
 		} else {
 			// check packet sync [8 bit]
 			if seq != mc.sequence {
-				mc.log(fmt.Sprintf("[warn] unexpected seq nr: expected %v, got %v", mc.sequence, seq))
+				mc.warn(fmt.Sprintf("unexpected seq nr: expected %v, got %v", mc.sequence, seq))
 				// For large packets, we stop reading as soon as sync error.
 				if len(prevData) > 0 {
 					mc.close()
@@ -94,18 +90,18 @@ func (mc *mysqlConn) readPacket() ([]byte, error) {
 				mc.close()
 				return nil, ErrInvalidConn
 			}
+			mc.awaitingResponse = false
+			mc.tracePacket(PacketReceived, firstSeq, prevData)
 			return prevData, nil
 		}
 
 		// read packet body [pktLen bytes]
 		data, err = readNext(pktLen)
 		if err != nil {
-			mc.close()
-			if cerr := mc.canceled.Value(); cerr != nil {
-				return nil, cerr
-			}
-			mc.log(err)
-			return nil, ErrInvalidConn
+			return nil, mc.readFailed(err)
+		}
+		if sc := mc.cfg.statsCollector; sc != nil {
+			sc.PacketRead(4 + pktLen)
 		}
 
 		// return data if this was the last packet
@@ -122,6 +118,8 @@ func (mc *mysqlConn) readPacket() ([]byte, error) {
 					return nil, ErrPktSync
 				}
 			}
+			mc.awaitingResponse = false
+			mc.tracePacket(PacketReceived, firstSeq, data)
 			return data, nil
 		}
 
@@ -156,6 +154,23 @@ func writeLengthEncodedString(buf *bytes.Buffer, b []byte) {
 
 /*----------------------END ADD for support plugin JWT --------------------------*/
 
+// readFailed closes the connection after reading a packet failed with err and
+// returns the error to report: the cause of the cancellation if the
+// connection was canceled, ErrReadTimeout if ReadTimeout elapsed, else
+// ErrInvalidConn.
+func (mc *mysqlConn) readFailed(err error) error {
+	mc.close()
+	if cerr := mc.canceled.Value(); cerr != nil {
+		return cerr
+	}
+	mc.log(err)
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: %w", ErrReadTimeout, err)
+	}
+	return ErrInvalidConn
+}
+
 // Write packet buffer 'data'
 func (mc *mysqlConn) writePacket(data []byte) error {
 	pktLen := len(data) - 4
@@ -163,6 +178,11 @@ func (mc *mysqlConn) writePacket(data []byte) error {
 		return ErrPktTooLarge
 	}
 
+	mc.tracePacket(PacketSent, mc.sequence, data[4:])
+	if mc.sequence == 0 {
+		mc.awaitingResponse = true
+	}
+
 	writeFunc := mc.writeWithTimeout
 	if mc.compress {
 		writeFunc = mc.compIO.writePackets
@@ -173,13 +193,7 @@ func (mc *mysqlConn) writePacket(data []byte) error {
 		putUint24(data[:3], size)
 		data[3] = mc.sequence
 
-		fmt.Printf(">> Paquet send (seq=%d, size=%d):\n%s\n", mc.sequence, size, string(data[:4+size]))
-
 		// Write packet
-		if debug {
-			fmt.Printf("[DEBUG-packets.go] writePacket: size=%v seq=%v", size, mc.sequence)
-		}
-
 		n, err := writeFunc(data[:4+size])
 		if err != nil {
 			mc.cleanup()
@@ -200,6 +214,9 @@ func (mc *mysqlConn) writePacket(data []byte) error {
 			mc.cleanup()
 			return io.ErrShortWrite
 		}
+		if sc := mc.cfg.statsCollector; sc != nil {
+			sc.PacketWritten(n)
+		}
 
 		mc.sequence++
 		if size != maxPacketSize {
@@ -210,6 +227,86 @@ func (mc *mysqlConn) writePacket(data []byte) error {
 	}
 }
 
+// writePacketBuffers writes head followed by payload as one or more packets,
+// like writePacket. The headers are sent together with head and the payload
+// using writev where supported, so that a large payload isn't copied into a
+// buffer. Compressed connections copy the payload.
+func (mc *mysqlConn) writePacketBuffers(head, payload []byte) error {
+	if mc.buf.busy() {
+		return ErrBusyBuffer
+	}
+	pktLen := len(head) + len(payload)
+	if pktLen > mc.maxAllowedPacket {
+		return ErrPktTooLarge
+	}
+	if mc.compress {
+		data := make([]byte, 4, 4+pktLen)
+		data = append(append(data, head...), payload...)
+		return mc.writePacket(data)
+	}
+	if mc.tracingPackets() {
+		mc.tracePacket(PacketSent, mc.sequence, append(append([]byte(nil), head...), payload...))
+	}
+	if mc.sequence == 0 {
+		mc.awaitingResponse = true
+	}
+
+	// header, head and payload of the first packet, header and payload of
+	// the following ones
+	n := pktLen/maxPacketSize + 1
+	headers := make([]byte, 4*n)
+	bufs := make(net.Buffers, 0, 1+2*n)
+	sizes := make([]int, 0, n)
+	for {
+		size := min(maxPacketSize, pktLen)
+		header := headers[:4:4]
+		headers = headers[4:]
+		putUint24(header, size)
+		header[3] = mc.sequence + uint8(len(sizes))
+		bufs = append(bufs, header)
+		sizes = append(sizes, size)
+
+		rest := size
+		if len(head) > 0 {
+			k := min(rest, len(head))
+			bufs = append(bufs, head[:k])
+			head, rest = head[k:], rest-k
+		}
+		bufs = append(bufs, payload[:rest])
+		payload = payload[rest:]
+
+		pktLen -= size
+		if size != maxPacketSize {
+			break
+		}
+	}
+
+	if to := mc.cfg.WriteTimeout; to > 0 {
+		if err := mc.netConn.SetWriteDeadline(time.Now().Add(to)); err != nil {
+			return err
+		}
+	}
+	n64, err := bufs.WriteTo(mc.netConn)
+	if err != nil {
+		mc.cleanup()
+		if cerr := mc.canceled.Value(); cerr != nil {
+			return cerr
+		}
+		if n64 == 0 {
+			mc.log(err)
+			return errBadConnNoWrite
+		}
+		return err
+	}
+	mc.sequence += uint8(len(sizes))
+	if sc := mc.cfg.statsCollector; sc != nil {
+		for _, size := range sizes {
+			sc.PacketWritten(4 + size)
+		}
+	}
+	return nil
+}
+
 /******************************************************************************
 *                           Initialization Process                            *
 ******************************************************************************/
@@ -239,7 +336,14 @@ func (mc *mysqlConn) readHandshakePacket() (data []byte, capabilities capability
 
 	// server version [null terminated string]
 	// connection id [4 bytes]
-	pos := 1 + bytes.IndexByte(data[1:], 0x00) + 1 + 4
+	end := bytes.IndexByte(data[1:], 0x00)
+	pos := 1 + end + 1 + 4
+	// cipher, filler and capability flags [11 bytes]
+	if end < 0 || len(data) < pos+11 {
+		return nil, 0, 0, "", ErrMalformPkt
+	}
+	mc.serverVersion = string(data[1 : pos-5])
+	mc.threadID = binary.LittleEndian.Uint32(data[pos-4 : pos])
 
 	// first part of the password cipher [8 bytes]
 	authData := data[pos : pos+8]
@@ -262,6 +366,11 @@ func (mc *mysqlConn) readHandshakePacket() (data []byte, capabilities capability
 	pos += 2
 
 	if len(data) > pos {
+		// up to the second part of the password cipher [28 bytes]
+		if len(data) < pos+28 {
+			return nil, 0, 0, "", ErrMalformPkt
+		}
+
 		// character set [1 byte]
 		// status flags [2 bytes]
 		pos += 3
@@ -290,7 +399,7 @@ func (mc *mysqlConn) readHandshakePacket() (data []byte, capabilities capability
 		// The official Python library uses the fixed length 12
 		// which seems to work but technically could have a hidden bug.
 		authData = append(authData, data[pos:pos+12]...)
-		pos += 13
+		pos = min(pos+13, len(data))
 
 		// EOF if version (>= 5.5.7 and < 5.5.10) or (>= 5.6.0 and < 5.6.2)
 		// \NUL otherwise
@@ -325,8 +434,14 @@ func (mc *mysqlConn) initCapabilities(serverCapabilities capabilityFlag, serverE
 			clientPluginAuth |
 			clientMultiResults |
 			clientConnectAttrs |
-			clientDeprecateEOF
+			clientSessionTrack |
+			clientQueryAttributes
 
+	// Cursors rely on the status flags of the EOF packet following the
+	// column definitions, which is omitted with clientDeprecateEOF.
+	if !mc.cursorFetch() {
+		clientCapabilities |= clientDeprecateEOF
+	}
 	if cfg.ClientFoundRows {
 		clientCapabilities |= clientFoundRows
 	}
@@ -346,10 +461,13 @@ func (mc *mysqlConn) initCapabilities(serverCapabilities capabilityFlag, serverE
 	}
 
 	// only keep client capabilities that server have
+	mc.serverCapabilities = serverCapabilities
+	mc.serverExtCapabilities = serverExtCapabilities
 	mc.capabilities = clientCapabilities & serverCapabilities
 
-	// set MariaDB extended clientCacheMetadata capability if server support it
-	mc.extCapabilities = clientCacheMetadata & serverExtCapabilities
+	// set MariaDB extended clientExtendedMetadata, clientCacheMetadata and
+	// clientStmtBulkOperations capabilities if server support them
+	mc.extCapabilities = (clientExtendedMetadata | clientCacheMetadata | clientStmtBulkOperations) & serverExtCapabilities
 }
 
 // Client Authentication Packet
@@ -382,15 +500,7 @@ func (mc *mysqlConn) writeHandshakeResponsePacket(authResp []byte, plugin string
 	binary.LittleEndian.PutUint32(data[8:], 0)
 
 	// Collation ID [1 byte]
-	data[12] = defaultCollationID
-	if cname := mc.cfg.Collation; cname != "" {
-		colID, ok := collations[cname]
-		if ok {
-			data[12] = colID
-		} else if len(mc.cfg.charsets) > 0 {
-			return fmt.Errorf("unknown collation: %q", cname)
-		}
-	}
+	data[12] = mc.collationID()
 	// Filler [23 bytes] (all 0x00)
 	// or filler 19bytes + mariadb extCapabilities
 	pos := 13
@@ -413,8 +523,21 @@ func (mc *mysqlConn) writeHandshakeResponsePacket(authResp []byte, plugin string
 			return err
 		}
 		// Switch to TLS
-		tlsConn := tls.Client(mc.netConn, mc.cfg.TLS)
-		if err := tlsConn.Handshake(); err != nil {
+		tlsConfig := mc.cfg.TLS
+		if mc.cfg.TLSConfig == "verify-ca" {
+			tlsConfig = verifyCAOnly(tlsConfig)
+		}
+		if mc.cfg.oidcBinding() != nil {
+			tlsConfig = mc.recordClientCert(tlsConfig)
+		}
+		tlsConn := tls.Client(mc.netConn, tlsConfig)
+		_, span := mc.startHook(mc.hookCtx, HookInfo{Op: HookTLS})
+		err := tlsConn.Handshake()
+		if span != nil && err == nil {
+			span.info.TLSResumed = tlsConn.ConnectionState().DidResume
+		}
+		span.end(err)
+		if err != nil {
 			if cerr := mc.canceled.Value(); cerr != nil {
 				return cerr
 			}
@@ -436,21 +559,13 @@ func (mc *mysqlConn) writeHandshakeResponsePacket(authResp []byte, plugin string
 		authPlugin = v
 	}
 
-	if authPlugin == "authentication_openid_connect" || authPlugin == "authentication_openid_connect_client" {
+	// The response of plugins announced by the server was computed by auth
+	// already.
+	if isOIDCPlugin(authPlugin) && !isOIDCPlugin(plugin) {
 		// OIDC: Build token response
-		tokenFilePath, ok := mc.cfg.Params["authentication_openid_connect_client_id_token_file"]
-		if !ok || tokenFilePath == "" {
-			return fmt.Errorf("OIDC plugin selected but no JWT token file provided")
-		}
-		jwtBytes, err := os.ReadFile(tokenFilePath)
-		if err != nil {
-			return fmt.Errorf("failed to read JWT token file: %v", err)
+		if authResp, err = mc.oidcAuthResponse(nil); err != nil {
+			return err
 		}
-		jwtToken := strings.TrimSpace(string(jwtBytes))
-		var buf bytes.Buffer
-		buf.WriteByte(0x01) // Capability flag
-		writeLengthEncodedString(&buf, []byte(jwtToken))
-		authResp = buf.Bytes()
 	}
 
 	/*-----------------------END ADD for support plugin JWT --------------------------*/
@@ -476,15 +591,20 @@ func (mc *mysqlConn) writeHandshakeResponsePacket(authResp []byte, plugin string
 		data = append(data, mc.connector.encodedAttributes...)
 	}
 
-	// Debug: Print the handshake packet bytes
-	// fmt.Printf("[DEBUG-packets.go] Auth response (authResp) bytes: %v\n", authResp)
-	// fmt.Printf("[DEBUG-packets.go] Full handshake packet bytes:\n")
-	// fmt.Println()
-
 	// Send the handshake response packet
 	return mc.writePacket(data)
 }
 
+// collationID returns the id of the configured collation for the handshake.
+// Collations which can't be set with their id are set with SET NAMES, see
+// sessionCharsets.
+func (mc *mysqlConn) collationID() byte {
+	if id, ok := mc.handshakeCollation(mc.cfg.Collation); ok {
+		return id
+	}
+	return defaultCollationID
+}
+
 // http://dev.mysql.com/doc/internals/en/connection-phase-packets.html#packet-Protocol::AuthSwitchResponse
 func (mc *mysqlConn) writeAuthSwitchPacket(authData []byte) error {
 	pktLen := 4 + len(authData)
@@ -523,6 +643,13 @@ func (mc *mysqlConn) writeCommandPacketStr(command byte, arg string) error {
 	// Reset Packet Sequence
 	mc.resetSequence()
 
+	// Send large queries without copying them
+	if len(arg) >= writevThreshold && !mc.compress {
+		err := mc.writePacketBuffers([]byte{command}, stringBytes(arg))
+		mc.syncSequence()
+		return err
+	}
+
 	pktLen := 1 + len(arg)
 	data, err := mc.buf.takeBuffer(pktLen + 4)
 	if err != nil {
@@ -560,6 +687,76 @@ func (mc *mysqlConn) writeCommandPacketUint32(command byte, arg uint32) error {
 	return mc.writePacket(data)
 }
 
+// COM_CHANGE_USER
+// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_com_change_user.html
+func (mc *mysqlConn) writeChangeUserPacket(authResp []byte, plugin string) error {
+	collation := mc.collationID()
+
+	// Reset Packet Sequence
+	mc.resetSequence()
+
+	// Cannot use the write buffer since the length of the connection
+	// attributes is not limited.
+	data := make([]byte, 4, 4+1+len(mc.cfg.User)+1+1+len(authResp)+len(mc.cfg.DBName)+1+2+len(plugin)+1)
+
+	// Add command byte
+	data = append(data, comChangeUser)
+
+	// User [null terminated string]
+	data = append(data, mc.cfg.User...)
+	data = append(data, 0)
+
+	// Auth Data [1 byte length + data]
+	data = append(data, byte(len(authResp)))
+	data = append(data, authResp...)
+
+	// Database name [null terminated string]
+	data = append(data, mc.cfg.DBName...)
+	data = append(data, 0)
+
+	// Character set [2 bytes]
+	data = binary.LittleEndian.AppendUint16(data, uint16(collation))
+
+	// Authentication plugin name [null terminated string]
+	if mc.capabilities&clientPluginAuth != 0 {
+		data = append(data, plugin...)
+		data = append(data, 0)
+	}
+
+	// Connection Attributes
+	if mc.capabilities&clientConnectAttrs != 0 {
+		data = appendLengthEncodedInteger(data, uint64(len(mc.connector.encodedAttributes)))
+		data = append(data, mc.connector.encodedAttributes...)
+	}
+
+	// Send CMD packet
+	err := mc.writePacket(data)
+	mc.syncSequence()
+	return err
+}
+
+// writeFetchPacket requests the next numRows rows of the cursor of a prepared
+// statement.
+func (mc *mysqlConn) writeFetchPacket(stmtID, numRows uint32) error {
+	// Reset Packet Sequence
+	mc.resetSequence()
+
+	data, err := mc.buf.takeSmallBuffer(4 + 1 + 4 + 4)
+	if err != nil {
+		return err
+	}
+
+	// Add command byte
+	data[4] = comStmtFetch
+
+	// Add statement_id [32 bit] and num_rows [32 bit]
+	binary.LittleEndian.PutUint32(data[5:], stmtID)
+	binary.LittleEndian.PutUint32(data[9:], numRows)
+
+	// Send CMD packet
+	return mc.writePacket(data)
+}
+
 /******************************************************************************
 *                              Result Packets                                 *
 ******************************************************************************/
@@ -621,6 +818,7 @@ func (mc *okHandler) readResultSetHeaderPacket() (int, bool, error) {
 	// handleOkPacket replaces both values; other cases leave the values unchanged.
 	mc.result.affectedRows = append(mc.result.affectedRows, 0)
 	mc.result.insertIds = append(mc.result.insertIds, 0)
+	mc.result.warningCounts = append(mc.result.warningCounts, 0)
 
 	data, err := mc.conn().readPacket()
 	if err != nil {
@@ -653,7 +851,7 @@ func (mc *okHandler) readResultSetHeaderPacket() (int, bool, error) {
 // Error Packet
 // http://dev.mysql.com/doc/internals/en/generic-response-packets.html#packet-ERR_Packet
 func (mc *mysqlConn) handleErrorPacket(data []byte) error {
-	if data[0] != iERR {
+	if len(data) < 3 || data[0] != iERR {
 		return ErrMalformPkt
 	}
 
@@ -684,7 +882,7 @@ func (mc *mysqlConn) handleErrorPacket(data []byte) error {
 	pos := 3
 
 	// SQL State [optional: # + 5bytes string]
-	if data[3] == 0x23 {
+	if len(data) >= 9 && data[3] == 0x23 {
 		copy(me.SQLState[:], data[4:4+5])
 		pos = 9
 	}
@@ -742,9 +940,15 @@ func (mc *okHandler) handleOkPacket(data []byte) error {
 
 	// Affected rows [Length Coded Binary]
 	affectedRows, _, n = readLengthEncodedInteger(data[1:])
+	if len(data) < 1+n {
+		return ErrMalformPkt
+	}
 
 	// Insert id [Length Coded Binary]
 	insertId, _, m = readLengthEncodedInteger(data[1+n:])
+	if len(data) < 1+n+m+2 {
+		return ErrMalformPkt
+	}
 
 	// Update for the current statement result (only used by
 	// readResultSetHeaderPacket).
@@ -757,12 +961,45 @@ func (mc *okHandler) handleOkPacket(data []byte) error {
 
 	// server_status [2 bytes]
 	mc.status = readStatus(data[1+n+m : 1+n+m+2])
-	if mc.status&statusMoreResultsExists != 0 {
-		return nil
-	}
 
 	// warning count [2 bytes]
+	if len(data) >= 1+n+m+4 {
+		mc.warnings = binary.LittleEndian.Uint16(data[1+n+m+2 : 1+n+m+4])
+		mc.result.warnings += int(mc.warnings)
+		if len(mc.result.warningCounts) > 0 {
+			mc.result.warningCounts[len(mc.result.warningCounts)-1] = int(mc.warnings)
+		}
+	} else {
+		mc.warnings = 0
+	}
 
+	// info and session state changes
+	if mc.capabilities&clientSessionTrack != 0 && mc.status&statusSessionStateChanged != 0 {
+		if len(data) < 1+n+m+4 {
+			return ErrMalformPkt
+		}
+		return mc.handleSessionState(data[1+n+m+4:])
+	}
+
+	return nil
+}
+
+// handleSessionState handles the info and session state changes at the end
+// of an OK packet.
+func (mc *okHandler) handleSessionState(data []byte) error {
+	state, err := parseSessionState(data)
+	if err != nil {
+		return err
+	}
+	if state.GTIDs != "" {
+		mc.result.lastGTID = state.GTIDs
+	}
+	if mc.connectVars != nil {
+		maps.Copy(mc.connectVars, state.SystemVariables)
+	}
+	if fn := mc.cfg.sessionStateCallback; fn != nil {
+		fn(state)
+	}
 	return nil
 }
 
@@ -776,86 +1013,109 @@ func (mc *mysqlConn) readColumns(count int) ([]mysqlField, error) {
 		if err != nil {
 			return nil, err
 		}
-
-		// Catalog
-		pos, err := skipLengthEncodedString(data)
-		if err != nil {
+		if err = mc.readColumn(data, &columns[i]); err != nil {
 			return nil, err
 		}
+	}
 
-		// Database [len coded string]
-		n, err := skipLengthEncodedString(data[pos:])
-		if err != nil {
-			return nil, err
-		}
-		pos += n
+	// skip EOF packet if client does not support deprecateEOF
+	if err := mc.skipEof(); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
 
-		// Table [len coded string]
-		if mc.cfg.ColumnsWithAlias {
-			tableName, _, n, err := readLengthEncodedString(data[pos:])
-			if err != nil {
-				return nil, err
-			}
-			pos += n
-			columns[i].tableName = string(tableName)
-		} else {
-			n, err = skipLengthEncodedString(data[pos:])
-			if err != nil {
-				return nil, err
-			}
-			pos += n
-		}
+// readColumn parses the column definition packet data into column.
+func (mc *mysqlConn) readColumn(data []byte, column *mysqlField) error {
+	// Catalog
+	pos, err := skipLengthEncodedString(data)
+	if err != nil {
+		return err
+	}
 
-		// Original table [len coded string]
-		n, err = skipLengthEncodedString(data[pos:])
+	// Database [len coded string]
+	n, err := skipLengthEncodedString(data[pos:])
+	if err != nil {
+		return err
+	}
+	pos += n
+
+	// Table [len coded string]
+	if mc.cfg.ColumnsWithAlias {
+		tableName, _, n, err := readLengthEncodedString(data[pos:])
 		if err != nil {
-			return nil, err
+			return err
 		}
 		pos += n
-
-		// Name [len coded string]
-		name, _, n, err := readLengthEncodedString(data[pos:])
+		column.tableName = string(tableName)
+	} else {
+		n, err = skipLengthEncodedString(data[pos:])
 		if err != nil {
-			return nil, err
+			return err
 		}
-		columns[i].name = string(name)
 		pos += n
+	}
 
-		// Original name [len coded string]
-		n, err = skipLengthEncodedString(data[pos:])
+	// Original table [len coded string]
+	n, err = skipLengthEncodedString(data[pos:])
+	if err != nil {
+		return err
+	}
+	pos += n
+
+	// Name [len coded string]
+	name, _, n, err := readLengthEncodedString(data[pos:])
+	if err != nil {
+		return err
+	}
+	column.name = string(name)
+	pos += n
+
+	// Original name [len coded string]
+	n, err = skipLengthEncodedString(data[pos:])
+	if err != nil {
+		return err
+	}
+	pos += n
+
+	// Extended metadata [len coded string], if negotiated with MariaDB
+	if mc.extCapabilities&clientExtendedMetadata != 0 {
+		info, _, n, err := readLengthEncodedString(data[pos:])
 		if err != nil {
-			return nil, err
+			return err
 		}
 		pos += n
+		if err = column.readExtendedMetadata(info); err != nil {
+			return err
+		}
+	}
 
-		// Filler [uint8]
-		pos++
+	// Filler [uint8]
+	pos++
 
-		// Charset [charset, collation uint8]
-		columns[i].charSet = data[pos]
-		pos += 2
+	// Charset [charset, collation uint8]
+	column.charSet = data[pos]
+	pos += 2
 
-		// Length [uint32]
-		columns[i].length = binary.LittleEndian.Uint32(data[pos : pos+4])
-		pos += 4
+	// Length [uint32]
+	column.length = binary.LittleEndian.Uint32(data[pos : pos+4])
+	pos += 4
 
-		// Field type [uint8]
-		columns[i].fieldType = fieldType(data[pos])
-		pos++
+	// Field type [uint8]
+	column.fieldType = fieldType(data[pos])
+	pos++
+	column.asJSON = (column.fieldType == fieldTypeJSON || column.extFormat == "json") &&
+		mc.cfg.parseJSON && !mc.cfg.rawBytes
+	column.asDecimal = (column.fieldType == fieldTypeNewDecimal || column.fieldType == fieldTypeDecimal) &&
+		mc.cfg.parseDecimal && !mc.cfg.rawBytes
 
-		// Flags [uint16]
-		columns[i].flags = fieldFlag(binary.LittleEndian.Uint16(data[pos : pos+2]))
-		pos += 2
-
-		// Decimals [uint8]
-		columns[i].decimals = data[pos]
-	}
+	// Flags [uint16]
+	column.flags = fieldFlag(binary.LittleEndian.Uint16(data[pos : pos+2]))
+	pos += 2
 
-	// skip EOF packet if client does not support deprecateEOF
-	if err := mc.skipEof(); err != nil {
-		return nil, err
-	}
-	return columns, nil
+	// Decimals [uint8]
+	column.decimals = data[pos]
+	return nil
 }
 
 // Read Packets as Field Packets until EOF-Packet or an Error appears
@@ -877,28 +1137,29 @@ func (rows *textRows) readRow(dest []driver.Value) error {
 	// In such case, 0xFE can mean string larger than 0xffffff.
 	// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_basic_dt_integers.html#sect_protocol_basic_dt_int_le
 	if data[0] == iEOF && len(data) <= 0xffffff {
-		if mc.capabilities&clientDeprecateEOF == 0 {
-			// Deprecated EOF packet
-			// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_basic_eof_packet.html
-			mc.status = readStatus(data[3:])
-		} else {
-			// Ok Packet with an 0xFE header
-			_, _, n := readLengthEncodedInteger(data[1:])   // affected_rows
-			_, _, m := readLengthEncodedInteger(data[1+n:]) // last_insert_id
-			mc.status = readStatus(data[1+n+m:])
-		}
+		err = mc.readEOFStatus(data)
 		rows.rs.done = true
 		if !rows.HasNextResultSet() {
 			rows.mc = nil
 		}
+		if err != nil {
+			return err
+		}
 		return io.EOF
 	}
 	if data[0] == iERR {
 		rows.mc = nil
 		return mc.handleErrorPacket(data)
 	}
+	if err := rows.countRow(len(data)); err != nil {
+		return err
+	}
 
 	// RowSet Packet
+	if mc.cfg.rawBytes && !mc.parseTime {
+		return readRawRow(data, dest)
+	}
+
 	var (
 		n      int
 		isNull bool
@@ -919,6 +1180,11 @@ func (rows *textRows) readRow(dest []driver.Value) error {
 			dest[i] = nil
 			continue
 		}
+		if rows.rs.columns[i].asJSON {
+			// json.RawMessage isn't copied by Scan like []byte
+			dest[i] = json.RawMessage(bytes.Clone(buf))
+			continue
+		}
 
 		switch rows.rs.columns[i].fieldType {
 		case fieldTypeTimestamp,
@@ -926,7 +1192,11 @@ func (rows *textRows) readRow(dest []driver.Value) error {
 			fieldTypeDate,
 			fieldTypeNewDate:
 			if mc.parseTime {
-				dest[i], err = parseDateTime(buf, mc.cfg.Loc)
+				if isZeroDateTime(buf) {
+					dest[i], err = mc.cfg.zeroDateTimeValue()
+				} else {
+					dest[i], err = parseDateTime(buf, mc.cfg.Loc)
+				}
 			} else {
 				dest[i] = buf
 			}
@@ -949,6 +1219,13 @@ func (rows *textRows) readRow(dest []driver.Value) error {
 		case fieldTypeDouble:
 			dest[i], err = strconv.ParseFloat(string(buf), 64)
 
+		case fieldTypeDecimal, fieldTypeNewDecimal:
+			if rows.rs.columns[i].asDecimal {
+				dest[i] = Decimal(buf)
+			} else {
+				dest[i] = buf
+			}
+
 		default:
 			dest[i] = buf
 		}
@@ -960,6 +1237,35 @@ func (rows *textRows) readRow(dest []driver.Value) error {
 	return nil
 }
 
+// readRawRow is the fast path of textRows.readRow with RawBytes: the values
+// are returned as slices of data without conversion.
+func readRawRow(data []byte, dest []driver.Value) error {
+	pos := 0
+	for i := range dest {
+		// Most values are shorter than 251 bytes and have a 1-byte length.
+		if pos < len(data) && data[pos] < 0xfb {
+			end := pos + 1 + int(data[pos])
+			if end <= len(data) {
+				dest[i] = data[pos+1 : end : end]
+				pos = end
+				continue
+			}
+		}
+
+		buf, isNull, n, err := readLengthEncodedString(data[pos:])
+		if err != nil {
+			return err
+		}
+		pos += n
+		if isNull {
+			dest[i] = nil
+		} else {
+			dest[i] = buf
+		}
+	}
+	return nil
+}
+
 func (mc *mysqlConn) skipPackets(n int) error {
 	for i := 0; i < n; i++ {
 		if _, err := mc.readPacket(); err != nil {
@@ -972,9 +1278,13 @@ func (mc *mysqlConn) skipPackets(n int) error {
 // skips EOF packet after n * ColumnDefinition packets when clientDeprecateEOF is not set
 func (mc *mysqlConn) skipEof() error {
 	if mc.capabilities&clientDeprecateEOF == 0 {
-		if _, err := mc.readPacket(); err != nil {
+		data, err := mc.readPacket()
+		if err != nil {
 			return err
 		}
+		if len(data) >= 5 && data[0] == iEOF {
+			mc.status = readStatus(data[3:])
+		}
 	}
 	return nil
 }
@@ -1001,21 +1311,47 @@ func (mc *mysqlConn) skipRows() error {
 			// text row packets may starts with LengthEncodedString.
 			// In such case, 0xFE can mean string larger than 0xffffff.
 			if len(data) <= 0xffffff {
-				if mc.capabilities&clientDeprecateEOF == 0 {
-					// EOF packet
-					mc.status = readStatus(data[3:])
-				} else {
-					// OK packet with an 0xFE header
-					_, _, n := readLengthEncodedInteger(data[1:])   // affected_rows
-					_, _, m := readLengthEncodedInteger(data[1+n:]) // last_insert_id
-					mc.status = readStatus(data[1+n+m:])
-				}
-				return nil
+				return mc.readEOFStatus(data)
 			}
 		}
 	}
 }
 
+// readEOFStatus reads the server status and the warning count from the
+// packet ending a result set: an EOF packet, or an OK packet with an 0xFE
+// header if clientDeprecateEOF is set. The OK packet also carries the session
+// state changes of the statement, e.g. of a CALL.
+func (mc *mysqlConn) readEOFStatus(data []byte) error {
+	if mc.capabilities&clientDeprecateEOF == 0 {
+		// EOF packet
+		// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_basic_eof_packet.html
+		if len(data) < 5 {
+			return ErrMalformPkt
+		}
+		mc.warnings = binary.LittleEndian.Uint16(data[1:3])
+		mc.status = readStatus(data[3:])
+		return nil
+	}
+	// OK packet with an 0xFE header
+	_, _, n := readLengthEncodedInteger(data[1:]) // affected_rows
+	if len(data) < 1+n {
+		return ErrMalformPkt
+	}
+	_, _, m := readLengthEncodedInteger(data[1+n:]) // last_insert_id
+	if len(data) < 1+n+m+2 {
+		return ErrMalformPkt
+	}
+	mc.status = readStatus(data[1+n+m:])
+	mc.warnings = 0
+	if len(data) >= 1+n+m+4 {
+		mc.warnings = binary.LittleEndian.Uint16(data[1+n+m+2:])
+		if mc.capabilities&clientSessionTrack != 0 && mc.status&statusSessionStateChanged != 0 {
+			return mc.resultUnchanged().handleSessionState(data[1+n+m+4:])
+		}
+	}
+	return nil
+}
+
 /******************************************************************************
 *                           Prepared Statements                               *
 ******************************************************************************/
@@ -1051,43 +1387,36 @@ func (stmt *mysqlStmt) readPrepareResultPacket() (uint16, error) {
 // http://dev.mysql.com/doc/internals/en/com-stmt-send-long-data.html
 func (stmt *mysqlStmt) writeCommandLongData(paramID int, arg []byte) error {
 	maxLen := stmt.mc.maxAllowedPacket - 1
-	pktLen := maxLen
 
-	// After the header (bytes 0-3) follows before the data:
+	// Before the data:
 	// 1 byte command
 	// 4 bytes stmtID
 	// 2 bytes paramID
 	const dataOffset = 1 + 4 + 2
 
-	// Cannot use the write buffer since
-	// a) the buffer is too small
-	// b) it is in use
-	data := make([]byte, 4+1+4+2+len(arg))
+	var head [dataOffset]byte
 
-	copy(data[4+dataOffset:], arg)
+	// Add command byte [1 byte]
+	head[0] = comStmtSendLongData
 
-	for argLen := len(arg); argLen > 0; argLen -= pktLen - dataOffset {
-		if dataOffset+argLen < maxLen {
-			pktLen = dataOffset + argLen
-		}
+	// Add stmtID [32 bit]
+	binary.LittleEndian.PutUint32(head[1:], stmt.id)
 
-		stmt.mc.resetSequence()
-		// Add command byte [1 byte]
-		data[4] = comStmtSendLongData
+	// Add paramID [16 bit]
+	binary.LittleEndian.PutUint16(head[5:], uint16(paramID))
 
-		// Add stmtID [32 bit]
-		binary.LittleEndian.PutUint32(data[5:], stmt.id)
+	// The write buffer is in use, so arg is sent with writev instead of
+	// being copied.
+	for len(arg) > 0 {
+		n := min(len(arg), maxLen-dataOffset)
 
-		// Add paramID [16 bit]
-		binary.LittleEndian.PutUint16(data[9:], uint16(paramID))
+		stmt.mc.resetSequence()
 
 		// Send CMD packet
-		err := stmt.mc.writePacket(data[:4+pktLen])
-		if err == nil {
-			data = data[pktLen-dataOffset:]
-			continue
+		if err := stmt.mc.writePacketBuffers(head[:], arg[:n]); err != nil {
+			return err
 		}
-		return err
+		arg = arg[n:]
 	}
 
 	// Reset Packet Sequence
@@ -1097,7 +1426,7 @@ func (stmt *mysqlStmt) writeCommandLongData(paramID int, arg []byte) error {
 
 // Execute Prepared Statement
 // http://dev.mysql.com/doc/internals/en/com-stmt-execute.html
-func (stmt *mysqlStmt) writeExecutePacket(args []driver.Value) error {
+func (stmt *mysqlStmt) writeExecutePacket(args []driver.Value, cursorType byte) error {
 	if len(args) != stmt.paramCount {
 		return fmt.Errorf(
 			"argument count mismatch (got: %d; has: %d)",
@@ -1134,8 +1463,8 @@ func (stmt *mysqlStmt) writeExecutePacket(args []driver.Value) error {
 	// statement_id [4 bytes]
 	binary.LittleEndian.PutUint32(data[5:], stmt.id)
 
-	// flags (0: CURSOR_TYPE_NO_CURSOR) [1 byte]
-	data[9] = 0x00
+	// flags (cursor type) [1 byte]
+	data[9] = cursorType
 
 	// iteration_count (uint32(1)) [4 bytes]
 	binary.LittleEndian.PutUint32(data[10:], 1)
@@ -1319,6 +1648,14 @@ func (mc *okHandler) discardResults() error {
 
 // http://dev.mysql.com/doc/internals/en/binary-protocol-resultset-row.html
 func (rows *binaryRows) readRow(dest []driver.Value) error {
+	if c := rows.cursor; c != nil && rows.rs.done {
+		// Request the next batch of rows from the cursor
+		if err := rows.mc.writeFetchPacket(c.stmtID, c.fetchSize); err != nil {
+			return rows.mc.markBadConn(err)
+		}
+		rows.rs.done = false
+	}
+
 	data, err := rows.mc.readPacket()
 	if err != nil {
 		return err
@@ -1328,16 +1665,15 @@ func (rows *binaryRows) readRow(dest []driver.Value) error {
 	if data[0] != iOK {
 		// EOF/OK Packet
 		if data[0] == iEOF {
-			if rows.mc.capabilities&clientDeprecateEOF == 0 {
-				// EOF packet
-				rows.mc.status = readStatus(data[3:])
-			} else {
-				// OK Packet with an 0xFE header
-				_, _, n := readLengthEncodedInteger(data[1:])
-				_, _, m := readLengthEncodedInteger(data[1+n:])
-				rows.mc.status = readStatus(data[1+n+m:])
-			}
+			err = rows.mc.readEOFStatus(data)
 			rows.rs.done = true
+			if err != nil {
+				return err
+			}
+			if rows.cursor != nil && rows.mc.status&statusLastRowSent == 0 {
+				// The batch is exhausted, but the cursor has more rows
+				return rows.readRow(dest)
+			}
 			if !rows.HasNextResultSet() {
 				rows.mc = nil
 			}
@@ -1349,6 +1685,12 @@ func (rows *binaryRows) readRow(dest []driver.Value) error {
 		// Error otherwise
 		return mc.handleErrorPacket(data)
 	}
+	if err := rows.countRow(len(data)); err != nil {
+		return err
+	}
+
+	// The values of the previous row may be overwritten
+	rows.arena = rows.arena[:0]
 
 	// NULL-bitmap,  [(column-count + 7 + 2) / 8 bytes]
 	pos := 1 + (len(dest)+7+2)>>3
@@ -1371,27 +1713,27 @@ func (rows *binaryRows) readRow(dest []driver.Value) error {
 		// Numeric Types
 		case fieldTypeTiny:
 			if rows.rs.columns[i].flags&flagUnsigned != 0 {
-				dest[i] = int64(data[pos])
+				dest[i] = rows.int64Value(int64(data[pos]))
 			} else {
-				dest[i] = int64(int8(data[pos]))
+				dest[i] = rows.int64Value(int64(int8(data[pos])))
 			}
 			pos++
 			continue
 
 		case fieldTypeShort, fieldTypeYear:
 			if rows.rs.columns[i].flags&flagUnsigned != 0 {
-				dest[i] = int64(binary.LittleEndian.Uint16(data[pos : pos+2]))
+				dest[i] = rows.int64Value(int64(binary.LittleEndian.Uint16(data[pos : pos+2])))
 			} else {
-				dest[i] = int64(int16(binary.LittleEndian.Uint16(data[pos : pos+2])))
+				dest[i] = rows.int64Value(int64(int16(binary.LittleEndian.Uint16(data[pos : pos+2]))))
 			}
 			pos += 2
 			continue
 
 		case fieldTypeInt24, fieldTypeLong:
 			if rows.rs.columns[i].flags&flagUnsigned != 0 {
-				dest[i] = int64(binary.LittleEndian.Uint32(data[pos : pos+4]))
+				dest[i] = rows.int64Value(int64(binary.LittleEndian.Uint32(data[pos : pos+4])))
 			} else {
-				dest[i] = int64(int32(binary.LittleEndian.Uint32(data[pos : pos+4])))
+				dest[i] = rows.int64Value(int64(int32(binary.LittleEndian.Uint32(data[pos : pos+4]))))
 			}
 			pos += 4
 			continue
@@ -1399,24 +1741,33 @@ func (rows *binaryRows) readRow(dest []driver.Value) error {
 		case fieldTypeLongLong:
 			if rows.rs.columns[i].flags&flagUnsigned != 0 {
 				val := binary.LittleEndian.Uint64(data[pos : pos+8])
-				if val > math.MaxInt64 {
-					dest[i] = uint64ToString(val)
+				if rows.arenaMode() {
+					dest[i] = rows.appendArena(strconv.AppendUint(rows.arena, val, 10))
 				} else {
-					dest[i] = int64(val)
+					// like the text protocol, also for values above math.MaxInt64
+					dest[i] = val
 				}
 			} else {
-				dest[i] = int64(binary.LittleEndian.Uint64(data[pos : pos+8]))
+				dest[i] = rows.int64Value(int64(binary.LittleEndian.Uint64(data[pos : pos+8])))
 			}
 			pos += 8
 			continue
 
 		case fieldTypeFloat:
-			dest[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[pos : pos+4]))
+			if v := math.Float32frombits(binary.LittleEndian.Uint32(data[pos : pos+4])); rows.arenaMode() {
+				dest[i] = rows.appendArena(strconv.AppendFloat(rows.arena, float64(v), 'g', -1, 32))
+			} else {
+				dest[i] = v
+			}
 			pos += 4
 			continue
 
 		case fieldTypeDouble:
-			dest[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[pos : pos+8]))
+			if v := math.Float64frombits(binary.LittleEndian.Uint64(data[pos : pos+8])); rows.arenaMode() {
+				dest[i] = rows.appendArena(strconv.AppendFloat(rows.arena, v, 'g', -1, 64))
+			} else {
+				dest[i] = v
+			}
 			pos += 8
 			continue
 
@@ -1432,6 +1783,12 @@ func (rows *binaryRows) readRow(dest []driver.Value) error {
 			pos += n
 			if err == nil {
 				if !isNull {
+					if rows.rs.columns[i].asJSON {
+						// json.RawMessage isn't copied by Scan like []byte
+						dest[i] = json.RawMessage(bytes.Clone(dest[i].([]byte)))
+					} else if rows.rs.columns[i].asDecimal {
+						dest[i] = Decimal(dest[i].([]byte))
+					}
 					continue
 				} else {
 					dest[i] = nil
@@ -1466,7 +1823,9 @@ func (rows *binaryRows) readRow(dest []driver.Value) error {
 						rows.rs.columns[i].decimals,
 					)
 				}
-				dest[i], err = formatBinaryTime(data[pos:pos+int(num)], dstlen)
+				dest[i], err = rows.appendTime(appendBinaryTime, data[pos:pos+int(num)], dstlen)
+			case rows.mc.parseTime && num == 0:
+				dest[i], err = rows.mc.cfg.zeroDateTimeValue()
 			case rows.mc.parseTime:
 				dest[i], err = parseBinaryDateTime(num, data[pos:], rows.mc.cfg.Loc)
 			default:
@@ -1486,7 +1845,7 @@ func (rows *binaryRows) readRow(dest []driver.Value) error {
 						)
 					}
 				}
-				dest[i], err = formatBinaryDateTime(data[pos:pos+int(num)], dstlen)
+				dest[i], err = rows.appendTime(appendBinaryDateTime, data[pos:pos+int(num)], dstlen)
 			}
 
 			if err == nil {
@@ -1504,3 +1863,35 @@ func (rows *binaryRows) readRow(dest []driver.Value) error {
 
 	return nil
 }
+
+// arenaMode reports whether values of the rows which aren't strings are
+// returned as text in the arena, see RawBytes.
+func (rows *binaryRows) arenaMode() bool {
+	return rows.mc.cfg.rawBytes && !rows.mc.parseTime
+}
+
+// appendArena takes the arena extended with the next value by an append
+// function and returns the value.
+func (rows *binaryRows) appendArena(arena []byte) []byte {
+	start := len(rows.arena)
+	rows.arena = arena
+	return arena[start:len(arena):len(arena)]
+}
+
+// int64Value returns v, or v as text in the arena in arena mode.
+func (rows *binaryRows) int64Value(v int64) driver.Value {
+	if rows.arenaMode() {
+		return rows.appendArena(strconv.AppendInt(rows.arena, v, 10))
+	}
+	return v
+}
+
+// appendTime formats a DATE, DATETIME or TIME value of the current row with
+// the append function fn into the arena.
+func (rows *binaryRows) appendTime(fn func(dst, src []byte, length uint8) ([]byte, error), src []byte, length uint8) (driver.Value, error) {
+	arena, err := fn(rows.arena, src, length)
+	if err != nil {
+		return nil, err
+	}
+	return rows.appendArena(arena), nil
+}
@@ -0,0 +1,120 @@
+//go:build go1.18
+// +build go1.18
+
+package mysql
+
+import (
+	"bytes"
+	"testing"
+)
+
+// fuzzConn returns a connection reading data as a single packet.
+func fuzzConn(seq uint8, data []byte) *mysqlConn {
+	conn, mc := newRWMockConn(seq)
+	header := make([]byte, 4)
+	putUint24(header, len(data))
+	header[3] = seq
+	conn.data = append(header, data...)
+	return mc
+}
+
+func FuzzReadHandshakePacket(f *testing.F) {
+	f.Add(mockHandshake()[4:])
+	f.Add([]byte{10, '5', '.', '5', 0, 1, 0, 0, 0, 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 0, 0xff, 0xf7})
+	f.Add([]byte{iERR, 0x10, 0x04, 'T', 'o', 'o', ' ', 'm', 'a', 'n', 'y'})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) == 0 || len(data) >= maxPacketSize {
+			t.Skip()
+		}
+		mc := fuzzConn(0, data)
+		mc.readHandshakePacket()
+	})
+}
+
+func FuzzReadAuthResult(f *testing.F) {
+	f.Add([]byte{iOK, 0, 0, 2, 0, 0, 0})
+	f.Add([]byte{iAuthMoreData, cachingSha2PasswordFastAuthSuccess})
+	f.Add(append([]byte("\xfecaching_sha2_password\x00"), "0123456789abcdefghij\x00"...))
+	f.Add([]byte{iEOF})
+	f.Add([]byte{iERR, 0x15, 0x04, '#', '2', '8', '0', '0', '0', 'd', 'e', 'n', 'i', 'e', 'd'})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) == 0 || len(data) >= maxPacketSize {
+			t.Skip()
+		}
+		mc := fuzzConn(2, data)
+		mc.capabilities = clientSessionTrack
+		mc.readAuthResult()
+	})
+}
+
+func FuzzHandleOkPacket(f *testing.F) {
+	f.Add([]byte{0, 0, 2, 0, 0, 0}, false)
+	f.Add([]byte{0xfc, 0x10, 0x27, 0xfe, 1, 0, 0, 0, 0, 0, 0, 0, 0x02, 0x40, 1, 0}, true)
+	f.Add(append([]byte{0, 0, 0x02, 0x40, 0, 0, 0, 0x0b, 0, 0x09, 0x08}, "autocommit"[:8]...), true)
+
+	f.Fuzz(func(t *testing.T, data []byte, sessionTrack bool) {
+		_, mc := newRWMockConn(0)
+		if sessionTrack {
+			mc.capabilities |= clientSessionTrack
+		}
+		mc.clearResult().handleOkPacket(append([]byte{iOK}, data...))
+	})
+}
+
+func FuzzHandleErrorPacket(f *testing.F) {
+	f.Add([]byte{0x15, 0x04, '#', '2', '8', '0', '0', '0', 'd', 'e', 'n', 'i', 'e', 'd'})
+	f.Add([]byte{0x10, 0x04, 'T', 'o', 'o', ' ', 'm', 'a', 'n', 'y'})
+	f.Add([]byte{0x15})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, mc := newRWMockConn(0)
+		if err := mc.handleErrorPacket(append([]byte{iERR}, data...)); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func FuzzReadEOFStatus(f *testing.F) {
+	f.Add([]byte{0, 0, 2, 0}, false)
+	f.Add([]byte{0, 0, 0x02, 0x40, 0, 0, 0}, true)
+	f.Add([]byte{0}, true)
+
+	f.Fuzz(func(t *testing.T, data []byte, deprecateEOF bool) {
+		_, mc := newRWMockConn(0)
+		mc.capabilities = clientSessionTrack
+		if deprecateEOF {
+			mc.capabilities |= clientDeprecateEOF
+		}
+		mc.readEOFStatus(append([]byte{iEOF}, data...))
+	})
+}
+
+func FuzzReadLengthEncodedInteger(f *testing.F) {
+	f.Add([]byte{0xfa})
+	f.Add([]byte{0xfb})
+	f.Add([]byte{0xfc, 0x10, 0x27})
+	f.Add([]byte{0xfd, 1, 2, 3})
+	f.Add([]byte{0xfe, 1, 2, 3, 4, 5, 6, 7, 8})
+	f.Add([]byte{0xfe, 1})
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		num, isNull, n := readLengthEncodedInteger(b)
+		if n > len(b) && !(len(b) == 0 && isNull) {
+			// truncated
+			if _, err := skipLengthEncodedString(b); err == nil {
+				t.Errorf("expected truncated %x to be rejected", b)
+			}
+			return
+		}
+		if !isNull {
+			if b2 := appendLengthEncodedInteger(nil, num); n == len(b2) && !bytes.Equal(b2, b[:n]) {
+				t.Errorf("%x read as %d, encoded as %x", b[:n], num, b2)
+			}
+		}
+		if s, _, m, err := readLengthEncodedString(b); err == nil && (m > len(b) || len(s) > len(b)) {
+			t.Errorf("read %d bytes of %x", m, b)
+		}
+	})
+}
@@ -314,6 +314,28 @@ func TestReadPacketFail(t *testing.T) {
 	}
 }
 
+func TestReadPacketReadTimeout(t *testing.T) {
+	cfg := NewConfig()
+	cfg.ReadTimeout = 20 * time.Millisecond
+	server, mc := newPipeConn(cfg)
+	defer server.Close()
+
+	// the header of a packet whose body never arrives
+	go server.Write([]byte{0x05, 0x00, 0x00, 0x00})
+
+	_, err := mc.readPacket()
+	if !errors.Is(err, ErrReadTimeout) {
+		t.Fatalf("expected ErrReadTimeout, got %v", err)
+	}
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("expected the net timeout to be wrapped, got %v", err)
+	}
+	if !mc.closed.Load() {
+		t.Error("expected the connection to be closed")
+	}
+}
+
 // https://github.com/go-sql-driver/mysql/pull/801
 // not-NUL terminated plugin_name in init packet
 func TestRegression801(t *testing.T) {
@@ -356,6 +378,51 @@ func TestRegression801(t *testing.T) {
 		t.Errorf("expected authData '%v', got '%v'", expectedAuthData, authData)
 	}
 }
+
+func TestWritePacketBuffers(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	payload := bytes.Repeat([]byte{'x'}, maxPacketSize+10)
+
+	if err := mc.writePacketBuffers([]byte{comQuery}, payload); err != nil {
+		t.Fatal(err)
+	}
+	if mc.sequence != 2 {
+		t.Errorf("expected sequence 2, got %d", mc.sequence)
+	}
+
+	// first packet: maxPacketSize bytes with the command byte
+	written := conn.written
+	if !bytes.Equal(written[:5], []byte{0xff, 0xff, 0xff, 0, comQuery}) {
+		t.Errorf("unexpected first header: %v", written[:5])
+	}
+	written = written[4+maxPacketSize:]
+	// second packet: the remaining 11 bytes
+	if !bytes.Equal(written[:4], []byte{11, 0, 0, 1}) {
+		t.Errorf("unexpected second header: %v", written[:4])
+	}
+	if len(written) != 4+11 {
+		t.Errorf("expected 15 bytes in the second packet, got %d", len(written))
+	}
+}
+
+func TestWriteCommandLongData(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.maxAllowedPacket = 16
+	stmt := &mysqlStmt{mc: mc, id: 7}
+
+	// 8 data bytes per packet after the 7 bytes of command, stmtID, paramID
+	if err := stmt.writeCommandLongData(1, []byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+	expected := []byte{
+		15, 0, 0, 0, comStmtSendLongData, 7, 0, 0, 0, 1, 0, '0', '1', '2', '3', '4', '5', '6', '7',
+		9, 0, 0, 0, comStmtSendLongData, 7, 0, 0, 0, 1, 0, '8', '9',
+	}
+	if !bytes.Equal(conn.written, expected) {
+		t.Errorf("unexpected packets:\nwant %v\ngot  %v", expected, conn.written)
+	}
+}
+
 func TestWritePacket_Normal(t *testing.T) {
 	conn, mc := newRWMockConn(0)
 	mc.maxAllowedPacket = 1024
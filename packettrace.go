@@ -0,0 +1,102 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+// PacketDirection is the direction of a traced packet.
+type PacketDirection uint8
+
+const (
+	PacketSent     PacketDirection = iota // sent to the server
+	PacketReceived                        // received from the server
+)
+
+func (d PacketDirection) String() string {
+	if d == PacketSent {
+		return "sent"
+	}
+	return "received"
+}
+
+// PacketInfo describes a packet passed to a PacketTracer. Packets split
+// because of their size are passed as one packet, with the sequence id of
+// the first part. Compressed connections pass the uncompressed packets.
+type PacketInfo struct {
+	Direction PacketDirection
+	ThreadID  uint32 // connection id assigned by the server, 0 before the handshake
+	Sequence  uint8  // sequence id
+	Command   byte   // command of the packet or the command it responds to, 0 while connecting
+	Length    int    // length of the payload
+
+	// Payload is the payload, truncated to the maximum passed to
+	// TracePackets. It is nil if Redacted is set and must not be retained
+	// after the tracer returns.
+	Payload []byte
+
+	// Redacted is set for the packets the client sends to authenticate,
+	// including COM_CHANGE_USER, as they contain passwords or tokens.
+	Redacted bool
+}
+
+// PacketTracer is called with the packets sent and received by a connection.
+// It is called synchronously and must not use the connection.
+type PacketTracer func(PacketInfo)
+
+// TracePackets calls fn with every packet sent or received by the
+// connections, for debugging the protocol. If maxPayload is positive, the
+// payloads passed are truncated to maxPayload bytes.
+func TracePackets(fn PacketTracer, maxPayload int) Option {
+	return func(cfg *Config) error {
+		cfg.packetTracer = fn
+		cfg.packetTraceMaxPayload = maxPayload
+		return nil
+	}
+}
+
+// tracingPackets reports whether packets are traced or recorded.
+func (mc *mysqlConn) tracingPackets() bool {
+	return mc.recorder != nil || mc.cfg.packetTracer != nil
+}
+
+// tracePacket passes a packet to the traffic recorder and the packet tracer,
+// if any.
+func (mc *mysqlConn) tracePacket(dir PacketDirection, seq uint8, data []byte) {
+	if !mc.tracingPackets() {
+		return
+	}
+	if dir == PacketSent && seq == 0 && len(data) > 0 {
+		mc.tracedCommand = data[0]
+	}
+	redacted := dir == PacketSent && mc.redactSent
+	if mc.recorder != nil {
+		mc.recorder.record(dir, seq, data, redacted)
+	}
+	if fn := mc.cfg.packetTracer; fn != nil {
+		info := PacketInfo{
+			Direction: dir,
+			ThreadID:  mc.threadID,
+			Sequence:  seq,
+			Command:   mc.tracedCommand,
+			Length:    len(data),
+			Redacted:  redacted,
+		}
+		if !redacted {
+			info.Payload = data
+			if n := mc.cfg.packetTraceMaxPayload; n > 0 && len(data) > n {
+				info.Payload = data[:n]
+			}
+		}
+		fn(info)
+	}
+}
+
+// redactTraffic sets whether the packets sent are redacted in the recorded
+// and traced packets, during authentication.
+func (mc *mysqlConn) redactTraffic(redact bool) {
+	mc.redactSent = redact
+}
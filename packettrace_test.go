@@ -0,0 +1,97 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/colussim/mysql-auth-oidc-go/mysqltest"
+)
+
+func TestTracePackets(t *testing.T) {
+	srv := &mysqltest.Server{}
+	defer srv.Close()
+	const query = "SELECT name FROM users"
+	srv.Handle(query, mysqltest.Result{Columns: []string{"name"}, Rows: [][]any{{"gopher"}}})
+
+	var packets []PacketInfo
+	cfg := NewConfig()
+	cfg.User, cfg.Passwd = "app", "secret"
+	cfg.DialFunc = srv.Dial
+	if err := cfg.Apply(TracePackets(func(p PacketInfo) {
+		p.Payload = append([]byte(nil), p.Payload...)
+		packets = append(packets, p)
+	}, 8)); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewConnector(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := c.Connect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := conn.(driver.QueryerContext).QueryContext(context.Background(), query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	conn.Close()
+
+	if len(packets) < 3 {
+		t.Fatalf("expected the handshake packets, got %+v", packets)
+	}
+	handshake, response := packets[0], packets[1]
+	if handshake.Direction != PacketReceived || handshake.Sequence != 0 || handshake.Command != 0 ||
+		handshake.ThreadID != 0 || handshake.Payload[0] != 10 || len(handshake.Payload) != 8 || handshake.Length <= 8 {
+		t.Errorf("unexpected handshake packet %+v", handshake)
+	}
+	if response.Direction != PacketSent || response.Sequence != 1 || !response.Redacted ||
+		response.Payload != nil || response.Length == 0 || response.ThreadID != 1 {
+		t.Errorf("expected the handshake response to be redacted, got %+v", response)
+	}
+
+	var sent, received int
+	for _, p := range packets[2:] {
+		if p.Redacted {
+			t.Errorf("expected only the handshake response to be redacted, got %+v", p)
+		}
+		if p.Command != comQuery {
+			continue
+		}
+		if p.Direction == PacketSent {
+			sent++
+			if p.Sequence != 0 || p.Length != 1+len(query) || string(p.Payload) != "\x03SELECT " {
+				t.Errorf("unexpected query packet %+v", p)
+			}
+		} else {
+			received++
+		}
+	}
+	// column count, column, EOF, row, EOF
+	if sent != 1 || received != 5 {
+		t.Errorf("expected 1 query packet and 5 result packets, got %d and %d", sent, received)
+	}
+	if last := packets[len(packets)-1]; last.Direction != PacketSent || last.Command != comQuit {
+		t.Errorf("expected COM_QUIT last, got %+v", last)
+	}
+}
+
+func TestTracePacketsInvalid(t *testing.T) {
+	cfg := NewConfig()
+	if err := cfg.Apply(TracePackets(func(PacketInfo) {}, -1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewConnector(cfg); err == nil {
+		t.Error("expected a negative payload limit to be rejected")
+	}
+}
@@ -0,0 +1,186 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+)
+
+// lastPreparedStmtID makes COM_STMT_EXECUTE refer to the statement most
+// recently prepared on the connection. This is a MariaDB extension.
+const lastPreparedStmtID = 0xffffffff
+
+// usePipelinedPrepare reports whether a query with the given arguments
+// should be prepared and executed in a single round trip.
+func (mc *mysqlConn) usePipelinedPrepare(args []driver.Value) bool {
+	if !mc.cfg.pipelinePrepare || len(args) == 0 || mc.cfg.InterpolateParams {
+		return false
+	}
+	// Responses of compressed connections can't be interleaved with writes,
+	// and MySQL doesn't know lastPreparedStmtID.
+	if mc.compress || mc.capabilities&clientMySQL != 0 {
+		return false
+	}
+
+	// Arguments sent with COM_STMT_SEND_LONG_DATA need the statement id.
+	longDataSize := max(mc.maxAllowedPacket/(len(args)+1), 64)
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			if len(v) >= longDataSize {
+				return false
+			}
+		case []byte:
+			if len(v) >= longDataSize {
+				return false
+			}
+		case json.RawMessage:
+			if len(v) >= longDataSize {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// prepareExecute writes COM_STMT_PREPARE and COM_STMT_EXECUTE back-to-back
+// and reads the response of the prepare. The statement is closed right away;
+// the response of the execute is left for the caller to read.
+func (mc *mysqlConn) prepareExecute(query string, args []driver.Value) (*mysqlStmt, error) {
+	if mc.closed.Load() {
+		return nil, driver.ErrBadConn
+	}
+
+	if err := mc.writeCommandPacketStr(comStmtPrepare, query); err != nil {
+		// STMT_PREPARE is safe to retry. So we can return ErrBadConn here.
+		mc.log(err)
+		return nil, driver.ErrBadConn
+	}
+
+	stmt := &mysqlStmt{
+		mc:         mc,
+		id:         lastPreparedStmtID,
+		paramCount: len(args),
+	}
+	if err := stmt.writeExecutePacket(args, cursorTypeNoCursor); err != nil {
+		if mc.closed.Load() {
+			return nil, err
+		}
+		// The arguments could not be encoded, thus only the prepare was sent.
+		if perr := stmt.readPrepareResult(); perr != nil {
+			return nil, perr
+		}
+		if cerr := stmt.Close(); cerr != nil {
+			return nil, cerr
+		}
+		return nil, err
+	}
+
+	if err := stmt.readPrepareResult(); err != nil {
+		if mc.closed.Load() {
+			return nil, err
+		}
+		// The server rejects the execute of the failed prepare as well.
+		mc.sequence = 1
+		if _, rerr := mc.readPacket(); rerr != nil {
+			return nil, rerr
+		}
+		return nil, err
+	}
+
+	// The execute response may already be buffered, so the close can't use
+	// the shared buffer. COM_STMT_CLOSE has no response and is processed by
+	// the server after the pending execute.
+	data := make([]byte, 4+1+4)
+	data[4] = comStmtClose
+	binary.LittleEndian.PutUint32(data[5:], stmt.id)
+	mc.openStmts--
+	mc.sequence = 0
+	if err := mc.writePacket(data); err != nil {
+		return nil, err
+	}
+	// The execute response continues the sequence of the execute command.
+	mc.sequence = 1
+	return stmt, nil
+}
+
+func (mc *mysqlConn) queryPipelined(query string, args []driver.Value) (*binaryRows, error) {
+	stmt, err := mc.prepareExecute(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.readQueryResult()
+}
+
+func (mc *mysqlConn) execPipelined(query string, args []driver.Value) (driver.Result, error) {
+	stmt, err := mc.prepareExecute(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.readExecResult()
+}
+
+// usePipelinedSessionInit reports whether the queries setting up the session
+// after authentication should be sent back-to-back.
+func (mc *mysqlConn) usePipelinedSessionInit() bool {
+	// Responses of compressed connections can't be interleaved with writes,
+	// and falling back to the next charset depends on the previous response.
+	// Unknown session variables are skipped with one statement per variable.
+	return mc.cfg.pipelineSessionInit && !mc.compress && len(mc.sessionCharsets()) <= 1 &&
+		!mc.skipUnknownSystemVars()
+}
+
+// initSessionPipelined sends the query for max_allowed_packet if
+// queryMaxAllowedPacket is set, SET NAMES and the SET statement for the DSN
+// params without waiting for the responses in between, then reads all
+// responses. Like initSession, it fails if any of the queries fails.
+func (mc *mysqlConn) initSessionPipelined(queryMaxAllowedPacket bool) error {
+	var queries []string
+	if queryMaxAllowedPacket {
+		queries = append(queries, "SELECT @@max_allowed_packet")
+	}
+	if charsets := mc.sessionCharsets(); len(charsets) > 0 {
+		queries = append(queries, mc.setNamesQuery(charsets[0]))
+	}
+	if query := mc.paramsQuery(); query != "" {
+		queries = append(queries, query)
+	}
+
+	for _, query := range queries {
+		if err := mc.writeQueryPacket(query); err != nil {
+			return err
+		}
+	}
+
+	// Each response continues the sequence of its query.
+	var firstErr error
+	for i := range queries {
+		mc.sequence = 1
+		var err error
+		if i == 0 && queryMaxAllowedPacket {
+			var maxap []byte
+			if maxap, err = mc.readSystemVar(); err == nil {
+				err = mc.setMaxAllowedPacket(maxap)
+			}
+		} else {
+			err = mc.readExecResult()
+		}
+		if err != nil {
+			if mc.closed.Load() {
+				return err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,172 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestUsePipelinedPrepare(t *testing.T) {
+	_, mc := newRWMockConn(0)
+	args := []driver.Value{int64(1)}
+
+	if mc.usePipelinedPrepare(args) {
+		t.Error("pipelining must be disabled by default")
+	}
+
+	mc.cfg.pipelinePrepare = true
+	if !mc.usePipelinedPrepare(args) {
+		t.Error("expected pipelining for MariaDB")
+	}
+	if mc.usePipelinedPrepare(nil) {
+		t.Error("queries without arguments must not be prepared")
+	}
+	if mc.usePipelinedPrepare([]driver.Value{string(make([]byte, mc.maxAllowedPacket))}) {
+		t.Error("long data arguments must not be pipelined")
+	}
+
+	mc.capabilities |= clientMySQL
+	if mc.usePipelinedPrepare(args) {
+		t.Error("MySQL servers must not be pipelined")
+	}
+}
+
+func TestExecPipelined(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+
+	var resp []byte
+	// prepare OK: statement id 5, 0 columns, 1 param
+	resp = append(resp, 12, 0, 0, 1, iOK, 5, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0)
+	// param definition and EOF
+	resp = append(resp, 1, 0, 0, 2, 0)
+	resp = append(resp, 5, 0, 0, 3, iEOF, 0, 0, 2, 0)
+	// execute OK: 1 affected row
+	resp = append(resp, 7, 0, 0, 1, iOK, 1, 0, 2, 0, 0, 0)
+	conn.queuedReplies = [][]byte{nil, resp}
+
+	res, err := mc.execPipelined("UPDATE t SET v = ?", []driver.Value{int64(42)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Errorf("expected 1 affected row, got %d", n)
+	}
+
+	if !bytes.Contains(conn.written, []byte{comStmtExecute, 0xff, 0xff, 0xff, 0xff}) {
+		t.Errorf("expected execute of the last prepared statement, got %x", conn.written)
+	}
+	if !bytes.HasSuffix(conn.written, []byte{5, 0, 0, 0, comStmtClose, 5, 0, 0, 0}) {
+		t.Errorf("expected close of statement 5, got %x", conn.written)
+	}
+}
+
+func TestExecPipelinedPrepareError(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+
+	errPacket := func(seq byte) []byte {
+		return []byte{9, 0, 0, seq, iERR, 0x28, 0x04, '#', '4', '2', '0', '0', '0'}
+	}
+	resp := append(errPacket(1), errPacket(1)...)
+	conn.queuedReplies = [][]byte{nil, resp}
+
+	_, err := mc.execPipelined("UPDATE", []driver.Value{int64(42)})
+	var mysqlErr *MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1064 {
+		t.Fatalf("expected syntax error, got %v", err)
+	}
+	if len(conn.data) != 0 || len(mc.buf.buf) != 0 {
+		t.Error("expected both error packets to be consumed")
+	}
+}
+
+// mockSessionInitResponses returns the responses to SELECT
+// @@max_allowed_packet with the value 65536, to SET NAMES with err (OK if
+// nil) and to the SET statement of the params.
+func mockSessionInitResponses(err []byte) []byte {
+	var resp []byte
+	resp = append(resp, 1, 0, 0, 1, 1)
+	resp = append(resp, 1, 0, 0, 2, 0)
+	resp = append(resp, 5, 0, 0, 3, iEOF, 0, 0, 2, 0)
+	resp = append(resp, 6, 0, 0, 4, 5, '6', '5', '5', '3', '6')
+	resp = append(resp, 5, 0, 0, 5, iEOF, 0, 0, 2, 0)
+	if err != nil {
+		resp = append(resp, err...)
+	} else {
+		resp = append(resp, 7, 0, 0, 1, iOK, 0, 0, 2, 0, 0, 0)
+	}
+	return append(resp, 7, 0, 0, 1, iOK, 0, 0, 2, 0, 0, 0)
+}
+
+func TestInitSessionPipelined(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.cfg.pipelineSessionInit = true
+	mc.cfg.charsets = []string{"utf8mb4"}
+	mc.cfg.Params = map[string]string{"time_zone": "'+00:00'"}
+	if !mc.usePipelinedSessionInit() {
+		t.Fatal("expected the session init to be pipelined")
+	}
+
+	// All queries must be sent before the first response is read.
+	conn.queuedReplies = [][]byte{nil, nil, mockSessionInitResponses(nil)}
+	if err := mc.initSessionPipelined(true); err != nil {
+		t.Fatal(err)
+	}
+	if mc.maxAllowedPacket != 65535 {
+		t.Errorf("expected maxAllowedPacket 65535, got %d", mc.maxAllowedPacket)
+	}
+	for _, query := range []string{"SELECT @@max_allowed_packet", "SET NAMES utf8mb4", "SET time_zone = '+00:00'"} {
+		if !bytes.Contains(conn.written, []byte(query)) {
+			t.Errorf("expected %q to be sent", query)
+		}
+	}
+	if len(conn.data) != 0 {
+		t.Errorf("expected all responses to be read, %d bytes left", len(conn.data))
+	}
+}
+
+func TestInitSessionPipelinedError(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.cfg.pipelineSessionInit = true
+	mc.cfg.charsets = []string{"foo"}
+	mc.cfg.Params = map[string]string{"time_zone": "'+00:00'"}
+
+	errPacket := []byte{0x17, 0, 0, 1, iERR, 0x1d, 0x04, '#', 'H', 'Y', '0', '0', '0'}
+	errPacket = append(errPacket, "Unknown character set"...)
+	errPacket[0] = byte(len(errPacket) - 4)
+	conn.queuedReplies = [][]byte{nil, nil, mockSessionInitResponses(errPacket)}
+
+	err := mc.initSessionPipelined(true)
+	var mysqlErr *MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1053 {
+		t.Fatalf("expected the error of SET NAMES, got %v", err)
+	}
+	if len(conn.data) != 0 {
+		t.Errorf("expected all responses to be read, %d bytes left", len(conn.data))
+	}
+}
+
+func TestUsePipelinedSessionInit(t *testing.T) {
+	_, mc := newRWMockConn(0)
+	if mc.usePipelinedSessionInit() {
+		t.Error("pipelining must be disabled by default")
+	}
+	mc.cfg.pipelineSessionInit = true
+	mc.cfg.charsets = []string{"utf8mb4", "utf8"}
+	if mc.usePipelinedSessionInit() {
+		t.Error("fallback charsets must not be pipelined")
+	}
+	mc.cfg.charsets = nil
+	mc.compress = true
+	if mc.usePipelinedSessionInit() {
+		t.Error("compressed connections must not be pipelined")
+	}
+}
@@ -0,0 +1,69 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	placeholderCacheSize   = 256       // Number of queries cached per connector
+	maxPlaceholderQueryLen = 16 * 1024 // Longer queries are not cached
+)
+
+// placeholderCache caches the positions of the placeholders of the queries
+// interpolated by the connections of a connector, so that repeated queries
+// aren't scanned again. It is safe for concurrent use. A nil
+// *placeholderCache scans every query.
+type placeholderCache struct {
+	items sync.Map // query string -> []int
+	n     atomic.Int32
+}
+
+func newPlaceholderCache() *placeholderCache {
+	return &placeholderCache{}
+}
+
+// get returns the positions of the '?' placeholders of query.
+func (c *placeholderCache) get(query string) []int {
+	if c == nil || len(query) > maxPlaceholderQueryLen {
+		return findPlaceholders(query)
+	}
+
+	if pos, ok := c.items.Load(query); ok {
+		return pos.([]int)
+	}
+
+	pos := findPlaceholders(query)
+	if _, loaded := c.items.LoadOrStore(query, pos); !loaded && c.n.Add(1) > placeholderCacheSize {
+		// evict an arbitrary query
+		c.items.Range(func(q, _ any) bool {
+			if _, deleted := c.items.LoadAndDelete(q); deleted {
+				c.n.Add(-1)
+			}
+			return false
+		})
+	}
+	return pos
+}
+
+// findPlaceholders returns the positions of the '?' placeholders of query.
+func findPlaceholders(query string) []int {
+	pos := make([]int, 0, strings.Count(query, "?"))
+	for i := 0; ; i++ {
+		q := strings.IndexByte(query[i:], '?')
+		if q == -1 {
+			return pos
+		}
+		i += q
+		pos = append(pos, i)
+	}
+}
@@ -0,0 +1,121 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFindPlaceholders(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   []int
+	}{
+		{"SELECT 1", []int{}},
+		{"?", []int{0}},
+		{"SELECT ?, ?", []int{7, 10}},
+		{"SELECT ??", []int{7, 8}},
+	}
+	for _, tt := range tests {
+		if pos := findPlaceholders(tt.query); !reflect.DeepEqual(pos, tt.pos) {
+			t.Errorf("%q: want %v, got %v", tt.query, tt.pos, pos)
+		}
+	}
+}
+
+func TestPlaceholderCache(t *testing.T) {
+	c := newPlaceholderCache()
+	if pos := c.get("SELECT ?+?"); !reflect.DeepEqual(pos, []int{7, 9}) {
+		t.Fatalf("unexpected positions: %v", pos)
+	}
+	if _, ok := c.items.Load("SELECT ?+?"); !ok {
+		t.Errorf("query was not cached")
+	}
+
+	long := "SELECT ?" + strings.Repeat(" ", maxPlaceholderQueryLen)
+	if pos := c.get(long); !reflect.DeepEqual(pos, []int{7}) {
+		t.Errorf("unexpected positions of long query: %v", pos)
+	}
+	if _, ok := c.items.Load(long); ok {
+		t.Errorf("long query was cached")
+	}
+
+	for i := 0; i < 2*placeholderCacheSize; i++ {
+		c.get(fmt.Sprintf("SELECT %d, ?", i))
+	}
+	n := 0
+	c.items.Range(func(_, _ any) bool {
+		n++
+		return true
+	})
+	if n > placeholderCacheSize {
+		t.Errorf("cache exceeds its size: %d", n)
+	}
+
+	var nilCache *placeholderCache
+	if pos := nilCache.get("SELECT ?"); !reflect.DeepEqual(pos, []int{7}) {
+		t.Errorf("unexpected positions without cache: %v", pos)
+	}
+}
+
+func TestInterpolateParamsConcurrent(t *testing.T) {
+	connector := newConnector(NewConfig())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			mc := &mysqlConn{
+				buf:              newBuffer(),
+				cfg:              connector.cfg,
+				connector:        connector,
+				maxAllowedPacket: maxPacketSize,
+			}
+			for j := 0; j < 100; j++ {
+				q, err := mc.interpolateParams(fmt.Sprintf("SELECT ?, %d, ?", j%10), []driver.Value{int64(i), "x"})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if expected := fmt.Sprintf("SELECT %d, %d, 'x'", i, j%10); q != expected {
+					t.Errorf("want %q, got %q", expected, q)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestInterpolateParamsFloat64(t *testing.T) {
+	mc := &mysqlConn{
+		buf:              newBuffer(),
+		maxAllowedPacket: maxPacketSize,
+		cfg:              NewConfig(),
+	}
+
+	values := []float64{0, math.Copysign(0, -1), 1, -1, 42, 0.5, 999999, -999999, 1e6, -1e6, 123456789, 1e21, math.Pi, math.Inf(1), math.NaN()}
+	for _, v := range values {
+		q, err := mc.interpolateParams("SELECT ?", []driver.Value{v})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := "SELECT " + strconv.FormatFloat(v, 'g', -1, 64); q != expected {
+			t.Errorf("%v: want %q, got %q", v, expected, q)
+		}
+	}
+}
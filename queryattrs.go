@@ -0,0 +1,157 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+type queryAttrsCtxKey struct{}
+
+// QueryAttrs returns a copy of ctx carrying the given query attributes.
+//
+// Queries executed with the returned context send the attributes along, where
+// they can be read with mysql_query_attribute_string(), e.g. by audit plugins.
+// Query attributes require MySQL 8.0.23 or newer and are ignored by other
+// servers. They are only sent with text protocol queries, i.e. queries
+// without arguments or with InterpolateParams enabled.
+//
+//	ctx := mysql.QueryAttrs(ctx, map[string]any{"trace_id": traceID})
+//	_, err := db.ExecContext(ctx, "UPDATE accounts SET balance = 0")
+func QueryAttrs(ctx context.Context, attrs map[string]any) context.Context {
+	return context.WithValue(ctx, queryAttrsCtxKey{}, attrs)
+}
+
+// QueryAttrsFromContext returns the query attributes stored in ctx by
+// QueryAttrs.
+func QueryAttrsFromContext(ctx context.Context) (map[string]any, bool) {
+	attrs, ok := ctx.Value(queryAttrsCtxKey{}).(map[string]any)
+	return attrs, ok
+}
+
+// appendQueryAttrs appends the parameter block of COM_QUERY, holding the
+// query attributes, to data.
+// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_com_query.html
+func (mc *mysqlConn) appendQueryAttrs(data []byte, attrs map[string]any) ([]byte, error) {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// parameter_count, parameter_set_count (always 1)
+	data = appendLengthEncodedInteger(data, uint64(len(names)))
+	data = appendLengthEncodedInteger(data, 1)
+	if len(names) == 0 {
+		return data, nil
+	}
+
+	nullMask := make([]byte, (len(names)+7)/8)
+	var types, values []byte
+	for i, name := range names {
+		v, err := converter{}.ConvertValue(attrs[name])
+		if err != nil {
+			return nil, fmt.Errorf("query attribute %q: %w", name, err)
+		}
+
+		typ, flags := fieldTypeString, byte(0x00)
+		switch v := v.(type) {
+		case nil:
+			nullMask[i/8] |= 1 << (uint(i) & 7)
+			typ = fieldTypeNULL
+		case int64:
+			typ = fieldTypeLongLong
+			values = binary.LittleEndian.AppendUint64(values, uint64(v))
+		case uint64:
+			typ, flags = fieldTypeLongLong, 0x80 // type is unsigned
+			values = binary.LittleEndian.AppendUint64(values, v)
+		case float64:
+			typ = fieldTypeDouble
+			values = binary.LittleEndian.AppendUint64(values, math.Float64bits(v))
+		case bool:
+			typ = fieldTypeTiny
+			if v {
+				values = append(values, 0x01)
+			} else {
+				values = append(values, 0x00)
+			}
+		case []byte:
+			if v == nil {
+				nullMask[i/8] |= 1 << (uint(i) & 7)
+				typ = fieldTypeNULL
+				break
+			}
+			values = appendLengthEncodedString(values, string(v))
+		case string:
+			values = appendLengthEncodedString(values, v)
+		case time.Time:
+			var b []byte
+			if v.IsZero() {
+				b = append(b, "0000-00-00"...)
+			} else if b, err = appendDateTime(b, v.In(mc.cfg.Loc), mc.cfg.timeTruncate); err != nil {
+				return nil, err
+			}
+			values = appendLengthEncodedString(values, string(b))
+		default:
+			return nil, fmt.Errorf("query attribute %q: cannot convert type: %T", name, v)
+		}
+
+		types = append(types, byte(typ), flags)
+		types = appendLengthEncodedString(types, name)
+	}
+
+	data = append(data, nullMask...)
+	// new_params_bind_flag
+	data = append(data, 0x01)
+	data = append(data, types...)
+	return append(data, values...), nil
+}
+
+// writeQueryPacket sends COM_QUERY, along with the attributes of the current
+// query context if the server supports them.
+func (mc *mysqlConn) writeQueryPacket(query string) error {
+	if mc.capabilities&clientQueryAttributes == 0 {
+		return mc.writeCommandPacketStr(comQuery, query)
+	}
+
+	var attrs map[string]any
+	if mc.queryCtx != nil {
+		attrs, _ = QueryAttrsFromContext(mc.queryCtx)
+	}
+	params, err := mc.appendQueryAttrs(nil, attrs)
+	if err != nil {
+		return err
+	}
+
+	// Reset Packet Sequence
+	mc.resetSequence()
+
+	pktLen := 1 + len(params) + len(query)
+	data, err := mc.buf.takeBuffer(pktLen + 4)
+	if err != nil {
+		return err
+	}
+
+	// Add command byte
+	data[4] = comQuery
+
+	// Add parameters and query
+	n := copy(data[5:], params)
+	copy(data[5+n:], query)
+
+	// Send CMD packet
+	err = mc.writePacket(data)
+	mc.syncSequence()
+	return err
+}
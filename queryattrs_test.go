@@ -0,0 +1,67 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestAppendQueryAttrs(t *testing.T) {
+	_, mc := newRWMockConn(0)
+
+	data, err := mc.appendQueryAttrs(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{0, 1}) {
+		t.Errorf("unexpected parameters without attributes: %x", data)
+	}
+
+	data, err = mc.appendQueryAttrs(nil, map[string]any{"b": int64(1), "a": "x", "c": nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []byte{
+		3, 1, // parameter_count, parameter_set_count
+		0x04, // null bitmap: "c"
+		1,    // new_params_bind_flag
+		byte(fieldTypeString), 0, 1, 'a',
+		byte(fieldTypeLongLong), 0, 1, 'b',
+		byte(fieldTypeNULL), 0, 1, 'c',
+		1, 'x', // "a"
+		1, 0, 0, 0, 0, 0, 0, 0, // "b"
+	}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("unexpected parameters:\n got: %x\nwant: %x", data, expected)
+	}
+
+	if _, err := mc.appendQueryAttrs(nil, map[string]any{"a": struct{}{}}); err == nil {
+		t.Error("expected error for unsupported attribute type")
+	}
+}
+
+func TestExecContextQueryAttrs(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.capabilities |= clientQueryAttributes
+	conn.queuedReplies = [][]byte{okPacket}
+
+	ctx := QueryAttrs(context.Background(), map[string]any{"trace_id": "abc"})
+	if _, err := mc.ExecContext(ctx, "DO 1", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(conn.written, []byte("trace_id\x03abcDO 1")) {
+		t.Errorf("expected attributes to precede the query, got %q", conn.written)
+	}
+	if mc.queryCtx != nil {
+		t.Error("expected query context to be cleared after the query")
+	}
+}
@@ -0,0 +1,72 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"github.com/colussim/mysql-auth-oidc-go/mysqlerrors"
+)
+
+// Modes of AutoReconnect
+const (
+	AutoReconnectOff        = "off"
+	AutoReconnectIdempotent = "idempotent"
+)
+
+// AutoReconnect sets whether commands failing because the server closed the
+// connection before responding, e.g. after wait_timeout, are retried on a
+// new connection:
+//
+//   - AutoReconnectOff: the commands fail, the default.
+//   - AutoReconnectIdempotent: the commands return driver.ErrBadConn, so that
+//     database/sql retries them on another connection, if they weren't sent
+//     in a transaction and either
+//   - the server reported closing the idle connection (MySQL 8.0.24 and
+//     newer), so the command wasn't executed, or
+//   - the connection was closed before any packet of the response was read
+//     and the command is idempotent: a SELECT query as accepted by
+//     RetryReadOnly of StatementPolicy, a prepare or a ping.
+//
+// Connections closed while idle in the pool are usually noticed before they
+// are used (see CheckConnLiveness); AutoReconnect covers the rest. Commands
+// of a sql.Conn aren't retried by database/sql and fail with
+// driver.ErrBadConn.
+func AutoReconnect(mode string) Option {
+	return func(cfg *Config) error {
+		switch mode {
+		case AutoReconnectOff, AutoReconnectIdempotent:
+			cfg.autoReconnect = mode
+			return nil
+		}
+		return fmt.Errorf("invalid autoReconnect value: %s", mode)
+	}
+}
+
+// reconnectErr returns driver.ErrBadConn instead of err if the command
+// failed because the server closed the connection before responding and may
+// be retried on a new connection, see AutoReconnect.
+func (mc *mysqlConn) reconnectErr(idempotent bool, err error) error {
+	if err == nil || mc.cfg.autoReconnect != AutoReconnectIdempotent || mc.status&statusInTrans != 0 {
+		return err
+	}
+
+	var mysqlErr *MySQLError
+	closedIdle := errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlerrors.ER_CLIENT_INTERACTION_TIMEOUT &&
+		mc.flavor != FlavorMariaDB
+	closedBeforeResponse := err == ErrInvalidConn && mc.awaitingResponse && idempotent
+	if !closedIdle && !closedBeforeResponse {
+		return err
+	}
+	mc.log("retrying command on a new connection: ", err)
+	mc.close()
+	return driver.ErrBadConn
+}
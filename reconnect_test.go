@@ -0,0 +1,83 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/colussim/mysql-auth-oidc-go/mysqltest"
+)
+
+// reconnectDB returns a database of srv with autoReconnect set to mode.
+func reconnectDB(t *testing.T, srv *mysqltest.Server, mode string) *sql.DB {
+	t.Helper()
+	cfg := NewConfig()
+	cfg.DialFunc = srv.Dial
+	if err := cfg.Apply(AutoReconnect(mode)); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewConnector(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(c)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestAutoReconnectReadOnly(t *testing.T) {
+	srv := &mysqltest.Server{}
+	defer srv.Close()
+	const query = "SELECT name FROM users"
+	srv.Handle(query, mysqltest.Result{Drop: true}, mysqltest.Result{Columns: []string{"name"}, Rows: [][]any{{"gopher"}}})
+	srv.Handle("DELETE FROM sessions", mysqltest.Result{Drop: true}, mysqltest.Result{AffectedRows: 1})
+
+	db := reconnectDB(t, srv, AutoReconnectIdempotent)
+	var name string
+	if err := db.QueryRowContext(context.Background(), query).Scan(&name); err != nil || name != "gopher" {
+		t.Fatalf("expected the query to be retried, got %q, %v", name, err)
+	}
+
+	// Statements which aren't idempotent may have been executed
+	if _, err := db.ExecContext(context.Background(), "DELETE FROM sessions"); err == nil {
+		t.Error("expected the DELETE not to be retried")
+	}
+
+	var deletes int
+	for _, q := range srv.Queries() {
+		if q == "DELETE FROM sessions" {
+			deletes++
+		}
+	}
+	if deletes != 1 {
+		t.Errorf("expected a single DELETE, got %q", srv.Queries())
+	}
+}
+
+func TestAutoReconnectClosedIdle(t *testing.T) {
+	srv := &mysqltest.Server{}
+	defer srv.Close()
+	closedIdle := mysqltest.Result{Err: &mysqltest.Error{Number: 4031, SQLState: "HY000", Message: "The client was disconnected by the server because of inactivity."}}
+	srv.Handle("DELETE FROM sessions", closedIdle, mysqltest.Result{AffectedRows: 3})
+
+	res, err := reconnectDB(t, srv, AutoReconnectIdempotent).ExecContext(context.Background(), "DELETE FROM sessions")
+	if err != nil {
+		t.Fatalf("expected the DELETE to be retried, got %v", err)
+	}
+	if n, _ := res.RowsAffected(); n != 3 {
+		t.Errorf("expected 3 affected rows, got %d", n)
+	}
+
+	srv.Handle("DELETE FROM sessions", closedIdle, mysqltest.Result{AffectedRows: 3})
+	if _, err := reconnectDB(t, srv, AutoReconnectOff).ExecContext(context.Background(), "DELETE FROM sessions"); !IsErrorNumber(err, 4031) {
+		t.Errorf("expected error 4031 without autoReconnect, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import "database/sql/driver"
+
+// WriteCommand sends a command packet with the given command byte and
+// arguments, starting a new packet sequence.
+//
+// WriteCommand and ReadPacket give access to commands not implemented by the
+// driver itself, e.g. the replication protocol used by the binlog package.
+// The connection must not be used for anything else while the response of
+// the command is being read.
+func (mc *mysqlConn) WriteCommand(command byte, arg []byte) error {
+	if mc.closed.Load() {
+		return driver.ErrBadConn
+	}
+	if err := mc.writeCommandPacketStr(command, string(arg)); err != nil {
+		return mc.markBadConn(err)
+	}
+	return nil
+}
+
+// ReadPacket reads the next packet of the response to a command sent with
+// WriteCommand. Error packets are returned as *MySQLError.
+//
+// The returned slice is only valid until the next read from the connection.
+func (mc *mysqlConn) ReadPacket() ([]byte, error) {
+	data, err := mc.readPacket()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 && data[0] == iERR {
+		return nil, mc.handleErrorPacket(data)
+	}
+	return data, nil
+}
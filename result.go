@@ -27,12 +27,30 @@ type Result interface {
 	// AllLastInsertIds returns a slice containing the last inserted ID for each
 	// executed statement.
 	AllLastInsertIds() []int64
+	// WarningCount returns the number of warnings reported by the server for
+	// the executed statements. See ShowWarnings to receive the warnings.
+	WarningCount() int
+	// StatementResults returns the result of each executed statement, in
+	// order, e.g. of the statements of a multi-statement Exec with
+	// multiStatements.
+	StatementResults() []StatementResult
+}
+
+// StatementResult is the result of one executed statement, see
+// Result.StatementResults.
+type StatementResult struct {
+	RowsAffected int64
+	LastInsertId int64
+	Warnings     int // number of warnings, see ShowWarnings
 }
 
 type mysqlResult struct {
-	// One entry in both slices is created for every executed statement result.
-	affectedRows []int64
-	insertIds    []int64
+	// One entry in the slices is created for every executed statement result.
+	affectedRows  []int64
+	insertIds     []int64
+	warningCounts []int
+	lastGTID      string
+	warnings      int
 }
 
 func (res *mysqlResult) LastInsertId() (int64, error) {
@@ -50,3 +68,25 @@ func (res *mysqlResult) AllLastInsertIds() []int64 {
 func (res *mysqlResult) AllRowsAffected() []int64 {
 	return slices.Clone(res.affectedRows) // defensive copy
 }
+
+func (res *mysqlResult) LastGTID() string {
+	return res.lastGTID
+}
+
+func (res *mysqlResult) WarningCount() int {
+	return res.warnings
+}
+
+func (res *mysqlResult) StatementResults() []StatementResult {
+	results := make([]StatementResult, len(res.affectedRows))
+	for i := range results {
+		results[i].RowsAffected = res.affectedRows[i]
+		if i < len(res.insertIds) {
+			results[i].LastInsertId = res.insertIds[i]
+		}
+		if i < len(res.warningCounts) {
+			results[i].Warnings = res.warningCounts[i]
+		}
+	}
+	return results
+}
@@ -0,0 +1,45 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStatementResults(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.cfg.MultiStatements = true
+
+	var resp []byte
+	// affected rows 1, insert id 5, more results, no warnings
+	resp = append(resp, mockPacket(1, iOK, 1, 5, byte(statusMoreResultsExists), 0, 0, 0)...)
+	// affected rows 3, insert id 0, 2 warnings
+	resp = append(resp, mockPacket(2, iOK, 3, 0, 0, 0, 2, 0)...)
+	conn.queuedReplies = [][]byte{resp}
+
+	res, err := mc.Exec("INSERT INTO t VALUES (5); UPDATE t SET a = 1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []StatementResult{
+		{RowsAffected: 1, LastInsertId: 5},
+		{RowsAffected: 3, Warnings: 2},
+	}
+	r := res.(Result)
+	if got := r.StatementResults(); !slices.Equal(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if n := r.WarningCount(); n != 2 {
+		t.Errorf("expected 2 warnings, got %d", n)
+	}
+	if n, _ := r.RowsAffected(); n != 3 {
+		t.Errorf("expected the last statement to affect 3 rows, got %d", n)
+	}
+}
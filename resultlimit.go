@@ -0,0 +1,41 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+// ResultSetLimits bounds every result set read to maxRows rows of maxBytes
+// bytes in total, as sent by the server, protecting against unbounded
+// queries. Zero values disable the respective limit.
+//
+// Reading a row beyond a limit fails with ErrResultSetTooLarge and closes the
+// connection, which stops the server from sending the rest of the result set.
+func ResultSetLimits(maxBytes, maxRows int64) Option {
+	return func(cfg *Config) error {
+		cfg.maxResultSetBytes = maxBytes
+		cfg.maxResultSetRows = maxRows
+		return nil
+	}
+}
+
+// countRow counts a row of size bytes read from the result set and checks
+// the result set limits. If a limit is exceeded, the connection is closed.
+func (rows *mysqlRows) countRow(size int) error {
+	mc := rows.mc
+	if mc.cfg.maxResultSetBytes <= 0 && mc.cfg.maxResultSetRows <= 0 {
+		return nil
+	}
+	rows.rs.rows++
+	rows.rs.bytes += int64(size)
+	if mc.cfg.maxResultSetRows > 0 && rows.rs.rows > mc.cfg.maxResultSetRows ||
+		mc.cfg.maxResultSetBytes > 0 && rows.rs.bytes > mc.cfg.maxResultSetBytes {
+		mc.close()
+		rows.mc = nil
+		return ErrResultSetTooLarge
+	}
+	return nil
+}
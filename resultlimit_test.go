@@ -0,0 +1,93 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"database/sql/driver"
+	"io"
+	"testing"
+)
+
+func TestResultSetLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxBytes int64
+		maxRows  int64
+		rows     int // rows read before the limit is exceeded
+	}{
+		{"unlimited", 0, 0, 3},
+		{"rows", 0, 2, 2},
+		{"bytes", 5, 0, 2}, // rows of 2 bytes
+		{"both", 100, 1, 1},
+	}
+	columns := []mysqlField{{fieldType: fieldTypeLongLong}}
+	for _, tt := range tests {
+		conn, mc := newRWMockConn(1)
+		if err := mc.cfg.Apply(ResultSetLimits(tt.maxBytes, tt.maxRows)); err != nil {
+			t.Fatal(err)
+		}
+		conn.data = append(conn.data, mockPacket(1, 1, '1')...)
+		conn.data = append(conn.data, mockPacket(2, 1, '2')...)
+		conn.data = append(conn.data, mockPacket(3, 1, '3')...)
+		conn.data = append(conn.data, mockEOF(4, 0)...)
+
+		rows := &textRows{mysqlRows{mc: mc, rs: resultSet{columns: columns}}}
+		dest := make([]driver.Value, 1)
+		n := 0
+		var err error
+		for err = rows.Next(dest); err == nil; err = rows.Next(dest) {
+			n++
+		}
+		if n != tt.rows {
+			t.Errorf("%s: expected %d rows, got %d", tt.name, tt.rows, n)
+		}
+		if tt.rows == 3 {
+			if err != io.EOF {
+				t.Errorf("%s: expected io.EOF, got %v", tt.name, err)
+			}
+			continue
+		}
+		if err != ErrResultSetTooLarge {
+			t.Errorf("%s: expected ErrResultSetTooLarge, got %v", tt.name, err)
+		}
+		if !mc.closed.Load() {
+			t.Errorf("%s: expected the connection to be closed", tt.name)
+		}
+		if err := rows.Close(); err != nil {
+			t.Errorf("%s: expected Close to succeed, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestResultSetLimitsBinary(t *testing.T) {
+	conn, mc := newRWMockConn(1)
+	mc.cfg.maxResultSetRows = 1
+	conn.data = append(conn.data, mockBinaryRow(1, 1)...)
+	conn.data = append(conn.data, mockBinaryRow(2, 2)...)
+
+	rows := &binaryRows{mysqlRows: mysqlRows{mc: mc, rs: resultSet{columns: []mysqlField{{fieldType: fieldTypeLongLong}}}}}
+	dest := make([]driver.Value, 1)
+	if err := rows.Next(dest); err != nil {
+		t.Fatal(err)
+	}
+	if err := rows.Next(dest); err != ErrResultSetTooLarge {
+		t.Errorf("expected ErrResultSetTooLarge, got %v", err)
+	}
+}
+
+func TestResultSetLimitsPerResultSet(t *testing.T) {
+	rows := &mysqlRows{mc: &mysqlConn{cfg: &Config{maxResultSetRows: 1}}}
+	if err := rows.countRow(10); err != nil {
+		t.Fatal(err)
+	}
+	rows.rs = resultSet{} // next result set
+	if err := rows.countRow(10); err != nil {
+		t.Errorf("expected the limit to apply per result set, got %v", err)
+	}
+}
@@ -19,16 +19,33 @@ type resultSet struct {
 	columns     []mysqlField
 	columnNames []string
 	done        bool
+	rows        int64 // rows read, counted for the result set limits
+	bytes       int64 // bytes of the rows read, counted for the result set limits
 }
 
 type mysqlRows struct {
-	mc     *mysqlConn
-	rs     resultSet
-	finish func()
+	mc      *mysqlConn
+	rs      resultSet
+	finish  func()
+	span    *hookSpan // HookFetch, ended by Close
+	fetched int64     // number of rows read
 }
 
 type binaryRows struct {
 	mysqlRows
+	cursor      *cursor
+	stmtColumns []mysqlField   // columns of the statement, used when the server omits metadata
+	arena       []byte         // values of the current row converted to text, reused for every row
+	outs        []any          // destinations of sql.Out arguments, until assigned
+	outRow      []driver.Value // last row read while outs are pending
+}
+
+// cursor is a server-side cursor opened by COM_STMT_EXECUTE. While no rows of
+// it are pending in the stream, the result set is done and the next rows have
+// to be requested with COM_STMT_FETCH.
+type cursor struct {
+	stmtID    uint32
+	fetchSize uint32
 }
 
 type textRows struct {
@@ -63,9 +80,22 @@ func (rows *mysqlRows) ColumnTypeDatabaseTypeName(i int) string {
 	return rows.rs.columns[i].typeDatabaseName()
 }
 
-// func (rows *mysqlRows) ColumnTypeLength(i int) (length int64, ok bool) {
-// 	return int64(rows.rs.columns[i].length), true
-// }
+// ColumnTypeLength returns the max length of string columns in characters,
+// or in bytes for binary strings.
+func (rows *mysqlRows) ColumnTypeLength(i int) (length int64, ok bool) {
+	column := rows.rs.columns[i]
+	switch column.fieldType {
+	case fieldTypeVarChar, fieldTypeVarString, fieldTypeString,
+		fieldTypeTinyBLOB, fieldTypeBLOB, fieldTypeMediumBLOB, fieldTypeLongBLOB:
+		return int64(column.length) / column.charsetMaxLen(), true
+	}
+	return 0, false
+}
+
+// ColumnMetadata returns the metadata of the column i.
+func (rows *mysqlRows) ColumnMetadata(i int) ColumnMetadata {
+	return rows.rs.columns[i].metadata()
+}
 
 func (rows *mysqlRows) ColumnTypeNullable(i int) (nullable, ok bool) {
 	return rows.rs.columns[i].flags&flagNotNULL == 0, true
@@ -98,6 +128,14 @@ func (rows *mysqlRows) ColumnTypeScanType(i int) reflect.Type {
 }
 
 func (rows *mysqlRows) Close() (err error) {
+	if span := rows.span; span != nil {
+		rows.span = nil
+		defer func() {
+			span.info.Rows = rows.fetched
+			err = span.end(err)
+		}()
+	}
+
 	if f := rows.finish; f != nil {
 		f()
 		rows.finish = nil
@@ -133,59 +171,66 @@ func (rows *mysqlRows) HasNextResultSet() (b bool) {
 	return rows.mc.status&statusMoreResultsExists != 0
 }
 
-func (rows *mysqlRows) nextResultSet() (int, error) {
+func (rows *mysqlRows) nextResultSet() (int, bool, error) {
 	if rows.mc == nil {
-		return 0, io.EOF
+		return 0, false, io.EOF
 	}
 	if err := rows.mc.error(); err != nil {
-		return 0, err
+		return 0, false, err
 	}
 
 	// Remove unread packets from stream
 	if !rows.rs.done {
 		if err := rows.mc.skipRows(); err != nil {
-			return 0, err
+			return 0, false, err
 		}
 		rows.rs.done = true
 	}
 
 	if !rows.HasNextResultSet() {
 		rows.mc = nil
-		return 0, io.EOF
+		return 0, false, io.EOF
 	}
 	rows.rs = resultSet{}
 	// rows.mc.affectedRows and rows.mc.insertIds accumulate on each call to
 	// nextResultSet.
-	resLen, _, err := rows.mc.resultUnchanged().readResultSetHeaderPacket()
+	resLen, metadataFollows, err := rows.mc.resultUnchanged().readResultSetHeaderPacket()
 	if err != nil {
 		// Clean up about multi-results flag
 		rows.rs.done = true
 		rows.mc.status = rows.mc.status & (^statusMoreResultsExists)
 	}
-	return resLen, err
+	return resLen, metadataFollows, err
 }
 
-func (rows *mysqlRows) nextNotEmptyResultSet() (int, error) {
+func (rows *mysqlRows) nextNotEmptyResultSet() (int, bool, error) {
 	for {
-		resLen, err := rows.nextResultSet()
+		resLen, metadataFollows, err := rows.nextResultSet()
 		if err != nil {
-			return 0, err
+			return 0, false, err
 		}
 
 		if resLen > 0 {
-			return resLen, nil
+			return resLen, metadataFollows, nil
 		}
 
 		rows.rs.done = true
 	}
 }
 
+// NextResultSet advances to the next result set, e.g. of a stored procedure.
+// In PS mode the OUT parameters of a procedure follow as a result set of
+// their own, flagged with statusPsOutParams.
 func (rows *binaryRows) NextResultSet() error {
-	resLen, err := rows.nextNotEmptyResultSet()
+	resLen, metadataFollows, err := rows.nextNotEmptyResultSet()
 	if err != nil {
 		return err
 	}
 
+	if !metadataFollows {
+		rows.rs.columns = rows.stmtColumns
+		return rows.mc.skipEof()
+	}
 	rows.rs.columns, err = rows.mc.readColumns(resLen)
 	return err
 }
@@ -197,13 +242,22 @@ func (rows *binaryRows) Next(dest []driver.Value) error {
 		}
 
 		// Fetch next row from stream
-		return rows.readRow(dest)
+		var err error
+		if rows.outs != nil {
+			err = rows.readOutRow(dest)
+		} else {
+			err = rows.readRow(dest)
+		}
+		if err == nil {
+			rows.fetched++
+		}
+		return rows.span.intercept(err)
 	}
 	return io.EOF
 }
 
 func (rows *textRows) NextResultSet() (err error) {
-	resLen, err := rows.nextNotEmptyResultSet()
+	resLen, _, err := rows.nextNotEmptyResultSet()
 	if err != nil {
 		return err
 	}
@@ -219,7 +273,11 @@ func (rows *textRows) Next(dest []driver.Value) error {
 		}
 
 		// Fetch next row from stream
-		return rows.readRow(dest)
+		err := rows.readRow(dest)
+		if err == nil {
+			rows.fetched++
+		}
+		return rows.span.intercept(err)
 	}
 	return io.EOF
 }
@@ -0,0 +1,429 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockTextRow returns a text protocol row with the values 1234567, NULL, the
+// string "abc" and a string of 300 bytes, which has a 3-byte length.
+func mockTextRow(seq byte) []byte {
+	long := strings.Repeat("x", 300)
+	payload := []byte{7, '1', '2', '3', '4', '5', '6', '7', 0xfb, 3, 'a', 'b', 'c', 0xfc, 44, 1}
+	payload = append(payload, long...)
+	return append([]byte{byte(len(payload)), byte(len(payload) >> 8), 0, seq}, payload...)
+}
+
+func mockTextRows(mc *mysqlConn) *textRows {
+	rows := &textRows{mysqlRows{mc: mc}}
+	rows.rs.columns = []mysqlField{
+		{fieldType: fieldTypeLongLong},
+		{fieldType: fieldTypeLongLong},
+		{fieldType: fieldTypeVarString},
+		{fieldType: fieldTypeBLOB},
+	}
+	return rows
+}
+
+func TestTextRowsRawBytes(t *testing.T) {
+	for _, raw := range []bool{false, true} {
+		conn, mc := newRWMockConn(1)
+		mc.cfg.rawBytes = raw
+		conn.data = mockTextRow(1)
+		rows := mockTextRows(mc)
+
+		dest := make([]driver.Value, 4)
+		if err := rows.readRow(dest); err != nil {
+			t.Fatalf("rawBytes=%v: %v", raw, err)
+		}
+
+		if raw {
+			if b, ok := dest[0].([]byte); !ok || string(b) != "1234567" {
+				t.Errorf("rawBytes=%v: expected []byte(\"1234567\"), got %#v", raw, dest[0])
+			}
+		} else if dest[0] != int64(1234567) {
+			t.Errorf("rawBytes=%v: expected int64(1234567), got %#v", raw, dest[0])
+		}
+		if dest[1] != nil {
+			t.Errorf("rawBytes=%v: expected NULL, got %#v", raw, dest[1])
+		}
+		if b, ok := dest[2].([]byte); !ok || string(b) != "abc" {
+			t.Errorf("rawBytes=%v: expected \"abc\", got %#v", raw, dest[2])
+		}
+		if b, ok := dest[3].([]byte); !ok || !bytes.Equal(b, bytes.Repeat([]byte{'x'}, 300)) {
+			t.Errorf("rawBytes=%v: expected 300 bytes, got %d", raw, len(b))
+		}
+	}
+}
+
+func TestTextRowsRawBytesParseTime(t *testing.T) {
+	conn, mc := newRWMockConn(1)
+	mc.cfg.rawBytes = true
+	mc.parseTime = true
+	conn.data = mockTextRow(1)
+	rows := mockTextRows(mc)
+
+	dest := make([]driver.Value, 4)
+	if err := rows.readRow(dest); err != nil {
+		t.Fatal(err)
+	}
+	if dest[0] != int64(1234567) {
+		t.Errorf("expected values to be converted with parseTime, got %#v", dest[0])
+	}
+}
+
+func benchmarkTextRowsReadRow(b *testing.B, raw bool) {
+	conn, mc := newRWMockConn(0)
+	mc.cfg.rawBytes = raw
+	row := mockTextRow(0)
+	rows := mockTextRows(mc)
+	dest := make([]driver.Value, len(rows.rs.columns))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		conn.data = row
+		mc.sequence = 0
+		if err := rows.readRow(dest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTextRowsReadRow(b *testing.B) {
+	b.Run("convert", func(b *testing.B) { benchmarkTextRowsReadRow(b, false) })
+	b.Run("rawBytes", func(b *testing.B) { benchmarkTextRowsReadRow(b, true) })
+}
+
+// mockBinaryTimeRow returns a binary protocol row with the DATETIME(6) value
+// 1978-12-30 15:46:23.987654, the DATE value 1978-12-30 and the TIME value
+// -26:03:04.
+func mockBinaryTimeRow(seq byte) []byte {
+	payload := []byte{
+		iOK, 0x00, // header, NULL-bitmap
+		11, 0xba, 0x07, 12, 30, 15, 46, 23, 0x06, 0x12, 0x0f, 0x00,
+		4, 0xba, 0x07, 12, 30,
+		8, 1, 1, 0, 0, 0, 2, 3, 4,
+	}
+	return append([]byte{byte(len(payload)), 0, 0, seq}, payload...)
+}
+
+func mockBinaryTimeRows(mc *mysqlConn) *binaryRows {
+	rows := &binaryRows{mysqlRows: mysqlRows{mc: mc}}
+	rows.rs.columns = []mysqlField{
+		{fieldType: fieldTypeDateTime, decimals: 6},
+		{fieldType: fieldTypeDate},
+		{fieldType: fieldTypeTime},
+	}
+	return rows
+}
+
+func TestBinaryRowsTime(t *testing.T) {
+	conn, mc := newRWMockConn(1)
+	conn.data = append(mockBinaryTimeRow(1), mockBinaryTimeRow(2)...)
+	rows := mockBinaryTimeRows(mc)
+
+	dest := make([]driver.Value, 3)
+	for i := 0; i < 2; i++ {
+		if err := rows.readRow(dest); err != nil {
+			t.Fatalf("row %d: %v", i, err)
+		}
+		for j, expected := range []string{"1978-12-30 15:46:23.987654", "1978-12-30", "-26:03:04"} {
+			if b, ok := dest[j].([]byte); !ok || string(b) != expected {
+				t.Errorf("row %d: expected %q, got %#v", i, expected, dest[j])
+			}
+		}
+	}
+}
+
+func BenchmarkBinaryRowsReadRowTime(b *testing.B) {
+	conn, mc := newRWMockConn(0)
+	row := mockBinaryTimeRow(0)
+	rows := mockBinaryTimeRows(mc)
+	dest := make([]driver.Value, len(rows.rs.columns))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		conn.data = row
+		mc.sequence = 0
+		if err := rows.readRow(dest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// mockBinaryRawRow returns a binary protocol row with the BIGINT 1234567, NULL,
+// the TINYINT -5, the DOUBLE 1.5, the string "abc" and the DATE 1978-12-30.
+func mockBinaryRawRow(seq byte) []byte {
+	payload := []byte{
+		iOK, 0x08, // header, NULL-bitmap
+		0x87, 0xd6, 0x12, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0xfb,
+		0, 0, 0, 0, 0, 0, 0xf8, 0x3f,
+		3, 'a', 'b', 'c',
+		4, 0xba, 0x07, 12, 30,
+	}
+	return append([]byte{byte(len(payload)), 0, 0, seq}, payload...)
+}
+
+func mockBinaryRawRows(mc *mysqlConn) *binaryRows {
+	rows := &binaryRows{mysqlRows: mysqlRows{mc: mc}}
+	rows.rs.columns = []mysqlField{
+		{fieldType: fieldTypeLongLong},
+		{fieldType: fieldTypeLongLong},
+		{fieldType: fieldTypeTiny},
+		{fieldType: fieldTypeDouble},
+		{fieldType: fieldTypeVarString},
+		{fieldType: fieldTypeDate},
+	}
+	return rows
+}
+
+func TestBinaryRowsRawBytes(t *testing.T) {
+	for _, raw := range []bool{false, true} {
+		conn, mc := newRWMockConn(1)
+		mc.cfg.rawBytes = raw
+		conn.data = mockBinaryRawRow(1)
+		rows := mockBinaryRawRows(mc)
+
+		dest := make([]driver.Value, 6)
+		if err := rows.readRow(dest); err != nil {
+			t.Fatalf("rawBytes=%v: %v", raw, err)
+		}
+
+		converted := []driver.Value{int64(1234567), nil, int64(-5), 1.5}
+		text := []string{"1234567", "", "-5", "1.5"}
+		for i := range converted {
+			if i == 1 {
+				if dest[i] != nil {
+					t.Errorf("rawBytes=%v: expected NULL, got %#v", raw, dest[i])
+				}
+				continue
+			}
+			if raw {
+				if b, ok := dest[i].([]byte); !ok || string(b) != text[i] {
+					t.Errorf("rawBytes=%v: expected []byte(%q), got %#v", raw, text[i], dest[i])
+				}
+			} else if dest[i] != converted[i] {
+				t.Errorf("rawBytes=%v: expected %#v, got %#v", raw, converted[i], dest[i])
+			}
+		}
+		if b, ok := dest[4].([]byte); !ok || string(b) != "abc" {
+			t.Errorf("rawBytes=%v: expected \"abc\", got %#v", raw, dest[4])
+		}
+		if b, ok := dest[5].([]byte); !ok || string(b) != "1978-12-30" {
+			t.Errorf("rawBytes=%v: expected \"1978-12-30\", got %#v", raw, dest[5])
+		}
+	}
+}
+
+func TestRowsUnsignedBigint(t *testing.T) {
+	columns := []mysqlField{
+		{fieldType: fieldTypeLongLong, flags: flagUnsigned | flagNotNULL},
+		{fieldType: fieldTypeLongLong, flags: flagUnsigned},
+	}
+	for _, c := range columns {
+		if got := c.scanType(); got != scanTypeUint64 && got != scanTypeNullUint {
+			t.Errorf("expected a uint64 scan type, got %v", got)
+		}
+	}
+
+	conn, mc := newRWMockConn(1)
+	conn.data = mockPacket(1, iOK, 0,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		42, 0, 0, 0, 0, 0, 0, 0)
+	binary := &binaryRows{mysqlRows: mysqlRows{mc: mc, rs: resultSet{columns: columns}}}
+	dest := make([]driver.Value, 2)
+	if err := binary.readRow(dest); err != nil {
+		t.Fatal(err)
+	}
+	if dest[0] != uint64(math.MaxUint64) || dest[1] != uint64(42) {
+		t.Errorf("binary: expected uint64 values, got %#v", dest)
+	}
+
+	conn, mc = newRWMockConn(1)
+	conn.data = mockPacket(1, append([]byte{20}, "18446744073709551615\x0242"...)...)
+	text := &textRows{mysqlRows{mc: mc, rs: resultSet{columns: columns}}}
+	if err := text.readRow(dest); err != nil {
+		t.Fatal(err)
+	}
+	if dest[0] != uint64(math.MaxUint64) || dest[1] != uint64(42) {
+		t.Errorf("text: expected uint64 values, got %#v", dest)
+	}
+}
+
+func benchmarkBinaryRowsReadRow(b *testing.B, raw bool) {
+	conn, mc := newRWMockConn(0)
+	mc.cfg.rawBytes = raw
+	row := mockBinaryRawRow(0)
+	rows := mockBinaryRawRows(mc)
+	dest := make([]driver.Value, len(rows.rs.columns))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		conn.data = row
+		mc.sequence = 0
+		if err := rows.readRow(dest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBinaryRowsReadRow(b *testing.B) {
+	b.Run("convert", func(b *testing.B) { benchmarkBinaryRowsReadRow(b, false) })
+	b.Run("rawBytes", func(b *testing.B) { benchmarkBinaryRowsReadRow(b, true) })
+}
+
+func TestColumnTypeLength(t *testing.T) {
+	rows := &mysqlRows{rs: resultSet{columns: []mysqlField{
+		{fieldType: fieldTypeVarString, length: 40, charSet: defaultCollationID},           // VARCHAR(10) utf8mb4
+		{fieldType: fieldTypeString, length: 10, charSet: collations["latin1_swedish_ci"]}, // CHAR(10) latin1
+		{fieldType: fieldTypeBLOB, length: 65535, charSet: binaryCollationID},              // BLOB
+		{fieldType: fieldTypeLong, length: 11},
+	}}}
+	tests := []struct {
+		length int64
+		ok     bool
+	}{{10, true}, {10, true}, {65535, true}, {0, false}}
+	for i, tt := range tests {
+		if length, ok := rows.ColumnTypeLength(i); length != tt.length || ok != tt.ok {
+			t.Errorf("column %d: expected %d, %v, got %d, %v", i, tt.length, tt.ok, length, ok)
+		}
+	}
+}
+
+func TestColumnMetadata(t *testing.T) {
+	var rows driver.Rows = &textRows{mysqlRows{rs: resultSet{columns: []mysqlField{{
+		tableName: "t",
+		name:      "id",
+		length:    20,
+		flags:     flagNotNULL | flagPriKey | flagUnsigned | flagAutoIncrement,
+		fieldType: fieldTypeLongLong,
+		charSet:   binaryCollationID,
+	}}}}}
+	md := rows.(ColumnMetadataRows).ColumnMetadata(0)
+	want := ColumnMetadata{
+		Table:         "t",
+		Name:          "id",
+		Type:          "UNSIGNED BIGINT",
+		Collation:     "binary",
+		Length:        20,
+		NotNull:       true,
+		PrimaryKey:    true,
+		Unsigned:      true,
+		AutoIncrement: true,
+	}
+	if md != want {
+		t.Errorf("expected %+v, got %+v", want, md)
+	}
+}
+
+func TestRowsZeroDateTime(t *testing.T) {
+	columns := []mysqlField{{fieldType: fieldTypeDateTime}}
+	tests := []struct {
+		mode string
+		want driver.Value
+		err  error
+	}{
+		{"", time.Time{}, nil},
+		{ZeroDateTimeNearest, time.Time{}, nil},
+		{ZeroDateTimeNil, nil, nil},
+		{ZeroDateTimeError, nil, ErrZeroDateTime},
+	}
+	for _, tt := range tests {
+		conn, mc := newRWMockConn(1)
+		mc.cfg.zeroDateTime = tt.mode
+		mc.parseTime = true
+		conn.data = mockPacket(1, append([]byte{19}, "0000-00-00 00:00:00"...)...)
+		text := &textRows{mysqlRows{mc: mc, rs: resultSet{columns: columns}}}
+		dest := []driver.Value{"unset"}
+		if err := text.readRow(dest); err != tt.err || err == nil && dest[0] != tt.want {
+			t.Errorf("text, zeroDateTime=%q: expected %#v, %v, got %#v, %v", tt.mode, tt.want, tt.err, dest[0], err)
+		}
+
+		conn, mc = newRWMockConn(1)
+		mc.cfg.zeroDateTime = tt.mode
+		mc.parseTime = true
+		conn.data = mockPacket(1, iOK, 0, 0)
+		binary := &binaryRows{mysqlRows: mysqlRows{mc: mc, rs: resultSet{columns: columns}}}
+		dest[0] = "unset"
+		if err := binary.readRow(dest); err != tt.err || err == nil && dest[0] != tt.want {
+			t.Errorf("binary, zeroDateTime=%q: expected %#v, %v, got %#v, %v", tt.mode, tt.want, tt.err, dest[0], err)
+		}
+	}
+}
+
+// mockExtendedColumn returns the definition of a utf8mb4 column with MariaDB
+// extended metadata of the kind and value.
+func mockExtendedColumn(seq byte, name string, ft fieldType, kind byte, value string) []byte {
+	payload := []byte{3, 'd', 'e', 'f', 0, 0, 0, byte(len(name))}
+	payload = append(payload, name...)
+	payload = append(payload, 0, byte(2+len(value)), kind, byte(len(value)))
+	payload = append(payload, value...)
+	payload = append(payload, 0x0c, 45, 0, 0xff, 0xff, 0xff, 0xff, byte(ft), 0, 0, 0, 0, 0)
+	return mockPacket(seq, payload...)
+}
+
+func TestReadColumnsExtendedMetadata(t *testing.T) {
+	conn, mc := newRWMockConn(2)
+	mc.cfg.parseJSON = true
+	mc.extCapabilities = clientExtendedMetadata
+	conn.data = append(conn.data, mockExtendedColumn(2, "doc", fieldTypeLongBLOB, 1, "json")...)
+	conn.data = append(conn.data, mockExtendedColumn(3, "id", fieldTypeString, 0, "uuid")...)
+	conn.data = append(conn.data, mockEOF(4, 0)...)
+	conn.data = append(conn.data, mockPacket(5, append(append([]byte{7}, `{"a":1}`...), 1, 'x')...)...)
+
+	columns, err := mc.readColumns(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if columns[0].name != "doc" || columns[0].extFormat != "json" || !columns[0].asJSON {
+		t.Errorf("unexpected JSON column %+v", columns[0])
+	}
+	if columns[1].name != "id" || columns[1].extType != "uuid" || columns[1].asJSON {
+		t.Errorf("unexpected UUID column %+v", columns[1])
+	}
+	if name := columns[0].typeDatabaseName(); name != "JSON" {
+		t.Errorf("expected JSON, got %s", name)
+	}
+	if name := columns[1].typeDatabaseName(); name != "UUID" {
+		t.Errorf("expected UUID, got %s", name)
+	}
+	if typ := columns[0].scanType(); typ != scanTypeNullJSON {
+		t.Errorf("expected scan type %v, got %v", scanTypeNullJSON, typ)
+	}
+
+	rows := &textRows{mysqlRows{mc: mc, rs: resultSet{columns: columns}}}
+	dest := make([]driver.Value, 2)
+	if err := rows.readRow(dest); err != nil {
+		t.Fatal(err)
+	}
+	if doc, ok := dest[0].(json.RawMessage); !ok || string(doc) != `{"a":1}` {
+		t.Errorf("expected json.RawMessage, got %#v", dest[0])
+	}
+}
+
+func TestReadExtendedMetadataMalformed(t *testing.T) {
+	var mf mysqlField
+	if err := mf.readExtendedMetadata([]byte{0}); err != ErrMalformPkt {
+		t.Errorf("expected ErrMalformPkt, got %v", err)
+	}
+	if err := mf.readExtendedMetadata([]byte{0, 5, 'u'}); err == nil {
+		t.Error("expected an error for a truncated value")
+	}
+}
@@ -0,0 +1,129 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var errNoTransaction = errors.New("mysql: savepoints require a transaction")
+
+// Savepointer is implemented by the connections of this driver. It manages
+// the savepoints of the current transaction.
+//
+// This is accessible through sql.Conn.Raw() for transactions begun with
+// sql.Conn.BeginTx:
+//
+//	tx, err := conn.BeginTx(ctx, nil)
+//	...
+//	err = conn.Raw(func(driverConn any) error {
+//		return driverConn.(mysql.Savepointer).Savepoint(ctx, "before_items")
+//	})
+//
+// Savepoint names are identifiers of up to 64 letters, digits, '_' and '$',
+// including reserved words.
+// They are released when the transaction ends.
+type Savepointer interface {
+	// Savepoint sets a savepoint with the given name. An existing savepoint
+	// with the same name is replaced.
+	Savepoint(ctx context.Context, name string) error
+
+	// RollbackToSavepoint rolls the transaction back to the savepoint. The
+	// savepoints set after it are released.
+	RollbackToSavepoint(ctx context.Context, name string) error
+
+	// ReleaseSavepoint releases the savepoint and the savepoints set after
+	// it, without rolling back.
+	ReleaseSavepoint(ctx context.Context, name string) error
+
+	// Savepoints returns the names of the savepoints of the transaction,
+	// the outermost first.
+	Savepoints() []string
+}
+
+// validSavepointName reports whether name can be used as a savepoint name.
+// The names are quoted, so that all-digit names and reserved words are
+// accepted by the server.
+func validSavepointName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '$') {
+			return false
+		}
+	}
+	return true
+}
+
+// savepointIndex returns the index of the savepoint in mc.savepoints, or -1.
+// Savepoint names are case-insensitive.
+func (mc *mysqlConn) savepointIndex(name string) int {
+	return slices.IndexFunc(mc.savepoints, func(s string) bool {
+		return strings.EqualFold(s, name)
+	})
+}
+
+// execSavepoint executes a savepoint statement on the savepoint name.
+func (mc *mysqlConn) execSavepoint(ctx context.Context, stmt, name string) error {
+	if mc.closed.Load() {
+		return ErrInvalidConn
+	}
+	if !validSavepointName(name) {
+		return fmt.Errorf("mysql: invalid savepoint name %q", name)
+	}
+	if mc.status&statusInTrans == 0 {
+		return errNoTransaction
+	}
+	if err := mc.watchCancel(ctx); err != nil {
+		return err
+	}
+	defer mc.finish()
+	return mc.exec(stmt + " `" + name + "`")
+}
+
+func (mc *mysqlConn) Savepoint(ctx context.Context, name string) error {
+	if err := mc.execSavepoint(ctx, "SAVEPOINT", name); err != nil {
+		return err
+	}
+	if i := mc.savepointIndex(name); i >= 0 {
+		mc.savepoints = slices.Delete(mc.savepoints, i, i+1)
+	}
+	mc.savepoints = append(mc.savepoints, name)
+	return nil
+}
+
+func (mc *mysqlConn) RollbackToSavepoint(ctx context.Context, name string) error {
+	if err := mc.execSavepoint(ctx, "ROLLBACK TO SAVEPOINT", name); err != nil {
+		return err
+	}
+	if i := mc.savepointIndex(name); i >= 0 {
+		mc.savepoints = mc.savepoints[:i+1]
+	}
+	return nil
+}
+
+func (mc *mysqlConn) ReleaseSavepoint(ctx context.Context, name string) error {
+	if err := mc.execSavepoint(ctx, "RELEASE SAVEPOINT", name); err != nil {
+		return err
+	}
+	if i := mc.savepointIndex(name); i >= 0 {
+		mc.savepoints = mc.savepoints[:i]
+	}
+	return nil
+}
+
+func (mc *mysqlConn) Savepoints() []string {
+	return slices.Clone(mc.savepoints)
+}
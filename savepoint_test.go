@@ -0,0 +1,122 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/colussim/mysql-auth-oidc-go/mysqltest"
+)
+
+func TestValidSavepointName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"sp1":                    true,
+		"1":                      true,
+		"select":                 true,
+		"before_items$":          true,
+		"":                       false,
+		"sp 1":                   false,
+		"sp`; DROP TABLE t --":   false,
+		string(make([]byte, 65)): false,
+	} {
+		if got := validSavepointName(name); got != want {
+			t.Errorf("%q: expected %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestSavepoints(t *testing.T) {
+	srv := &mysqltest.Server{}
+	defer srv.Close()
+	cfg := NewConfig()
+	cfg.DialFunc = srv.Dial
+	c, err := NewConnector(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	savepoints := func(f func(sp Savepointer) error) {
+		t.Helper()
+		if err := conn.Raw(func(driverConn any) error {
+			return f(driverConn.(Savepointer))
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect := func(want ...string) {
+		t.Helper()
+		savepoints(func(sp Savepointer) error {
+			if got := sp.Savepoints(); !reflect.DeepEqual(got, want) {
+				t.Errorf("expected savepoints %q, got %q", want, got)
+			}
+			return nil
+		})
+	}
+
+	if err := conn.Raw(func(driverConn any) error {
+		return driverConn.(Savepointer).Savepoint(ctx, "sp1")
+	}); err != errNoTransaction {
+		t.Errorf("expected %v outside a transaction, got %v", errNoTransaction, err)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	savepoints(func(sp Savepointer) error {
+		for _, name := range []string{"a", "b", "c", "d"} {
+			if err := sp.Savepoint(ctx, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	expect("a", "b", "c", "d")
+	savepoints(func(sp Savepointer) error { return sp.Savepoint(ctx, "B") })
+	expect("a", "c", "d", "B")
+	savepoints(func(sp Savepointer) error { return sp.RollbackToSavepoint(ctx, "c") })
+	expect("a", "c")
+	savepoints(func(sp Savepointer) error { return sp.ReleaseSavepoint(ctx, "c") })
+	expect("a")
+	if err := conn.Raw(func(driverConn any) error {
+		return driverConn.(Savepointer).Savepoint(ctx, "a; COMMIT")
+	}); err == nil {
+		t.Error("expected an invalid savepoint name to be rejected")
+	}
+	// reserved words and numbers are quoted
+	savepoints(func(sp Savepointer) error { return sp.Savepoint(ctx, "select") })
+	savepoints(func(sp Savepointer) error { return sp.Savepoint(ctx, "1") })
+	expect("a", "select", "1")
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	expect()
+
+	want := []string{
+		"START TRANSACTION",
+		"SAVEPOINT `a`", "SAVEPOINT `b`", "SAVEPOINT `c`", "SAVEPOINT `d`", "SAVEPOINT `B`",
+		"ROLLBACK TO SAVEPOINT `c`", "RELEASE SAVEPOINT `c`", "SAVEPOINT `select`", "SAVEPOINT `1`",
+		"COMMIT",
+	}
+	if got := srv.Queries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,60 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import "strings"
+
+// ServerInfo describes the server of a connection as reported in the
+// handshake.
+type ServerInfo struct {
+	Version  string       // e.g. "8.0.36" or "5.5.5-10.11.6-MariaDB"
+	Flavor   ServerFlavor // detected from Version or configured with UseServerFlavor
+	ThreadID uint32       // connection id, as returned by CONNECTION_ID()
+
+	// Capability flags (CLIENT_*) advertised by the server and those in use
+	// on the connection, i.e. supported by both the server and the driver.
+	Capabilities           uint32
+	NegotiatedCapabilities uint32
+
+	// MariaDB extended capability flags (MARIADB_CLIENT_*), 0 for MySQL.
+	ExtendedCapabilities           uint32
+	NegotiatedExtendedCapabilities uint32
+}
+
+// IsMariaDB reports whether the server is MariaDB.
+func (si ServerInfo) IsMariaDB() bool {
+	return strings.Contains(si.Version, "MariaDB")
+}
+
+// ServerInfoer is implemented by the connections of this driver.
+//
+// This is accessible through sql.Conn.Raw():
+//
+//	var info mysql.ServerInfo
+//	err := conn.Raw(func(driverConn any) error {
+//		info = driverConn.(mysql.ServerInfoer).ServerInfo()
+//		return nil
+//	})
+type ServerInfoer interface {
+	// ServerInfo returns the version, thread id and capabilities of the
+	// server of the connection.
+	ServerInfo() ServerInfo
+}
+
+func (mc *mysqlConn) ServerInfo() ServerInfo {
+	return ServerInfo{
+		Version:                        mc.serverVersion,
+		Flavor:                         mc.flavor,
+		ThreadID:                       mc.threadID,
+		Capabilities:                   uint32(mc.serverCapabilities),
+		NegotiatedCapabilities:         uint32(mc.capabilities),
+		ExtendedCapabilities:           uint32(mc.serverExtCapabilities),
+		NegotiatedExtendedCapabilities: uint32(mc.extCapabilities),
+	}
+}
@@ -0,0 +1,53 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"testing"
+)
+
+func TestServerInfo(t *testing.T) {
+	c := newMockServerConnector(t, mockPacket(2, iOK, 0, 0, 2, 0, 0, 0))
+	conn, err := c.Connect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	info := conn.(ServerInfoer).ServerInfo()
+	if info.Version != "8.0.36" || info.ThreadID != 42 || info.IsMariaDB() {
+		t.Errorf("unexpected server info %+v", info)
+	}
+	if expected := uint32(clientMySQL | clientProtocol41 | clientSecureConn | clientPluginAuth); info.Capabilities != expected {
+		t.Errorf("expected capabilities %#x, got %#x", expected, info.Capabilities)
+	}
+	if info.NegotiatedCapabilities != info.Capabilities {
+		t.Errorf("expected negotiated capabilities %#x, got %#x", info.Capabilities, info.NegotiatedCapabilities)
+	}
+	if info.ExtendedCapabilities != 0 || info.NegotiatedExtendedCapabilities != 0 {
+		t.Errorf("expected no extended capabilities, got %+v", info)
+	}
+}
+
+func TestServerInfoMariaDB(t *testing.T) {
+	_, mc := newRWMockConn(0)
+	mc.serverVersion = "5.5.5-10.11.6-MariaDB"
+	mc.serverExtCapabilities = clientStmtBulkOperations | clientExtendedMetadata
+	mc.extCapabilities = clientStmtBulkOperations
+
+	info := mc.ServerInfo()
+	if !info.IsMariaDB() {
+		t.Error("expected a MariaDB server")
+	}
+	if info.ExtendedCapabilities != uint32(clientStmtBulkOperations|clientExtendedMetadata) ||
+		info.NegotiatedExtendedCapabilities != uint32(clientStmtBulkOperations) {
+		t.Errorf("unexpected extended capabilities %+v", info)
+	}
+}
@@ -0,0 +1,137 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+// SessionState holds the session state changes the server reported with the
+// result of a statement. Which changes are reported is controlled by the
+// session_track_* system variables of the server.
+type SessionState struct {
+	// SystemVariables holds the new values of changed system variables.
+	SystemVariables map[string]string
+
+	// Schema is the new default schema, if it was changed.
+	Schema string
+
+	// StateChanged is set if any session state was changed.
+	StateChanged bool
+
+	// GTIDs is the set of GTIDs of the committed transactions.
+	GTIDs string
+
+	// TransactionCharacteristics is the SQL to restart the transaction with
+	// its current characteristics.
+	TransactionCharacteristics string
+
+	// TransactionState describes the state of the current transaction.
+	TransactionState string
+}
+
+// SessionStateFunc is called with the session state changes reported by the
+// server.
+type SessionStateFunc func(state SessionState)
+
+// SessionStateCallback sets a function called whenever the server reports
+// session state changes, e.g. to track the default schema or the GTIDs needed
+// to route reads to consistent replicas.
+//
+// The callback runs on the goroutine using the connection and must not use
+// the connection.
+func SessionStateCallback(fn SessionStateFunc) Option {
+	return func(cfg *Config) error {
+		cfg.sessionStateCallback = fn
+		return nil
+	}
+}
+
+// parseSessionState parses the tail of an OK packet following the warning
+// count: the info string and the session state changes.
+func parseSessionState(data []byte) (SessionState, error) {
+	var state SessionState
+	if len(data) == 0 {
+		return state, nil
+	}
+
+	// info [len coded string]
+	_, _, n, err := readLengthEncodedString(data)
+	if err != nil {
+		return state, ErrMalformPkt
+	}
+	if len(data) == n {
+		return state, nil
+	}
+
+	// session state info [len coded string]
+	changes, _, _, err := readLengthEncodedString(data[n:])
+	if err != nil {
+		return state, ErrMalformPkt
+	}
+
+	for len(changes) > 0 {
+		// type [1 byte], data [len coded string]
+		typ := changes[0]
+		if len(changes) < 2 {
+			return state, ErrMalformPkt
+		}
+		entry, _, n, err := readLengthEncodedString(changes[1:])
+		if err != nil {
+			return state, ErrMalformPkt
+		}
+		changes = changes[1+n:]
+
+		switch typ {
+		case sessionTrackSystemVariables:
+			name, _, n, err := readLengthEncodedString(entry)
+			if err != nil || n >= len(entry) {
+				return state, ErrMalformPkt
+			}
+			value, _, _, err := readLengthEncodedString(entry[n:])
+			if err != nil {
+				return state, ErrMalformPkt
+			}
+			if state.SystemVariables == nil {
+				state.SystemVariables = make(map[string]string)
+			}
+			state.SystemVariables[string(name)] = string(value)
+
+		case sessionTrackSchema:
+			state.Schema, err = readSessionStateString(entry)
+
+		case sessionTrackStateChange:
+			state.StateChanged = true
+
+		case sessionTrackGTIDs:
+			// encoding specification [1 byte], GTIDs [len coded string]
+			if len(entry) < 2 {
+				return state, ErrMalformPkt
+			}
+			state.GTIDs, err = readSessionStateString(entry[1:])
+
+		case sessionTrackTransactionCharacteristics:
+			state.TransactionCharacteristics, err = readSessionStateString(entry)
+
+		case sessionTrackTransactionState:
+			state.TransactionState, err = readSessionStateString(entry)
+		}
+		if err != nil {
+			return state, err
+		}
+	}
+	return state, nil
+}
+
+func readSessionStateString(b []byte) (string, error) {
+	if len(b) == 0 {
+		return "", ErrMalformPkt
+	}
+	s, _, _, err := readLengthEncodedString(b)
+	if err != nil {
+		return "", ErrMalformPkt
+	}
+	return string(s), nil
+}
@@ -0,0 +1,115 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// sessionStateOkPacket returns an OK packet reporting the given session state
+// changes.
+func sessionStateOkPacket(changes []byte) []byte {
+	status := statusInAutocommit | statusSessionStateChanged
+	payload := []byte{iOK, 0, 0, byte(status), byte(status >> 8), 0, 0}
+	payload = append(payload, 0) // info
+	payload = appendLengthEncodedString(payload, string(changes))
+	return append([]byte{byte(len(payload)), 0, 0, 1}, payload...)
+}
+
+func TestParseSessionState(t *testing.T) {
+	var changes []byte
+	changes = append(changes, sessionTrackSystemVariables)
+	changes = appendLengthEncodedString(changes, "\x09time_zone\x06+00:00")
+	changes = append(changes, sessionTrackSchema)
+	changes = appendLengthEncodedString(changes, "\x04test")
+	changes = append(changes, sessionTrackStateChange)
+	changes = appendLengthEncodedString(changes, "1")
+	changes = append(changes, sessionTrackGTIDs)
+	changes = appendLengthEncodedString(changes, "\x00\x0cuuid:1-10,12")
+	changes = append(changes, sessionTrackTransactionState)
+	changes = appendLengthEncodedString(changes, "\x08________")
+
+	data := sessionStateOkPacket(changes)
+	state, err := parseSessionState(data[4+7:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := SessionState{
+		SystemVariables:  map[string]string{"time_zone": "+00:00"},
+		Schema:           "test",
+		StateChanged:     true,
+		GTIDs:            "uuid:1-10,12",
+		TransactionState: "________",
+	}
+	if !reflect.DeepEqual(state, expected) {
+		t.Errorf("unexpected session state:\n got: %#v\nwant: %#v", state, expected)
+	}
+
+	if _, err := parseSessionState([]byte{0, 3, sessionTrackSchema, 5}); err != ErrMalformPkt {
+		t.Errorf("expected ErrMalformPkt for truncated changes, got %v", err)
+	}
+}
+
+func TestSessionStateCallback(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.capabilities |= clientSessionTrack
+
+	var states []SessionState
+	mc.cfg.sessionStateCallback = func(state SessionState) {
+		states = append(states, state)
+	}
+
+	changes := append([]byte{sessionTrackSchema}, appendLengthEncodedString(nil, "\x04test")...)
+	conn.queuedReplies = [][]byte{sessionStateOkPacket(changes), okPacket}
+	if err := mc.exec("USE test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mc.exec("DO 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(states) != 1 || states[0].Schema != "test" {
+		t.Errorf("expected a single schema change, got %#v", states)
+	}
+}
+
+func TestSessionStateResultSetEnd(t *testing.T) {
+	changes := append([]byte{sessionTrackSchema}, appendLengthEncodedString(nil, "\x04test")...)
+	end := sessionStateOkPacket(changes)
+	end[4] = iEOF // OK packet ending a result set with clientDeprecateEOF
+
+	for _, binary := range []bool{false, true} {
+		conn, mc := newRWMockConn(1)
+		mc.capabilities |= clientSessionTrack | clientDeprecateEOF
+		var states []SessionState
+		mc.cfg.sessionStateCallback = func(state SessionState) {
+			states = append(states, state)
+		}
+		conn.data = end
+
+		columns := []mysqlField{{fieldType: fieldTypeLongLong}}
+		var rows driver.Rows = &textRows{mysqlRows{mc: mc, rs: resultSet{columns: columns}}}
+		if binary {
+			rows = &binaryRows{mysqlRows: mysqlRows{mc: mc, rs: resultSet{columns: columns}}}
+		}
+		if err := rows.Next(make([]driver.Value, 1)); err != io.EOF {
+			t.Fatalf("binary=%v: expected io.EOF, got %v", binary, err)
+		}
+		if mc.status&statusInAutocommit == 0 {
+			t.Errorf("binary=%v: expected the status of the OK packet", binary)
+		}
+		if len(states) != 1 || states[0].Schema != "test" {
+			t.Errorf("binary=%v: expected a single schema change, got %#v", binary, states)
+		}
+	}
+}
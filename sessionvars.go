@@ -0,0 +1,115 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"errors"
+	"strings"
+)
+
+// sessionVar is a system variable assignment of the sessionVars DSN param.
+type sessionVar struct {
+	name  string
+	value string // SQL expression, e.g. a quoted string
+}
+
+// SessionVars sets system variables of every connection in the same SET
+// statement as the system variables of the DSN params, right after
+// connecting. vars is a comma-separated list of assignments, with the values
+// written as in SQL:
+//
+//	sql_mode='ANSI,STRICT_TRANS_TABLES',time_zone='+00:00',group_concat_max_len=1000000
+//
+// Unlike DSN params, the variables are set in the given order and values
+// may contain commas within quotes or parentheses. In a DSN, '+' is taken
+// literally and '&' must be escaped as %26.
+func SessionVars(vars string) Option {
+	return func(cfg *Config) error {
+		sv, err := parseSessionVars(vars)
+		if err != nil {
+			return err
+		}
+		cfg.sessionVars = sv
+		return nil
+	}
+}
+
+// parseSessionVars parses the comma-separated assignments of SessionVars.
+func parseSessionVars(s string) ([]sessionVar, error) {
+	var vars []sessionVar
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) {
+			c := s[i]
+			switch {
+			case quote != 0:
+				if c == '\\' && quote != '`' {
+					i++
+				} else if c == quote {
+					quote = 0
+				}
+				continue
+			case c == '\'' || c == '"' || c == '`':
+				quote = c
+				continue
+			case c == '(':
+				depth++
+				continue
+			case c == ')':
+				depth--
+				continue
+			case c == ';':
+				return nil, errors.New("invalid sessionVars: unquoted ';'")
+			case c != ',' || depth > 0:
+				continue
+			}
+		} else if quote != 0 || depth != 0 {
+			return nil, errors.New("invalid sessionVars: unterminated quote or parenthesis")
+		}
+
+		name, value, found := strings.Cut(s[start:i], "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !found || !isSessionVarName(name) || value == "" {
+			return nil, errors.New("invalid sessionVars: expected name=value, got " + s[start:i])
+		}
+		vars = append(vars, sessionVar{name: name, value: value})
+		start = i + 1
+	}
+	return vars, nil
+}
+
+// isSessionVarName reports whether name is a plain system variable name,
+// optionally prefixed by a scope like "@@session.".
+func isSessionVarName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '@') {
+			return false
+		}
+	}
+	return true
+}
+
+// formatSessionVars formats vars like the sessionVars DSN param.
+func formatSessionVars(vars []sessionVar) string {
+	var b strings.Builder
+	for i, v := range vars {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(v.name)
+		b.WriteByte('=')
+		b.WriteString(v.value)
+	}
+	return b.String()
+}
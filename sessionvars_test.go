@@ -0,0 +1,56 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSessionVars(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []sessionVar
+	}{
+		{"a=1", []sessionVar{{"a", "1"}}},
+		{" a = 1 , b='x' ", []sessionVar{{"a", "1"}, {"b", "'x'"}}},
+		{"sql_mode='ANSI,STRICT_TRANS_TABLES',time_zone='+00:00'", []sessionVar{{"sql_mode", "'ANSI,STRICT_TRANS_TABLES'"}, {"time_zone", "'+00:00'"}}},
+		{`a='it\'s, quoted',b="x,y"`, []sessionVar{{"a", `'it\'s, quoted'`}, {"b", `"x,y"`}}},
+		{"a=CONCAT('x', 'y'),@@session.b=2", []sessionVar{{"a", "CONCAT('x', 'y')"}, {"@@session.b", "2"}}},
+	}
+	for _, tt := range tests {
+		got, err := parseSessionVars(tt.in)
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.in, tt.want, got)
+		}
+	}
+
+	for _, in := range []string{"", "a", "a=", "=1", "a=1,", "a b=1", "a='x", "a=f(1", "a=1;DROP TABLE t,b=2"} {
+		if _, err := parseSessionVars(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
+
+func TestSessionVarsQuery(t *testing.T) {
+	_, mc := newRWMockConn(0)
+	if err := mc.cfg.Apply(SessionVars("sql_mode='ANSI',time_zone='+00:00'")); err != nil {
+		t.Fatal(err)
+	}
+	mc.cfg.Params = map[string]string{"autocommit": "1"}
+
+	want := "SET autocommit = 1, sql_mode = 'ANSI', time_zone = '+00:00'"
+	if got := mc.paramsQuery(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,59 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Set is the value of a SET column, the list of its members. ENUM columns
+// are returned as strings and need no helper. An empty Set is the empty
+// string. NULL is not supported, use sql.Null[Set] for nullable columns.
+type Set []string
+
+// Scan implements sql.Scanner.
+func (s *Set) Scan(src any) error {
+	var v string
+	switch src := src.(type) {
+	case []byte:
+		v = string(src)
+	case string:
+		v = src
+	default:
+		return fmt.Errorf("mysql: cannot scan %T into Set", src)
+	}
+	if v == "" {
+		*s = Set{}
+		return nil
+	}
+	*s = strings.Split(v, ",")
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (s Set) Value() (driver.Value, error) {
+	for _, m := range s {
+		if strings.Contains(m, ",") {
+			return nil, fmt.Errorf("mysql: SET member %q contains a comma", m)
+		}
+	}
+	return strings.Join(s, ","), nil
+}
+
+// Contains reports whether member is a member of the set.
+func (s Set) Contains(member string) bool {
+	for _, m := range s {
+		if m == member {
+			return true
+		}
+	}
+	return false
+}
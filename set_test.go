@@ -0,0 +1,53 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		src  any
+		want Set
+	}{
+		{[]byte(""), Set{}},
+		{[]byte("a"), Set{"a"}},
+		{"a,b c,d", Set{"a", "b c", "d"}},
+	}
+	for _, tt := range tests {
+		var s Set
+		if err := s.Scan(tt.src); err != nil {
+			t.Errorf("Scan(%#v): %v", tt.src, err)
+		} else if !reflect.DeepEqual(s, tt.want) {
+			t.Errorf("Scan(%#v): expected %q, got %q", tt.src, tt.want, s)
+		}
+
+		v, err := tt.want.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Set
+		if err := back.Scan(v); err != nil || !reflect.DeepEqual(back, tt.want) {
+			t.Errorf("expected %q to round-trip, got %q, %v", tt.want, back, err)
+		}
+	}
+
+	var s Set
+	if err := s.Scan(int64(1)); err == nil {
+		t.Error("expected an error for an int64")
+	}
+	if _, err := (Set{"a,b"}).Value(); err == nil {
+		t.Error("expected an error for a member with a comma")
+	}
+	if !(Set{"a", "b"}).Contains("b") || (Set{"a"}).Contains("b") {
+		t.Error("unexpected Contains results")
+	}
+}
@@ -0,0 +1,180 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// SlowQuery describes a query reported as slow.
+type SlowQuery struct {
+	Query    string        // the query, with literals replaced by '?' if redacted
+	Duration time.Duration // time from sending the query to closing its rows
+	Rows     int64         // rows read by a query, or affected by a statement
+	Err      error         // error of the query, if any
+
+	// status flags reported by the server
+	QueryWasSlow    bool // exceeded long_query_time on the server
+	NoIndexUsed     bool
+	NoGoodIndexUsed bool
+}
+
+// SlowQueryFunc is called with queries reported as slow. It is called
+// synchronously on the goroutine using the connection and must not use the
+// connection.
+type SlowQueryFunc func(ctx context.Context, q SlowQuery)
+
+// SlowQueryLog reports queries and statements taking at least threshold, or
+// flagged as slow by the server, to fn. Queries are timed until their rows
+// are closed. If fn is nil, slow queries are logged as warnings.
+//
+// Set RedactSlowQueries to keep literals, which may contain personal data,
+// out of the reports.
+func SlowQueryLog(threshold time.Duration, fn SlowQueryFunc) Option {
+	return func(cfg *Config) error {
+		cfg.slowQueryThreshold = threshold
+		cfg.slowQueryFunc = fn
+		return nil
+	}
+}
+
+// RedactSlowQueries replaces the string and numeric literals of queries
+// reported by SlowQueryLog with '?'.
+func RedactSlowQueries(yes bool) Option {
+	return func(cfg *Config) error {
+		cfg.slowQueryRedact = yes
+		return nil
+	}
+}
+
+// reportSlowQuery reports the query started at start if it was slow.
+func (mc *mysqlConn) reportSlowQuery(ctx context.Context, query string, start time.Time, rows int64, err error) {
+	threshold := mc.cfg.slowQueryThreshold
+	if threshold <= 0 || start.IsZero() {
+		return
+	}
+	d := time.Since(start)
+	if d < threshold && mc.status&statusQueryWasSlow == 0 {
+		return
+	}
+
+	if mc.cfg.slowQueryRedact {
+		query = redactQuery(query)
+	}
+	q := SlowQuery{
+		Query:           query,
+		Duration:        d,
+		Rows:            rows,
+		Err:             err,
+		QueryWasSlow:    mc.status&statusQueryWasSlow != 0,
+		NoIndexUsed:     mc.status&statusNoIndexUsed != 0,
+		NoGoodIndexUsed: mc.status&statusNoGoodIndexUsed != 0,
+	}
+	if fn := mc.cfg.slowQueryFunc; fn != nil {
+		fn(ctx, q)
+		return
+	}
+	mc.warn("slow query (", q.Duration, ", ", q.Rows, " rows): ", q.Query)
+}
+
+// redactQuery replaces the string and numeric literals of query with '?'.
+// Quoted identifiers and comments are kept.
+func redactQuery(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			i = skipQuoted(query, i)
+			b.WriteByte('?')
+		case c == '`':
+			end := skipQuoted(query, i)
+			b.WriteString(query[i:end])
+			i = end
+		case c == '#' || c == '-' || c == '/':
+			end := skipComment(query, i)
+			if end == i {
+				end++
+			}
+			b.WriteString(query[i:end])
+			i = end
+		case isDigit(c) && (i == 0 || !isIdentChar(query[i-1])):
+			i++
+			for i < len(query) && (isIdentChar(query[i]) || query[i] == '.') {
+				i++
+			}
+			b.WriteByte('?')
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// skipQuoted returns the index after the quoted string or identifier
+// starting at query[start], honoring backslash escapes and doubled quotes.
+func skipQuoted(query string, start int) int {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+// skipComment returns the index after the comment starting at query[start],
+// or start if no comment starts there. # and -- comments end with the line.
+func skipComment(query string, start int) int {
+	rest := query[start:]
+	switch {
+	case strings.HasPrefix(rest, "#"), isDashComment(rest):
+		if end := strings.IndexByte(rest, '\n'); end >= 0 {
+			return start + end + 1
+		}
+		return len(query)
+	case strings.HasPrefix(rest, "/*"):
+		if end := strings.Index(rest[2:], "*/"); end >= 0 {
+			return start + 2 + end + 2
+		}
+		return len(query)
+	}
+	return start
+}
+
+// isDashComment reports whether s starts with a -- comment, which needs
+// whitespace after the dashes.
+func isDashComment(s string) bool {
+	if !strings.HasPrefix(s, "--") {
+		return false
+	}
+	return len(s) == 2 || s[2] == ' ' || s[2] == '\t' || s[2] == '\n' || s[2] == '\r'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentChar(c byte) bool {
+	return isDigit(c) || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c == '$'
+}
@@ -0,0 +1,66 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct{ query, want string }{
+		{"SELECT * FROM t WHERE a = 'x' AND b = 42", "SELECT * FROM t WHERE a = ? AND b = ?"},
+		{`UPDATE t SET s = "it\"s", d = 'O''Brien' WHERE id=7`, "UPDATE t SET s = ?, d = ? WHERE id=?"},
+		{"SELECT `col 'x'`, t1.c2 FROM t1 LIMIT 10", "SELECT `col 'x'`, t1.c2 FROM t1 LIMIT ?"},
+		{"SELECT 1.5, 0x1F, -3", "SELECT ?, ?, -?"},
+		{"SELECT 'unterminated", "SELECT ?"},
+		{"SELECT a FROM t -- don't\nWHERE b = 1", "SELECT a FROM t -- don't\nWHERE b = ?"},
+		{"SELECT /*+ MAX_EXECUTION_TIME(1000) */ a # id's\n", "SELECT /*+ MAX_EXECUTION_TIME(1000) */ a # id's\n"},
+		{"SELECT 5--3, 1 /* it's", "SELECT ?--?, ? /* it's"},
+	}
+	for _, test := range tests {
+		if got := redactQuery(test.query); got != test.want {
+			t.Errorf("redactQuery(%q): expected %q, got %q", test.query, test.want, got)
+		}
+	}
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	var reported []SlowQuery
+	conn, mc := newRWMockConn(0)
+	if err := mc.cfg.Apply(SlowQueryLog(time.Hour, func(ctx context.Context, q SlowQuery) {
+		reported = append(reported, q)
+	}), RedactSlowQueries(true)); err != nil {
+		t.Fatal(err)
+	}
+
+	// OK packets with 2 affected rows, the second flagged as slow and not
+	// using an index (status 0x0822)
+	conn.queuedReplies = [][]byte{
+		{7, 0, 0, 1, iOK, 2, 0, 2, 0, 0, 0},
+		{7, 0, 0, 1, iOK, 2, 0, 0x22, 0x08, 0, 0},
+	}
+	if _, err := mc.ExecContext(context.Background(), "DELETE FROM t WHERE id < 10", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(reported) != 0 {
+		t.Fatalf("expected fast statement not to be reported, got %+v", reported)
+	}
+	if _, err := mc.ExecContext(context.Background(), "DELETE FROM t WHERE id < 20", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(reported) != 1 {
+		t.Fatalf("expected 1 slow statement, got %+v", reported)
+	}
+	q := reported[0]
+	if q.Query != "DELETE FROM t WHERE id < ?" || q.Rows != 2 || !q.QueryWasSlow || !q.NoIndexUsed || q.NoGoodIndexUsed {
+		t.Errorf("unexpected report %+v", q)
+	}
+}
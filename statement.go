@@ -9,11 +9,15 @@
 package mysql
 
 import (
+	"bytes"
+	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
+	"slices"
+	"strconv"
 )
 
 type mysqlStmt struct {
@@ -21,6 +25,10 @@ type mysqlStmt struct {
 	id         uint32
 	paramCount int
 	columns    []mysqlField
+	columnDefs [][]byte // column definition packets columns were read from
+	columnsCfg *Config  // configuration columns were read with
+	queryStr   string   // reported to Hooks
+	params     []string // parameters of the placeholders if there are named ones, "" for '?'
 }
 
 func (stmt *mysqlStmt) Close() error {
@@ -33,12 +41,17 @@ func (stmt *mysqlStmt) Close() error {
 		return nil
 	}
 
+	stmt.mc.openStmts--
 	err := stmt.mc.writeCommandPacketUint32(comStmtClose, stmt.id)
 	stmt.mc = nil
 	return err
 }
 
 func (stmt *mysqlStmt) NumInput() int {
+	// Named parameters may be used several times
+	if stmt.params != nil {
+		return -1
+	}
 	return stmt.paramCount
 }
 
@@ -47,6 +60,9 @@ func (stmt *mysqlStmt) ColumnConverter(idx int) driver.ValueConverter {
 }
 
 func (stmt *mysqlStmt) CheckNamedValue(nv *driver.NamedValue) (err error) {
+	if _, ok := nv.Value.(sql.Out); ok {
+		return nil
+	}
 	nv.Value, err = converter{}.ConvertValue(nv.Value)
 	return
 }
@@ -56,11 +72,16 @@ func (stmt *mysqlStmt) Exec(args []driver.Value) (driver.Result, error) {
 		return nil, driver.ErrBadConn
 	}
 	// Send command
-	err := stmt.writeExecutePacket(args)
+	err := stmt.writeExecutePacket(args, cursorTypeNoCursor)
 	if err != nil {
 		return nil, stmt.mc.markBadConn(err)
 	}
 
+	return stmt.readExecResult()
+}
+
+// readExecResult reads the response of COM_STMT_EXECUTE, discarding rows.
+func (stmt *mysqlStmt) readExecResult() (driver.Result, error) {
 	mc := stmt.mc
 	handleOk := stmt.mc.clearResult()
 
@@ -74,7 +95,7 @@ func (stmt *mysqlStmt) Exec(args []driver.Value) (driver.Result, error) {
 		// Columns
 		if metadataFollows && stmt.mc.extCapabilities&clientCacheMetadata != 0 {
 			// we can not skip column metadata because next stmt.Query() may use it.
-			if stmt.columns, err = mc.readColumns(resLen); err != nil {
+			if _, err = stmt.readColumns(resLen); err != nil {
 				return nil, err
 			}
 		} else {
@@ -97,6 +118,47 @@ func (stmt *mysqlStmt) Exec(args []driver.Value) (driver.Result, error) {
 	return &copied, nil
 }
 
+// readColumns reads the column definitions of a result set of the statement.
+// MySQL sends them with every execution: the columns read before are reused
+// for the definitions which didn't change, which saves parsing them and
+// their allocations for wide result sets.
+func (stmt *mysqlStmt) readColumns(count int) ([]mysqlField, error) {
+	mc := stmt.mc
+	columns, defs := stmt.columns, stmt.columnDefs
+	reuse := len(columns) == count && len(defs) == count && stmt.columnsCfg == mc.cfg
+	if !reuse {
+		columns, defs = make([]mysqlField, count), make([][]byte, count)
+	}
+	copied := !reuse
+
+	for i := range count {
+		data, err := mc.readPacket()
+		if err != nil {
+			return nil, err
+		}
+		if reuse && bytes.Equal(data, defs[i]) {
+			continue
+		}
+		if !copied {
+			// rows of earlier executions may still use the columns
+			columns, defs = slices.Clone(columns), slices.Clone(defs)
+			copied = true
+		}
+		columns[i] = mysqlField{}
+		if err = mc.readColumn(data, &columns[i]); err != nil {
+			return nil, err
+		}
+		defs[i] = bytes.Clone(data)
+	}
+
+	// skip EOF packet if client does not support deprecateEOF
+	if err := mc.skipEof(); err != nil {
+		return nil, err
+	}
+	stmt.columns, stmt.columnDefs, stmt.columnsCfg = columns, defs, mc.cfg
+	return columns, nil
+}
+
 func (stmt *mysqlStmt) Query(args []driver.Value) (driver.Rows, error) {
 	return stmt.query(args)
 }
@@ -105,12 +167,22 @@ func (stmt *mysqlStmt) query(args []driver.Value) (*binaryRows, error) {
 	if stmt.mc.closed.Load() {
 		return nil, driver.ErrBadConn
 	}
+	cursorType := cursorTypeNoCursor
+	if stmt.mc.cursorFetch() {
+		cursorType = cursorTypeReadOnly
+	}
+
 	// Send command
-	err := stmt.writeExecutePacket(args)
+	err := stmt.writeExecutePacket(args, cursorType)
 	if err != nil {
 		return nil, stmt.mc.markBadConn(err)
 	}
 
+	return stmt.readQueryResult()
+}
+
+// readQueryResult reads the response of COM_STMT_EXECUTE up to the first row.
+func (stmt *mysqlStmt) readQueryResult() (*binaryRows, error) {
 	mc := stmt.mc
 
 	// Read Result
@@ -121,20 +193,30 @@ func (stmt *mysqlStmt) query(args []driver.Value) (*binaryRows, error) {
 	}
 
 	rows := new(binaryRows)
+	rows.mc = mc
+	rows.stmtColumns = stmt.columns
 
 	if resLen > 0 {
-		rows.mc = mc
 		if metadataFollows {
-			if rows.rs.columns, err = mc.readColumns(resLen); err != nil {
+			if rows.rs.columns, err = stmt.readColumns(resLen); err != nil {
 				return nil, err
 			}
-			stmt.columns = rows.rs.columns
 		} else {
 			if err = mc.skipEof(); err != nil {
 				return nil, err
 			}
 			rows.rs.columns = stmt.columns
 		}
+
+		if mc.cursorFetch() && mc.status&statusCursorExists != 0 {
+			// No rows follow until they are fetched from the cursor
+			fetchSize := mc.cfg.fetchSize
+			if fetchSize == 0 {
+				fetchSize = defaultFetchSize
+			}
+			rows.cursor = &cursor{stmtID: stmt.id, fetchSize: uint32(fetchSize)}
+			rows.rs.done = true
+		}
 	} else {
 		rows.rs.done = true
 
@@ -177,6 +259,10 @@ func (c converter) ConvertValue(v any) (driver.Value, error) {
 		if u, ok := sv.(uint64); ok {
 			return u, nil
 		}
+		// json.RawMessage is sent as a JSON document, e.g. for JSON.
+		if j, ok := sv.(json.RawMessage); ok {
+			return j, nil
+		}
 		return nil, fmt.Errorf("non-Value type %T returned from Value", sv)
 	}
 	rv := reflect.ValueOf(v)
@@ -192,7 +278,12 @@ func (c converter) ConvertValue(v any) (driver.Value, error) {
 		return rv.Int(), nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		return rv.Uint(), nil
-	case reflect.Float32, reflect.Float64:
+	case reflect.Float32:
+		// Widen to the float64 closest to the shortest decimal of the
+		// float32, e.g. 0.1 instead of 0.10000000149011612.
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(rv.Float(), 'g', -1, 32), 64)
+		return f, nil
+	case reflect.Float64:
 		return rv.Float(), nil
 	case reflect.Bool:
 		return rv.Bool(), nil
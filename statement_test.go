@@ -12,6 +12,7 @@ import (
 	"bytes"
 	"database/sql/driver"
 	"encoding/json"
+	"io"
 	"testing"
 )
 
@@ -149,3 +150,204 @@ func TestConvertJSON(t *testing.T) {
 		t.Fatalf("json.RawMessage converted, got %#v %T", out, out)
 	}
 }
+
+// mockPacket prepends the packet header to payload.
+func mockPacket(seq byte, payload ...byte) []byte {
+	return append([]byte{byte(len(payload)), 0, 0, seq}, payload...)
+}
+
+// mockColumn returns the definition of a BIGINT column.
+func mockColumn(seq byte) []byte {
+	return mockPacket(seq,
+		3, 'd', 'e', 'f', 0, 0, 0, 1, 'v', 0, // catalog, schema, tables, names
+		0x0c, 0x3f, 0, 20, 0, 0, 0, byte(fieldTypeLongLong), 0, 0, 0, 0, 0)
+}
+
+// mockBinaryRow returns a binary protocol row with a single BIGINT column.
+func mockBinaryRow(seq, v byte) []byte {
+	return mockPacket(seq, iOK, 0, v, 0, 0, 0, 0, 0, 0, 0)
+}
+
+func mockEOF(seq byte, status statusFlag) []byte {
+	return mockPacket(seq, iEOF, 0, 0, byte(status), byte(status>>8))
+}
+
+func TestStmtQueryCursorFetch(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.cfg.useCursorFetch = true
+	mc.cfg.fetchSize = 1
+	stmt := &mysqlStmt{mc: mc, id: 7}
+
+	var metadata []byte
+	metadata = append(metadata, mockPacket(1, 1)...)
+	metadata = append(metadata, mockColumn(2)...)
+	metadata = append(metadata, mockEOF(3, statusCursorExists)...)
+	conn.queuedReplies = [][]byte{
+		metadata,
+		append(mockBinaryRow(1, 1), mockEOF(2, statusCursorExists)...),
+		append(mockBinaryRow(1, 2), mockEOF(2, statusCursorExists|statusLastRowSent)...),
+	}
+
+	rows, err := stmt.query(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows.cursor == nil {
+		t.Fatal("expected rows to be read from a cursor")
+	}
+	if !bytes.HasPrefix(conn.written[4:], []byte{comStmtExecute, 7, 0, 0, 0, cursorTypeReadOnly}) {
+		t.Errorf("expected execute with read-only cursor, got %x", conn.written)
+	}
+
+	dest := make([]driver.Value, 1)
+	for _, want := range []int64{1, 2} {
+		if err := rows.Next(dest); err != nil {
+			t.Fatal(err)
+		}
+		if dest[0] != want {
+			t.Errorf("expected %d, got %v", want, dest[0])
+		}
+	}
+	if err := rows.Next(dest); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+
+	fetch := []byte{9, 0, 0, 0, comStmtFetch, 7, 0, 0, 0, 1, 0, 0, 0}
+	if n := bytes.Count(conn.written, fetch); n != 2 {
+		t.Errorf("expected 2 fetches, got %d", n)
+	}
+}
+
+func TestStmtQueryProcedureOutParams(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	stmt := &mysqlStmt{mc: mc, id: 1}
+
+	var resp []byte
+	// result set of a SELECT in the procedure
+	resp = append(resp, mockPacket(1, 1)...)
+	resp = append(resp, mockColumn(2)...)
+	resp = append(resp, mockEOF(3, 0)...)
+	resp = append(resp, mockBinaryRow(4, 1)...)
+	resp = append(resp, mockEOF(5, statusMoreResultsExists)...)
+	// OUT parameters
+	resp = append(resp, mockPacket(6, 1)...)
+	resp = append(resp, mockColumn(7)...)
+	resp = append(resp, mockEOF(8, statusPsOutParams|statusMoreResultsExists)...)
+	resp = append(resp, mockBinaryRow(9, 2)...)
+	resp = append(resp, mockEOF(10, statusPsOutParams|statusMoreResultsExists)...)
+	// trailing OK of the CALL
+	resp = append(resp, mockPacket(11, iOK, 0, 0, 0, 0, 0, 0)...)
+	conn.queuedReplies = [][]byte{resp}
+
+	rows, err := stmt.query(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dest := make([]driver.Value, 1)
+	for i, want := range []int64{1, 2} {
+		if i > 0 {
+			if !rows.HasNextResultSet() {
+				t.Fatal("expected another result set")
+			}
+			if err := rows.NextResultSet(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := rows.Next(dest); err != nil {
+			t.Fatal(err)
+		}
+		if dest[0] != want {
+			t.Errorf("expected %d, got %v", want, dest[0])
+		}
+		if err := rows.Next(dest); err != io.EOF {
+			t.Fatalf("expected io.EOF, got %v", err)
+		}
+	}
+
+	if err := rows.NextResultSet(); err != io.EOF {
+		t.Fatalf("expected io.EOF after the trailing OK, got %v", err)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(conn.data) != 0 || len(mc.buf.buf) != 0 {
+		t.Error("expected the whole response to be consumed")
+	}
+}
+
+func TestStmtQueryCachedMetadataNextResultSet(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.extCapabilities |= clientCacheMetadata
+	stmt := &mysqlStmt{mc: mc, id: 1, columns: []mysqlField{{fieldType: fieldTypeLongLong}}}
+
+	// both result sets omit their metadata
+	var resp []byte
+	resp = append(resp, mockPacket(1, 1, 0)...)
+	resp = append(resp, mockEOF(2, 0)...)
+	resp = append(resp, mockBinaryRow(3, 1)...)
+	resp = append(resp, mockEOF(4, statusMoreResultsExists)...)
+	resp = append(resp, mockPacket(5, 1, 0)...)
+	resp = append(resp, mockEOF(6, 0)...)
+	resp = append(resp, mockBinaryRow(7, 2)...)
+	resp = append(resp, mockEOF(8, 0)...)
+	conn.queuedReplies = [][]byte{resp}
+
+	rows, err := stmt.query(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest := make([]driver.Value, 1)
+	if err := rows.Next(dest); err != nil || dest[0] != int64(1) {
+		t.Fatalf("expected 1, got %v (%v)", dest[0], err)
+	}
+	if err := rows.NextResultSet(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rows.Next(dest); err != nil || dest[0] != int64(2) {
+		t.Fatalf("expected 2, got %v (%v)", dest[0], err)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStmtQueryReusesColumns(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	stmt := &mysqlStmt{mc: mc, id: 1}
+
+	renamed := mockColumn(2)
+	renamed[12] = 'w' // name
+	result := func(column []byte) []byte {
+		var resp []byte
+		resp = append(resp, mockPacket(1, 1)...)
+		resp = append(resp, column...)
+		resp = append(resp, mockEOF(3, 0)...)
+		resp = append(resp, mockBinaryRow(4, 1)...)
+		resp = append(resp, mockEOF(5, 0)...)
+		return resp
+	}
+	conn.queuedReplies = [][]byte{result(mockColumn(2)), result(mockColumn(2)), result(renamed)}
+
+	var columns [][]mysqlField
+	for range 3 {
+		rows, err := stmt.query(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		columns = append(columns, rows.rs.columns)
+		if err := rows.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if &columns[0][0] != &columns[1][0] {
+		t.Error("expected the columns of an unchanged definition to be reused")
+	}
+	if &columns[1][0] == &columns[2][0] {
+		t.Error("expected the columns of a changed definition to be read")
+	}
+	if columns[0][0].name != "v" || columns[2][0].name != "w" {
+		t.Errorf("expected the columns v and w, got %q and %q", columns[0][0].name, columns[2][0].name)
+	}
+}
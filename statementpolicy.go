@@ -0,0 +1,89 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"time"
+)
+
+// StatementPolicy makes the statements of a connector resilient against
+// slow or failing servers without an external proxy.
+type StatementPolicy struct {
+	// Timeout bounds the execution of every statement, up to its result
+	// set header or OK packet; reading the rows isn't limited. A statement
+	// exceeding it fails with ErrStatementTimeout and the connection is
+	// canceled like with a canceled context, see CancelMode. 0 disables it.
+	Timeout time.Duration
+
+	// RetryReadOnly makes SELECT queries outside of transactions, which fail
+	// with ErrStatementTimeout or an error IsRetryableError accepts before
+	// returning rows, return driver.ErrBadConn after closing the connection.
+	// database/sql retries them on another connection then, the last of
+	// its two retries on a new connection. Queries of a sql.Conn aren't
+	// retried by database/sql and fail with driver.ErrBadConn.
+	//
+	// The retries don't fail over to another host: the new connection
+	// dials the same address. Only a BeforeConnect function or DialFunc
+	// choosing among several hosts makes it reach another replica.
+	RetryReadOnly bool
+}
+
+// UseStatementPolicy sets the statement policy of the connector.
+func UseStatementPolicy(p StatementPolicy) Option {
+	return func(cfg *Config) error {
+		cfg.statementTimeout = p.Timeout
+		cfg.retryReadOnly = p.RetryReadOnly
+		return nil
+	}
+}
+
+// startStatementTimer starts the timer canceling the connection when the
+// statement timeout elapses. The returned function stops it.
+func (mc *mysqlConn) startStatementTimer() (stop func() bool) {
+	if mc.cfg.statementTimeout <= 0 {
+		return func() bool { return false }
+	}
+	t := time.AfterFunc(mc.cfg.statementTimeout, func() {
+		mc.cancel(ErrStatementTimeout)
+	})
+	return t.Stop
+}
+
+// retryReadOnly returns driver.ErrBadConn instead of err if the read-only
+// query failed with an error worth a retry on another connection, closing
+// the connection.
+func (mc *mysqlConn) retryReadOnly(query string, err error) error {
+	if !mc.cfg.retryReadOnly || mc.status&statusInTrans != 0 || !isReadOnlyQuery(query) {
+		return err
+	}
+	if !errors.Is(err, ErrStatementTimeout) && !IsRetryableError(err) {
+		return err
+	}
+	mc.log("retrying read-only statement on another connection: ", err)
+	mc.close()
+	return driver.ErrBadConn
+}
+
+// isReadOnlyQuery reports whether query is a single SELECT statement which
+// neither locks rows nor writes its result into variables or files.
+func isReadOnlyQuery(query string) bool {
+	if selectKeyword(query) < 0 || strings.IndexByte(query, ';') >= 0 {
+		return false
+	}
+	upper := strings.ToUpper(query)
+	for _, clause := range []string{"FOR UPDATE", "FOR SHARE", "LOCK IN SHARE MODE", "INTO"} {
+		if strings.Contains(upper, clause) {
+			return false
+		}
+	}
+	return true
+}
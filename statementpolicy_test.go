@@ -0,0 +1,145 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIsReadOnlyQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"SELECT 1", true},
+		{"  /* report */ select * from t where id = ?", true},
+		{"SELECT * FROM t FOR UPDATE", false},
+		{"SELECT * FROM t FOR SHARE", false},
+		{"SELECT * FROM t LOCK IN SHARE MODE", false},
+		{"SELECT id INTO @id FROM t", false},
+		{"SELECT 1; DELETE FROM t", false},
+		{"UPDATE t SET a = 1", false},
+		{"WITH x AS (SELECT 1) SELECT * FROM x", false},
+	}
+	for _, tt := range tests {
+		if got := isReadOnlyQuery(tt.query); got != tt.want {
+			t.Errorf("isReadOnlyQuery(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+// hangingReply leaves the first n commands unanswered until the test ends
+// and answers the following ones with reply.
+func hangingReply(t *testing.T, n int32, reply func(cmd []byte) []byte) func(cmd []byte) []byte {
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	var calls atomic.Int32
+	return func(cmd []byte) []byte {
+		if calls.Add(1) <= n {
+			<-done
+			return nil
+		}
+		return reply(cmd)
+	}
+}
+
+func TestStatementTimeout(t *testing.T) {
+	ok := mockPacket(2, iOK, 0, 0, 2, 0, 0, 0)
+	c := newMockServerConnectorReply(t, ok, hangingReply(t, 2, selectOneReply('1')),
+		UseStatementPolicy(StatementPolicy{Timeout: 50 * time.Millisecond}))
+
+	for _, query := range []string{"SELECT 1", "UPDATE t SET a = 1"} {
+		conn, err := c.Connect(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		mc := conn.(*mysqlConn)
+		start := time.Now()
+		if query == "SELECT 1" {
+			_, err = mc.QueryContext(context.Background(), query, nil)
+		} else {
+			_, err = mc.ExecContext(context.Background(), query, nil)
+		}
+		if err != ErrStatementTimeout {
+			t.Errorf("%s: expected ErrStatementTimeout, got %v", query, err)
+		}
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("%s: expected the statement to time out after 50ms, took %v", query, d)
+		}
+		if !mc.closed.Load() {
+			t.Errorf("%s: expected the connection to be canceled", query)
+		}
+	}
+}
+
+func TestStatementTimeoutStopped(t *testing.T) {
+	ok := mockPacket(2, iOK, 0, 0, 2, 0, 0, 0)
+	c := newMockServerConnectorReply(t, ok, selectOneReply('1'),
+		UseStatementPolicy(StatementPolicy{Timeout: 20 * time.Millisecond}))
+	conn, err := c.Connect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	mc := conn.(*mysqlConn)
+
+	rows, err := mc.QueryContext(context.Background(), "SELECT 1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Reading the rows isn't limited by the timeout
+	time.Sleep(50 * time.Millisecond)
+	if err := rows.Next(make([]driver.Value, 1)); err != nil {
+		t.Errorf("expected the row to be read, got %v", err)
+	}
+	rows.Close()
+}
+
+func TestRetryReadOnly(t *testing.T) {
+	ok := mockPacket(2, iOK, 0, 0, 2, 0, 0, 0)
+	c := newMockServerConnectorReply(t, ok, hangingReply(t, 2, selectOneReply('1')),
+		UseStatementPolicy(StatementPolicy{Timeout: 50 * time.Millisecond, RetryReadOnly: true}))
+
+	conn, err := c.Connect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.(*mysqlConn).QueryContext(context.Background(), "SELECT 1", nil); err != driver.ErrBadConn {
+		t.Errorf("expected driver.ErrBadConn, got %v", err)
+	}
+
+	conn, err = c.Connect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.(*mysqlConn).ExecContext(context.Background(), "UPDATE t SET a = 1", nil); err != ErrStatementTimeout {
+		t.Errorf("expected writes not to be retried, got %v", err)
+	}
+}
+
+func TestRetryReadOnlyDB(t *testing.T) {
+	ok := mockPacket(2, iOK, 0, 0, 2, 0, 0, 0)
+	c := newMockServerConnectorReply(t, ok, hangingReply(t, 1, selectOneReply('1')),
+		UseStatementPolicy(StatementPolicy{Timeout: 50 * time.Millisecond, RetryReadOnly: true}))
+	db := sql.OpenDB(c)
+	defer db.Close()
+
+	var v int
+	if err := db.QueryRow("SELECT 1").Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v != 1 {
+		t.Errorf("expected 1, got %d", v)
+	}
+}
@@ -0,0 +1,183 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StatsCollector receives statistics of the connections of a Connector, e.g.
+// to export them as metrics. Set it with the CollectStats option.
+//
+// The methods are called synchronously by all connections of the Connector,
+// so they must be safe for concurrent use and return quickly.
+//
+// The driver doesn't provide a Prometheus adapter, as it would make the
+// Prometheus client a dependency of all users of the driver. A collector
+// maps the calls to metrics, e.g.:
+//
+//	func (c collector) OpDone(info *mysql.HookInfo, d time.Duration, err error) {
+//		c.opDuration.WithLabelValues(string(info.Op), info.AuthPlugin).Observe(d.Seconds())
+//		if err != nil {
+//			c.opErrors.WithLabelValues(string(info.Op)).Inc()
+//		}
+//	}
+//
+//	func (c collector) PacketRead(n int) { c.bytesRead.Add(float64(n)) }
+type StatsCollector interface {
+	// OpDone is called when an operation ends, with its duration and error.
+	// The operations are those reported to Hooks; HookConnect reports
+	// opened and failed connections, HookTLS the handshake durations and
+	// resumed sessions, HookAuth the plugin connections authenticated with.
+	OpDone(info *HookInfo, d time.Duration, err error)
+
+	// PacketRead and PacketWritten are called for each packet with its
+	// size including the header. Compressed packets are reported before
+	// compression.
+	PacketRead(n int)
+	PacketWritten(n int)
+
+	// Compressed is called for each compressed packet sent or received.
+	Compressed(uncompressed, compressed int)
+
+	// ConnectRetry is called when a failed connect is retried, see
+	// DialRetries.
+	ConnectRetry()
+}
+
+// CollectStats sets the StatsCollector receiving statistics of the
+// connections.
+func CollectStats(c StatsCollector) Option {
+	return func(cfg *Config) error {
+		cfg.statsCollector = c
+		return nil
+	}
+}
+
+// OpStats contains the statistics of an operation in a StatsSnapshot.
+type OpStats struct {
+	Count    int64         // The number of operations
+	Errors   int64         // The number of failed operations
+	Duration time.Duration // The total duration of the operations
+}
+
+// StatsSnapshot contains the statistics collected by StatsCounters.
+type StatsSnapshot struct {
+	Ops         map[HookOp]OpStats
+	AuthPlugins map[string]int64 // The number of successful authentications per plugin
+
+	PacketsRead       int64
+	BytesRead         int64
+	PacketsWritten    int64
+	BytesWritten      int64
+	BytesUncompressed int64 // Size of compressed packets before compression
+	BytesCompressed   int64 // Size of compressed packets after compression
+	ConnectRetries    int64
+	TLSResumed        int64 // The number of TLS handshakes resuming a cached session
+}
+
+// StatsCounters is a StatsCollector summing up the statistics, e.g. to
+// publish them with expvar or a metrics library:
+//
+//	counters := new(mysql.StatsCounters)
+//	cfg.Apply(mysql.CollectStats(counters))
+//	...
+//	snapshot := counters.Snapshot()
+//
+// The zero value is ready to use.
+type StatsCounters struct {
+	mu          sync.Mutex
+	ops         map[HookOp]OpStats
+	authPlugins map[string]int64
+
+	packetsRead       atomic.Int64
+	bytesRead         atomic.Int64
+	packetsWritten    atomic.Int64
+	bytesWritten      atomic.Int64
+	bytesUncompressed atomic.Int64
+	bytesCompressed   atomic.Int64
+	connectRetries    atomic.Int64
+	tlsResumed        atomic.Int64
+}
+
+var _ StatsCollector = (*StatsCounters)(nil)
+
+// OpDone implements StatsCollector interface.
+func (sc *StatsCounters) OpDone(info *HookInfo, d time.Duration, err error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if sc.ops == nil {
+		sc.ops = make(map[HookOp]OpStats)
+	}
+	s := sc.ops[info.Op]
+	s.Count++
+	s.Duration += d
+	if err != nil {
+		s.Errors++
+	}
+	sc.ops[info.Op] = s
+
+	if info.Op == HookTLS && err == nil && info.TLSResumed {
+		sc.tlsResumed.Add(1)
+	}
+	if info.Op == HookAuth && err == nil {
+		if sc.authPlugins == nil {
+			sc.authPlugins = make(map[string]int64)
+		}
+		sc.authPlugins[info.AuthPlugin]++
+	}
+}
+
+// PacketRead implements StatsCollector interface.
+func (sc *StatsCounters) PacketRead(n int) {
+	sc.packetsRead.Add(1)
+	sc.bytesRead.Add(int64(n))
+}
+
+// PacketWritten implements StatsCollector interface.
+func (sc *StatsCounters) PacketWritten(n int) {
+	sc.packetsWritten.Add(1)
+	sc.bytesWritten.Add(int64(n))
+}
+
+// Compressed implements StatsCollector interface.
+func (sc *StatsCounters) Compressed(uncompressed, compressed int) {
+	sc.bytesUncompressed.Add(int64(uncompressed))
+	sc.bytesCompressed.Add(int64(compressed))
+}
+
+// ConnectRetry implements StatsCollector interface.
+func (sc *StatsCounters) ConnectRetry() {
+	sc.connectRetries.Add(1)
+}
+
+// Snapshot returns the statistics collected so far.
+func (sc *StatsCounters) Snapshot() StatsSnapshot {
+	sc.mu.Lock()
+	ops := maps.Clone(sc.ops)
+	authPlugins := maps.Clone(sc.authPlugins)
+	sc.mu.Unlock()
+
+	return StatsSnapshot{
+		Ops:               ops,
+		AuthPlugins:       authPlugins,
+		PacketsRead:       sc.packetsRead.Load(),
+		BytesRead:         sc.bytesRead.Load(),
+		PacketsWritten:    sc.packetsWritten.Load(),
+		BytesWritten:      sc.bytesWritten.Load(),
+		BytesUncompressed: sc.bytesUncompressed.Load(),
+		BytesCompressed:   sc.bytesCompressed.Load(),
+		ConnectRetries:    sc.connectRetries.Load(),
+		TLSResumed:        sc.tlsResumed.Load(),
+	}
+}
@@ -0,0 +1,83 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestStatsCounters(t *testing.T) {
+	counters := new(StatsCounters)
+	conn, mc := newRWMockConn(0)
+	mc.cfg.statsCollector = counters
+
+	conn.queuedReplies = [][]byte{
+		{7, 0, 0, 1, iOK, 1, 0, 2, 0, 0, 0},
+		{9, 0, 0, 1, iERR, 0x15, 0x04, '#', '2', '8', '0', '0', '0'},
+	}
+	if _, err := mc.ExecContext(context.Background(), "DO 1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mc.ExecContext(context.Background(), "DO 2", nil); err == nil {
+		t.Fatal("error expected")
+	}
+
+	s := counters.Snapshot()
+	if exec := s.Ops[HookExec]; exec.Count != 2 || exec.Errors != 1 {
+		t.Errorf("expected 2 execs with 1 error, got %+v", exec)
+	}
+	// 4 byte header, command and query
+	if s.PacketsWritten != 2 || s.BytesWritten != 2*(4+1+4) {
+		t.Errorf("expected 2 packets of 9 bytes written, got %d packets of %d bytes", s.PacketsWritten, s.BytesWritten)
+	}
+	if s.PacketsRead != 2 || s.BytesRead != 11+13 {
+		t.Errorf("expected 2 packets of 24 bytes read, got %d packets of %d bytes", s.PacketsRead, s.BytesRead)
+	}
+}
+
+func TestStatsCountersConnectRetry(t *testing.T) {
+	counters := new(StatsCounters)
+	cfg := NewConfig()
+	cfg.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: errors.New("connection refused")}
+	}
+	if err := cfg.Apply(CollectStats(counters), DialRetries(1, time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newConnector(cfg).Connect(context.Background()); err == nil {
+		t.Fatal("error expected")
+	}
+
+	s := counters.Snapshot()
+	if s.ConnectRetries != 1 {
+		t.Errorf("expected 1 retry, got %d", s.ConnectRetries)
+	}
+	if c := s.Ops[HookConnect]; c.Count != 2 || c.Errors != 2 {
+		t.Errorf("expected 2 failed connects, got %+v", c)
+	}
+}
+
+func TestStatsCountersTLSResumed(t *testing.T) {
+	counters := new(StatsCounters)
+	counters.OpDone(&HookInfo{Op: HookTLS}, 3*time.Millisecond, nil)
+	counters.OpDone(&HookInfo{Op: HookTLS, TLSResumed: true}, time.Millisecond, nil)
+
+	s := counters.Snapshot()
+	if handshakes := s.Ops[HookTLS]; handshakes.Count != 2 || handshakes.Duration != 4*time.Millisecond {
+		t.Errorf("expected 2 TLS handshakes taking 4ms, got %+v", handshakes)
+	}
+	if s.TLSResumed != 1 {
+		t.Errorf("expected 1 resumed TLS session, got %d", s.TLSResumed)
+	}
+}
@@ -0,0 +1,76 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import "container/list"
+
+// stmtCache is a LRU cache of server-side prepared statements keyed by query
+// text. It is owned by a single connection and must not be used concurrently.
+type stmtCache struct {
+	size  int
+	lru   *list.List // front is the most recently used entry
+	items map[string]*list.Element
+}
+
+type stmtCacheEntry struct {
+	query string
+	stmt  *mysqlStmt
+}
+
+func newStmtCache(size int) *stmtCache {
+	return &stmtCache{
+		size:  size,
+		lru:   list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// get returns the cached statement for query, or nil on a cache miss.
+func (c *stmtCache) get(query string) *mysqlStmt {
+	if e, ok := c.items[query]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*stmtCacheEntry).stmt
+	}
+	return nil
+}
+
+// put adds stmt to the cache and returns the least recently used statement
+// if it had to be evicted. The caller is responsible for closing it.
+func (c *stmtCache) put(query string, stmt *mysqlStmt) (evicted *mysqlStmt) {
+	if e, ok := c.items[query]; ok {
+		c.lru.MoveToFront(e)
+		entry := e.Value.(*stmtCacheEntry)
+		evicted, entry.stmt = entry.stmt, stmt
+		return evicted
+	}
+
+	c.items[query] = c.lru.PushFront(&stmtCacheEntry{query: query, stmt: stmt})
+	if c.lru.Len() <= c.size {
+		return nil
+	}
+
+	oldest := c.lru.Back()
+	c.lru.Remove(oldest)
+	entry := oldest.Value.(*stmtCacheEntry)
+	delete(c.items, entry.query)
+	return entry.stmt
+}
+
+// len returns the number of cached statements.
+func (c *stmtCache) len() int {
+	return c.lru.Len()
+}
+
+// clear removes all statements from the cache without closing them.
+// It is used when the server already discarded them, e.g. after the session
+// has been reset.
+func (c *stmtCache) clear() {
+	c.lru.Init()
+	clear(c.items)
+}
@@ -0,0 +1,74 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestStmtCacheLRU(t *testing.T) {
+	c := newStmtCache(2)
+	s1, s2, s3 := &mysqlStmt{id: 1}, &mysqlStmt{id: 2}, &mysqlStmt{id: 3}
+
+	if evicted := c.put("q1", s1); evicted != nil {
+		t.Fatalf("unexpected eviction: %v", evicted.id)
+	}
+	if evicted := c.put("q2", s2); evicted != nil {
+		t.Fatalf("unexpected eviction: %v", evicted.id)
+	}
+
+	// q1 becomes the most recently used entry
+	if stmt := c.get("q1"); stmt != s1 {
+		t.Fatalf("expected stmt 1, got %v", stmt)
+	}
+
+	if evicted := c.put("q3", s3); evicted != s2 {
+		t.Fatalf("expected stmt 2 to be evicted, got %v", evicted)
+	}
+	if stmt := c.get("q2"); stmt != nil {
+		t.Errorf("expected cache miss for q2, got stmt %v", stmt.id)
+	}
+	if c.len() != 2 {
+		t.Errorf("expected 2 cached statements, got %d", c.len())
+	}
+
+	// replacing an entry returns the previous statement
+	s4 := &mysqlStmt{id: 4}
+	if evicted := c.put("q3", s4); evicted != s3 {
+		t.Errorf("expected stmt 3 to be replaced, got %v", evicted)
+	}
+
+	c.clear()
+	if c.len() != 0 || c.get("q1") != nil {
+		t.Error("expected empty cache after clear")
+	}
+}
+
+func TestUseStmtCache(t *testing.T) {
+	mc := &mysqlConn{cfg: NewConfig()}
+	args := []driver.Value{int64(1)}
+
+	if mc.useStmtCache(args) {
+		t.Error("statement cache must not be used when disabled")
+	}
+
+	mc.stmtCache = newStmtCache(1)
+	if !mc.useStmtCache(args) {
+		t.Error("expected statement cache to be used")
+	}
+	if mc.useStmtCache(nil) {
+		t.Error("statement cache must not be used for queries without arguments")
+	}
+
+	mc.cfg.InterpolateParams = true
+	if mc.useStmtCache(args) {
+		t.Error("statement cache must not be used with interpolateParams")
+	}
+}
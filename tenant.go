@@ -0,0 +1,183 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TenantSession describes the session state applied to a connection while it
+// is used on behalf of a tenant.
+type TenantSession struct {
+	// Roles are activated with SET ROLE. Each entry is a role specification
+	// as accepted by MySQL, e.g. "'tenant_a'@'%'".
+	Roles []string
+
+	// Vars are set with SET SESSION. Names starting with '@' are user
+	// defined variables; empty names fail the query. Values are SQL
+	// expressions and must be quoted accordingly, e.g. "'tenant_a'".
+	Vars map[string]string
+}
+
+// TenantResolverFunc maps a tenant id to the session state of the tenant.
+type TenantResolverFunc func(ctx context.Context, tenantID string) (TenantSession, error)
+
+type tenantCtxKey struct{}
+
+// WithTenant returns a copy of ctx carrying the given tenant id.
+//
+// When a TenantResolver is configured, queries executed with the returned
+// context run with the session state of the tenant. The state is reset when
+// the connection is used with a context of another tenant or without tenant.
+//
+//	ctx := mysql.WithTenant(r.Context(), "tenant_a")
+//	rows, err := db.QueryContext(ctx, "SELECT * FROM orders")
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantCtxKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant id stored in ctx by WithTenant.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantCtxKey{}).(string)
+	return tenantID, ok
+}
+
+// TenantResolver sets the function mapping tenant ids found in query contexts
+// (see WithTenant) to the session state applied to the connection.
+func TenantResolver(fn TenantResolverFunc) Option {
+	return func(cfg *Config) error {
+		cfg.tenantResolver = fn
+		return nil
+	}
+}
+
+// sortedVars returns the variable names of ts in a deterministic order.
+func (ts *TenantSession) sortedVars() []string {
+	names := make([]string, 0, len(ts.Vars))
+	for name := range ts.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validate reports variables the statements of the session state can't be
+// built for.
+func (ts *TenantSession) validate() error {
+	for name := range ts.Vars {
+		if name == "" || name == "@" {
+			return fmt.Errorf("tenant session: invalid variable name %q", name)
+		}
+	}
+	return nil
+}
+
+// applyQueries returns the statements activating the session state.
+func (ts *TenantSession) applyQueries() []string {
+	var queries []string
+	if len(ts.Roles) > 0 {
+		queries = append(queries, "SET ROLE "+strings.Join(ts.Roles, ", "))
+	}
+	if len(ts.Vars) > 0 {
+		var q strings.Builder
+		q.WriteString("SET ")
+		for i, name := range ts.sortedVars() {
+			if i > 0 {
+				q.WriteString(", ")
+			}
+			if name[0] != '@' {
+				q.WriteString("SESSION ")
+			}
+			q.WriteString(name)
+			q.WriteString(" = ")
+			q.WriteString(ts.Vars[name])
+		}
+		queries = append(queries, q.String())
+	}
+	return queries
+}
+
+// resetQueries returns the statements reverting the session state.
+func (ts *TenantSession) resetQueries() []string {
+	var queries []string
+	if len(ts.Roles) > 0 {
+		queries = append(queries, "SET ROLE DEFAULT")
+	}
+	if len(ts.Vars) > 0 {
+		var q strings.Builder
+		q.WriteString("SET ")
+		for i, name := range ts.sortedVars() {
+			if i > 0 {
+				q.WriteString(", ")
+			}
+			if name[0] == '@' {
+				// user defined variables have no default value
+				q.WriteString(name)
+				q.WriteString(" = NULL")
+			} else {
+				q.WriteString("SESSION ")
+				q.WriteString(name)
+				q.WriteString(" = DEFAULT")
+			}
+		}
+		queries = append(queries, q.String())
+	}
+	return queries
+}
+
+// switchTenant applies the session state of the tenant found in ctx, after
+// resetting the state of the tenant the connection was previously used for.
+// If a statement fails, the connection is closed, as it may carry part of
+// the state of a tenant.
+func (mc *mysqlConn) switchTenant(ctx context.Context) error {
+	resolver := mc.cfg.tenantResolver
+	if resolver == nil {
+		return nil
+	}
+
+	tenantID, _ := TenantFromContext(ctx)
+	if tenantID == mc.tenantID {
+		return nil
+	}
+
+	if mc.tenantID != "" {
+		for _, q := range mc.tenantSession.resetQueries() {
+			if err := mc.exec(q); err != nil {
+				mc.cleanup()
+				return err
+			}
+		}
+		mc.tenantID = ""
+		mc.tenantSession = TenantSession{}
+	}
+
+	if tenantID == "" {
+		return nil
+	}
+
+	ts, err := resolver(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if err := ts.validate(); err != nil {
+		return err
+	}
+	for _, q := range ts.applyQueries() {
+		if err := mc.exec(q); err != nil {
+			mc.cleanup()
+			return err
+		}
+	}
+	mc.tenantID = tenantID
+	mc.tenantSession = ts
+	return nil
+}
@@ -0,0 +1,117 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+var okPacket = []byte{7, 0, 0, 1, iOK, 0, 0, 2, 0, 0, 0}
+
+func TestTenantSessionQueries(t *testing.T) {
+	ts := TenantSession{
+		Roles: []string{"'tenant_a'", "'reporting'"},
+		Vars:  map[string]string{"@tenant_id": "'a'", "sql_mode": "'ANSI'"},
+	}
+
+	apply := []string{
+		"SET ROLE 'tenant_a', 'reporting'",
+		"SET @tenant_id = 'a', SESSION sql_mode = 'ANSI'",
+	}
+	if got := ts.applyQueries(); !reflect.DeepEqual(got, apply) {
+		t.Errorf("apply queries mismatch:\ngot  %q\nwant %q", got, apply)
+	}
+
+	reset := []string{
+		"SET ROLE DEFAULT",
+		"SET @tenant_id = NULL, SESSION sql_mode = DEFAULT",
+	}
+	if got := ts.resetQueries(); !reflect.DeepEqual(got, reset) {
+		t.Errorf("reset queries mismatch:\ngot  %q\nwant %q", got, reset)
+	}
+
+	empty := TenantSession{}
+	if len(empty.applyQueries()) != 0 || len(empty.resetQueries()) != 0 {
+		t.Error("expected no queries for empty tenant session")
+	}
+}
+
+func TestSwitchTenant(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.cfg.tenantResolver = func(_ context.Context, tenantID string) (TenantSession, error) {
+		return TenantSession{Roles: []string{"'" + tenantID + "'"}}, nil
+	}
+
+	// apply tenant a
+	conn.queuedReplies = [][]byte{okPacket}
+	if err := mc.switchTenant(WithTenant(context.Background(), "a")); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(conn.written, []byte("SET ROLE 'a'")) {
+		t.Errorf("expected SET ROLE for tenant a, got %q", conn.written)
+	}
+
+	// same tenant: nothing is sent
+	conn.written = nil
+	if err := mc.switchTenant(WithTenant(context.Background(), "a")); err != nil {
+		t.Fatal(err)
+	}
+	if len(conn.written) != 0 {
+		t.Errorf("expected no queries, got %q", conn.written)
+	}
+
+	// no tenant: the role is reset
+	conn.queuedReplies = [][]byte{okPacket}
+	if err := mc.switchTenant(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(conn.written, []byte("SET ROLE DEFAULT")) {
+		t.Errorf("expected SET ROLE DEFAULT, got %q", conn.written)
+	}
+	if mc.tenantID != "" {
+		t.Errorf("expected no active tenant, got %q", mc.tenantID)
+	}
+}
+
+func TestSwitchTenantPartialFailure(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	mc.cfg.tenantResolver = func(_ context.Context, tenantID string) (TenantSession, error) {
+		return TenantSession{
+			Roles: []string{"'" + tenantID + "'"},
+			Vars:  map[string]string{"@tenant_id": "'" + tenantID + "'"},
+		}, nil
+	}
+
+	// the role is activated, setting the variables fails
+	conn.queuedReplies = [][]byte{okPacket, mockPacket(1, iERR, 0x7a, 0x04, '#', '4', '2', '0', '0', '0', 'n', 'o')}
+	if err := mc.switchTenant(WithTenant(context.Background(), "a")); err == nil {
+		t.Fatal("expected an error")
+	}
+	if mc.IsValid() {
+		t.Error("expected the connection carrying the role of the tenant to be discarded")
+	}
+}
+
+func TestSwitchTenantInvalidVar(t *testing.T) {
+	for _, name := range []string{"", "@"} {
+		conn, mc := newRWMockConn(0)
+		mc.cfg.tenantResolver = func(_ context.Context, tenantID string) (TenantSession, error) {
+			return TenantSession{Vars: map[string]string{name: "'" + tenantID + "'"}}, nil
+		}
+		if err := mc.switchTenant(WithTenant(context.Background(), "a")); err == nil {
+			t.Errorf("%q: expected an error", name)
+		}
+		if len(conn.written) != 0 || mc.tenantID != "" {
+			t.Errorf("%q: expected no session state to be applied", name)
+		}
+	}
+}
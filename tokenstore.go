@@ -0,0 +1,151 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// NewMemoryTokenStore returns a TokenStore keeping the refresh tokens in
+// memory, e.g. for tests or long-running processes logging in at startup.
+// The tokens are lost when the process exits.
+func NewMemoryTokenStore() TokenStore {
+	return &memoryTokenStore{tokens: make(map[OIDCTokenKey]string)}
+}
+
+type memoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[OIDCTokenKey]string
+}
+
+func (m *memoryTokenStore) LoadRefreshToken(ctx context.Context, key OIDCTokenKey) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	token, ok := m.tokens[key]
+	if !ok {
+		return "", ErrNoRefreshToken
+	}
+	return token, nil
+}
+
+func (m *memoryTokenStore) SaveRefreshToken(ctx context.Context, key OIDCTokenKey, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[key] = token
+	return nil
+}
+
+// NewFileTokenStore returns a TokenStore keeping the refresh token in the
+// file at path, readable by the owner only. The file is replaced atomically,
+// so that concurrent processes never read a partial token. A missing file
+// reports ErrNoRefreshToken.
+//
+// The file holds a single token: use a file per OIDC client.
+func NewFileTokenStore(path string) TokenStore {
+	return fileTokenStore(path)
+}
+
+// fileTokenStore is a TokenStore of a refresh token file.
+type fileTokenStore string
+
+func (f fileTokenStore) LoadRefreshToken(ctx context.Context, key OIDCTokenKey) (string, error) {
+	b, err := os.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return "", ErrNoRefreshToken
+	} else if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func (f fileTokenStore) SaveRefreshToken(ctx context.Context, key OIDCTokenKey, token string) error {
+	// CreateTemp creates the file with mode 0600
+	tmp, err := os.CreateTemp(filepath.Dir(string(f)), ".refresh-token-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(token + "\n"); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
+
+// renewedRefreshTokens holds the refresh tokens renewed by dsnTokenStores,
+// by token key and the refresh token of the DSN.
+var renewedRefreshTokens sync.Map // renewedRefreshTokenKey -> string
+
+type renewedRefreshTokenKey struct {
+	key     OIDCTokenKey
+	initial string
+}
+
+// dsnTokenStore is a TokenStore of the refresh token of the DSN, which keeps
+// the renewed refresh tokens in memory.
+type dsnTokenStore string
+
+func (d dsnTokenStore) LoadRefreshToken(ctx context.Context, key OIDCTokenKey) (string, error) {
+	if token, ok := renewedRefreshTokens.Load(renewedRefreshTokenKey{key, string(d)}); ok {
+		return token.(string), nil
+	}
+	return string(d), nil
+}
+
+func (d dsnTokenStore) SaveRefreshToken(ctx context.Context, key OIDCTokenKey, token string) error {
+	renewedRefreshTokens.Store(renewedRefreshTokenKey{key, string(d)}, token)
+	return nil
+}
+
+// NewKeychainTokenStore returns a TokenStore keeping the refresh tokens in
+// the keychain of the OS under service, e.g. the name of the CLI tool:
+//
+//   - macOS: the login keychain, with the security tool
+//   - Windows: the Credential Manager, as generic credentials
+//   - Linux and BSDs: the Secret Service, e.g. GNOME Keyring or KWallet, with
+//     the secret-tool of libsecret
+//
+// On other platforms, the store reports ErrKeychainUnsupported. Each OIDC
+// client has its own item, named after the client id, the token endpoint
+// and the audience.
+func NewKeychainTokenStore(service string) TokenStore {
+	return keychainTokenStore(service)
+}
+
+// keychainTokenStore is a TokenStore of the OS keychain, implemented by
+// keychainLoad and keychainSave of the platform.
+type keychainTokenStore string
+
+func (k keychainTokenStore) LoadRefreshToken(ctx context.Context, key OIDCTokenKey) (string, error) {
+	return keychainLoad(ctx, string(k), keychainAccount(key))
+}
+
+func (k keychainTokenStore) SaveRefreshToken(ctx context.Context, key OIDCTokenKey, token string) error {
+	return keychainSave(ctx, string(k), keychainAccount(key), token)
+}
+
+// keychainAccount returns the account of the keychain item of key.
+func keychainAccount(key OIDCTokenKey) string {
+	account := key.ClientID + "@" + key.Issuer
+	if key.Audience != "" {
+		account += "#" + key.Audience
+	}
+	return account
+}
@@ -0,0 +1,47 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// errSecItemNotFound is the exit code of the security tool for missing items.
+const errSecItemNotFound = 44
+
+func keychainLoad(ctx context.Context, service, account string) (string, error) {
+	out, err := exec.CommandContext(ctx, "security", "find-generic-password", "-s", service, "-a", account, "-w").Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == errSecItemNotFound {
+		return "", ErrNoRefreshToken
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read the keychain: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func keychainSave(ctx context.Context, service, account, token string) error {
+	// the command is passed on stdin, so that the token doesn't show up in
+	// the arguments of the process
+	cmd := exec.CommandContext(ctx, "security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		strconv.Quote(service), strconv.Quote(account), strconv.Quote(token)))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to write the keychain: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// testTokenStoreRoundTrip checks the store of refresh tokens of a client.
+func testTokenStoreRoundTrip(t *testing.T, store TokenStore) {
+	ctx := context.Background()
+	key := OIDCTokenKey{Issuer: "https://idp.example.com/token", ClientID: "cli"}
+	if _, err := store.LoadRefreshToken(ctx, key); !errors.Is(err, ErrNoRefreshToken) {
+		t.Fatalf("expected ErrNoRefreshToken, got %v", err)
+	}
+	for _, token := range []string{"rt-1", "rt-2"} {
+		if err := store.SaveRefreshToken(ctx, key, token); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := store.LoadRefreshToken(ctx, key); err != nil || got != token {
+			t.Errorf("expected %q, got %q (%v)", token, got, err)
+		}
+	}
+}
+
+func TestMemoryTokenStore(t *testing.T) {
+	store := NewMemoryTokenStore()
+	testTokenStoreRoundTrip(t, store)
+
+	// the tokens are kept by client
+	other := OIDCTokenKey{Issuer: "https://idp.example.com/token", ClientID: "other"}
+	if _, err := store.LoadRefreshToken(context.Background(), other); !errors.Is(err, ErrNoRefreshToken) {
+		t.Errorf("expected ErrNoRefreshToken for another client, got %v", err)
+	}
+}
+
+func TestFileTokenStore(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "refresh-token")
+	testTokenStoreRoundTrip(t, NewFileTokenStore(file))
+
+	if runtime.GOOS != "windows" {
+		fi, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := fi.Mode().Perm(); perm != 0600 {
+			t.Errorf("expected the file to be readable by the owner only, got %v", perm)
+		}
+	}
+
+	// the temporary files are removed
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the token file, got %d files", len(entries))
+	}
+}
+
+func TestKeychainAccount(t *testing.T) {
+	for _, tc := range []struct {
+		key      OIDCTokenKey
+		expected string
+	}{
+		{OIDCTokenKey{Issuer: "https://idp.example.com/token", ClientID: "cli"}, "cli@https://idp.example.com/token"},
+		{OIDCTokenKey{Issuer: "https://idp.example.com/token", ClientID: "cli", Audience: "mysql"}, "cli@https://idp.example.com/token#mysql"},
+	} {
+		if got := keychainAccount(tc.key); got != tc.expected {
+			t.Errorf("expected %q, got %q", tc.expected, got)
+		}
+	}
+}
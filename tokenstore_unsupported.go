@@ -0,0 +1,22 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build !darwin && !windows && !linux && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !darwin,!windows,!linux,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package mysql
+
+import "context"
+
+func keychainLoad(ctx context.Context, service, account string) (string, error) {
+	return "", ErrKeychainUnsupported
+}
+
+func keychainSave(ctx context.Context, service, account, token string) error {
+	return ErrKeychainUnsupported
+}
@@ -0,0 +1,115 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RecordTraffic records the protocol packets of every connection into a new
+// file in dir, for debugging protocol issues. The traces can be replayed
+// against the driver with mysqltest.ReadTrace, which turns them into test
+// fixtures.
+//
+// The packets the client sends while authenticating, including
+// COM_CHANGE_USER, are redacted, as they contain passwords or tokens. All
+// other packets are recorded as is, including statements and result sets,
+// so traces may contain sensitive data.
+//
+// Trace files are text files with one packet per line: '>' for packets sent
+// to the server and '<' for packets received, followed by the sequence id
+// and the hex encoded payload or "redacted". Lines starting with '#' are
+// comments. Packets are recorded before compression and after TLS
+// decryption.
+func RecordTraffic(dir string) Option {
+	return func(cfg *Config) error {
+		cfg.recordTraffic = dir
+		return nil
+	}
+}
+
+// lastTraceID numbers the trace files of the process.
+var lastTraceID atomic.Uint64
+
+// trafficRecorder writes the packets of a connection to a trace file.
+type trafficRecorder struct {
+	mu sync.Mutex // the connection may be closed by the watcher
+	f  *os.File
+	w  *bufio.Writer
+}
+
+// newTrafficRecorder creates a new trace file in dir for a connection to
+// addr.
+func newTrafficRecorder(dir, addr string) (*trafficRecorder, error) {
+	now := time.Now()
+	name := fmt.Sprintf("mysql-%s-%d-%d.trace", now.Format("20060102T150405"), os.Getpid(), lastTraceID.Add(1))
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	r := &trafficRecorder{f: f, w: bufio.NewWriter(f)}
+	fmt.Fprintf(r.w, "# mysql traffic of %s at %s\n", addr, now.Format(time.RFC3339))
+	return r, nil
+}
+
+// record records the packet with sequence id seq and payload data, or
+// "redacted" instead of the payload if redacted is set.
+func (r *trafficRecorder) record(dir PacketDirection, seq uint8, data []byte, redacted bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return
+	}
+	c := '<'
+	if dir == PacketSent {
+		c = '>'
+	}
+	fmt.Fprintf(r.w, "%c %d ", c, seq)
+	if redacted {
+		r.w.WriteString("redacted")
+	} else {
+		hex.NewEncoder(r.w).Write(data)
+	}
+	r.w.WriteByte('\n')
+}
+
+func (r *trafficRecorder) close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.w.Flush()
+	if cerr := r.f.Close(); err == nil {
+		err = cerr
+	}
+	r.f = nil
+	return err
+}
+
+// startRecording starts recording the traffic of the connection if
+// configured. Failing to create the trace file doesn't fail the connection.
+func (mc *mysqlConn) startRecording() {
+	if mc.cfg.recordTraffic == "" {
+		return
+	}
+	r, err := newTrafficRecorder(mc.cfg.recordTraffic, mc.cfg.Addr)
+	if err != nil {
+		mc.warn("could not record traffic: ", err)
+		return
+	}
+	mc.recorder = r
+}
@@ -0,0 +1,106 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/colussim/mysql-auth-oidc-go/mysqltest"
+)
+
+// queryTrace runs a query on a new connection of c and returns the result.
+func queryTrace(t *testing.T, c *connector, query string) (string, error) {
+	db := sql.OpenDB(c)
+	defer db.Close()
+	var name string
+	err := db.QueryRowContext(context.Background(), query).Scan(&name)
+	return name, err
+}
+
+func TestRecordReplayTraffic(t *testing.T) {
+	const password = "s3cret-password"
+	srv := &mysqltest.Server{
+		AuthPlugin: "mysql_clear_password",
+		Authenticate: func(c mysqltest.Credentials) error {
+			if !c.CheckPassword(password) {
+				return &mysqltest.Error{Number: 1045, SQLState: "28000", Message: "Access denied"}
+			}
+			return nil
+		},
+	}
+	defer srv.Close()
+	srv.Handle("SELECT name FROM users WHERE id = 1", mysqltest.Result{
+		Columns: []string{"name"},
+		Rows:    [][]any{{"gopher"}},
+	})
+
+	dir := t.TempDir()
+	cfg := NewConfig()
+	cfg.User, cfg.Passwd = "app", password
+	cfg.AllowCleartextPasswords = true
+	cfg.DialFunc = srv.Dial
+	if err := cfg.Apply(RecordTraffic(dir)); err != nil {
+		t.Fatal(err)
+	}
+	if name, err := queryTrace(t, newConnector(cfg), "SELECT name FROM users WHERE id = 1"); err != nil || name != "gopher" {
+		t.Fatalf("expected gopher, got %q, %v", name, err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.trace"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one trace file, got %v, %v", files, err)
+	}
+	b, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := string(b)
+	if strings.Contains(recorded, hex.EncodeToString([]byte(password))) {
+		t.Error("expected the password to be redacted")
+	}
+	for _, want := range []string{
+		"> 1 redacted\n", // handshake response
+		"> 0 " + hex.EncodeToString([]byte("\x03SELECT name FROM users WHERE id = 1")) + "\n",
+		hex.EncodeToString([]byte("gopher")),
+	} {
+		if !strings.Contains(recorded, want) {
+			t.Errorf("expected the trace to contain %q, got:\n%s", want, recorded)
+		}
+	}
+
+	// Replay the session without the server and password
+	trace, err := mysqltest.ReadTrace(strings.NewReader(recorded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg = NewConfig()
+	cfg.User = "app"
+	cfg.AllowCleartextPasswords = true
+	cfg.DialFunc = trace.Dial
+	if name, err := queryTrace(t, newConnector(cfg), "SELECT name FROM users WHERE id = 1"); err != nil || name != "gopher" {
+		t.Fatalf("expected gopher when replaying, got %q, %v", name, err)
+	}
+	if err := trace.Err(); err != nil {
+		t.Errorf("expected the client to follow the trace, got %v", err)
+	}
+
+	// Deviating from the trace fails
+	if _, err := queryTrace(t, newConnector(cfg), "SELECT name FROM users WHERE id = 2"); err == nil {
+		t.Error("expected a different query to fail")
+	}
+	if trace.Err() == nil {
+		t.Error("expected the deviation to be reported")
+	}
+}
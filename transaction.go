@@ -8,8 +8,49 @@
 
 package mysql
 
+import "context"
+
+type consistentSnapshotCtxKey struct{}
+
+// WithConsistentSnapshot returns a copy of ctx which starts the transactions
+// begun with it WITH CONSISTENT SNAPSHOT: their reads see the data as of the
+// start of the transaction rather than as of their first read. This applies
+// to REPEATABLE READ transactions on InnoDB, and on MariaDB also returns the
+// binlog position of the snapshot in the binlog_snapshot_file and
+// binlog_snapshot_position status variables:
+//
+//	tx, err := db.BeginTx(mysql.WithConsistentSnapshot(ctx), &sql.TxOptions{ReadOnly: true})
+//
+// Transactions begun with the sql.LevelSnapshot isolation level always start
+// WITH CONSISTENT SNAPSHOT.
+func WithConsistentSnapshot(ctx context.Context) context.Context {
+	return context.WithValue(ctx, consistentSnapshotCtxKey{}, true)
+}
+
+func consistentSnapshotFromContext(ctx context.Context) bool {
+	v, _ := ctx.Value(consistentSnapshotCtxKey{}).(bool)
+	return v
+}
+
+// startTransactionQuery returns the statement starting a transaction with the
+// given characteristics.
+func startTransactionQuery(readOnly, consistentSnapshot bool) string {
+	q := "START TRANSACTION"
+	if consistentSnapshot {
+		q += " WITH CONSISTENT SNAPSHOT"
+		if readOnly {
+			q += ","
+		}
+	}
+	if readOnly {
+		q += " READ ONLY"
+	}
+	return q
+}
+
 type mysqlTx struct {
-	mc *mysqlConn
+	mc  *mysqlConn
+	ctx context.Context // context of BeginTx, of the hooks and the GTID of the commit
 }
 
 func (tx *mysqlTx) Commit() (err error) {
@@ -23,7 +64,11 @@ func (tx *mysqlTx) Commit() (err error) {
 		}
 		return
 	}
-	err = tx.mc.exec("COMMIT")
+	err = tx.mc.execHooked(tx.ctx, "COMMIT")
+	if err == nil {
+		recordGTID(tx.ctx, &tx.mc.result)
+	}
+	tx.mc.savepoints = nil
 	tx.mc = nil
 	return
 }
@@ -39,7 +84,8 @@ func (tx *mysqlTx) Rollback() (err error) {
 		}
 		return
 	}
-	err = tx.mc.exec("ROLLBACK")
+	err = tx.mc.execHooked(tx.ctx, "ROLLBACK")
+	tx.mc.savepoints = nil
 	tx.mc = nil
 	return
 }
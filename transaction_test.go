@@ -0,0 +1,94 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/colussim/mysql-auth-oidc-go/mysqltest"
+)
+
+func TestStartTransactionQuery(t *testing.T) {
+	for _, tt := range []struct {
+		readOnly, consistentSnapshot bool
+		want                         string
+	}{
+		{false, false, "START TRANSACTION"},
+		{true, false, "START TRANSACTION READ ONLY"},
+		{false, true, "START TRANSACTION WITH CONSISTENT SNAPSHOT"},
+		{true, true, "START TRANSACTION WITH CONSISTENT SNAPSHOT, READ ONLY"},
+	} {
+		if got := startTransactionQuery(tt.readOnly, tt.consistentSnapshot); got != tt.want {
+			t.Errorf("readOnly=%v, consistentSnapshot=%v: expected %q, got %q", tt.readOnly, tt.consistentSnapshot, tt.want, got)
+		}
+	}
+}
+
+func TestBeginTxOptions(t *testing.T) {
+	srv := &mysqltest.Server{}
+	defer srv.Close()
+	for _, q := range []string{
+		"START TRANSACTION READ ONLY",
+		"START TRANSACTION WITH CONSISTENT SNAPSHOT",
+		"START TRANSACTION WITH CONSISTENT SNAPSHOT, READ ONLY",
+		"ROLLBACK",
+	} {
+		srv.Handle(q, mysqltest.Result{})
+	}
+
+	cfg := NewConfig()
+	cfg.DialFunc = srv.Dial
+	c, err := NewConnector(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(c)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	for _, tt := range []struct {
+		ctx  context.Context
+		opts *sql.TxOptions
+	}{
+		{ctx, &sql.TxOptions{ReadOnly: true}},
+		{ctx, &sql.TxOptions{Isolation: sql.LevelSnapshot}},
+		{WithConsistentSnapshot(ctx), &sql.TxOptions{ReadOnly: true}},
+	} {
+		tx, err := db.BeginTx(tt.ctx, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Rollback(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelLinearizable}); err == nil {
+		t.Error("expected an unsupported isolation level to fail")
+	}
+
+	var begins []string
+	for _, q := range srv.Queries() {
+		if q != "ROLLBACK" {
+			begins = append(begins, q)
+		}
+	}
+	want := []string{
+		"START TRANSACTION READ ONLY",
+		"SET TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+		"START TRANSACTION WITH CONSISTENT SNAPSHOT",
+		"START TRANSACTION WITH CONSISTENT SNAPSHOT, READ ONLY",
+	}
+	if !reflect.DeepEqual(begins, want) {
+		t.Errorf("expected %q, got %q", want, begins)
+	}
+}
@@ -10,6 +10,7 @@ package mysql
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
 	"database/sql/driver"
 	"encoding/binary"
@@ -21,6 +22,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+	"unsafe"
 )
 
 // Registry for custom tls.Configs
@@ -55,7 +57,7 @@ var (
 //	})
 //	db, err := sql.Open("mysql", "user@tcp(localhost:3306)/test?tls=custom")
 func RegisterTLSConfig(key string, config *tls.Config) error {
-	if _, isBool := readBool(key); isBool || strings.ToLower(key) == "skip-verify" || strings.ToLower(key) == "preferred" {
+	if _, isBool := readBool(key); isBool || isTLSMode(strings.ToLower(key)) {
 		return fmt.Errorf("key '%s' is reserved", key)
 	}
 
@@ -87,6 +89,38 @@ func getTLSConfigClone(key string) (config *tls.Config) {
 	return
 }
 
+// verifyCAOnly returns a copy of c verifying the certificate chain of the
+// server against c.RootCAs without checking the host name, like the
+// verify-ca mode of the MySQL client. The VerifyConnection function of c, if
+// any, is still called. It is applied to the config of each TLS handshake
+// rather than to Config.TLS, so that the verification isn't wrapped again
+// when a Config is normalized again.
+func verifyCAOnly(c *tls.Config) *tls.Config {
+	c = c.Clone()
+	c.InsecureSkipVerify = true
+	roots, verifyConnection := c.RootCAs, c.VerifyConnection
+	c.VerifyConnection = func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("tls: server did not send a certificate")
+		}
+		opts := x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		if _, err := cs.PeerCertificates[0].Verify(opts); err != nil {
+			return err
+		}
+		if verifyConnection != nil {
+			return verifyConnection(cs)
+		}
+		return nil
+	}
+	return c
+}
+
 // Returns the bool value of the input.
 // The 2nd return value indicates if the input was a valid bool value
 func readBool(input string) (value bool, valid bool) {
@@ -105,11 +139,28 @@ func readBool(input string) (value bool, valid bool) {
 *                           Time related utils                                *
 ******************************************************************************/
 
+// zeroDateTimeValue returns the value of a zero DATE or DATETIME with
+// parseTime.
+func (cfg *Config) zeroDateTimeValue() (driver.Value, error) {
+	switch cfg.zeroDateTime {
+	case ZeroDateTimeNil:
+		return nil, nil
+	case ZeroDateTimeError:
+		return nil, ErrZeroDateTime
+	}
+	return time.Time{}, nil
+}
+
+// isZeroDateTime reports whether b is a zero DATE or DATETIME of the text
+// protocol.
+func isZeroDateTime(b []byte) bool {
+	return len(b) >= 10 && len(b) <= len(zeroDateTime) && string(b) == string(zeroDateTime[:len(b)])
+}
+
 func parseDateTime(b []byte, loc *time.Location) (time.Time, error) {
-	const base = "0000-00-00 00:00:00.000000"
 	switch len(b) {
 	case 10, 19, 21, 22, 23, 24, 25, 26: // up to "YYYY-MM-DD HH:MM:SS.MMMMMM"
-		if string(b) == base[:len(b)] {
+		if isZeroDateTime(b) {
 			return time.Time{}, nil
 		}
 
@@ -327,7 +378,7 @@ func appendDateTime(buf []byte, t time.Time, timeTruncate time.Duration) ([]byte
 	return append(buf, localBuf[:n]...), nil
 }
 
-// zeroDateTime is used in formatBinaryDateTime to avoid an allocation
+// zeroDateTime is used in appendBinaryDateTime to avoid an allocation
 // if the DATE or DATETIME has the zero value.
 // It must never be changed.
 // The current behavior depends on database/sql copying the result.
@@ -385,13 +436,14 @@ func appendMicrosecs(dst, src []byte, decimals int) []byte {
 	}
 }
 
-func formatBinaryDateTime(src []byte, length uint8) (driver.Value, error) {
+// appendBinaryDateTime appends the DATE or DATETIME value src of the binary
+// protocol to dst in text format.
+func appendBinaryDateTime(dst, src []byte, length uint8) ([]byte, error) {
 	// length expects the deterministic length of the zero value,
 	// negative time and 100+ hours are automatically added if needed
 	if len(src) == 0 {
-		return zeroDateTime[:length], nil
+		return append(dst, zeroDateTime[:length]...), nil
 	}
-	var dst []byte      // return value
 	var p1, p2, p3 byte // current digit pair
 
 	switch length {
@@ -412,7 +464,6 @@ func formatBinaryDateTime(src []byte, length uint8) (driver.Value, error) {
 		}
 		return nil, fmt.Errorf("illegal %s packet length %d", t, len(src))
 	}
-	dst = make([]byte, 0, length)
 	// start with the date
 	year := binary.LittleEndian.Uint16(src[:2])
 	pt := year / 100
@@ -444,13 +495,14 @@ func formatBinaryDateTime(src []byte, length uint8) (driver.Value, error) {
 	return appendMicrosecs(dst, src[2:], int(length)-20), nil
 }
 
-func formatBinaryTime(src []byte, length uint8) (driver.Value, error) {
+// appendBinaryTime appends the TIME value src of the binary protocol to dst in
+// text format.
+func appendBinaryTime(dst, src []byte, length uint8) ([]byte, error) {
 	// length expects the deterministic length of the zero value,
 	// negative time and 100+ hours are automatically added if needed
 	if len(src) == 0 {
-		return zeroDateTime[11 : 11+length], nil
+		return append(dst, zeroDateTime[11:11+length]...), nil
 	}
-	var dst []byte // return value
 
 	switch length {
 	case
@@ -464,8 +516,6 @@ func formatBinaryTime(src []byte, length uint8) (driver.Value, error) {
 	default:
 		return nil, fmt.Errorf("invalid TIME packet length %d", len(src))
 	}
-	// +2 to enable negative time and 100+ hours
-	dst = make([]byte, 0, length+2)
 	if src[0] == 1 {
 		dst = append(dst, '-')
 	}
@@ -502,45 +552,31 @@ func getUint24(data []byte) int {
 	return int(data[2])<<16 | int(data[1])<<8 | int(data[0])
 }
 
-func uint64ToString(n uint64) []byte {
-	var a [20]byte
-	i := 20
-
-	// U+0030 = 0
-	// ...
-	// U+0039 = 9
-
-	var q uint64
-	for n >= 10 {
-		i--
-		q = n / 10
-		a[i] = uint8(n-q*10) + 0x30
-		n = q
-	}
-
-	i--
-	a[i] = uint8(n) + 0x30
-
-	return a[i:]
-}
-
 // returns the string read as a bytes slice, whether the value is NULL,
 // the number of bytes read and an error, in case the string is longer than
 // the input slice
 func readLengthEncodedString(b []byte) ([]byte, bool, int, error) {
 	// Get length
 	num, isNull, n := readLengthEncodedInteger(b)
+	if len(b) < n {
+		return nil, false, n, io.EOF
+	}
 	if num < 1 {
 		return b[n:n], isNull, n, nil
 	}
 
-	n += int(num)
-
 	// Check data length
-	if len(b) >= n {
+	if uint64(len(b)-n) >= num {
+		n += int(num)
 		return b[n-int(num) : n : n], false, n, nil
 	}
-	return nil, false, n, io.EOF
+	return nil, false, len(b), io.EOF
+}
+
+// stringBytes returns the bytes of s without copying them. They must not be
+// modified.
+func stringBytes(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s))
 }
 
 // returns the number of bytes skipped and an error, in case the string is
@@ -548,20 +584,22 @@ func readLengthEncodedString(b []byte) ([]byte, bool, int, error) {
 func skipLengthEncodedString(b []byte) (int, error) {
 	// Get length
 	num, _, n := readLengthEncodedInteger(b)
+	if len(b) < n {
+		return n, io.EOF
+	}
 	if num < 1 {
 		return n, nil
 	}
 
-	n += int(num)
-
 	// Check data length
-	if len(b) >= n {
-		return n, nil
+	if uint64(len(b)-n) >= num {
+		return n + int(num), nil
 	}
-	return n, io.EOF
+	return len(b), io.EOF
 }
 
-// returns the number read, whether the value is NULL and the number of bytes read
+// returns the number read, whether the value is NULL and the number of bytes read.
+// Truncated values are returned as NULL, with a number of bytes read beyond b.
 func readLengthEncodedInteger(b []byte) (uint64, bool, int) {
 	// See issue #349
 	if len(b) == 0 {
@@ -575,14 +613,23 @@ func readLengthEncodedInteger(b []byte) (uint64, bool, int) {
 
 	// 252: value of following 2
 	case 0xfc:
+		if len(b) < 3 {
+			return 0, true, 3
+		}
 		return uint64(binary.LittleEndian.Uint16(b[1:])), false, 3
 
 	// 253: value of following 3
 	case 0xfd:
+		if len(b) < 4 {
+			return 0, true, 4
+		}
 		return uint64(getUint24(b[1:])), false, 4
 
 	// 254: value of following 8
 	case 0xfe:
+		if len(b) < 9 {
+			return 0, true, 9
+		}
 		return uint64(binary.LittleEndian.Uint64(b[1:])), false, 9
 	}
 
@@ -806,8 +853,7 @@ func namedValueToValue(named []driver.NamedValue) ([]driver.Value, error) {
 	dargs := make([]driver.Value, len(named))
 	for n, param := range named {
 		if len(param.Name) > 0 {
-			// TODO: support the use of Named Parameters #561
-			return nil, errors.New("mysql: driver does not support the use of Named Parameters")
+			return nil, fmt.Errorf("mysql: named parameter %q not found in the query", param.Name)
 		}
 		dargs[n] = param.Value
 	}
@@ -816,7 +862,7 @@ func namedValueToValue(named []driver.NamedValue) ([]driver.Value, error) {
 
 func mapIsolationLevel(level driver.IsolationLevel) (string, error) {
 	switch sql.IsolationLevel(level) {
-	case sql.LevelRepeatableRead:
+	case sql.LevelRepeatableRead, sql.LevelSnapshot:
 		return "REPEATABLE READ", nil
 	case sql.LevelReadCommitted:
 		return "READ COMMITTED", nil
@@ -10,9 +10,17 @@ package mysql
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"database/sql"
 	"database/sql/driver"
 	"encoding/binary"
+	"math/big"
+	"net"
 	"testing"
 	"time"
 )
@@ -54,7 +62,7 @@ func TestLengthEncodedInteger(t *testing.T) {
 	}
 }
 
-func TestFormatBinaryDateTime(t *testing.T) {
+func TestAppendBinaryDateTime(t *testing.T) {
 	rawDate := [11]byte{}
 	binary.LittleEndian.PutUint16(rawDate[:2], 1978)   // years
 	rawDate[2] = 12                                    // months
@@ -64,15 +72,11 @@ func TestFormatBinaryDateTime(t *testing.T) {
 	rawDate[6] = 23                                    // seconds
 	binary.LittleEndian.PutUint32(rawDate[7:], 987654) // microseconds
 	expect := func(expected string, inlen, outlen uint8) {
-		actual, _ := formatBinaryDateTime(rawDate[:inlen], outlen)
-		bytes, ok := actual.([]byte)
-		if !ok {
-			t.Errorf("formatBinaryDateTime must return []byte, was %T", actual)
-		}
-		if string(bytes) != expected {
+		actual, _ := appendBinaryDateTime([]byte("x"), rawDate[:inlen], outlen)
+		if string(actual) != "x"+expected {
 			t.Errorf(
 				"expected %q, got %q for length in %d, out %d",
-				expected, actual, inlen, outlen,
+				expected, actual[1:], inlen, outlen,
 			)
 		}
 	}
@@ -83,17 +87,13 @@ func TestFormatBinaryDateTime(t *testing.T) {
 	expect("1978-12-30 15:46:23.987654", 11, 26)
 }
 
-func TestFormatBinaryTime(t *testing.T) {
+func TestAppendBinaryTime(t *testing.T) {
 	expect := func(expected string, src []byte, outlen uint8) {
-		actual, _ := formatBinaryTime(src, outlen)
-		bytes, ok := actual.([]byte)
-		if !ok {
-			t.Errorf("formatBinaryDateTime must return []byte, was %T", actual)
-		}
-		if string(bytes) != expected {
+		actual, _ := appendBinaryTime([]byte("x"), src, outlen)
+		if string(actual) != "x"+expected {
 			t.Errorf(
 				"expected %q, got %q for src=%q and outlen=%d",
-				expected, actual, src, outlen)
+				expected, actual[1:], src, outlen)
 		}
 	}
 
@@ -208,6 +208,10 @@ func TestIsolationLevelMapping(t *testing.T) {
 			level:    driver.IsolationLevel(sql.LevelRepeatableRead),
 			expected: "REPEATABLE READ",
 		},
+		{
+			level:    driver.IsolationLevel(sql.LevelSnapshot),
+			expected: "REPEATABLE READ",
+		},
 		{
 			level:    driver.IsolationLevel(sql.LevelReadUncommitted),
 			expected: "READ UNCOMMITTED",
@@ -518,3 +522,128 @@ func TestParseDateTimeFail(t *testing.T) {
 		})
 	}
 }
+
+// testCertificate returns a certificate for host signed by parent, or a
+// self-signed CA certificate if parent is nil.
+func testCertificate(t *testing.T, host string, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	issuer, signer := tmpl, any(key)
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		issuer, signer = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, issuer, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestVerifyCAOnly(t *testing.T) {
+	ca := testCertificate(t, "ca", nil)
+	cert := testCertificate(t, "db.internal", &ca)
+	otherCA := testCertificate(t, "other-ca", nil)
+
+	// net.Pipe is unbuffered and deadlocks the TLS 1.3 handshake
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}}).Handshake()
+			conn.Close()
+		}
+	}()
+
+	handshake := func(roots *x509.CertPool) error {
+		client, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+		c := &tls.Config{RootCAs: roots, ServerName: "10.0.0.7"}
+		err = tls.Client(client, verifyCAOnly(c)).Handshake()
+		if c.InsecureSkipVerify || c.VerifyConnection != nil {
+			t.Error("expected the TLS config not to be modified")
+		}
+		return err
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+	if err := handshake(roots); err != nil {
+		t.Errorf("expected a certificate issued by the CA to be accepted regardless of the host name, got %v", err)
+	}
+
+	roots = x509.NewCertPool()
+	roots.AddCert(otherCA.Leaf)
+	if err := handshake(roots); err == nil {
+		t.Error("expected a certificate issued by another CA to be rejected")
+	}
+}
+
+func BenchmarkParseDateTime(b *testing.B) {
+	src := []byte("1978-12-30 15:46:23.987654")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := parseDateTime(src, time.UTC); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAppendDateTime(b *testing.B) {
+	t := time.Date(1978, 12, 30, 15, 46, 23, 987654000, time.UTC)
+	buf := make([]byte, 0, 32)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := appendDateTime(buf, t, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAppendBinaryDateTime(b *testing.B) {
+	src := []byte{0xba, 0x07, 12, 30, 15, 46, 23, 0x06, 0x12, 0x0f, 0x00}
+	buf := make([]byte, 0, 32)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := appendBinaryDateTime(buf, src, 26); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAppendBinaryTime(b *testing.B) {
+	src := []byte{1, 1, 0, 0, 0, 2, 3, 4}
+	buf := make([]byte, 0, 32)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := appendBinaryTime(buf, src, 8); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"sync"
+)
+
+// WarmUp establishes connections until n are ready in the connector, so
+// that database/sql fills its pool without handshakes when traffic arrives,
+// e.g. at the cold start of a serverless function. The connections are
+// established concurrently, within the limit of MaxConcurrentConnects, and
+// authenticate like Connect: the token of OIDCTokenProvider is fetched once
+// and shared.
+//
+// Connect hands out the ready connections before establishing new ones.
+// Those which turned stale in the meantime, e.g. closed by the server after
+// wait_timeout or authenticated with an expired OIDC token, are closed
+// instead. WarmUp returns the first error of the connects; the connections
+// established anyway are kept.
+//
+// WarmUp is a method of the driver.Connector returned by NewConnector:
+//
+//	err := connector.(interface {
+//		WarmUp(context.Context, int) error
+//	}).WarmUp(ctx, 10)
+//	db := sql.OpenDB(connector)
+//	db.SetMaxIdleConns(10)
+func (c *connector) WarmUp(ctx context.Context, n int) error {
+	missing := n - c.warmConns()
+	if missing <= 0 {
+		return nil
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	for i := 0; i < missing; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dc, err := c.establish(ctx)
+			if err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				errMu.Unlock()
+				return
+			}
+			c.warmMu.Lock()
+			c.warm = append(c.warm, dc.(*mysqlConn))
+			c.warmMu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// warmConns returns the number of connections of WarmUp not handed out yet.
+func (c *connector) warmConns() int {
+	c.warmMu.Lock()
+	defer c.warmMu.Unlock()
+	return len(c.warm)
+}
+
+// takeWarmConn returns a connection of WarmUp, nil if none is left. Stale
+// connections are closed.
+func (c *connector) takeWarmConn(ctx context.Context) *mysqlConn {
+	for {
+		c.warmMu.Lock()
+		if len(c.warm) == 0 {
+			c.warmMu.Unlock()
+			return nil
+		}
+		mc := c.warm[0]
+		c.warm = c.warm[1:]
+		c.warmMu.Unlock()
+
+		// the checks of database/sql for idle connections
+		if mc.IsValid() && mc.ResetSession(ctx) == nil {
+			return mc
+		}
+		mc.Close()
+	}
+}
+
+// Close closes the connections of WarmUp not handed out. sql.DB.Close calls
+// it.
+func (c *connector) Close() error {
+	c.warmMu.Lock()
+	warm := c.warm
+	c.warm = nil
+	c.warmMu.Unlock()
+	for _, mc := range warm {
+		mc.Close()
+	}
+	return nil
+}
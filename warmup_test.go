@@ -0,0 +1,119 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+)
+
+// newCountingMockServerConnector returns a mock server connector counting
+// its dials.
+func newCountingMockServerConnector(t *testing.T, dials *atomic.Int32) *connector {
+	c := newMockServerConnector(t, mockPacket(2, iOK, 0, 0, 2, 0, 0, 0))
+	dial := c.cfg.DialFunc
+	c.cfg.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials.Add(1)
+		return dial(ctx, network, addr)
+	}
+	return c
+}
+
+func TestConnectorWarmUp(t *testing.T) {
+	var dials atomic.Int32
+	c := newCountingMockServerConnector(t, &dials)
+	defer c.Close()
+	ctx := context.Background()
+
+	if err := c.WarmUp(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if n := dials.Load(); n != 3 {
+		t.Fatalf("expected 3 dials, got %d", n)
+	}
+	if w := c.ConnectStats().Warm; w != 3 {
+		t.Errorf("expected 3 warm connections, got %d", w)
+	}
+
+	// already warm
+	if err := c.WarmUp(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if n := dials.Load(); n != 3 {
+		t.Errorf("expected no dials, got %d", n-3)
+	}
+
+	for i := 0; i < 4; i++ {
+		conn, err := c.Connect(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+	}
+	if n := dials.Load(); n != 4 {
+		t.Errorf("expected the warm connections to be handed out first, got %d dials", n)
+	}
+	if w := c.ConnectStats().Warm; w != 0 {
+		t.Errorf("expected no warm connections left, got %d", w)
+	}
+}
+
+func TestConnectorWarmUpStale(t *testing.T) {
+	var dials atomic.Int32
+	c := newCountingMockServerConnector(t, &dials)
+	defer c.Close()
+	ctx := context.Background()
+
+	if err := c.WarmUp(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	stale := c.warm[0]
+	stale.Close()
+
+	conn, err := c.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if conn == stale || dials.Load() != 2 {
+		t.Errorf("expected the stale connection to be replaced, got %d dials", dials.Load())
+	}
+}
+
+func TestConnectorWarmUpError(t *testing.T) {
+	var dials atomic.Int32
+	c := newCountingMockServerConnector(t, &dials)
+	dial := c.cfg.DialFunc
+	var attempts atomic.Int32
+	c.cfg.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if attempts.Add(1) > 2 {
+			return nil, errors.New("connection refused")
+		}
+		return dial(ctx, network, addr)
+	}
+
+	if err := c.WarmUp(context.Background(), 3); err == nil {
+		t.Error("expected the failed connect to be reported")
+	}
+	if w := c.ConnectStats().Warm; w != 2 {
+		t.Errorf("expected the established connections to be kept, got %d", w)
+	}
+
+	// the warm connections are closed with the connector
+	warm := c.warm
+	c.Close()
+	for _, mc := range warm {
+		if !mc.closed.Load() {
+			t.Error("expected the warm connections to be closed")
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"strconv"
+)
+
+// Warning is a note, warning or error reported by SHOW WARNINGS.
+type Warning struct {
+	Level   string // "Note", "Warning" or "Error"
+	Code    uint16
+	Message string
+}
+
+// WarningsFunc is called with the warnings of a statement. It is called
+// synchronously on the goroutine using the connection and must not use the
+// connection.
+type WarningsFunc func(ctx context.Context, query string, warnings []Warning)
+
+// ShowWarnings sets fn to be called with the warnings of statements and
+// queries for which the server reports warnings, e.g. truncated values or
+// deprecated syntax. The warnings are fetched with SHOW WARNINGS when a
+// statement has been executed or the rows of a query are closed, which costs
+// a round trip for each statement with warnings.
+//
+// Like SHOW WARNINGS, only the warnings of the last statement of a query
+// with multiple statements are reported. Without ShowWarnings, the number of
+// warnings is available through Result.WarningCount.
+func ShowWarnings(fn WarningsFunc) Option {
+	return func(cfg *Config) error {
+		cfg.warningsFunc = fn
+		return nil
+	}
+}
+
+// reportWarnings fetches the warnings of the last statement and passes them
+// to the WarningsFunc, if the statement succeeded with warnings.
+func (mc *mysqlConn) reportWarnings(ctx context.Context, query string, err error) {
+	fn := mc.cfg.warningsFunc
+	if fn == nil || err != nil || mc.warnings == 0 || mc.closed.Load() {
+		return
+	}
+	warnings, err := mc.showWarnings()
+	if err != nil {
+		mc.warn("fetching warnings failed: ", err)
+		return
+	}
+	fn(ctx, query, warnings)
+}
+
+// showWarnings reads the warnings of the last statement with SHOW WARNINGS.
+func (mc *mysqlConn) showWarnings() ([]Warning, error) {
+	handleOk := mc.clearResult()
+	if err := mc.writeQueryPacket("SHOW WARNINGS"); err != nil {
+		return nil, err
+	}
+	resLen, _, err := handleOk.readResultSetHeaderPacket()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := new(textRows)
+	rows.mc = mc
+	if rows.rs.columns, err = mc.readColumns(resLen); err != nil {
+		return nil, err
+	}
+	if resLen < 3 {
+		return nil, mc.skipRows()
+	}
+
+	warnings := make([]Warning, 0, mc.warnings)
+	dest := make([]driver.Value, resLen)
+	for {
+		if err := rows.readRow(dest); err == io.EOF {
+			return warnings, nil
+		} else if err != nil {
+			return nil, err
+		}
+		var w Warning
+		if b, ok := dest[0].([]byte); ok {
+			w.Level = string(b)
+		}
+		switch code := dest[1].(type) {
+		case int64:
+			w.Code = uint16(code)
+		case uint64:
+			w.Code = uint16(code)
+		case []byte:
+			n, _ := strconv.ParseUint(string(code), 10, 16)
+			w.Code = uint16(n)
+		}
+		if b, ok := dest[2].([]byte); ok {
+			w.Message = string(b)
+		}
+		warnings = append(warnings, w)
+	}
+}
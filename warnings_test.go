@@ -0,0 +1,76 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+// mockVarCharColumn returns the definition of a VARCHAR column.
+func mockVarCharColumn(seq byte) []byte {
+	return mockPacket(seq,
+		3, 'd', 'e', 'f', 0, 0, 0, 1, 'v', 0, // catalog, schema, tables, names
+		0x0c, 0x21, 0, 0, 2, 0, 0, byte(fieldTypeVarString), 0, 0, 0, 0, 0)
+}
+
+func TestShowWarnings(t *testing.T) {
+	conn, mc := newRWMockConn(0)
+	var reported []Warning
+	var reportedQuery string
+	if err := mc.cfg.Apply(ShowWarnings(func(ctx context.Context, query string, warnings []Warning) {
+		reportedQuery, reported = query, warnings
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := "Data truncated for column 'name' at row 1"
+	row := []byte{7, 'W', 'a', 'r', 'n', 'i', 'n', 'g', 4, '1', '2', '6', '5', byte(len(msg))}
+	row = append(row, msg...)
+	var warnings []byte
+	warnings = append(warnings, mockPacket(1, 3)...)
+	warnings = append(warnings, mockVarCharColumn(2)...)
+	warnings = append(warnings, mockColumn(3)...)
+	warnings = append(warnings, mockVarCharColumn(4)...)
+	warnings = append(warnings, mockEOF(5, 0)...)
+	warnings = append(warnings, mockPacket(6, row...)...)
+	warnings = append(warnings, mockEOF(7, 0)...)
+
+	// OK packet with 1 affected row and 1 warning
+	conn.queuedReplies = [][]byte{
+		{7, 0, 0, 1, iOK, 1, 0, 2, 0, 1, 0},
+		warnings,
+	}
+	res, err := mc.ExecContext(context.Background(), "INSERT INTO t (name) VALUES ('too long')", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := res.(Result).WarningCount(); n != 1 {
+		t.Errorf("expected 1 warning to be counted, got %d", n)
+	}
+	if !bytes.HasSuffix(conn.written, []byte("SHOW WARNINGS")) {
+		t.Errorf("expected SHOW WARNINGS to be sent, got %q", conn.written)
+	}
+	expected := Warning{Level: "Warning", Code: 1265, Message: msg}
+	if reportedQuery != "INSERT INTO t (name) VALUES ('too long')" || len(reported) != 1 || reported[0] != expected {
+		t.Errorf("unexpected warnings of %q: %+v", reportedQuery, reported)
+	}
+
+	// no round trip without warnings
+	reported = nil
+	conn.written = nil
+	conn.queuedReplies = [][]byte{{7, 0, 0, 1, iOK, 1, 0, 2, 0, 0, 0}}
+	if _, err := mc.ExecContext(context.Background(), "INSERT INTO t (name) VALUES ('ok')", nil); err != nil {
+		t.Fatal(err)
+	}
+	if reported != nil || bytes.Contains(conn.written, []byte("SHOW WARNINGS")) {
+		t.Errorf("expected no warnings to be fetched, got %+v", reported)
+	}
+}
@@ -0,0 +1,227 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// maxXIDPartLen is the maximum length of the gtrid and bqual of an XID.
+const maxXIDPartLen = 64
+
+// XID identifies an XA transaction branch. All of its parts are sent, so
+// the zero FormatID is sent as 0 rather than omitted: set it to 1 to match
+// XIDs of other clients omitting it, the default of the server.
+type XID struct {
+	GTRID    []byte // global transaction identifier, 1 to 64 bytes
+	BQUAL    []byte // branch qualifier, up to 64 bytes
+	FormatID int    // format of GTRID and BQUAL
+}
+
+func (x XID) validate() error {
+	if len(x.GTRID) == 0 || len(x.GTRID) > maxXIDPartLen {
+		return fmt.Errorf("mysql: XID gtrid must be 1 to %d bytes, got %d", maxXIDPartLen, len(x.GTRID))
+	}
+	if len(x.BQUAL) > maxXIDPartLen {
+		return fmt.Errorf("mysql: XID bqual must be at most %d bytes, got %d", maxXIDPartLen, len(x.BQUAL))
+	}
+	return nil
+}
+
+// String returns the XID as used in XA statements, with gtrid and bqual as
+// hexadecimal literals.
+func (x XID) String() string {
+	return "X'" + hex.EncodeToString(x.GTRID) + "',X'" + hex.EncodeToString(x.BQUAL) + "'," + strconv.Itoa(x.FormatID)
+}
+
+// XAConn is implemented by the connections of this driver. It runs the
+// statements of XA transactions, for transaction managers coordinating
+// distributed transactions.
+//
+// This is accessible through sql.Conn.Raw(), so that all statements of a
+// transaction branch run on the same connection:
+//
+//	xid := mysql.XID{GTRID: []byte("order-42"), BQUAL: []byte("inventory"), FormatID: 1}
+//	err := conn.Raw(func(driverConn any) error {
+//		return driverConn.(mysql.XAConn).XAStart(ctx, xid)
+//	})
+//	... execute the statements of the branch with conn ...
+//	err = conn.Raw(func(driverConn any) error {
+//		xa := driverConn.(mysql.XAConn)
+//		if err := xa.XAEnd(ctx, xid); err != nil {
+//			return err
+//		}
+//		return xa.XAPrepare(ctx, xid)
+//	})
+//
+// A prepared transaction branch outlives the connection and can be committed
+// or rolled back from any connection, see XARecover.
+//
+// Like statements executed with the connection, the XA statements apply the
+// WithConfigOverride and WithTenant state of ctx and are reported to Hooks,
+// CollectStats and ErrorInterceptor.
+type XAConn interface {
+	// XAStart starts the transaction branch xid.
+	XAStart(ctx context.Context, xid XID) error
+
+	// XAEnd ends the statements of the transaction branch xid.
+	XAEnd(ctx context.Context, xid XID) error
+
+	// XAPrepare prepares the ended transaction branch xid for commit.
+	XAPrepare(ctx context.Context, xid XID) error
+
+	// XACommit commits the prepared transaction branch xid. With onePhase,
+	// the ended branch is prepared and committed at once.
+	XACommit(ctx context.Context, xid XID, onePhase bool) error
+
+	// XARollback rolls back the transaction branch xid.
+	XARollback(ctx context.Context, xid XID) error
+
+	// XARecover returns the prepared transaction branches on the server,
+	// to be committed or rolled back after a failure of the coordinator.
+	XARecover(ctx context.Context) ([]XID, error)
+}
+
+// execXA executes the XA statement stmt on xid, followed by suffix, with the
+// configuration override, tenant and hooks of ctx like ExecContext.
+func (mc *mysqlConn) execXA(ctx context.Context, stmt string, xid XID, suffix string) error {
+	if mc.closed.Load() {
+		return driver.ErrBadConn
+	}
+	if err := xid.validate(); err != nil {
+		return err
+	}
+	if err := mc.watchCancel(ctx); err != nil {
+		return err
+	}
+	defer mc.finish()
+
+	if err := mc.overrideConfig(ctx); err != nil {
+		return err
+	}
+	if err := mc.switchTenant(ctx); err != nil {
+		return err
+	}
+
+	query := stmt + " " + xid.String() + suffix
+	_, span := mc.startHook(ctx, HookInfo{Op: HookExec, Query: query})
+	return span.end(mc.markBadConn(mc.exec(query)))
+}
+
+func (mc *mysqlConn) XAStart(ctx context.Context, xid XID) error {
+	return mc.execXA(ctx, "XA START", xid, "")
+}
+
+func (mc *mysqlConn) XAEnd(ctx context.Context, xid XID) error {
+	return mc.execXA(ctx, "XA END", xid, "")
+}
+
+func (mc *mysqlConn) XAPrepare(ctx context.Context, xid XID) error {
+	return mc.execXA(ctx, "XA PREPARE", xid, "")
+}
+
+func (mc *mysqlConn) XACommit(ctx context.Context, xid XID, onePhase bool) error {
+	if onePhase {
+		return mc.execXA(ctx, "XA COMMIT", xid, " ONE PHASE")
+	}
+	return mc.execXA(ctx, "XA COMMIT", xid, "")
+}
+
+func (mc *mysqlConn) XARollback(ctx context.Context, xid XID) error {
+	return mc.execXA(ctx, "XA ROLLBACK", xid, "")
+}
+
+func (mc *mysqlConn) XARecover(ctx context.Context) ([]XID, error) {
+	if mc.closed.Load() {
+		return nil, driver.ErrBadConn
+	}
+	if err := mc.watchCancel(ctx); err != nil {
+		return nil, err
+	}
+	defer mc.finish()
+
+	if err := mc.overrideConfig(ctx); err != nil {
+		return nil, err
+	}
+	if err := mc.switchTenant(ctx); err != nil {
+		return nil, err
+	}
+
+	const query = "XA RECOVER"
+	ctx, span := mc.startHook(ctx, HookInfo{Op: HookQuery, Query: query})
+	rows, err := mc.query(query, nil)
+	if err = span.end(err); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	fetch := mc.startFetchHook(ctx, span, query)
+	xids, err := readRecoveredXIDs(rows)
+	if fetch != nil {
+		fetch.info.Rows = int64(len(xids))
+	}
+	return xids, fetch.end(err)
+}
+
+// readRecoveredXIDs reads the XIDs of the rows of XA RECOVER.
+func readRecoveredXIDs(rows *textRows) ([]XID, error) {
+	if len(rows.Columns()) < 4 {
+		return nil, errors.New("mysql: unexpected XA RECOVER result")
+	}
+
+	var xids []XID
+	dest := make([]driver.Value, len(rows.Columns()))
+	for {
+		if err := rows.Next(dest); err == io.EOF {
+			return xids, nil
+		} else if err != nil {
+			return nil, err
+		}
+		xid, err := parseRecoveredXID(dest)
+		if err != nil {
+			return nil, err
+		}
+		xids = append(xids, xid)
+	}
+}
+
+// parseRecoveredXID parses a row of XA RECOVER: formatID, gtrid_length,
+// bqual_length and data, the concatenated gtrid and bqual.
+func parseRecoveredXID(row []driver.Value) (XID, error) {
+	var nums [3]int
+	for i := range nums {
+		var err error
+		switch v := row[i].(type) {
+		case int64:
+			nums[i] = int(v)
+		case []byte:
+			nums[i], err = strconv.Atoi(string(v))
+		default:
+			err = fmt.Errorf("unexpected type %T", v)
+		}
+		if err != nil {
+			return XID{}, fmt.Errorf("mysql: invalid XA RECOVER column %d: %w", i, err)
+		}
+	}
+	data, _ := row[3].([]byte)
+	gtridLen, bqualLen := nums[1], nums[2]
+	if gtridLen < 0 || bqualLen < 0 || gtridLen+bqualLen != len(data) {
+		return XID{}, fmt.Errorf("mysql: invalid XA RECOVER data %q for lengths %d and %d", data, gtridLen, bqualLen)
+	}
+	return XID{
+		GTRID:    append([]byte(nil), data[:gtridLen]...),
+		BQUAL:    append([]byte(nil), data[gtridLen:]...),
+		FormatID: nums[0],
+	}, nil
+}
@@ -0,0 +1,169 @@
+// Go MySQL Driver - A MySQL-Driver for Go's database/sql package
+//
+// Copyright 2024 The Go-MySQL-Driver Authors. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/colussim/mysql-auth-oidc-go/mysqltest"
+)
+
+func TestXIDString(t *testing.T) {
+	xid := XID{GTRID: []byte("order-42"), BQUAL: []byte("inv'"), FormatID: 1}
+	if got, want := xid.String(), "X'6f726465722d3432',X'696e7627',1"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	// the zero format id isn't the default of the server
+	if got, want := (XID{GTRID: []byte("g")}).String(), "X'67',X'',0"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	for _, xid := range []XID{{}, {GTRID: bytes.Repeat([]byte("g"), 65)}, {GTRID: []byte("g"), BQUAL: bytes.Repeat([]byte("b"), 65)}} {
+		if err := xid.validate(); err == nil {
+			t.Errorf("expected %v to be invalid", xid)
+		}
+	}
+}
+
+func TestXATransaction(t *testing.T) {
+	xid := XID{GTRID: []byte("order-42"), BQUAL: []byte("inv"), FormatID: 7}
+	srv := &mysqltest.Server{}
+	defer srv.Close()
+	want := []string{
+		"XA START " + xid.String(),
+		"XA END " + xid.String(),
+		"XA PREPARE " + xid.String(),
+		"XA COMMIT " + xid.String(),
+		"XA ROLLBACK " + xid.String(),
+		"XA COMMIT " + xid.String() + " ONE PHASE",
+	}
+	for _, q := range want {
+		srv.Handle(q, mysqltest.Result{})
+	}
+	srv.Handle("XA RECOVER", mysqltest.Result{
+		Columns: []string{"formatID", "gtrid_length", "bqual_length", "data"},
+		Rows:    [][]any{{7, 8, 3, []byte("order-42inv")}, {1, 2, 0, []byte("tx")}},
+	})
+
+	cfg := NewConfig()
+	cfg.DialFunc = srv.Dial
+	c, err := NewConnector(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(c)
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var recovered []XID
+	if err := conn.Raw(func(driverConn any) error {
+		xa := driverConn.(XAConn)
+		for _, f := range []func(context.Context, XID) error{xa.XAStart, xa.XAEnd, xa.XAPrepare} {
+			if err := f(ctx, xid); err != nil {
+				return err
+			}
+		}
+		if err := xa.XACommit(ctx, xid, false); err != nil {
+			return err
+		}
+		if err := xa.XARollback(ctx, xid); err != nil {
+			return err
+		}
+		if err := xa.XACommit(ctx, xid, true); err != nil {
+			return err
+		}
+		if err := xa.XAStart(ctx, XID{}); err == nil {
+			t.Error("expected an empty XID to be rejected")
+		}
+		var err error
+		recovered, err = xa.XARecover(ctx)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := srv.Queries(); !reflect.DeepEqual(got, append(want, "XA RECOVER")) {
+		t.Errorf("expected %q, got %q", append(want, "XA RECOVER"), got)
+	}
+	wantXIDs := []XID{xid, {GTRID: []byte("tx"), FormatID: 1}}
+	if !reflect.DeepEqual(recovered, wantXIDs) {
+		t.Errorf("expected %v, got %v", wantXIDs, recovered)
+	}
+}
+
+func TestXAHooksAndTenant(t *testing.T) {
+	xid := XID{GTRID: []byte("order-42"), FormatID: 1}
+	srv := &mysqltest.Server{}
+	defer srv.Close()
+	srv.Handle("SET @tenant_id = 'a'", mysqltest.Result{})
+	srv.Handle("XA START "+xid.String(), mysqltest.Result{})
+	srv.Handle("XA RECOVER", mysqltest.Result{
+		Columns: []string{"formatID", "gtrid_length", "bqual_length", "data"},
+		Rows:    [][]any{{1, 8, 0, []byte("order-42")}},
+	})
+
+	var events []string
+	cfg := NewConfig()
+	cfg.DialFunc = srv.Dial
+	if err := cfg.Apply(
+		Hooks(recordingHook{"a", &events}),
+		TenantResolver(func(_ context.Context, tenantID string) (TenantSession, error) {
+			return TenantSession{Vars: map[string]string{"@tenant_id": "'" + tenantID + "'"}}, nil
+		}),
+	); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewConnector(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(c)
+	defer db.Close()
+	ctx := WithTenant(context.Background(), "a")
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	events = nil
+	if err := conn.Raw(func(driverConn any) error {
+		xa := driverConn.(XAConn)
+		if err := xa.XAStart(ctx, xid); err != nil {
+			return err
+		}
+		_, err := xa.XARecover(ctx)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := srv.Queries(), []string{"SET @tenant_id = 'a'", "XA START " + xid.String(), "XA RECOVER"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	want := []string{
+		`a before exec "XA START X'6f726465722d3432',X'',1"`,
+		"a after exec rows=0 affected=0 err=<nil>",
+		`a before query "XA RECOVER"`,
+		"a after query rows=0 affected=0 err=<nil>",
+		`a before fetch "XA RECOVER"`,
+		"a after fetch rows=1 affected=0 err=<nil>",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("expected %q, got %q", want, events)
+	}
+}